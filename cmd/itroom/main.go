@@ -2,20 +2,43 @@ package main
 
 import (
 	"log"
+	"os"
 
+	"github.com/MawCeron/it-room/internal/cli"
+	"github.com/MawCeron/it-room/internal/config"
 	"github.com/MawCeron/it-room/internal/db"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/service"
 	"github.com/MawCeron/it-room/internal/ui"
 )
 
 func main() {
-	// DB initialization
-	d, err := db.New("itroom.db")
+	cfg, err := config.Load(config.DefaultPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	// DB initialization, snapshotting the database first if configured
+	opts := db.Options{SnapshotDir: cfg.Backup.Dir, SnapshotKeep: cfg.Backup.Keep}
+	switch cfg.Backup.OnStartup {
+	case config.SnapshotNever:
+		opts.SnapshotDir = ""
+	case config.SnapshotAlways:
+		opts.SnapshotAll = true
+	}
+
+	// Subcommands run without the TUI, for scripts and cron jobs
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run("itroom.db", opts, cfg, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
+
+	d, err := db.Open("itroom.db", opts)
 	if err != nil {
 		log.Fatalf("failed to open DB: %v", err)
 	}
 	defer d.Close()
 
-	app := ui.NewApp(d)
+	app := ui.NewApp(service.New(repo.NewStores(d.Conn)), cfg)
 	if err := app.Run(); err != nil {
 		log.Fatalf("ui error: %v", err)
 	}
@@ -0,0 +1,474 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// assetOutput is an asset as returned by the API, with its warranty state
+type assetOutput struct {
+	*models.AssetSummary
+	WarrantyState string `json:"warranty_state"`
+}
+
+// assetInput holds the asset fields accepted by create and update
+// Catalog entries are given by name; omitted fields are left unchanged on
+// update and an empty warranty_end_date or notes clears them
+// A purchase_cost given without a currency is in the configured one, and a
+// null purchase_cost clears it along with its currency
+// Fields holds custom field values by field name, an empty value clearing
+// the field
+type assetInput struct {
+	AssetTag        *string `json:"asset_tag"`
+	Type            *string `json:"type"`
+	Make            *string `json:"make"`
+	Model           *string `json:"model"`
+	SerialNumber    *string `json:"serial_number"`
+	PurchaseDate    *string `json:"purchase_date"`
+	WarrantyEndDate *string `json:"warranty_end_date"`
+	Location        *string `json:"location"`
+	Status          *string `json:"status"`
+	Notes           *string `json:"notes"`
+
+	PurchaseCost  optionalNumber `json:"purchase_cost"`
+	Currency      *string        `json:"currency"`
+	Supplier      *string        `json:"supplier"`
+	PONumber      *string        `json:"po_number"`
+	InvoiceNumber *string        `json:"invoice_number"`
+
+	Fields map[string]string `json:"fields"`
+}
+
+// optionalNumber is a number field that tells an omitted value apart from
+// null
+type optionalNumber struct {
+	Set   bool
+	Value *float64
+}
+
+// UnmarshalJSON records that the field was given, null or not
+func (n *optionalNumber) UnmarshalJSON(b []byte) error {
+	n.Set = true
+	return json.Unmarshal(b, &n.Value)
+}
+
+// actionInput is the optional body of the assign, return and retire actions
+type actionInput struct {
+	Employee     string  `json:"employee"` // Email or unique name, for assign
+	Date         *string `json:"date"`
+	Due          *string `json:"due_date"`
+	Notes        *string `json:"notes"`
+	WithChildren bool    `json:"with_children"` // Also assign or return the assets linked under it
+}
+
+// listAssets returns a page of assets matching the query parameters
+// Pages are keyed on the sort column so they stay stable while assets are
+// added
+func (s *Server) listAssets(w http.ResponseWriter, r *http.Request) error {
+	q, err := s.assetQuery(r)
+	if err != nil {
+		return err
+	}
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		return err
+	}
+	var after *repo.AssetCursor
+	if cursor != "" {
+		after = &repo.AssetCursor{}
+		if err := decodeCursor(cursor, after); err != nil {
+			return errBadRequest("invalid cursor")
+		}
+	}
+
+	assetRepo := repo.NewAssetRepo(s.db.Conn)
+	assets, last, err := assetRepo.PageSummaries(q, after, limit)
+	if err != nil {
+		return err
+	}
+	page := &Page[assetOutput]{Items: []assetOutput{}}
+	if len(assets) == limit {
+		// A full page only has a successor if another row follows it
+		more, _, err := assetRepo.PageSummaries(q, last, 1)
+		if err != nil {
+			return err
+		}
+		if len(more) > 0 {
+			page.NextCursor = encodeCursor(last)
+		}
+	}
+	for _, a := range assets {
+		page.Items = append(page.Items, s.assetOutput(a))
+	}
+	return writeJSON(w, http.StatusOK, page)
+}
+
+// assetQuery builds the repository query from the status, category, make,
+// q, sort and desc parameters, and the repeatable field parameter, e.g.
+// field=RAM=16
+func (s *Server) assetQuery(r *http.Request) (repo.AssetQuery, error) {
+	params := r.URL.Query()
+	q := repo.AssetQuery{
+		SortColumn: params.Get("sort"),
+		Filter:     params.Get("q"),
+		Make:       params.Get("make"),
+	}
+	var err error
+	if q.Fields, err = fieldFilters(params["field"]); err != nil {
+		return q, err
+	}
+	if q.SortColumn == "" {
+		q.SortColumn = "asset_tag"
+	}
+	if _, ok := repo.AssetSortColumns[q.SortColumn]; !ok {
+		return q, errBadRequest("unknown sort column %q", q.SortColumn)
+	}
+	if v := params.Get("desc"); v != "" {
+		desc, err := strconv.ParseBool(v)
+		if err != nil {
+			return q, errBadRequest("desc must be true or false")
+		}
+		q.SortDesc = desc
+	}
+
+	assetRepo := repo.NewAssetRepo(s.db.Conn)
+	if v := params.Get("status"); v != "" {
+		st, err := assetRepo.FindAssetStatus(v)
+		if err != nil {
+			return q, lookupError(err, "status", v)
+		}
+		q.StatusID = st.StatusID
+	}
+	if v := params.Get("category"); v != "" {
+		cat, err := assetRepo.FindAssetCategory(v)
+		if err != nil {
+			return q, lookupError(err, "category", v)
+		}
+		q.CategoryID = cat.CategoryId
+	}
+	return q, nil
+}
+
+// getAsset returns one asset by tag
+func (s *Server) getAsset(w http.ResponseWriter, r *http.Request) error {
+	a, err := s.findAsset(r)
+	if err != nil {
+		return err
+	}
+	if err := s.loadFields(a); err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, s.assetOutput(a))
+}
+
+// createAsset adds an asset; it starts out Available unless a status is given
+func (s *Server) createAsset(w http.ResponseWriter, r *http.Request) error {
+	var in assetInput
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+	for field, value := range map[string]*string{
+		"asset_tag": in.AssetTag, "type": in.Type, "make": in.Make, "model": in.Model,
+		"serial_number": in.SerialNumber, "location": in.Location,
+	} {
+		if value == nil || strings.TrimSpace(*value) == "" {
+			return errValidation("%s is required", field)
+		}
+	}
+
+	a := models.Asset{PurchaseDate: today(), StatusID: models.StatusAvailable, CreatedBy: actor(r)}
+	if err := s.applyAssetInput(&in, &a); err != nil {
+		return err
+	}
+	if err := s.svc.CreateAsset(r.Context(), &a); err != nil {
+		return err
+	}
+
+	return s.writeAsset(w, http.StatusCreated, a.AssetID)
+}
+
+// updateAsset changes the fields present in the request body
+func (s *Server) updateAsset(w http.ResponseWriter, r *http.Request) error {
+	current, err := s.findAsset(r)
+	if err != nil {
+		return err
+	}
+	var in assetInput
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+
+	a := current.Asset
+	a.UpdatedBy = actor(r)
+	if err := s.applyAssetInput(&in, &a); err != nil {
+		return err
+	}
+	if err := s.svc.UpdateAsset(r.Context(), &a); err != nil {
+		return err
+	}
+
+	return s.writeAsset(w, http.StatusOK, a.AssetID)
+}
+
+// applyAssetInput copies the fields present in in to a, resolving catalog
+// names to IDs
+func (s *Server) applyAssetInput(in *assetInput, a *models.Asset) error {
+	assetRepo := repo.NewAssetRepo(s.db.Conn)
+
+	if in.AssetTag != nil {
+		a.AssetTag = strings.TrimSpace(*in.AssetTag)
+	}
+	if in.Make != nil {
+		a.Maker = strings.TrimSpace(*in.Make)
+	}
+	if in.Model != nil {
+		a.Model = strings.TrimSpace(*in.Model)
+	}
+	if in.SerialNumber != nil {
+		a.SerialNumber = strings.TrimSpace(*in.SerialNumber)
+	}
+	if in.Notes != nil {
+		a.Notes = optional(in.Notes)
+	}
+	if in.Fields != nil {
+		a.Fields = in.Fields
+	}
+	if in.PurchaseCost.Set {
+		a.PurchaseCost = in.PurchaseCost.Value
+		if a.PurchaseCost == nil {
+			a.Currency = nil
+		} else if a.Currency == nil {
+			a.Currency = optional(&s.cfg.Currency)
+		}
+	}
+	if in.Currency != nil {
+		a.Currency = optional(in.Currency)
+	}
+	if in.Supplier != nil {
+		a.Supplier = optional(in.Supplier)
+	}
+	if in.PONumber != nil {
+		a.PONumber = optional(in.PONumber)
+	}
+	if in.InvoiceNumber != nil {
+		a.InvoiceNumber = optional(in.InvoiceNumber)
+	}
+	if in.PurchaseDate != nil {
+		d, err := parseDate("purchase_date", in.PurchaseDate)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			return errValidation("purchase_date cannot be empty")
+		}
+		a.PurchaseDate = *d
+	}
+	if in.WarrantyEndDate != nil {
+		d, err := parseDate("warranty_end_date", in.WarrantyEndDate)
+		if err != nil {
+			return err
+		}
+		a.WarrantyEndDate = d
+	}
+	if in.Type != nil {
+		t, err := assetRepo.FindAssetType(*in.Type)
+		if err != nil {
+			return lookupError(err, "asset type", *in.Type)
+		}
+		a.TypeID = t.TypeID
+	}
+	if in.Location != nil {
+		l, err := repo.NewLocationRepo(s.db.Conn).FindByName(*in.Location)
+		if err != nil {
+			return lookupError(err, "location", *in.Location)
+		}
+		a.LocationID = l.LocationID
+	}
+	if in.Status != nil {
+		st, err := assetRepo.FindAssetStatus(*in.Status)
+		if err != nil {
+			return lookupError(err, "status", *in.Status)
+		}
+		a.StatusID = st.StatusID
+	}
+	return nil
+}
+
+// assignAsset hands an asset to an employee
+func (s *Server) assignAsset(w http.ResponseWriter, r *http.Request) error {
+	a, err := s.findAsset(r)
+	if err != nil {
+		return err
+	}
+	var in actionInput
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+	if strings.TrimSpace(in.Employee) == "" {
+		return errValidation("employee is required")
+	}
+
+	assignment := models.AssetAssignment{AssetID: a.AssetID, Notes: optional(in.Notes), AssignedBy: actor(r)}
+	if assignment.AssignmentDate, err = timestamp("date", in.Date); err != nil {
+		return err
+	}
+	if assignment.DueDate, err = parseDate("due_date", in.Due); err != nil {
+		return err
+	}
+	assign := s.svc.AssignAsset
+	if in.WithChildren {
+		assign = s.svc.AssignAssetWithChildren
+	}
+	if err := assign(r.Context(), &assignment, in.Employee); err != nil {
+		return err
+	}
+
+	return s.writeAsset(w, http.StatusOK, a.AssetID)
+}
+
+// returnAsset closes the open assignment of an asset
+func (s *Server) returnAsset(w http.ResponseWriter, r *http.Request) error {
+	a, in, err := s.assetAction(r)
+	if err != nil {
+		return err
+	}
+	when, err := timestamp("date", in.Date)
+	if err != nil {
+		return err
+	}
+	returnFn := s.svc.ReturnAsset
+	if in.WithChildren {
+		returnFn = s.svc.ReturnAssetWithChildren
+	}
+	if err := returnFn(r.Context(), a.AssetID, when, actor(r)); err != nil {
+		return err
+	}
+
+	return s.writeAsset(w, http.StatusOK, a.AssetID)
+}
+
+// retireAsset marks an asset as retired, returning it first if assigned
+func (s *Server) retireAsset(w http.ResponseWriter, r *http.Request) error {
+	a, in, err := s.assetAction(r)
+	if err != nil {
+		return err
+	}
+	when, err := timestamp("date", in.Date)
+	if err != nil {
+		return err
+	}
+	if err := s.svc.RetireAsset(r.Context(), a.AssetID, when, actor(r)); err != nil {
+		return err
+	}
+
+	return s.writeAsset(w, http.StatusOK, a.AssetID)
+}
+
+// assetAction finds the asset of an action and reads its optional body
+func (s *Server) assetAction(r *http.Request) (*models.AssetSummary, *actionInput, error) {
+	a, err := s.findAsset(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var in actionInput
+	if r.ContentLength != 0 {
+		if err := readJSON(r, &in); err != nil {
+			return nil, nil, err
+		}
+	}
+	return a, &in, nil
+}
+
+// getAssetTree returns the tree of linked assets an asset belongs to
+func (s *Server) getAssetTree(w http.ResponseWriter, r *http.Request) error {
+	a, err := s.findAsset(r)
+	if err != nil {
+		return err
+	}
+	tree, err := s.svc.AssetTree(r.Context(), a.AssetID)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, tree)
+}
+
+// listAssetAssignments returns the assignment history of an asset
+func (s *Server) listAssetAssignments(w http.ResponseWriter, r *http.Request) error {
+	a, err := s.findAsset(r)
+	if err != nil {
+		return err
+	}
+	assignments, err := repo.NewAssignmentRepo(s.db.Conn).ListByAsset(a.AssetID)
+	if err != nil {
+		return err
+	}
+	return writePage(w, r, assignments)
+}
+
+// listAssetMaintenance returns the maintenance logs of an asset
+func (s *Server) listAssetMaintenance(w http.ResponseWriter, r *http.Request) error {
+	a, err := s.findAsset(r)
+	if err != nil {
+		return err
+	}
+	logs, err := repo.NewMaintenanceRepo(s.db.Conn).ListByAsset(a.AssetID)
+	if err != nil {
+		return err
+	}
+	return writePage(w, r, logs)
+}
+
+// findAsset retrieves the asset named by the tag path parameter
+func (s *Server) findAsset(r *http.Request) (*models.AssetSummary, error) {
+	return s.svc.AssetByTag(r.Context(), r.PathValue("tag"))
+}
+
+// writeAsset renders the stored state of an asset
+func (s *Server) writeAsset(w http.ResponseWriter, status int, assetID string) error {
+	a, err := repo.NewAssetRepo(s.db.Conn).GetSummary(assetID)
+	if err != nil {
+		return err
+	}
+	if err := s.loadFields(a); err != nil {
+		return err
+	}
+	return writeJSON(w, status, s.assetOutput(a))
+}
+
+// assetOutput pairs an asset with its warranty state as of today
+func (s *Server) assetOutput(a *models.AssetSummary) assetOutput {
+	state := a.WarrantyStateOn(today(), s.cfg.WarrantySoonDays())
+	return assetOutput{AssetSummary: a, WarrantyState: state.String()}
+}
+
+// lookupError reports a catalog name that matched nothing as a validation
+// error
+func lookupError(err error, what, name string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return errValidation("%s %q not found", what, name)
+	}
+	return err
+}
+
+// today returns the current date at midnight UTC
+func today() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// timestamp parses an optional YYYY-MM-DD date, defaulting to now
+func timestamp(field string, value *string) (time.Time, error) {
+	d, err := parseDate(field, value)
+	if err != nil || d == nil {
+		return time.Now().UTC().Truncate(time.Second), err
+	}
+	return *d, nil
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/service"
+)
+
+// maintenanceInput holds the fields of a new maintenance log
+type maintenanceInput struct {
+	AssetTag    string   `json:"asset_tag"`
+	Type        string   `json:"type"`
+	Date        *string  `json:"maintenance_date"`
+	Cost        *float64 `json:"cost"`
+	Description string   `json:"description"`
+	PerformedBy *string  `json:"performed_by"`
+}
+
+// listAssignments returns the asset assignments, oldest first
+// The open parameter keeps only current assignments and employee_id only
+// those of one employee
+func (s *Server) listAssignments(w http.ResponseWriter, r *http.Request) error {
+	params := r.URL.Query()
+	var openOnly bool
+	if v := params.Get("open"); v != "" {
+		var err error
+		if openOnly, err = strconv.ParseBool(v); err != nil {
+			return errBadRequest("open must be true or false")
+		}
+	}
+	employeeID := 0
+	if v := params.Get("employee_id"); v != "" {
+		var err error
+		if employeeID, err = strconv.Atoi(v); err != nil {
+			return errBadRequest("employee_id must be a number")
+		}
+	}
+
+	assignments, err := repo.NewAssignmentRepo(s.db.Conn).List()
+	if err != nil {
+		return err
+	}
+	out := []*models.AssetAssignment{}
+	for _, a := range assignments {
+		if openOnly && a.ReturnDate != nil || employeeID != 0 && a.EmployeeID != employeeID {
+			continue
+		}
+		out = append(out, a)
+	}
+	return writePage(w, r, out)
+}
+
+// listMaintenance returns the maintenance logs, oldest first, optionally of
+// one maintenance type
+func (s *Server) listMaintenance(w http.ResponseWriter, r *http.Request) error {
+	logs, err := repo.NewMaintenanceRepo(s.db.Conn).List()
+	if err != nil {
+		return err
+	}
+	typeName := r.URL.Query().Get("type")
+	out := []*models.MaintenanceLog{}
+	for _, m := range logs {
+		if typeName != "" && !strings.EqualFold(m.TypeName, typeName) {
+			continue
+		}
+		out = append(out, m)
+	}
+	return writePage(w, r, out)
+}
+
+// createMaintenance records maintenance performed on an asset
+func (s *Server) createMaintenance(w http.ResponseWriter, r *http.Request) error {
+	var in maintenanceInput
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+	if strings.TrimSpace(in.AssetTag) == "" || strings.TrimSpace(in.Type) == "" || strings.TrimSpace(in.Description) == "" {
+		return errValidation("asset_tag, type and description are required")
+	}
+
+	a, err := s.svc.AssetByTag(r.Context(), in.AssetTag)
+	if errors.Is(err, service.ErrNotFound) {
+		return errValidation("asset %q not found", in.AssetTag)
+	} else if err != nil {
+		return err
+	}
+	m := models.MaintenanceLog{
+		AssetID:     a.AssetID,
+		TypeName:    in.Type,
+		Cost:        in.Cost,
+		Description: in.Description,
+		PerformedBy: optional(in.PerformedBy),
+		RecordedBy:  actor(r),
+	}
+	if m.MaintenanceDate, err = timestamp("maintenance_date", in.Date); err != nil {
+		return err
+	}
+	if err := s.svc.LogMaintenance(r.Context(), &m); err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, m)
+}
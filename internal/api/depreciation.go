@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// depreciationInput holds the depreciation rule of a category
+// Declining balance rules without a factor are double declining
+type depreciationInput struct {
+	Method          string   `json:"method"`
+	UsefulLifeYears int      `json:"useful_life_years"`
+	SalvagePercent  float64  `json:"salvage_percent"`
+	Factor          *float64 `json:"factor"`
+}
+
+// listDepreciation returns the depreciation rule of each category that has
+// one
+func (s *Server) listDepreciation(w http.ResponseWriter, r *http.Request) error {
+	rules, err := repo.NewDepreciationRepo(s.db.Conn).List()
+	if err != nil {
+		return err
+	}
+	return writePage(w, r, rules)
+}
+
+// setDepreciation sets how the assets of a category depreciate, replacing
+// the rule it had
+func (s *Server) setDepreciation(w http.ResponseWriter, r *http.Request) error {
+	cat, err := repo.NewAssetRepo(s.db.Conn).FindAssetCategory(r.PathValue("category"))
+	if err != nil {
+		return lookupError(err, "category", r.PathValue("category"))
+	}
+	var in depreciationInput
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+
+	rule := models.DepreciationRule{CategoryID: cat.CategoryId, Method: in.Method, UsefulLifeYears: in.UsefulLifeYears,
+		SalvagePercent: in.SalvagePercent, Factor: in.Factor, UpdatedBy: actor(r)}
+	if rule.Method == "" {
+		rule.Method = models.DepreciationStraightLine
+	}
+	if err := s.svc.SetDepreciationRule(r.Context(), &rule); err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, rule)
+}
+
+// deleteDepreciation removes the depreciation rule of a category, returning
+// the rule
+func (s *Server) deleteDepreciation(w http.ResponseWriter, r *http.Request) error {
+	cat, err := repo.NewAssetRepo(s.db.Conn).FindAssetCategory(r.PathValue("category"))
+	if err != nil {
+		return lookupError(err, "category", r.PathValue("category"))
+	}
+	rule, err := s.svc.DeleteDepreciationRule(r.Context(), cat.CategoryId)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, rule)
+}
+
+// bookValueReport returns what the assets with a purchase cost matching the
+// asset query parameters are worth on the date parameter, today by default
+// The by parameter sums them by category or location instead of listing
+// each asset
+func (s *Server) bookValueReport(w http.ResponseWriter, r *http.Request) error {
+	q, err := s.assetQuery(r)
+	if err != nil {
+		return err
+	}
+	by := r.URL.Query().Get("by")
+	if by == "" {
+		by = "asset"
+	}
+	if by != "asset" && by != "category" && by != "location" {
+		return errBadRequest("by must be asset, category or location")
+	}
+	date := r.URL.Query().Get("date")
+	d, err := parseDate("date", &date)
+	if err != nil {
+		return err
+	}
+	day := today()
+	if d != nil {
+		day, q.AsOf = *d, d
+	} else {
+		q.ExcludeRetired = true
+	}
+
+	values, err := s.svc.BookValues(r.Context(), q, day)
+	if err != nil {
+		return err
+	}
+	switch by {
+	case "category":
+		return writePage(w, r, models.TotalBookValues(values, func(v *models.BookValue) string { return v.CategoryName }))
+	case "location":
+		return writePage(w, r, models.TotalBookValues(values, func(v *models.BookValue) string { return v.LocationName }))
+	}
+	return writePage(w, r, values)
+}
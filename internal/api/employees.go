@@ -0,0 +1,90 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// employeeInput holds the fields of a new employee
+type employeeInput struct {
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+// listEmployees returns the employees, optionally those whose name or email
+// contains the q parameter
+func (s *Server) listEmployees(w http.ResponseWriter, r *http.Request) error {
+	employeeRepo := repo.NewEmployeeRepo(s.db.Conn)
+	var employees []*models.Employee
+	var err error
+	if q := r.URL.Query().Get("q"); q != "" {
+		employees, err = employeeRepo.Search(q, -1)
+	} else {
+		employees, err = employeeRepo.List()
+	}
+	if err != nil {
+		return err
+	}
+	return writePage(w, r, employees)
+}
+
+// getEmployee returns one employee by ID
+func (s *Server) getEmployee(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return errBadRequest("employee ID must be a number")
+	}
+	e, err := repo.NewEmployeeRepo(s.db.Conn).Get(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errNotFound("employee %d not found", id)
+	} else if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, e)
+}
+
+// createEmployee adds an employee
+func (s *Server) createEmployee(w http.ResponseWriter, r *http.Request) error {
+	var in employeeInput
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+	e := models.Employee{FullName: in.FullName, Email: in.Email, CreatedBy: actor(r)}
+	if err := s.svc.CreateEmployee(r.Context(), &e); err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, e)
+}
+
+// listLocations returns every location
+func (s *Server) listLocations(w http.ResponseWriter, r *http.Request) error {
+	locations, err := repo.NewLocationRepo(s.db.Conn).List()
+	if err != nil {
+		return err
+	}
+	return writePage(w, r, locations)
+}
+
+// listLicenses returns the licenses, optionally those whose software name
+// contains the q parameter
+func (s *Server) listLicenses(w http.ResponseWriter, r *http.Request) error {
+	licenseRepo := repo.NewLicenseRepo(s.db.Conn)
+	var licenses []*models.SoftwareLicense
+	var err error
+	if q := r.URL.Query().Get("q"); q != "" {
+		licenses, err = licenseRepo.Search(q, -1)
+	} else {
+		licenses, err = licenseRepo.List()
+	}
+	if err != nil {
+		return err
+	}
+	auth.HideLicenseKeys(user(r), licenses)
+	return writePage(w, r, licenses)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// fieldInput holds the definition of a custom field
+// Exactly one of type and category is given, by name
+type fieldInput struct {
+	Name     string   `json:"name"`
+	Kind     string   `json:"kind"`
+	Type     *string  `json:"type"`
+	Category *string  `json:"category"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options"` // For enum fields
+	Pattern  *string  `json:"pattern"` // For text fields
+	Min      *float64 `json:"min"`     // For number fields
+	Max      *float64 `json:"max"`     // For number fields
+}
+
+// listFields returns the custom fields, or those that apply to the asset
+// type given as the type parameter
+func (s *Server) listFields(w http.ResponseWriter, r *http.Request) error {
+	fieldRepo := repo.NewFieldRepo(s.db.Conn)
+	var fields []*models.CustomField
+	var err error
+	if v := r.URL.Query().Get("type"); v != "" {
+		t, err := repo.NewAssetRepo(s.db.Conn).FindAssetType(v)
+		if err != nil {
+			return lookupError(err, "asset type", v)
+		}
+		fields, err = fieldRepo.ListForType(t.TypeID)
+	} else {
+		fields, err = fieldRepo.List()
+	}
+	if err != nil {
+		return err
+	}
+	return writePage(w, r, fields)
+}
+
+// createField defines a custom field for an asset type or category
+func (s *Server) createField(w http.ResponseWriter, r *http.Request) error {
+	var in fieldInput
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+	if (in.Type == nil) == (in.Category == nil) {
+		return errValidation("exactly one of type and category is required")
+	}
+
+	f := models.CustomField{Name: in.Name, Kind: in.Kind, Required: in.Required, Options: in.Options,
+		Pattern: optional(in.Pattern), Min: in.Min, Max: in.Max, CreatedBy: actor(r)}
+	if f.Kind == "" {
+		f.Kind = models.FieldText
+	}
+	assetRepo := repo.NewAssetRepo(s.db.Conn)
+	if in.Type != nil {
+		t, err := assetRepo.FindAssetType(*in.Type)
+		if err != nil {
+			return lookupError(err, "asset type", *in.Type)
+		}
+		f.TypeID = &t.TypeID
+	} else {
+		cat, err := assetRepo.FindAssetCategory(*in.Category)
+		if err != nil {
+			return lookupError(err, "category", *in.Category)
+		}
+		f.CategoryID = &cat.CategoryId
+	}
+	if err := s.svc.CreateCustomField(r.Context(), &f); err != nil {
+		return err
+	}
+
+	created, err := repo.NewFieldRepo(s.db.Conn).Get(f.FieldID)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, created)
+}
+
+// deleteField removes a custom field and its values, returning the field
+func (s *Server) deleteField(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return errBadRequest("invalid field ID %q", r.PathValue("id"))
+	}
+	f, err := s.svc.DeleteCustomField(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, f)
+}
+
+// fieldFilters reads the field parameters, each a name=value pair, into
+// custom field values by name
+func fieldFilters(params []string) (map[string]string, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	out := map[string]string{}
+	for _, p := range params {
+		name, value, ok := strings.Cut(p, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, errBadRequest("invalid field %q, expected name=value", p)
+		}
+		out[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return out, nil
+}
+
+// loadFields fills in the custom field values of an asset
+func (s *Server) loadFields(a *models.AssetSummary) error {
+	values, err := repo.NewFieldRepo(s.db.Conn).ListValues(a.AssetID)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		if a.Fields == nil {
+			a.Fields = map[string]string{}
+		}
+		a.Fields[v.FieldName] = v.Value
+	}
+	return nil
+}
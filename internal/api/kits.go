@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+// listKits returns the kits with their assets and state
+func (s *Server) listKits(w http.ResponseWriter, r *http.Request) error {
+	kits, err := s.svc.ListKits(r.Context())
+	if err != nil {
+		return err
+	}
+	return writePage(w, r, kits)
+}
+
+// getKit returns one kit with its assets and state
+func (s *Server) getKit(w http.ResponseWriter, r *http.Request) error {
+	k, err := s.svc.KitByTag(r.Context(), r.PathValue("tag"))
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, k)
+}
+
+// assignKit hands every asset of a kit to an employee
+func (s *Server) assignKit(w http.ResponseWriter, r *http.Request) error {
+	k, err := s.svc.KitByTag(r.Context(), r.PathValue("tag"))
+	if err != nil {
+		return err
+	}
+	var in actionInput
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+	if strings.TrimSpace(in.Employee) == "" {
+		return errValidation("employee is required")
+	}
+
+	assignment := models.AssetAssignment{Notes: optional(in.Notes), AssignedBy: actor(r)}
+	if assignment.AssignmentDate, err = timestamp("date", in.Date); err != nil {
+		return err
+	}
+	if assignment.DueDate, err = parseDate("due_date", in.Due); err != nil {
+		return err
+	}
+	if err := s.svc.AssignKit(r.Context(), k.KitID, assignment, in.Employee); err != nil {
+		return err
+	}
+
+	return s.writeKit(w, r, k.KitTag)
+}
+
+// returnKit returns the assigned assets of a kit
+func (s *Server) returnKit(w http.ResponseWriter, r *http.Request) error {
+	k, err := s.svc.KitByTag(r.Context(), r.PathValue("tag"))
+	if err != nil {
+		return err
+	}
+	var in actionInput
+	if r.ContentLength != 0 {
+		if err := readJSON(r, &in); err != nil {
+			return err
+		}
+	}
+	when, err := timestamp("date", in.Date)
+	if err != nil {
+		return err
+	}
+	if err := s.svc.ReturnKit(r.Context(), k.KitID, when, actor(r)); err != nil {
+		return err
+	}
+
+	return s.writeKit(w, r, k.KitTag)
+}
+
+// writeKit renders the stored state of a kit
+func (s *Server) writeKit(w http.ResponseWriter, r *http.Request, tag string) error {
+	k, err := s.svc.KitByTag(r.Context(), tag)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, k)
+}
@@ -0,0 +1,18 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPIDocument describes every endpoint of the API
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// openAPI serves the OpenAPI document
+func (s *Server) openAPI(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	_, err := w.Write(openAPIDocument)
+	return err
+}
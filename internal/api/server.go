@@ -0,0 +1,375 @@
+// Package api serves the inventory over a local HTTP JSON API
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/config"
+	"github.com/MawCeron/it-room/internal/db"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/service"
+)
+
+// Prefix is the path all API routes live under
+const Prefix = "/api/v1"
+
+// Page sizes accepted by the limit query parameter
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// Server routes API requests to the service layer
+type Server struct {
+	db     *db.DB
+	svc    *service.Service
+	cfg    *config.Config
+	mux    *http.ServeMux
+	logger *log.Logger
+}
+
+// New creates a server for the given database
+// Requests are logged to logger when it is not nil
+func New(d *db.DB, cfg *config.Config, logger *log.Logger) *Server {
+	s := &Server{db: d, svc: service.New(repo.NewStores(d.Conn)), cfg: cfg, mux: http.NewServeMux(), logger: logger}
+	s.routes()
+	return s
+}
+
+// routes registers every endpoint with the permission it requires
+func (s *Server) routes() {
+	s.handlePublic("GET /openapi.json", s.openAPI)
+
+	s.handle("GET /assets", auth.ViewInventory, s.listAssets)
+	s.handle("POST /assets", auth.EditAssets, s.createAsset)
+	s.handle("GET /assets/{tag}", auth.ViewInventory, s.getAsset)
+	s.handle("PATCH /assets/{tag}", auth.EditAssets, s.updateAsset)
+	s.handle("POST /assets/{tag}/assign", auth.AssignAssets, s.assignAsset)
+	s.handle("POST /assets/{tag}/return", auth.AssignAssets, s.returnAsset)
+	s.handle("POST /assets/{tag}/retire", auth.RetireAssets, s.retireAsset)
+	s.handle("GET /assets/{tag}/assignments", auth.ViewInventory, s.listAssetAssignments)
+	s.handle("GET /assets/{tag}/maintenance", auth.ViewInventory, s.listAssetMaintenance)
+	s.handle("GET /assets/{tag}/tree", auth.ViewInventory, s.getAssetTree)
+
+	s.handle("GET /employees", auth.ViewInventory, s.listEmployees)
+	s.handle("POST /employees", auth.ManageEmployees, s.createEmployee)
+	s.handle("GET /employees/{id}", auth.ViewInventory, s.getEmployee)
+
+	s.handle("GET /kits", auth.ViewInventory, s.listKits)
+	s.handle("GET /kits/{tag}", auth.ViewInventory, s.getKit)
+	s.handle("POST /kits/{tag}/assign", auth.AssignAssets, s.assignKit)
+	s.handle("POST /kits/{tag}/return", auth.AssignAssets, s.returnKit)
+
+	s.handle("GET /stock", auth.ViewInventory, s.listStock)
+	s.handle("GET /stock/{item}", auth.ViewInventory, s.getStockItem)
+	s.handle("POST /stock/{item}/adjust", auth.EditAssets, s.adjustStock)
+	s.handle("POST /stock/{item}/issue", auth.AssignAssets, s.issueStock)
+	s.handle("POST /stock/{item}/return", auth.AssignAssets, s.returnStock)
+
+	s.handle("GET /fields", auth.ViewInventory, s.listFields)
+	s.handle("POST /fields", auth.ManageFields, s.createField)
+	s.handle("DELETE /fields/{id}", auth.ManageFields, s.deleteField)
+
+	s.handle("GET /depreciation", auth.ViewInventory, s.listDepreciation)
+	s.handle("PUT /depreciation/{category}", auth.ManageDepreciation, s.setDepreciation)
+	s.handle("DELETE /depreciation/{category}", auth.ManageDepreciation, s.deleteDepreciation)
+	s.handle("GET /reports/book-value", auth.ViewInventory, s.bookValueReport)
+
+	s.handle("GET /locations", auth.ViewInventory, s.listLocations)
+	s.handle("GET /licenses", auth.ViewInventory, s.listLicenses)
+	s.handle("GET /assignments", auth.ViewInventory, s.listAssignments)
+	s.handle("GET /maintenance", auth.ViewInventory, s.listMaintenance)
+	s.handle("POST /maintenance", auth.LogMaintenance, s.createMaintenance)
+
+	s.mux.HandleFunc(Prefix+"/", func(w http.ResponseWriter, r *http.Request) {
+		s.writeError(w, errNotFound("no such endpoint"))
+	})
+}
+
+// handlerFunc is an endpoint returning an error to be rendered as JSON
+type handlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// handle registers an endpoint under Prefix, e.g. "GET /assets", that
+// requires permission p once authentication is enabled
+func (s *Server) handle(pattern string, p auth.Permission, h handlerFunc) {
+	s.handlePublic(pattern, func(w http.ResponseWriter, r *http.Request) error {
+		u, err := s.authenticate(r)
+		if err != nil {
+			return err
+		}
+		if err := auth.Check(u, p); err != nil {
+			return err
+		}
+		return h(w, r.WithContext(context.WithValue(r.Context(), userKey{}, u)))
+	})
+}
+
+// handlePublic registers an endpoint under Prefix open to anyone
+func (s *Server) handlePublic(pattern string, h handlerFunc) {
+	method, path, _ := strings.Cut(pattern, " ")
+	s.mux.HandleFunc(method+" "+Prefix+path, func(w http.ResponseWriter, r *http.Request) {
+		if err := h(w, r); err != nil {
+			s.writeError(w, err)
+		}
+	})
+}
+
+// userKey is the request context key of the signed in user
+type userKey struct{}
+
+// authenticate returns the user signed in by the Authorization header,
+// either a bearer API token or basic username and password
+// It returns nil while no user exists and authentication is disabled
+func (s *Server) authenticate(r *http.Request) (*models.User, error) {
+	users := repo.NewUserRepo(s.db.Conn)
+	enabled, err := auth.Enabled(users)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	var u *models.User
+	header := r.Header.Get("Authorization")
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		u, err = auth.LoginToken(users, strings.TrimSpace(token))
+	} else if username, password, ok := r.BasicAuth(); ok {
+		u, err = auth.Login(users, username, password)
+	} else {
+		return nil, errUnauthorized("authentication required")
+	}
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		return nil, errUnauthorized("%v", err)
+	}
+	return u, err
+}
+
+// actor returns the username changes made by a request are attributed to
+func actor(r *http.Request) *string {
+	return auth.Actor(user(r))
+}
+
+// user returns who signed the request, nil when authentication is not
+// enabled
+func user(r *http.Request) *models.User {
+	u, _ := r.Context().Value(userKey{}).(*models.User)
+	return u
+}
+
+// ServeHTTP implements http.Handler, logging each request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.mux.ServeHTTP(rec, r)
+	if s.logger != nil {
+		s.logger.Printf("%s %s %d %s", r.Method, r.URL.RequestURI(), rec.status, time.Since(start).Round(time.Microsecond))
+	}
+}
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Error is an API error rendered as {"error": {"code": ..., "message": ...}}
+type Error struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func errBadRequest(format string, args ...any) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: "invalid_request", Message: fmt.Sprintf(format, args...)}
+}
+
+func errValidation(format string, args ...any) *Error {
+	return &Error{Status: http.StatusUnprocessableEntity, Code: "validation_failed", Message: fmt.Sprintf(format, args...)}
+}
+
+func errUnauthorized(format string, args ...any) *Error {
+	return &Error{Status: http.StatusUnauthorized, Code: "unauthorized", Message: fmt.Sprintf(format, args...)}
+}
+
+func errNotFound(format string, args ...any) *Error {
+	return &Error{Status: http.StatusNotFound, Code: "not_found", Message: fmt.Sprintf(format, args...)}
+}
+
+func errConflict(format string, args ...any) *Error {
+	return &Error{Status: http.StatusConflict, Code: "conflict", Message: fmt.Sprintf(format, args...)}
+}
+
+// writeError renders err, mapping domain and repository errors to status
+// codes
+// Unexpected errors are reported as internal without their details, which
+// go to the server's logger
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	var apiErr *Error
+	var domainErr *service.Error
+	switch {
+	case errors.As(err, &apiErr):
+	case errors.As(err, &domainErr):
+		switch domainErr.Kind {
+		case service.ErrNotFound:
+			apiErr = errNotFound("%s", domainErr.Message)
+		case service.ErrConflict:
+			apiErr = errConflict("%s", domainErr.Message)
+		default:
+			apiErr = errValidation("%s", domainErr.Message)
+		}
+	case errors.Is(err, auth.ErrForbidden):
+		apiErr = &Error{Status: http.StatusForbidden, Code: "forbidden", Message: err.Error()}
+	case errors.Is(err, sql.ErrNoRows):
+		apiErr = errNotFound("not found")
+	case errors.Is(err, repo.ErrAssetAssigned), errors.Is(err, repo.ErrAssetNotAssigned), errors.Is(err, repo.ErrAssetRetired),
+		errors.Is(err, repo.ErrAssetUnderMaintenance):
+		apiErr = errConflict("%v", err)
+	case strings.Contains(err.Error(), "UNIQUE constraint failed: "):
+		apiErr = errConflict("%s already exists", uniqueColumn(err))
+	default:
+		if s.logger != nil {
+			s.logger.Printf("api: %v", err)
+		}
+		apiErr = &Error{Status: http.StatusInternalServerError, Code: "internal", Message: "internal error"}
+	}
+	if apiErr.Status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer, Basic realm="itroom"`)
+	}
+	writeJSON(w, apiErr.Status, map[string]*Error{"error": apiErr})
+}
+
+// uniqueColumn returns the column named by a SQLite unique constraint error,
+// e.g. asset_tag for "UNIQUE constraint failed: assets.asset_tag (2067)"
+func uniqueColumn(err error) string {
+	_, column, _ := strings.Cut(err.Error(), "UNIQUE constraint failed: ")
+	column, _, _ = strings.Cut(column, " ")
+	if _, name, ok := strings.Cut(column, "."); ok {
+		return name
+	}
+	return "a record with the same value"
+}
+
+// writeJSON renders v with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+// readJSON decodes a request body into v, rejecting unknown fields
+func readJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errBadRequest("invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+// Page is a page of list results
+// NextCursor is passed as the cursor parameter to fetch the following page
+// and is omitted on the last page
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// pageParams reads the limit and cursor query parameters
+func pageParams(r *http.Request) (int, string, error) {
+	limit := defaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLimit {
+			return 0, "", errBadRequest("limit must be between 1 and %d", maxLimit)
+		}
+		limit = n
+	}
+	return limit, r.URL.Query().Get("cursor"), nil
+}
+
+// paginate returns the page of items at the offset encoded in the cursor
+func paginate[T any](r *http.Request, items []T) (*Page[T], error) {
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		return nil, err
+	}
+	offset := 0
+	if cursor != "" {
+		if err := decodeCursor(cursor, &offset); err != nil || offset < 0 {
+			return nil, errBadRequest("invalid cursor")
+		}
+	}
+
+	page := &Page[T]{Items: []T{}}
+	if offset < len(items) {
+		end := min(offset+limit, len(items))
+		page.Items = items[offset:end]
+		if end < len(items) {
+			page.NextCursor = encodeCursor(end)
+		}
+	}
+	return page, nil
+}
+
+// encodeCursor serializes a pagination position into an opaque string
+func encodeCursor(v any) string {
+	b, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor parses a cursor produced by encodeCursor
+func decodeCursor(cursor string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// parseDate parses an optional YYYY-MM-DD value of the named field
+func parseDate(field string, value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(repo.DateLayout, *value)
+	if err != nil {
+		return nil, errValidation("%s must be a YYYY-MM-DD date", field)
+	}
+	return &t, nil
+}
+
+// optional returns nil for an empty or missing string
+func optional(s *string) *string {
+	if s == nil || strings.TrimSpace(*s) == "" {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	return &v
+}
+
+// writePage renders the page of items selected by the limit and cursor
+// parameters
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) error {
+	page, err := paginate(r, items)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, page)
+}
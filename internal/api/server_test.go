@@ -0,0 +1,284 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/api"
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/config"
+	"github.com/MawCeron/it-room/internal/db"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/service"
+)
+
+// Catalog IDs seeded by the schema
+const (
+	laptop   = 1
+	mainSite = 1
+)
+
+// logBuffer collects the server log, written from the handler goroutines
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// testServer is an API server over a fresh database
+type testServer struct {
+	*httptest.Server
+	db  *db.DB
+	svc *service.Service
+	log *logBuffer
+}
+
+func newServer(t *testing.T) *testServer {
+	t.Helper()
+	d, err := db.New(filepath.Join(t.TempDir(), "itroom.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := &logBuffer{}
+	srv := httptest.NewServer(api.New(d, config.Default(), log.New(logs, "", 0)))
+	t.Cleanup(func() {
+		srv.Close()
+		d.Close()
+	})
+	return &testServer{Server: srv, db: d, svc: service.New(repo.NewStores(d.Conn)), log: logs}
+}
+
+// addUser creates a user with the given role and returns an API token for it
+func (s *testServer) addUser(t *testing.T, username, role string) string {
+	t.Helper()
+	users := repo.NewUserRepo(s.db.Conn)
+	u := &models.User{Username: username, Role: role}
+	// Sign-ins go through tokens, so the password hash is never checked
+	if err := users.Create(u, "unused"); err != nil {
+		t.Fatal(err)
+	}
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := users.CreateToken(&models.APIToken{UserID: u.UserID, Name: "test"}, hash); err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// addAsset stores a laptop with the given tag
+func (s *testServer) addAsset(t *testing.T, tag string) {
+	t.Helper()
+	a := &models.Asset{AssetTag: tag, TypeID: laptop, SerialNumber: "SN-" + tag, Maker: "Dell", Model: "Latitude",
+		PurchaseDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), LocationID: mainSite}
+	if err := s.svc.CreateAsset(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// response is a decoded API response
+type response struct {
+	Status int
+	Body   string
+	Error  struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	Items      []map[string]any `json:"items"`
+	NextCursor string           `json:"next_cursor"`
+}
+
+// do sends a request signed with token, when not empty, and decodes the
+// response
+func (s *testServer) do(t *testing.T, method, path, token, body string) *response {
+	t.Helper()
+	req, err := http.NewRequest(method, s.URL+api.Prefix+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &response{Status: res.StatusCode, Body: string(b)}
+	if err := json.Unmarshal(b, out); err != nil {
+		t.Fatalf("%s %s: invalid JSON %q: %v", method, path, b, err)
+	}
+	return out
+}
+
+const newAssetBody = `{"asset_tag": "EQ-100", "type": "Laptop", "make": "Dell", "model": "Latitude",
+	"serial_number": "SN-100", "location": "Main"}`
+
+func TestAuthentication(t *testing.T) {
+	s := newServer(t)
+
+	// Until the first user exists every request is allowed
+	if res := s.do(t, "GET", "/assets", "", ""); res.Status != http.StatusOK {
+		t.Errorf("GET /assets without users = %d %s, want 200", res.Status, res.Body)
+	}
+
+	admin := s.addUser(t, "ada", models.RoleAdmin)
+	for _, token := range []string{"", "itr_unknown"} {
+		res := s.do(t, "GET", "/assets", token, "")
+		if res.Status != http.StatusUnauthorized || res.Error.Code != "unauthorized" {
+			t.Errorf("GET /assets with token %q = %d %s, want 401", token, res.Status, res.Body)
+		}
+	}
+	if res := s.do(t, "GET", "/assets", admin, ""); res.Status != http.StatusOK {
+		t.Errorf("GET /assets as admin = %d %s, want 200", res.Status, res.Body)
+	}
+	if res := s.do(t, "GET", "/openapi.json", "", ""); res.Status != http.StatusOK {
+		t.Errorf("GET /openapi.json without a token = %d, want 200", res.Status)
+	}
+}
+
+func TestViewerCannotWrite(t *testing.T) {
+	s := newServer(t)
+	s.addAsset(t, "EQ-001")
+	viewer := s.addUser(t, "vera", models.RoleViewer)
+
+	for _, tt := range []struct{ method, path, body string }{
+		{"POST", "/assets", newAssetBody},
+		{"PATCH", "/assets/EQ-001", `{"notes": "dented"}`},
+		{"POST", "/assets/EQ-001/assign", `{"employee": "ana@example.com"}`},
+		{"POST", "/assets/EQ-001/retire", `{}`},
+		{"POST", "/employees", `{"full_name": "Luis", "email": "luis@example.com"}`},
+		{"POST", "/maintenance", `{"asset_tag": "EQ-001", "type": "Repair", "description": "Screen"}`},
+	} {
+		res := s.do(t, tt.method, tt.path, viewer, tt.body)
+		if res.Status != http.StatusForbidden || res.Error.Code != "forbidden" {
+			t.Errorf("%s %s as viewer = %d %s, want 403", tt.method, tt.path, res.Status, res.Body)
+		}
+	}
+	if res := s.do(t, "GET", "/assets/EQ-001", viewer, ""); res.Status != http.StatusOK {
+		t.Errorf("GET /assets/EQ-001 as viewer = %d %s, want 200", res.Status, res.Body)
+	}
+	if res := s.do(t, "PATCH", "/assets/EQ-001", viewer, `{"notes": "dented"}`); !strings.Contains(res.Error.Message, "viewer") {
+		t.Errorf("403 message = %q, want it to name the role", res.Error.Message)
+	}
+}
+
+func TestServiceErrors(t *testing.T) {
+	s := newServer(t)
+	s.addAsset(t, "EQ-001")
+
+	for _, tt := range []struct {
+		method, path, body string
+		status             int
+		code               string
+	}{
+		{"GET", "/assets/EQ-404", "", http.StatusNotFound, "not_found"},
+		{"GET", "/no-such-endpoint", "", http.StatusNotFound, "not_found"},
+		{"POST", "/assets", strings.Replace(newAssetBody, "EQ-100", "EQ-001", 1), http.StatusConflict, "conflict"},
+		{"POST", "/assets/EQ-001/return", `{}`, http.StatusConflict, "conflict"},
+		{"POST", "/assets", strings.Replace(newAssetBody, `"location": "Main"`, `"location": "Main", "status": "Retired"`, 1),
+			http.StatusUnprocessableEntity, "validation_failed"},
+		{"POST", "/assets", `{"asset_tag": "EQ-101"}`, http.StatusUnprocessableEntity, "validation_failed"},
+		{"POST", "/assets", `{"asset_tag": `, http.StatusBadRequest, "invalid_request"},
+		{"GET", "/assets?sort=price", "", http.StatusBadRequest, "invalid_request"},
+	} {
+		res := s.do(t, tt.method, tt.path, "", tt.body)
+		if res.Status != tt.status || res.Error.Code != tt.code || res.Error.Message == "" {
+			t.Errorf("%s %s = %d %s, want %d %s", tt.method, tt.path, res.Status, res.Body, tt.status, tt.code)
+		}
+	}
+}
+
+func TestInternalErrorsAreLogged(t *testing.T) {
+	s := newServer(t)
+	s.db.Close()
+
+	res := s.do(t, "GET", "/assets", "", "")
+	if res.Status != http.StatusInternalServerError || res.Error.Code != "internal" || res.Error.Message != "internal error" {
+		t.Errorf("GET /assets on a closed database = %d %s, want a bare 500", res.Status, res.Body)
+	}
+	if strings.Contains(res.Body, "closed") {
+		t.Errorf("500 body %s reveals the error", res.Body)
+	}
+	if logs := s.log.String(); !strings.Contains(logs, "api: ") || !strings.Contains(logs, "database is closed") {
+		t.Errorf("log = %q, want the internal error", logs)
+	}
+}
+
+func TestAssetPages(t *testing.T) {
+	s := newServer(t)
+	for i := 1; i <= 5; i++ {
+		s.addAsset(t, fmt.Sprintf("EQ-%03d", i*10))
+	}
+
+	var tags []string
+	path := "/assets?limit=2"
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatalf("pagination did not end, tags so far %v", tags)
+		}
+		res := s.do(t, "GET", path, "", "")
+		if res.Status != http.StatusOK {
+			t.Fatalf("GET %s = %d %s", path, res.Status, res.Body)
+		}
+		for _, item := range res.Items {
+			tags = append(tags, item["asset_tag"].(string))
+		}
+		if res.NextCursor == "" {
+			break
+		}
+		if pages == 0 {
+			// Pages are keyed on the sort column, so an asset added before
+			// the cursor does not shift the following pages
+			s.addAsset(t, "EQ-000")
+		}
+		path = "/assets?limit=2&cursor=" + res.NextCursor
+	}
+	if want := "EQ-010 EQ-020 EQ-030 EQ-040 EQ-050"; strings.Join(tags, " ") != want {
+		t.Errorf("paged tags = %v, want %s", tags, want)
+	}
+
+	res := s.do(t, "GET", "/assets?limit=3&sort=asset_tag&desc=true", "", "")
+	if len(res.Items) != 3 || res.Items[0]["asset_tag"] != "EQ-050" || res.NextCursor == "" {
+		t.Errorf("first descending page = %v, next %q", res.Items, res.NextCursor)
+	}
+	res = s.do(t, "GET", "/assets?limit=3&sort=asset_tag&desc=true&cursor="+res.NextCursor, "", "")
+	if len(res.Items) != 3 || res.Items[0]["asset_tag"] != "EQ-020" || res.NextCursor != "" {
+		t.Errorf("last descending page = %v, next %q, want EQ-020 to EQ-000 and no cursor", res.Items, res.NextCursor)
+	}
+
+	for _, path := range []string{"/assets?cursor=%21%21", "/assets?limit=0", "/assets?limit=501"} {
+		if res := s.do(t, "GET", path, "", ""); res.Status != http.StatusBadRequest {
+			t.Errorf("GET %s = %d %s, want 400", path, res.Status, res.Body)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// stockInput holds the fields of a stock adjustment, issue or return
+type stockInput struct {
+	Location string  `json:"location"`
+	Quantity int     `json:"quantity"` // Signed for adjustments, positive for issues and returns
+	Employee string  `json:"employee"` // Email or unique name, for issues and returns
+	Date     *string `json:"date"`
+	Notes    *string `json:"notes"`
+}
+
+// listStock returns the stock items with their totals
+func (s *Server) listStock(w http.ResponseWriter, r *http.Request) error {
+	items, err := s.svc.ListStockItems(r.Context())
+	if err != nil {
+		return err
+	}
+	return writePage(w, r, items)
+}
+
+// getStockItem returns one stock item with its units by location and holder
+func (s *Server) getStockItem(w http.ResponseWriter, r *http.Request) error {
+	d, err := s.svc.StockItemByName(r.Context(), r.PathValue("item"))
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, d)
+}
+
+// adjustStock adds units of an item at a location, or removes them
+func (s *Server) adjustStock(w http.ResponseWriter, r *http.Request) error {
+	m, _, err := s.readStockMovement(r, false)
+	if err != nil {
+		return err
+	}
+	if err := s.svc.AdjustStock(r.Context(), m); err != nil {
+		return err
+	}
+	return s.writeStockItem(w, r, m.ItemName)
+}
+
+// issueStock hands units of an item to an employee
+func (s *Server) issueStock(w http.ResponseWriter, r *http.Request) error {
+	m, employee, err := s.readStockMovement(r, true)
+	if err != nil {
+		return err
+	}
+	if err := s.svc.IssueStock(r.Context(), m, employee); err != nil {
+		return err
+	}
+	return s.writeStockItem(w, r, m.ItemName)
+}
+
+// returnStock takes units of an item back from an employee
+func (s *Server) returnStock(w http.ResponseWriter, r *http.Request) error {
+	m, employee, err := s.readStockMovement(r, true)
+	if err != nil {
+		return err
+	}
+	if err := s.svc.ReturnStock(r.Context(), m, employee); err != nil {
+		return err
+	}
+	return s.writeStockItem(w, r, m.ItemName)
+}
+
+// readStockMovement reads a stockInput into a movement of the item named in
+// the path, returning the employee given for issues and returns
+func (s *Server) readStockMovement(r *http.Request, withEmployee bool) (*models.StockMovement, string, error) {
+	d, err := s.svc.StockItemByName(r.Context(), r.PathValue("item"))
+	if err != nil {
+		return nil, "", err
+	}
+	var in stockInput
+	if err := readJSON(r, &in); err != nil {
+		return nil, "", err
+	}
+	switch {
+	case strings.TrimSpace(in.Location) == "":
+		return nil, "", errValidation("location is required")
+	case withEmployee && strings.TrimSpace(in.Employee) == "":
+		return nil, "", errValidation("employee is required")
+	}
+	l, err := repo.NewLocationRepo(s.db.Conn).FindByName(in.Location)
+	if err != nil {
+		return nil, "", lookupError(err, "location", in.Location)
+	}
+
+	m := &models.StockMovement{ItemID: d.ItemID, LocationID: l.LocationID, Quantity: in.Quantity,
+		Notes: optional(in.Notes), MovedBy: actor(r)}
+	if m.MovedAt, err = timestamp("date", in.Date); err != nil {
+		return nil, "", err
+	}
+	return m, in.Employee, nil
+}
+
+// writeStockItem renders the stored state of a stock item
+func (s *Server) writeStockItem(w http.ResponseWriter, r *http.Request, name string) error {
+	d, err := s.svc.StockItemByName(r.Context(), name)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, d)
+}
@@ -0,0 +1,151 @@
+// Package auth signs users in and decides what their role allows
+//
+// Authentication is enabled as soon as the first user is created; until
+// then every operation is allowed and changes are not attributed
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+var (
+	// ErrInvalidCredentials is returned for an unknown user, a wrong
+	// password or an unknown token
+	ErrInvalidCredentials = errors.New("invalid username, password or token")
+	// ErrForbidden is returned when a role does not allow an operation
+	ErrForbidden = errors.New("permission denied")
+)
+
+// Permission is a group of operations a role may be allowed
+type Permission int
+
+const (
+	ViewInventory      Permission = iota // Read any record, export and save views
+	EditAssets                           // Create and update assets, add comments and attachments
+	AssignAssets                         // Assign and return assets
+	LogMaintenance                       // Record maintenance
+	ManageEmployees                      // Create employees
+	RetireAssets                         // Retire assets
+	ImportData                           // Import records in bulk
+	ManageUsers                          // Manage users and API tokens
+	ManageBackups                        // Take and restore database snapshots
+	RepairData                           // Apply automatic fixes to inconsistent records
+	ManageFields                         // Define the custom fields of asset types and categories
+	ManageDepreciation                   // Set how the assets of each category depreciate
+	ViewSecrets                          // Read software license keys
+)
+
+// permissionNames describes each permission for error messages
+var permissionNames = map[Permission]string{
+	ViewInventory:      "view the inventory",
+	EditAssets:         "edit assets",
+	AssignAssets:       "assign assets",
+	LogMaintenance:     "record maintenance",
+	ManageEmployees:    "manage employees",
+	RetireAssets:       "retire assets",
+	ImportData:         "import data",
+	ManageUsers:        "manage users",
+	ManageBackups:      "manage backups",
+	RepairData:         "repair data",
+	ManageFields:       "manage custom fields",
+	ManageDepreciation: "manage depreciation",
+	ViewSecrets:        "view license keys",
+}
+
+func (p Permission) String() string { return permissionNames[p] }
+
+// rolePermissions lists what each role allows; admins are allowed everything
+var rolePermissions = map[string][]Permission{
+	models.RoleViewer:     {ViewInventory},
+	models.RoleTechnician: {ViewInventory, EditAssets, AssignAssets, LogMaintenance, ViewSecrets},
+}
+
+// ValidRole reports whether role is one of models.Roles
+func ValidRole(role string) bool {
+	return slices.Contains(models.Roles, role)
+}
+
+// Can reports whether u may perform operations of permission p
+// A nil user, meaning authentication is not enabled, may do anything
+func Can(u *models.User, p Permission) bool {
+	if u == nil || u.Role == models.RoleAdmin {
+		return true
+	}
+	return slices.Contains(rolePermissions[u.Role], p)
+}
+
+// Check returns an ErrForbidden error when u may not perform p
+func Check(u *models.User, p Permission) error {
+	if Can(u, p) {
+		return nil
+	}
+	return fmt.Errorf("%w: role %s cannot %s", ErrForbidden, u.Role, p)
+}
+
+// Actor returns the username changes made by u are attributed to, or nil
+// when nobody is signed in
+func Actor(u *models.User) *string {
+	if u == nil {
+		return nil
+	}
+	name := u.Username
+	return &name
+}
+
+// HideLicenseKeys blanks the keys of licenses unless u may view secrets
+func HideLicenseKeys(u *models.User, licenses []*models.SoftwareLicense) {
+	if Can(u, ViewSecrets) {
+		return
+	}
+	for _, l := range licenses {
+		l.LicenseKey = ""
+	}
+}
+
+// HideAuditSecrets removes the license keys recorded in entries unless u may
+// view secrets
+func HideAuditSecrets(u *models.User, entries []*models.AuditEntry) {
+	if Can(u, ViewSecrets) {
+		return
+	}
+	for _, e := range entries {
+		if e.Entity == "software_licenses" {
+			e.Omit("license_key")
+		}
+	}
+}
+
+// Enabled reports whether any user exists, so signing in is required
+func Enabled(users repo.UserStore) (bool, error) {
+	n, err := users.Count()
+	return n > 0, err
+}
+
+// Login returns the user with the given username and password
+func Login(users repo.UserStore, username, password string) (*models.User, error) {
+	u, hash, err := users.FindByUsername(username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, err
+	}
+	if !CheckPassword(hash, password) {
+		return nil, ErrInvalidCredentials
+	}
+	return u, nil
+}
+
+// LoginToken returns the user owning an API token
+func LoginToken(users repo.UserStore, token string) (*models.User, error) {
+	u, err := users.FindByToken(HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidCredentials
+	}
+	return u, err
+}
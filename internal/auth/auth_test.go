@@ -0,0 +1,175 @@
+package auth_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo/memrepo"
+)
+
+func TestPasswords(t *testing.T) {
+	if _, err := auth.HashPassword("short"); err == nil {
+		t.Error("HashPassword accepted a password shorter than the minimum")
+	}
+
+	hash, err := auth.HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(hash, "correct horse") || !strings.HasPrefix(hash, "pbkdf2-sha256$") {
+		t.Errorf("hash = %s, want a pbkdf2-sha256 hash without the password", hash)
+	}
+	if !auth.CheckPassword(hash, "correct horse") {
+		t.Error("CheckPassword rejected the right password")
+	}
+	for _, wrong := range []string{"correct horsE", "correct horse ", ""} {
+		if auth.CheckPassword(hash, wrong) {
+			t.Errorf("CheckPassword accepted %q", wrong)
+		}
+	}
+
+	// Salting gives the same password a different hash each time
+	again, err := auth.HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == hash {
+		t.Error("two hashes of the same password are equal, want different salts")
+	}
+
+	for _, bad := range []string{"", "plain", "md5$1$c2FsdA$a2V5", "pbkdf2-sha256$x$c2FsdA$a2V5", "pbkdf2-sha256$0$c2FsdA$a2V5",
+		"pbkdf2-sha256$1$!!$a2V5"} {
+		if auth.CheckPassword(bad, "correct horse") {
+			t.Errorf("CheckPassword accepted the malformed hash %q", bad)
+		}
+	}
+}
+
+func TestLogin(t *testing.T) {
+	users := memrepo.New().Users
+	if enabled, err := auth.Enabled(users); err != nil || enabled {
+		t.Errorf("Enabled without users = %v, %v, want false", enabled, err)
+	}
+
+	hash, err := auth.HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ana := &models.User{Username: "ana", Role: models.RoleTechnician}
+	if err := users.Create(ana, hash); err != nil {
+		t.Fatal(err)
+	}
+	if enabled, err := auth.Enabled(users); err != nil || !enabled {
+		t.Errorf("Enabled with a user = %v, %v, want true", enabled, err)
+	}
+
+	if u, err := auth.Login(users, "ana", "correct horse"); err != nil || u.UserID != ana.UserID {
+		t.Errorf("Login = %+v, %v, want ana", u, err)
+	}
+	for _, c := range [][2]string{{"ana", "wrong password"}, {"luis", "correct horse"}} {
+		if _, err := auth.Login(users, c[0], c[1]); !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Errorf("Login(%s, %s) error = %v, want ErrInvalidCredentials", c[0], c[1], err)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	users := memrepo.New().Users
+	ana := &models.User{Username: "ana", Role: models.RoleViewer}
+	if err := users.Create(ana, "unused"); err != nil {
+		t.Fatal(err)
+	}
+
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, "itr_") || hash != auth.HashToken(token) || strings.Contains(hash, token) {
+		t.Errorf("NewToken = %s, %s, want an itr_ token and its hash", token, hash)
+	}
+	if other, _, err := auth.NewToken(); err != nil || other == token {
+		t.Errorf("NewToken twice = %s, %v, want a different token", other, err)
+	}
+	if err := users.CreateToken(&models.APIToken{UserID: ana.UserID, Name: "ci"}, hash); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the hash is stored, so the token is looked up by hashing it
+	if u, err := auth.LoginToken(users, token); err != nil || u.Username != "ana" {
+		t.Errorf("LoginToken = %+v, %v, want ana", u, err)
+	}
+	for _, wrong := range []string{hash, token + "x", ""} {
+		if _, err := auth.LoginToken(users, wrong); !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Errorf("LoginToken(%q) error = %v, want ErrInvalidCredentials", wrong, err)
+		}
+	}
+}
+
+func TestRolePermissions(t *testing.T) {
+	all := []auth.Permission{auth.ViewInventory, auth.EditAssets, auth.AssignAssets, auth.LogMaintenance,
+		auth.ManageEmployees, auth.RetireAssets, auth.ImportData, auth.ManageUsers, auth.ManageBackups,
+		auth.RepairData, auth.ManageFields, auth.ManageDepreciation, auth.ViewSecrets}
+	allowed := map[string][]auth.Permission{
+		models.RoleViewer:     {auth.ViewInventory},
+		models.RoleTechnician: {auth.ViewInventory, auth.EditAssets, auth.AssignAssets, auth.LogMaintenance, auth.ViewSecrets},
+		models.RoleAdmin:      all,
+	}
+
+	for _, role := range models.Roles {
+		if !auth.ValidRole(role) {
+			t.Errorf("ValidRole(%s) = false", role)
+		}
+		u := &models.User{Username: role, Role: role}
+		for _, p := range all {
+			want := false
+			for _, a := range allowed[role] {
+				want = want || a == p
+			}
+			if got := auth.Can(u, p); got != want {
+				t.Errorf("Can(%s, %s) = %v, want %v", role, p, got, want)
+			}
+			if err := auth.Check(u, p); (err == nil) != want || (err != nil && !errors.Is(err, auth.ErrForbidden)) {
+				t.Errorf("Check(%s, %s) = %v", role, p, err)
+			}
+		}
+	}
+	if auth.ValidRole("owner") {
+		t.Error("ValidRole(owner) = true")
+	}
+
+	// Without authentication every operation is allowed and unattributed
+	for _, p := range all {
+		if !auth.Can(nil, p) {
+			t.Errorf("Can(nil, %s) = false", p)
+		}
+	}
+	if auth.Actor(nil) != nil {
+		t.Error("Actor(nil) is not nil")
+	}
+	if name := auth.Actor(&models.User{Username: "ana"}); name == nil || *name != "ana" {
+		t.Errorf("Actor = %v, want ana", name)
+	}
+}
+
+func TestHideSecrets(t *testing.T) {
+	viewer := &models.User{Username: "vera", Role: models.RoleViewer}
+	technician := &models.User{Username: "tom", Role: models.RoleTechnician}
+
+	for _, tt := range []struct {
+		user *models.User
+		want string
+	}{
+		{viewer, ""},
+		{technician, "KEY-123"},
+		{nil, "KEY-123"},
+	} {
+		licenses := []*models.SoftwareLicense{{LicenseKey: "KEY-123"}}
+		auth.HideLicenseKeys(tt.user, licenses)
+		if licenses[0].LicenseKey != tt.want {
+			t.Errorf("license key shown to %+v = %q, want %q", tt.user, licenses[0].LicenseKey, tt.want)
+		}
+	}
+}
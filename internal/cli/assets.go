@@ -0,0 +1,392 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// assetOutput is an asset as printed by --json, with its warranty state
+type assetOutput struct {
+	*models.AssetSummary
+	WarrantyState string `json:"warranty_state"`
+}
+
+// assetList prints the assets matching the given filters
+func (c *CLI) assetList(args []string) error {
+	fs := c.newFlagSet("asset list")
+	var qf assetQueryFlags
+	qf.register(fs)
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	q, err := c.assetQuery(&qf)
+	if err != nil {
+		return err
+	}
+	assets, err := repo.NewAssetRepo(c.db.Conn).ListSummaries(q)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		out := make([]assetOutput, 0, len(assets))
+		for _, a := range assets {
+			out = append(out, c.assetOutput(a))
+		}
+		return c.printJSON(out)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "TAG\tCATEGORY\tTYPE\tMAKE\tMODEL\tSERIAL\tSTATUS\tLOCATION\tHOLDER\tWARRANTY END")
+	for _, a := range assets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			a.AssetTag, a.CategoryName, a.TypeName, a.Maker, a.Model, a.SerialNumber,
+			a.StatusName, a.LocationName, valueOrEmpty(a.HolderName), formatDate(a.WarrantyEndDate))
+	}
+	return tw.Flush()
+}
+
+// assetShow prints every field of one asset
+func (c *CLI) assetShow(args []string) error {
+	fs := c.newFlagSet("asset show")
+	asJSON := fs.Bool("json", false, "print JSON")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	a, err := repo.NewAssetRepo(c.db.Conn).GetSummaryByTag(pos[0])
+	if err != nil {
+		return notFound(err, "asset", pos[0])
+	}
+	values, err := c.loadFields(a)
+	if err != nil {
+		return err
+	}
+	out := c.assetOutput(a)
+	if *asJSON {
+		return c.printJSON(out)
+	}
+	bookValue, err := c.bookValue(a)
+	if err != nil {
+		return err
+	}
+
+	rows := [][2]string{
+		{"Asset ID", a.AssetID},
+		{"Asset Tag", a.AssetTag},
+		{"Category", a.CategoryName},
+		{"Type", a.TypeName},
+		{"Make", a.Maker},
+		{"Model", a.Model},
+		{"Serial Number", a.SerialNumber},
+		{"Status", a.StatusName},
+		{"Location", a.LocationName},
+		{"Holder", valueOrEmpty(a.HolderName)},
+		{"Purchase Date", formatDate(&a.PurchaseDate)},
+		{"Warranty End", formatDate(a.WarrantyEndDate)},
+		{"Warranty", out.WarrantyState},
+		{"Purchase Cost", formatCost(a.PurchaseCost, a.Currency)},
+		{"Book Value", bookValue},
+		{"Supplier", valueOrEmpty(a.Supplier)},
+		{"PO Number", valueOrEmpty(a.PONumber)},
+		{"Invoice Number", valueOrEmpty(a.InvoiceNumber)},
+	}
+	for _, v := range values {
+		rows = append(rows, [2]string{v.FieldName, v.Value})
+	}
+	rows = append(rows, [2]string{"Notes", valueOrEmpty(a.Notes)})
+
+	tw := c.newTable()
+	for _, f := range rows {
+		fmt.Fprintf(tw, "%s:\t%s\n", f[0], f[1])
+	}
+	return tw.Flush()
+}
+
+// assetAdd creates an asset from flags
+func (c *CLI) assetAdd(args []string) error {
+	fs := c.newFlagSet("asset add")
+	var f assetFlags
+	f.register(fs)
+	asJSON := fs.Bool("json", false, "print the created asset as JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	for name, value := range map[string]string{
+		"tag": f.tag, "type": f.typeName, "make": f.maker, "model": f.model,
+		"serial": f.serial, "location": f.location,
+	} {
+		if strings.TrimSpace(value) == "" {
+			return usagef("-%s is required", name)
+		}
+	}
+
+	a := models.Asset{PurchaseDate: today(), StatusID: models.StatusAvailable, CreatedBy: c.actor()}
+	if err := c.applyAssetFlags(fs, &f, &a); err != nil {
+		return err
+	}
+	if err := c.svc.CreateAsset(c.ctx, &a); err != nil {
+		return err
+	}
+
+	return c.printAsset(a.AssetID, *asJSON)
+}
+
+// assetUpdate changes the fields of an asset given as flags
+func (c *CLI) assetUpdate(args []string) error {
+	fs := c.newFlagSet("asset update")
+	var f assetFlags
+	f.register(fs)
+	asJSON := fs.Bool("json", false, "print the updated asset as JSON")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	current, err := c.svc.AssetByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+
+	a := current.Asset
+	a.UpdatedBy = c.actor()
+	if err := c.applyAssetFlags(fs, &f, &a); err != nil {
+		return err
+	}
+	if err := c.svc.UpdateAsset(c.ctx, &a); err != nil {
+		return err
+	}
+
+	return c.printAsset(a.AssetID, *asJSON)
+}
+
+// assetRetire marks an asset as retired
+func (c *CLI) assetRetire(args []string) error {
+	fs := c.newFlagSet("asset retire")
+	date := fs.String("date", "", "retirement date (YYYY-MM-DD), defaults to now")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	when, err := timestampFlag("date", *date)
+	if err != nil {
+		return err
+	}
+	a, err := c.svc.AssetByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if err := c.svc.RetireAsset(c.ctx, a.AssetID, when, c.actor()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Retired %s\n", a.AssetTag)
+	return nil
+}
+
+// assetQueryFlags holds the filters and ordering of an asset listing
+type assetQueryFlags struct {
+	status, category, filter, sort string
+	desc                           bool
+	fields                         listFlag
+}
+
+// register defines the listing flags on fs
+func (f *assetQueryFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.status, "status", "", "only assets with this status")
+	fs.StringVar(&f.category, "category", "", "only assets in this category (name or code prefix)")
+	fs.StringVar(&f.filter, "filter", "", "only assets whose tag, serial, make, model, type, location, holder, supplier, PO, invoice or custom field contains this text")
+	fs.StringVar(&f.sort, "sort", "asset_tag", "column to sort by: "+strings.Join(sortColumns(), ", "))
+	fs.BoolVar(&f.desc, "desc", false, "sort in descending order")
+	fs.Var(&f.fields, "field", "only assets with this custom field value, e.g. -field RAM=16 (repeatable)")
+}
+
+// assetQuery builds the repository query for the listing flags, resolving
+// catalog names to IDs
+func (c *CLI) assetQuery(f *assetQueryFlags) (repo.AssetQuery, error) {
+	q := repo.AssetQuery{SortColumn: f.sort, SortDesc: f.desc, Filter: f.filter}
+	if _, ok := repo.AssetSortColumns[f.sort]; !ok {
+		return q, usagef("unknown sort column %q", f.sort)
+	}
+	if len(f.fields) > 0 {
+		var err error
+		if q.Fields, err = fieldValues(f.fields); err != nil {
+			return q, err
+		}
+	}
+
+	assetRepo := repo.NewAssetRepo(c.db.Conn)
+	if f.status != "" {
+		s, err := assetRepo.FindAssetStatus(f.status)
+		if err != nil {
+			return q, notFound(err, "status", f.status)
+		}
+		q.StatusID = s.StatusID
+	}
+	if f.category != "" {
+		cat, err := assetRepo.FindAssetCategory(f.category)
+		if err != nil {
+			return q, notFound(err, "category", f.category)
+		}
+		q.CategoryID = cat.CategoryId
+	}
+	return q, nil
+}
+
+// assetFlags holds the asset fields accepted by add and update
+type assetFlags struct {
+	tag, typeName, maker, model, serial string
+	purchase, warranty, location        string
+	status, notes                       string
+	cost, currency, supplier, po        string
+	invoice                             string
+	fields                              listFlag
+}
+
+// register defines the asset field flags on fs
+func (f *assetFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.tag, "tag", "", "asset tag")
+	fs.StringVar(&f.typeName, "type", "", "asset type name, e.g. Laptop")
+	fs.StringVar(&f.maker, "make", "", "manufacturer")
+	fs.StringVar(&f.model, "model", "", "model")
+	fs.StringVar(&f.serial, "serial", "", "serial number")
+	fs.StringVar(&f.purchase, "purchase-date", "", "purchase date (YYYY-MM-DD), defaults to today when adding")
+	fs.StringVar(&f.warranty, "warranty-end", "", "warranty end date (YYYY-MM-DD), empty for none")
+	fs.StringVar(&f.location, "location", "", "location name")
+	fs.StringVar(&f.status, "status", "", "Available or Under Maintenance; use assign and retire for the other statuses")
+	fs.StringVar(&f.notes, "notes", "", "free-form notes")
+	fs.StringVar(&f.cost, "cost", "", "purchase cost, empty for none")
+	fs.StringVar(&f.currency, "currency", "", "ISO 4217 code of the purchase cost, defaults to the configured currency")
+	fs.StringVar(&f.supplier, "supplier", "", "supplier the asset was bought from")
+	fs.StringVar(&f.po, "po", "", "purchase order number")
+	fs.StringVar(&f.invoice, "invoice", "", "invoice number")
+	fs.Var(&f.fields, "field", "custom field value, e.g. -field RAM=16, or -field RAM= to clear it (repeatable)")
+}
+
+// applyAssetFlags copies the flags set on the command line into a,
+// resolving catalog names to IDs
+func (c *CLI) applyAssetFlags(fs *flag.FlagSet, f *assetFlags, a *models.Asset) error {
+	assetRepo := repo.NewAssetRepo(c.db.Conn)
+
+	var err error
+	fs.Visit(func(fl *flag.Flag) {
+		if err != nil {
+			return
+		}
+		switch fl.Name {
+		case "tag":
+			a.AssetTag = strings.TrimSpace(f.tag)
+		case "make":
+			a.Maker = strings.TrimSpace(f.maker)
+		case "model":
+			a.Model = strings.TrimSpace(f.model)
+		case "serial":
+			a.SerialNumber = strings.TrimSpace(f.serial)
+		case "notes":
+			a.Notes = optional(f.notes)
+		case "field":
+			a.Fields, err = fieldValues(f.fields)
+		case "cost":
+			if a.PurchaseCost, err = floatFlag("cost", f.cost); a.PurchaseCost == nil {
+				a.Currency = nil
+			} else if a.Currency == nil {
+				a.Currency = optional(c.cfg.Currency)
+			}
+		case "currency":
+			a.Currency = optional(f.currency)
+		case "supplier":
+			a.Supplier = optional(f.supplier)
+		case "po":
+			a.PONumber = optional(f.po)
+		case "invoice":
+			a.InvoiceNumber = optional(f.invoice)
+		case "purchase-date":
+			a.PurchaseDate, err = parseDate("purchase date", f.purchase)
+		case "warranty-end":
+			a.WarrantyEndDate = nil
+			if strings.TrimSpace(f.warranty) != "" {
+				var end time.Time
+				end, err = parseDate("warranty end date", f.warranty)
+				a.WarrantyEndDate = &end
+			}
+		case "type":
+			var t *models.AssetType
+			if t, err = assetRepo.FindAssetType(f.typeName); err == nil {
+				a.TypeID = t.TypeID
+			}
+			err = notFound(err, "asset type", f.typeName)
+		case "location":
+			var l *models.Location
+			if l, err = repo.NewLocationRepo(c.db.Conn).FindByName(f.location); err == nil {
+				a.LocationID = l.LocationID
+			}
+			err = notFound(err, "location", f.location)
+		case "status":
+			var s *models.AssetStatus
+			if s, err = assetRepo.FindAssetStatus(f.status); err == nil {
+				a.StatusID = s.StatusID
+			}
+			err = notFound(err, "status", f.status)
+		}
+	})
+	return err
+}
+
+// printAsset prints the tag of a saved asset, or the whole asset as JSON
+func (c *CLI) printAsset(assetID string, asJSON bool) error {
+	a, err := repo.NewAssetRepo(c.db.Conn).GetSummary(assetID)
+	if err != nil {
+		return err
+	}
+	if _, err := c.loadFields(a); err != nil {
+		return err
+	}
+	if asJSON {
+		return c.printJSON(c.assetOutput(a))
+	}
+	fmt.Fprintf(c.stdout, "Saved %s\n", a.AssetTag)
+	return nil
+}
+
+// assetOutput pairs an asset with its warranty state as of today
+func (c *CLI) assetOutput(a *models.AssetSummary) assetOutput {
+	state := a.WarrantyStateOn(today(), c.cfg.WarrantySoonDays())
+	return assetOutput{AssetSummary: a, WarrantyState: state.String()}
+}
+
+// loadFields fills in the custom field values of an asset, also returning
+// them in display order
+func (c *CLI) loadFields(a *models.AssetSummary) ([]*models.AssetFieldValue, error) {
+	values, err := repo.NewFieldRepo(c.db.Conn).ListValues(a.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if a.Fields == nil {
+			a.Fields = map[string]string{}
+		}
+		a.Fields[v.FieldName] = v.Value
+	}
+	return values, nil
+}
+
+// sortColumns returns the accepted sort column keys in a stable order
+func sortColumns() []string {
+	keys := make([]string, 0, len(repo.AssetSortColumns))
+	for k := range repo.AssetSortColumns {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+// assign hands an asset to an employee
+func (c *CLI) assign(args []string) error {
+	fs := c.newFlagSet("assign")
+	date := fs.String("date", "", "assignment date (YYYY-MM-DD), defaults to now")
+	due := fs.String("due", "", "date the asset is due back (YYYY-MM-DD), for loans")
+	notes := fs.String("notes", "", "free-form notes")
+	withChildren := fs.Bool("with-children", false, "assign the assets linked under it too")
+	pos, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	assignment := models.AssetAssignment{Notes: optional(*notes), AssignedBy: c.actor()}
+	if assignment.AssignmentDate, err = timestampFlag("date", *date); err != nil {
+		return err
+	}
+	if *due != "" {
+		dueDate, err := parseDate("due date", *due)
+		if err != nil {
+			return err
+		}
+		assignment.DueDate = &dueDate
+	}
+
+	a, err := c.svc.AssetByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	assignment.AssetID = a.AssetID
+	assign := c.svc.AssignAsset
+	if *withChildren {
+		assign = c.svc.AssignAssetWithChildren
+	}
+	if err := assign(c.ctx, &assignment, pos[1]); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Assigned %s to %s\n", assignment.AssetTag, assignment.EmployeeName)
+	return nil
+}
+
+// returnAsset closes the open assignment of an asset
+func (c *CLI) returnAsset(args []string) error {
+	fs := c.newFlagSet("return")
+	date := fs.String("date", "", "return date (YYYY-MM-DD), defaults to now")
+	withChildren := fs.Bool("with-children", false, "return the assets linked under it held by the same employee too")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	when, err := timestampFlag("date", *date)
+	if err != nil {
+		return err
+	}
+	a, err := c.svc.AssetByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	returnFn := c.svc.ReturnAsset
+	if *withChildren {
+		returnFn = c.svc.ReturnAssetWithChildren
+	}
+	if err := returnFn(c.ctx, a.AssetID, when, c.actor()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Returned %s\n", a.AssetTag)
+	return nil
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// audit prints the audit log, newest first
+func (c *CLI) audit(args []string) error {
+	fs := c.newFlagSet("audit")
+	var q repo.AuditQuery
+	fs.StringVar(&q.Entity, "entity", "", "only changes to this table, e.g. assets or asset_assignments")
+	fs.StringVar(&q.EntityID, "id", "", "only changes to the record with this ID")
+	tag := fs.String("asset", "", "only changes concerning the asset with this tag")
+	fs.StringVar(&q.Actor, "actor", "", "only changes made by this user")
+	fs.StringVar(&q.Field, "field", "", "only changes to this field, e.g. serial_number")
+	since := fs.String("since", "", "only changes on or after this date (YYYY-MM-DD)")
+	until := fs.String("until", "", "only changes on or before this date (YYYY-MM-DD)")
+	fs.IntVar(&q.Limit, "limit", 50, "print at most this many entries, 0 for all")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if q.Limit < 0 {
+		return usagef("-limit cannot be negative")
+	}
+
+	for _, d := range []struct {
+		name, value string
+		dst         **time.Time
+	}{{"since", *since, &q.Since}, {"until", *until, &q.Until}} {
+		if d.value == "" {
+			continue
+		}
+		t, err := parseDate(d.name, d.value)
+		if err != nil {
+			return err
+		}
+		*d.dst = &t
+	}
+
+	if *tag != "" {
+		a, err := repo.NewAssetRepo(c.db.Conn).GetSummaryByTag(*tag)
+		if err != nil {
+			return notFound(err, "asset", *tag)
+		}
+		q.AssetID = a.AssetID
+	}
+
+	entries, err := repo.NewAuditRepo(c.db.Conn).List(q)
+	if err != nil {
+		return err
+	}
+	auth.HideAuditSecrets(c.user, entries)
+
+	if *asJSON {
+		if entries == nil {
+			entries = []*models.AuditEntry{}
+		}
+		return c.printJSON(entries)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "CHANGED\tBY\tRECORD\tID\tASSET\tOPERATION\tCHANGES")
+	for _, e := range entries {
+		var changes []string
+		for _, ch := range e.Changes() {
+			changes = append(changes, ch.String())
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.ChangedAt.Format(repo.TimestampLayout), valueOrEmpty(e.Actor), e.Entity, e.EntityID,
+			valueOrEmpty(e.AssetTag), e.Operation, strings.Join(changes, ", "))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/db"
+)
+
+// backupCreate takes a snapshot of the database while it stays in use
+func (c *CLI) backupCreate(args []string) error {
+	fs := c.newFlagSet("backup create")
+	dir := fs.String("dir", c.cfg.Backup.Dir, "directory holding the timestamped snapshots")
+	keep := fs.Int("keep", c.cfg.Backup.Keep, "snapshots kept after rotating, 0 keeps all")
+	output := fs.String("o", "", "write the backup to this file instead, without rotating")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if *keep < 0 {
+		return usagef("-keep cannot be negative")
+	}
+
+	path := *output
+	if path != "" {
+		if err := c.db.Backup(path); err != nil {
+			return err
+		}
+	} else {
+		if *dir == "" {
+			return usagef("-dir or -o is required")
+		}
+		var err error
+		if path, err = c.db.Snapshot(*dir, *keep); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(c.stdout, "Saved %s\n", path)
+	return nil
+}
+
+// backupList prints the snapshots, newest first
+func (c *CLI) backupList(args []string) error {
+	fs := c.newFlagSet("backup list")
+	dir := fs.String("dir", c.cfg.Backup.Dir, "directory holding the timestamped snapshots")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	snapshots, err := db.ListSnapshots(*dir)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		if snapshots == nil {
+			snapshots = []db.Snapshot{}
+		}
+		return c.printJSON(snapshots)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "TAKEN (UTC)\tSIZE\tPATH")
+	for _, s := range snapshots {
+		fmt.Fprintf(tw, "%s\t%d KB\t%s\n", s.Taken.Format("2006-01-02 15:04:05"), (s.Size+1023)/1024, s.Path)
+	}
+	return tw.Flush()
+}
+
+// restore replaces the database with a backup after snapshotting the
+// current contents, then migrates the restored schema
+// The database is opened as is, so a damaged one can be restored over; if
+// it cannot be snapshotted, -force restores without the snapshot
+func (c *CLI) restore(args []string) error {
+	fs := c.newFlagSet("restore")
+	dir := fs.String("dir", c.cfg.Backup.Dir, "directory receiving a snapshot of the current database first")
+	force := fs.Bool("force", false, "restore even if the current database cannot be snapshotted")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *dir == "" {
+		return usagef("-dir is required")
+	}
+
+	version, err := db.CheckBackup(pos[0])
+	if err != nil {
+		return err
+	}
+	saved, err := c.db.Snapshot(*dir, 0)
+	switch {
+	case err == nil:
+		fmt.Fprintf(c.stdout, "Saved the current database to %s\n", saved)
+	case *force:
+		fmt.Fprintf(c.stderr, "itroom: could not snapshot the current database, restoring without a snapshot: %v\n", err)
+	default:
+		return fmt.Errorf("could not snapshot the current database, run with -force to restore without a snapshot: %w", err)
+	}
+
+	if err := c.db.Restore(pos[0]); err != nil {
+		return err
+	}
+	current, err := c.db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Restored %s (schema version %d, now %d)\n", pos[0], version, current)
+	return nil
+}
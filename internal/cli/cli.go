@@ -0,0 +1,406 @@
+// Package cli implements the non-interactive itroom subcommands
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/config"
+	"github.com/MawCeron/it-room/internal/db"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/service"
+)
+
+// Exit codes returned by Run
+const (
+	ExitOK    = 0
+	ExitError = 1 // The command failed
+	ExitUsage = 2 // The command line is invalid
+	ExitAuth  = 3 // Signing in failed or the user's role does not allow the command
+)
+
+// CLI runs subcommands against an open database
+type CLI struct {
+	ctx    context.Context // Context of the operations run by the command
+	db     *db.DB
+	svc    *service.Service
+	cfg    *config.Config
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	user   *models.User // Signed in user, nil while authentication is not enabled
+}
+
+// command is a subcommand, possibly grouping further subcommands
+type command struct {
+	name  string
+	usage string // Arguments summary shown in the usage text
+	help  string
+	run   func(c *CLI, args []string) error
+	subs  []command
+
+	perm   auth.Permission // Required to run the command
+	public bool            // Runs without signing in
+	// Opens the database as is, without a startup snapshot or migrations,
+	// and runs without signing in when the users cannot be read, so it works
+	// on a damaged database
+	asIs bool
+}
+
+// commands lists every subcommand in the order shown by the usage text
+var commands = []command{
+	{name: "asset", help: "Manage assets", subs: []command{
+		{name: "list", usage: "[flags]", help: "List assets", run: (*CLI).assetList},
+		{name: "show", usage: "<tag> [flags]", help: "Show an asset", run: (*CLI).assetShow},
+		{name: "add", usage: "[flags]", help: "Add an asset", run: (*CLI).assetAdd, perm: auth.EditAssets},
+		{name: "update", usage: "<tag> [flags]", help: "Update the given fields of an asset", run: (*CLI).assetUpdate, perm: auth.EditAssets},
+		{name: "retire", usage: "<tag> [flags]", help: "Retire an asset, returning it first if assigned", run: (*CLI).assetRetire, perm: auth.RetireAssets},
+		{name: "move", usage: "<tag> <location> [flags]", help: "Move an asset to a location", run: (*CLI).assetMove, perm: auth.EditAssets},
+		{name: "link", usage: "<child tag> <attached-to|installed-in|powered-by> <parent tag>", help: "Link an asset to its parent", run: (*CLI).assetLink, perm: auth.EditAssets},
+		{name: "unlink", usage: "<child tag>", help: "Unlink an asset from its parent", run: (*CLI).assetUnlink, perm: auth.EditAssets},
+		{name: "tree", usage: "<tag> [flags]", help: "Show the linked assets around an asset", run: (*CLI).assetTree},
+	}},
+	{name: "assign", usage: "<tag> <employee email or name> [flags]", help: "Assign an asset to an employee", run: (*CLI).assign, perm: auth.AssignAssets},
+	{name: "return", usage: "<tag> [flags]", help: "Return an assigned asset", run: (*CLI).returnAsset, perm: auth.AssignAssets},
+	{name: "kit", help: "Manage kits of assets assigned as a unit", subs: []command{
+		{name: "template", help: "Manage kit templates", subs: []command{
+			{name: "add", usage: "<name> -item <type[=quantity]>... [flags]", help: "Add a kit template", run: (*CLI).kitTemplateAdd, perm: auth.EditAssets},
+			{name: "list", usage: "[flags]", help: "List kit templates", run: (*CLI).kitTemplateList},
+		}},
+		{name: "list", usage: "[flags]", help: "List kits with their state", run: (*CLI).kitList},
+		{name: "show", usage: "<kit tag> [flags]", help: "Show a kit and its assets", run: (*CLI).kitShow},
+		{name: "add", usage: "<kit tag> -asset <tag>... [flags]", help: "Group assets into a kit", run: (*CLI).kitAdd, perm: auth.EditAssets},
+		{name: "assign", usage: "<kit tag> <employee email or name> [flags]", help: "Assign every asset of a kit", run: (*CLI).kitAssign, perm: auth.AssignAssets},
+		{name: "return", usage: "<kit tag> [flags]", help: "Return the assigned assets of a kit", run: (*CLI).kitReturn, perm: auth.AssignAssets},
+		{name: "transfer", usage: "<kit tag> <location>", help: "Move every asset of a kit to a location", run: (*CLI).kitTransfer, perm: auth.EditAssets},
+		{name: "swap", usage: "<kit tag> <old tag> <new tag> [flags]", help: "Replace one asset of a kit with another of the same type", run: (*CLI).kitSwap, perm: auth.EditAssets},
+		{name: "dissolve", usage: "<kit tag>", help: "Delete a kit, keeping its assets", run: (*CLI).kitDissolve, perm: auth.EditAssets},
+	}},
+	{name: "stock", help: "Manage accessories tracked by quantity instead of serial number", subs: []command{
+		{name: "add", usage: "<item> [flags]", help: "Add a stock item", run: (*CLI).stockAdd, perm: auth.EditAssets},
+		{name: "list", usage: "[flags]", help: "List stock items with the units on hand and issued", run: (*CLI).stockList},
+		{name: "show", usage: "<item> [flags]", help: "Show the units of an item by location and holder", run: (*CLI).stockShow},
+		{name: "history", usage: "<item> [flags]", help: "Show the movements of an item, newest first", run: (*CLI).stockHistory},
+		{name: "held", usage: "<employee email or name> [flags]", help: "List the stock items an employee holds", run: (*CLI).stockHeld},
+		{name: "adjust", usage: "<item> <location> -quantity <n> [flags]", help: "Add or remove units of an item at a location", run: (*CLI).stockAdjust, perm: auth.EditAssets},
+		{name: "issue", usage: "<item> <employee email or name> -from <location> [flags]", help: "Issue units of an item to an employee", run: (*CLI).stockIssue, perm: auth.AssignAssets},
+		{name: "return", usage: "<item> <employee email or name> -to <location> [flags]", help: "Take units of an item back from an employee", run: (*CLI).stockReturn, perm: auth.AssignAssets},
+	}},
+	{name: "field", help: "Manage custom asset fields", subs: []command{
+		{name: "add", usage: "<name> -type <type>|-category <category> [flags]", help: "Add a custom field to an asset type or category", run: (*CLI).fieldAdd, perm: auth.ManageFields},
+		{name: "list", usage: "[flags]", help: "List custom fields", run: (*CLI).fieldList},
+		{name: "delete", usage: "<field id>", help: "Delete a custom field and its values", run: (*CLI).fieldDelete, perm: auth.ManageFields},
+	}},
+	{name: "depreciation", help: "Manage how the assets of each category depreciate", subs: []command{
+		{name: "set", usage: "<category> -life <years> [flags]", help: "Set the depreciation rule of a category", run: (*CLI).depreciationSet, perm: auth.ManageDepreciation},
+		{name: "list", usage: "[flags]", help: "List depreciation rules", run: (*CLI).depreciationList},
+		{name: "delete", usage: "<category>", help: "Delete the depreciation rule of a category", run: (*CLI).depreciationDelete, perm: auth.ManageDepreciation},
+	}},
+	{name: "license", help: "Manage software licenses", subs: []command{
+		{name: "list", usage: "[flags]", help: "List licenses", run: (*CLI).licenseList},
+	}},
+	{name: "import", help: "Import data from files", subs: []command{
+		{name: "assets", usage: "<file.csv> [flags]", help: "Import assets from CSV in a single transaction", run: (*CLI).importAssets, perm: auth.ImportData},
+	}},
+	{name: "seed", usage: "[flags]", help: "Fill an empty database with generated demo data", run: (*CLI).seed, perm: auth.ImportData},
+	{name: "export", usage: "<assets|assignments|licenses|consumables|maintenance> [flags]", help: "Export records to CSV, JSON Lines or XLSX", run: (*CLI).export},
+	{name: "report", help: "Print reports", subs: []command{
+		{name: "warranty", usage: "[flags]", help: "Warranty states by vendor, or upcoming expirations", run: (*CLI).reportWarranty},
+		{name: "as-of", usage: "<YYYY-MM-DD> [flags]", help: "Assets with their status, location and holder on a past date", run: (*CLI).reportAsOf},
+		{name: "book-value", usage: "[flags]", help: "Book value of the assets with a purchase cost, by asset, category or location", run: (*CLI).reportBookValue},
+	}},
+	{name: "audit", usage: "[flags]", help: "Show the audit log of changes, newest first", run: (*CLI).audit},
+	{name: "backup", help: "Back up the database", subs: []command{
+		{name: "create", usage: "[flags]", help: "Take a snapshot while the database stays in use", run: (*CLI).backupCreate, perm: auth.ManageBackups},
+		{name: "list", usage: "[flags]", help: "List snapshots, newest first", run: (*CLI).backupList, perm: auth.ManageBackups},
+	}},
+	{name: "restore", usage: "<backup.db> [flags]", help: "Replace the database with a backup, snapshotting it first", run: (*CLI).restore, perm: auth.ManageBackups, asIs: true},
+	{name: "doctor", usage: "[flags]", help: "Check the database for corruption and inconsistent records", run: (*CLI).doctor},
+	{name: "serve", usage: "[flags]", help: "Serve the REST API until interrupted", run: (*CLI).serve, public: true},
+	{name: "user", help: "Manage users and API tokens", subs: []command{
+		{name: "list", usage: "[flags]", help: "List users", run: (*CLI).userList, perm: auth.ManageUsers},
+		{name: "add", usage: "<username> [flags]", help: "Add a user, reading the password from stdin", run: (*CLI).userAdd, perm: auth.ManageUsers},
+		{name: "role", usage: "<username> <viewer|technician|admin>", help: "Change the role of a user", run: (*CLI).userRole, perm: auth.ManageUsers},
+		{name: "passwd", usage: "<username>", help: "Change a password, reading it from stdin", run: (*CLI).userPasswd},
+		{name: "remove", usage: "<username>", help: "Remove a user and their tokens", run: (*CLI).userRemove, perm: auth.ManageUsers},
+		{name: "token", help: "Manage API tokens", subs: []command{
+			{name: "create", usage: "<username> [flags]", help: "Create an API token and print it", run: (*CLI).tokenCreate},
+			{name: "list", usage: "[flags]", help: "List API tokens", run: (*CLI).tokenList, perm: auth.ManageUsers},
+			{name: "revoke", usage: "<token id>", help: "Revoke an API token", run: (*CLI).tokenRevoke, perm: auth.ManageUsers},
+		}},
+	}},
+}
+
+// Run executes the subcommand in args and returns the process exit code
+// The database at dbPath is opened with opts only once the command is
+// known, so help and usage errors leave it alone
+// Passwords are read from stdin, output goes to stdout and errors to stderr
+// Once users exist, commands sign in with ITROOM_TOKEN, or ITROOM_USER and
+// ITROOM_PASSWORD
+func Run(dbPath string, opts db.Options, cfg *config.Config, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &CLI{ctx: context.Background(), cfg: cfg, stdin: stdin, stdout: stdout, stderr: stderr}
+
+	cmd, path, rest, err := findCommand(commands, args, "itroom")
+	if err != nil {
+		fmt.Fprintf(stderr, "itroom: %v\n\n", err)
+		c.printUsage(stderr, cmd, path)
+		return ExitUsage
+	}
+	if cmd == nil || cmd.run == nil {
+		c.printUsage(stdout, cmd, path)
+		return ExitOK
+	}
+
+	open := func() (*db.DB, error) { return db.Open(dbPath, opts) }
+	if cmd.asIs {
+		open = func() (*db.DB, error) { return db.OpenAsIs(dbPath) }
+	}
+	d, err := open()
+	if err != nil {
+		fmt.Fprintf(stderr, "itroom: failed to open DB: %v\n", err)
+		return ExitError
+	}
+	defer d.Close()
+	c.db, c.svc = d, service.New(repo.NewStores(d.Conn))
+
+	if !cmd.public {
+		err := c.signIn()
+		if errors.Is(err, errUsersUnreadable) && cmd.asIs {
+			fmt.Fprintf(stderr, "itroom: %v, running %s without signing in\n", err, path)
+			err = nil
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "itroom: %v\n", err)
+			return ExitAuth
+		}
+		if err := auth.Check(c.user, cmd.perm); err != nil {
+			fmt.Fprintf(stderr, "itroom: %v\n", err)
+			return ExitAuth
+		}
+	}
+
+	err = cmd.run(c, rest)
+	var usage usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.As(err, &usage):
+		fmt.Fprintf(stderr, "itroom: %v\nusage: %s %s\n", err, path, cmd.usage)
+		return ExitUsage
+	case errors.Is(err, auth.ErrForbidden):
+		fmt.Fprintf(stderr, "itroom: %v\n", err)
+		return ExitAuth
+	}
+	fmt.Fprintf(stderr, "itroom: %v\n", err)
+	return ExitError
+}
+
+// errUsersUnreadable is returned by signIn when the users cannot be read
+var errUsersUnreadable = errors.New("cannot read the users")
+
+// signIn authenticates the user from the environment when any user exists
+func (c *CLI) signIn() error {
+	users := repo.NewUserRepo(c.db.Conn)
+	enabled, err := auth.Enabled(users)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsersUnreadable, err)
+	}
+	if !enabled {
+		return nil
+	}
+
+	if token := os.Getenv("ITROOM_TOKEN"); token != "" {
+		c.user, err = auth.LoginToken(users, token)
+		return err
+	}
+	username := os.Getenv("ITROOM_USER")
+	if username == "" {
+		return errors.New("sign in by setting ITROOM_TOKEN, or ITROOM_USER and ITROOM_PASSWORD")
+	}
+	c.user, err = auth.Login(users, username, os.Getenv("ITROOM_PASSWORD"))
+	return err
+}
+
+// actor returns the username changes are attributed to
+func (c *CLI) actor() *string {
+	return auth.Actor(c.user)
+}
+
+// findCommand walks args down the command tree
+// It returns the deepest command found, its full name and the arguments
+// left for it; a nil command means no command was given
+func findCommand(cmds []command, args []string, path string) (*command, string, []string, error) {
+	var found *command
+	for len(args) > 0 && (found == nil || found.run == nil) {
+		if isHelp(args[0]) {
+			return found, path, nil, nil
+		}
+
+		var next *command
+		for i := range cmds {
+			if cmds[i].name == args[0] {
+				next = &cmds[i]
+			}
+		}
+		if next == nil {
+			return found, path, nil, fmt.Errorf("unknown command %q", args[0])
+		}
+		found, cmds, path, args = next, next.subs, path+" "+next.name, args[1:]
+	}
+	return found, path, args, nil
+}
+
+// isHelp reports whether arg asks for the usage text
+func isHelp(arg string) bool {
+	return arg == "help" || arg == "-h" || arg == "--help"
+}
+
+// printUsage lists the subcommands of cmd, or every command for nil
+func (c *CLI) printUsage(w io.Writer, cmd *command, path string) {
+	cmds := commands
+	if cmd != nil {
+		cmds = cmd.subs
+		fmt.Fprintf(w, "usage: %s <command>\n", path)
+	} else {
+		fmt.Fprintln(w, "usage: itroom [command]")
+		fmt.Fprintln(w, "\nWithout a command itroom starts the interactive interface.")
+	}
+
+	fmt.Fprintln(w, "\nCommands:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	var list func(cmds []command, prefix string)
+	list = func(cmds []command, prefix string) {
+		for _, sub := range cmds {
+			if sub.run != nil {
+				fmt.Fprintf(tw, "  %s %s\t%s\n", prefix+sub.name, sub.usage, sub.help)
+			}
+			list(sub.subs, prefix+sub.name+" ")
+		}
+	}
+	list(cmds, path+" ")
+	tw.Flush()
+	fmt.Fprintln(w, "\nRun a command with -h to see its flags.")
+}
+
+// usageError reports an invalid command line
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+// usagef returns a usageError with a formatted message
+func usagef(format string, args ...any) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+// newFlagSet creates the flag set of a command, writing its help to stderr
+func (c *CLI) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("itroom "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// parseArgs parses flags interleaved with positional arguments and checks
+// the number of positional arguments
+func parseArgs(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	var out []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, usageError{err.Error()}
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		out = append(out, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(out) != positional {
+		return nil, usagef("expected %d argument(s), got %d", positional, len(out))
+	}
+	return out, nil
+}
+
+// newTable returns a writer aligning tab separated columns
+func (c *CLI) newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+}
+
+// printJSON writes v as indented JSON
+func (c *CLI) printJSON(v any) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// parseDate parses a YYYY-MM-DD flag value
+func parseDate(name, value string) (time.Time, error) {
+	t, err := time.Parse(repo.DateLayout, value)
+	if err != nil {
+		return time.Time{}, usagef("invalid %s %q, expected YYYY-MM-DD", name, value)
+	}
+	return t, nil
+}
+
+// today returns the current calendar day as a UTC date
+func today() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// notFound turns sql.ErrNoRows into a readable error about what was missing
+func notFound(err error, what, name string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s %q not found", what, name)
+	}
+	return err
+}
+
+// optional returns nil for an empty string
+func optional(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// timestampFlag parses an optional YYYY-MM-DD flag value used for event
+// dates, defaulting to the current time in UTC like datetime('now')
+func timestampFlag(name, value string) (time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return time.Now().UTC(), nil
+	}
+	return parseDate(name, value)
+}
+
+// formatDate formats an optional date, rendering nil as an empty string
+func formatDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(repo.DateLayout)
+}
+
+// valueOrEmpty dereferences an optional string
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// depreciationSet sets how the assets of a category depreciate
+func (c *CLI) depreciationSet(args []string) error {
+	fs := c.newFlagSet("depreciation set")
+	method := fs.String("method", models.DepreciationStraightLine, "depreciation method: "+strings.Join(models.DepreciationMethods, ", "))
+	life := fs.Int("life", 0, "useful life in years")
+	salvage := fs.Float64("salvage", 0, "value left at the end of the useful life, as a percentage of the cost")
+	factor := fs.String("factor", "", "multiple of the straight-line rate for declining balance, defaults to 2")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *life <= 0 {
+		return usagef("-life is required")
+	}
+
+	cat, err := repo.NewAssetRepo(c.db.Conn).FindAssetCategory(pos[0])
+	if err != nil {
+		return notFound(err, "category", pos[0])
+	}
+	rule := &models.DepreciationRule{CategoryID: cat.CategoryId, Method: *method, UsefulLifeYears: *life,
+		SalvagePercent: *salvage, UpdatedBy: c.actor()}
+	if rule.Factor, err = floatFlag("factor", *factor); err != nil {
+		return err
+	}
+	if err := c.svc.SetDepreciationRule(c.ctx, rule); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Assets in %s now depreciate %s over %d years\n", rule.CategoryName, describeMethod(rule), rule.UsefulLifeYears)
+	return nil
+}
+
+// depreciationList prints the depreciation rule of each category that has
+// one
+func (c *CLI) depreciationList(args []string) error {
+	fs := c.newFlagSet("depreciation list")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	rules, err := repo.NewDepreciationRepo(c.db.Conn).List()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if rules == nil {
+			rules = []*models.DepreciationRule{}
+		}
+		return c.printJSON(rules)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "CATEGORY\tMETHOD\tUSEFUL LIFE\tSALVAGE\tUPDATED BY")
+	for _, r := range rules {
+		fmt.Fprintf(tw, "%s\t%s\t%d years\t%v%%\t%s\n",
+			r.CategoryName, describeMethod(r), r.UsefulLifeYears, r.SalvagePercent, valueOrEmpty(r.UpdatedBy))
+	}
+	return tw.Flush()
+}
+
+// depreciationDelete removes the depreciation rule of a category
+func (c *CLI) depreciationDelete(args []string) error {
+	fs := c.newFlagSet("depreciation delete")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	cat, err := repo.NewAssetRepo(c.db.Conn).FindAssetCategory(pos[0])
+	if err != nil {
+		return notFound(err, "category", pos[0])
+	}
+	if _, err := c.svc.DeleteDepreciationRule(c.ctx, cat.CategoryId); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Assets in %s now keep their cost\n", cat.Description)
+	return nil
+}
+
+// reportBookValue prints what the assets with a purchase cost are worth on
+// a date, one per line or summed by category or location
+func (c *CLI) reportBookValue(args []string) error {
+	fs := c.newFlagSet("report book-value")
+	var qf assetQueryFlags
+	qf.register(fs)
+	date := fs.String("date", "", "value the assets on this date (YYYY-MM-DD), with the location they had then; defaults to today")
+	by := fs.String("by", "asset", "list each asset, or sum them by category or location")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if *by != "asset" && *by != "category" && *by != "location" {
+		return usagef("-by must be asset, category or location")
+	}
+
+	q, err := c.assetQuery(&qf)
+	if err != nil {
+		return err
+	}
+	day := today()
+	if *date != "" {
+		if day, err = parseDate("date", *date); err != nil {
+			return err
+		}
+		q.AsOf = &day
+	} else {
+		q.ExcludeRetired = true
+	}
+	values, err := c.svc.BookValues(c.ctx, q, day)
+	if err != nil {
+		return err
+	}
+
+	tw := c.newTable()
+	if *by == "asset" {
+		if *asJSON {
+			return c.printJSON(values)
+		}
+		fmt.Fprintln(tw, "TAG\tCATEGORY\tLOCATION\tPURCHASED\tMETHOD\tCURRENCY\tCOST\tDEPRECIATION\tBOOK VALUE")
+		for _, v := range values {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				v.AssetTag, v.CategoryName, v.LocationName, formatDate(&v.PurchaseDate), v.Method, v.Currency,
+				formatMoney(v.Cost), formatMoney(v.Depreciation), formatMoney(v.Value))
+		}
+		return tw.Flush()
+	}
+
+	group := func(v *models.BookValue) string { return v.CategoryName }
+	if *by == "location" {
+		group = func(v *models.BookValue) string { return v.LocationName }
+	}
+	totals := models.TotalBookValues(values, group)
+	if *asJSON {
+		if totals == nil {
+			totals = []*models.BookValueTotal{}
+		}
+		return c.printJSON(totals)
+	}
+	fmt.Fprintf(tw, "%s\tCURRENCY\tASSETS\tCOST\tDEPRECIATION\tBOOK VALUE\n", strings.ToUpper(*by))
+	for _, t := range totals {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n",
+			t.Group, t.Currency, t.Assets, formatMoney(t.Cost), formatMoney(t.Depreciation), formatMoney(t.Value))
+	}
+	return tw.Flush()
+}
+
+// bookValue formats what an asset is worth today, empty without a purchase
+// cost
+func (c *CLI) bookValue(a *models.AssetSummary) (string, error) {
+	if a.PurchaseCost == nil {
+		return "", nil
+	}
+	value := *a.PurchaseCost
+	rule, err := repo.NewDepreciationRepo(c.db.Conn).Get(a.CategoryID)
+	switch {
+	case err == nil:
+		value = rule.BookValueOn(value, a.PurchaseDate, today())
+	case !errors.Is(err, sql.ErrNoRows):
+		return "", err
+	}
+	return formatCost(&value, a.Currency), nil
+}
+
+// formatCost formats an optional amount with its currency
+func formatCost(v *float64, currency *string) string {
+	if v == nil {
+		return ""
+	}
+	return strings.TrimSpace(formatMoney(*v) + " " + valueOrEmpty(currency))
+}
+
+// describeMethod names the method of a rule along with its factor
+func describeMethod(r *models.DepreciationRule) string {
+	if r.Factor != nil {
+		return fmt.Sprintf("%s (x%v)", r.Method, *r.Factor)
+	}
+	return r.Method
+}
+
+// formatMoney formats an amount with two decimals
+func formatMoney(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
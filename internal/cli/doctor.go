@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/doctor"
+)
+
+// doctor checks the database for corruption and inconsistent records and,
+// with --fix, applies the safe fixes after taking a snapshot
+func (c *CLI) doctor(args []string) error {
+	fs := c.newFlagSet("doctor")
+	fix := fs.Bool("fix", false, "apply the automatic fixes, taking a snapshot first")
+	list := fs.Bool("checks", false, "list the checks and their fixes instead of running them")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *list {
+		tw := c.newTable()
+		fmt.Fprintln(tw, "CHECK\tDESCRIPTION\tFIX")
+		for _, ch := range doctor.Checks {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", ch.ID, ch.Description, ch.FixAction)
+		}
+		return tw.Flush()
+	}
+
+	if *fix {
+		if err := auth.Check(c.user, auth.RepairData); err != nil {
+			return err
+		}
+		if c.cfg.Backup.Dir != "" {
+			path, err := c.db.Snapshot(c.cfg.Backup.Dir, c.cfg.Backup.Keep)
+			if err != nil {
+				return fmt.Errorf("could not snapshot the database before fixing: %w", err)
+			}
+			if !*asJSON {
+				fmt.Fprintf(c.stdout, "Saved %s\n", path)
+			}
+		}
+		fixed, err := doctor.Fix(c.db.Conn, c.actor())
+		if err != nil {
+			return err
+		}
+		if !*asJSON {
+			for _, ch := range doctor.Checks {
+				if fixed[ch.ID] > 0 {
+					fmt.Fprintf(c.stdout, "Fixed %d row(s): %s, %s\n", fixed[ch.ID], ch.Description, ch.FixAction)
+				}
+			}
+		}
+	}
+
+	findings, err := doctor.Run(c.db.Conn)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		if findings == nil {
+			findings = []doctor.Finding{}
+		}
+		if err := c.printJSON(findings); err != nil {
+			return err
+		}
+	} else if len(findings) > 0 {
+		tw := c.newTable()
+		fmt.Fprintln(tw, "CHECK\tRECORD\tID\tFIXABLE\tPROBLEM")
+		for _, f := range findings {
+			fixable := "no"
+			if f.Fixable {
+				fixable = "yes"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", f.Check, f.Entity, f.ID, fixable, f.Message)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(findings))
+	}
+	if !*asJSON {
+		fmt.Fprintln(c.stdout, "No problems found")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/dataio"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// export writes every record of an entity to a file or stdout
+// Assets accept the same filters as asset list
+func (c *CLI) export(args []string) error {
+	fs := c.newFlagSet("export")
+	format := fs.String("format", "", "csv, jsonl or xlsx; defaults to the output extension, or csv")
+	output := fs.String("o", "", "output file, stdout when empty")
+	var qf assetQueryFlags
+	qf.register(fs)
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	entity, ok := dataio.FindEntity(pos[0])
+	if !ok {
+		var names []string
+		for _, e := range dataio.Entities {
+			names = append(names, e.Name)
+		}
+		return usagef("unknown entity %q, expected one of %s", pos[0], strings.Join(names, ", "))
+	}
+
+	f := dataio.FormatForPath(*output)
+	if *format != "" {
+		if f, err = dataio.ParseFormat(*format); err != nil {
+			return usageError{err.Error()}
+		}
+	}
+
+	var table *dataio.Table
+	if entity.Name == "assets" {
+		q, err := c.assetQuery(&qf)
+		if err != nil {
+			return err
+		}
+		if table, err = dataio.LoadAssets(repo.NewStores(c.db.Conn), q); err != nil {
+			return err
+		}
+	} else if table, err = entity.Load(c.db.Conn); err != nil {
+		return err
+	}
+	if !auth.Can(c.user, auth.ViewSecrets) {
+		table.Drop("license_key")
+	}
+
+	if *output == "" {
+		return dataio.Write(c.stdout, f, table)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := dataio.Write(file, f, table); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "Exported %d %s to %s\n", len(table.Rows), entity.Name, *output)
+	return nil
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// fieldAdd defines a custom field for an asset type or category
+func (c *CLI) fieldAdd(args []string) error {
+	fs := c.newFlagSet("field add")
+	kind := fs.String("kind", models.FieldText, "kind of value: "+strings.Join(models.FieldKinds, ", "))
+	typeName := fs.String("type", "", "asset type the field belongs to, e.g. Laptop")
+	category := fs.String("category", "", "category whose types all get the field (name or code prefix)")
+	required := fs.Bool("required", false, "assets must have a value for the field")
+	var options listFlag
+	fs.Var(&options, "option", "value an enum field accepts (repeatable)")
+	pattern := fs.String("pattern", "", "regular expression text values must match as a whole")
+	minValue := fs.String("min", "", "lowest number accepted")
+	maxValue := fs.String("max", "", "highest number accepted")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if (*typeName == "") == (*category == "") {
+		return usagef("exactly one of -type and -category is required")
+	}
+
+	f := &models.CustomField{Name: pos[0], Kind: *kind, Required: *required, Options: options,
+		Pattern: optional(*pattern), CreatedBy: c.actor()}
+	if f.Min, err = floatFlag("min", *minValue); err != nil {
+		return err
+	}
+	if f.Max, err = floatFlag("max", *maxValue); err != nil {
+		return err
+	}
+	assetRepo := repo.NewAssetRepo(c.db.Conn)
+	if *typeName != "" {
+		typ, err := assetRepo.FindAssetType(*typeName)
+		if err != nil {
+			return notFound(err, "asset type", *typeName)
+		}
+		f.TypeID = &typ.TypeID
+	} else {
+		cat, err := assetRepo.FindAssetCategory(*category)
+		if err != nil {
+			return notFound(err, "category", *category)
+		}
+		f.CategoryID = &cat.CategoryId
+	}
+	if err := c.svc.CreateCustomField(c.ctx, f); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Created field %s (ID %d)\n", f.Name, f.FieldID)
+	return nil
+}
+
+// fieldList prints the custom fields, or those of one asset type
+func (c *CLI) fieldList(args []string) error {
+	fs := c.newFlagSet("field list")
+	typeName := fs.String("type", "", "only the fields that apply to this asset type")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	fieldRepo := repo.NewFieldRepo(c.db.Conn)
+	var fields []*models.CustomField
+	var err error
+	if *typeName != "" {
+		typ, err := repo.NewAssetRepo(c.db.Conn).FindAssetType(*typeName)
+		if err != nil {
+			return notFound(err, "asset type", *typeName)
+		}
+		fields, err = fieldRepo.ListForType(typ.TypeID)
+	} else {
+		fields, err = fieldRepo.List()
+	}
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if fields == nil {
+			fields = []*models.CustomField{}
+		}
+		return c.printJSON(fields)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "ID\tNAME\tKIND\tCATEGORY\tTYPE\tREQUIRED\tACCEPTS")
+	for _, f := range fields {
+		required := ""
+		if f.Required {
+			required = "yes"
+		}
+		typ := "(all)"
+		if f.TypeName != nil {
+			typ = *f.TypeName
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", f.FieldID, f.Name, f.Kind, f.CategoryName, typ, required, accepts(f))
+	}
+	return tw.Flush()
+}
+
+// fieldDelete removes a custom field and its values
+func (c *CLI) fieldDelete(args []string) error {
+	fs := c.newFlagSet("field delete")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(pos[0])
+	if err != nil {
+		return usagef("invalid field ID %q", pos[0])
+	}
+
+	f, err := c.svc.DeleteCustomField(c.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Deleted field %s and its values\n", f.Name)
+	return nil
+}
+
+// accepts describes the values a custom field accepts beyond its kind
+func accepts(f *models.CustomField) string {
+	switch {
+	case len(f.Options) > 0:
+		return strings.Join(f.Options, ", ")
+	case f.Pattern != nil:
+		return *f.Pattern
+	case f.Min != nil && f.Max != nil:
+		return fmt.Sprintf("%v to %v", *f.Min, *f.Max)
+	case f.Min != nil:
+		return fmt.Sprintf(">= %v", *f.Min)
+	case f.Max != nil:
+		return fmt.Sprintf("<= %v", *f.Max)
+	}
+	return ""
+}
+
+// floatFlag parses an optional number flag, nil when empty
+func floatFlag(name, value string) (*float64, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil, usagef("invalid -%s %q, expected a number", name, value)
+	}
+	return &n, nil
+}
+
+// fieldValues turns -field name=value flags into values by field name
+func fieldValues(flags listFlag) (map[string]string, error) {
+	out := map[string]string{}
+	for _, f := range flags {
+		name, value, ok := strings.Cut(f, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, usagef("invalid -field %q, expected name=value", f)
+		}
+		out[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return out, nil
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/dataio"
+)
+
+// mappingFlag collects repeated -map field=Header flags
+type mappingFlag map[string]string
+
+func (m mappingFlag) String() string {
+	var parts []string
+	for k, v := range m {
+		parts = append(parts, k+"="+v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (m mappingFlag) Set(s string) error {
+	key, header, ok := strings.Cut(s, "=")
+	if !ok || key == "" || header == "" {
+		return fmt.Errorf("expected field=Header, got %q", s)
+	}
+	m[strings.TrimSpace(key)] = strings.TrimSpace(header)
+	return nil
+}
+
+// importAssets loads assets from a CSV file in a single transaction
+func (c *CLI) importAssets(args []string) error {
+	fs := c.newFlagSet("import assets")
+	mapping := mappingFlag{}
+	fs.Var(mapping, "map", "map a field to a CSV header, e.g. -map serial_number=\"S/N\" (repeatable)")
+	dateFormat := fs.String("date-format", "", "date format such as DD/MM/YYYY, detected when empty")
+	dryRun := fs.Bool("dry-run", false, "validate every row without saving")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(pos[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	header, records, err := dataio.ReadCSV(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", pos[0], err)
+	}
+
+	m := dataio.GuessMapping(header)
+	for key, name := range mapping {
+		if !isAssetField(key) {
+			return usagef("unknown field %q in -map", key)
+		}
+		i := indexOfHeader(header, name)
+		if i < 0 {
+			return fmt.Errorf("%s has no column %q", pos[0], name)
+		}
+		m[key] = i
+	}
+
+	opts := dataio.ImportOptions{Mapping: m, DryRun: *dryRun, Actor: c.actor(), Currency: c.cfg.Currency}
+	if *dateFormat != "" {
+		opts.DateLayout = dataio.ParseDateFormat(*dateFormat)
+	}
+	report, err := c.svc.ImportAssets(c.ctx, header, records, opts)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		if err := c.printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		c.printImportReport(header, m, report)
+	}
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d error(s), nothing was imported", len(report.Errors))
+	}
+	return nil
+}
+
+// printImportReport describes the mapping used, the row errors and the outcome
+func (c *CLI) printImportReport(header []string, m dataio.Mapping, report *dataio.ImportReport) {
+	fmt.Fprintf(c.stdout, "Read %d rows, date format %s\n", report.Rows, dataio.DescribeDateLayout(report.DateLayout))
+
+	tw := c.newTable()
+	for _, f := range dataio.AssetFields {
+		column := "(not mapped)"
+		if i, ok := m[f.Key]; ok {
+			column = fmt.Sprintf("%q", header[i])
+		}
+		fmt.Fprintf(tw, "  %s\t<- %s\n", f.Key, column)
+	}
+	for _, name := range report.CustomColumns {
+		fmt.Fprintf(tw, "  %s\t<- custom field\n", name)
+	}
+	tw.Flush()
+	if len(report.IgnoredColumns) > 0 {
+		fmt.Fprintf(c.stdout, "Ignored columns matching no field: %s\n", strings.Join(report.IgnoredColumns, ", "))
+	}
+
+	for _, e := range report.Errors {
+		fmt.Fprintln(c.stdout, e.Error())
+	}
+
+	switch {
+	case len(report.Errors) > 0:
+	case report.DryRun:
+		fmt.Fprintf(c.stdout, "Dry run: all %d rows are valid, nothing was saved\n", report.Imported)
+	default:
+		fmt.Fprintf(c.stdout, "Imported %d assets\n", report.Imported)
+	}
+}
+
+// isAssetField reports whether key names an importable asset field
+func isAssetField(key string) bool {
+	for _, f := range dataio.AssetFields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// indexOfHeader returns the position of a header name, ignoring case
+func indexOfHeader(header []string, name string) int {
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,334 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// listFlag collects the values of a repeated flag
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(s string) error {
+	if s = strings.TrimSpace(s); s == "" {
+		return fmt.Errorf("empty value")
+	}
+	*l = append(*l, s)
+	return nil
+}
+
+// kitTemplateAdd creates a kit template from --item Type[=quantity] flags
+func (c *CLI) kitTemplateAdd(args []string) error {
+	fs := c.newFlagSet("kit template add")
+	var items listFlag
+	fs.Var(&items, "item", "asset type in the kit, with an optional quantity, e.g. -item Mouse or -item Monitor=2 (repeatable)")
+	description := fs.String("description", "", "what the kit is for")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return usagef("at least one -item is required")
+	}
+
+	t := &models.KitTemplate{Name: pos[0], Description: optional(*description)}
+	assetRepo := repo.NewAssetRepo(c.db.Conn)
+	for _, item := range items {
+		name, qty, hasQty := strings.Cut(item, "=")
+		quantity := 1
+		if hasQty {
+			if quantity, err = strconv.Atoi(strings.TrimSpace(qty)); err != nil {
+				return usagef("invalid quantity in -item %q", item)
+			}
+		}
+		typ, err := assetRepo.FindAssetType(strings.TrimSpace(name))
+		if err != nil {
+			return notFound(err, "asset type", name)
+		}
+		t.Items = append(t.Items, models.KitTemplateItem{TypeID: typ.TypeID, Quantity: quantity})
+	}
+	if err := c.svc.CreateKitTemplate(c.ctx, t); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Created kit template %s\n", t.Name)
+	return nil
+}
+
+// kitTemplateList prints the kit templates and their items
+func (c *CLI) kitTemplateList(args []string) error {
+	fs := c.newFlagSet("kit template list")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	templates, err := repo.NewKitRepo(c.db.Conn).ListTemplates()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if templates == nil {
+			templates = []*models.KitTemplate{}
+		}
+		return c.printJSON(templates)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "TEMPLATE\tITEMS\tDESCRIPTION")
+	for _, t := range templates {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", t.Name, templateItems(t), valueOrEmpty(t.Description))
+	}
+	return tw.Flush()
+}
+
+// kitList prints the kits with their state and holder
+func (c *CLI) kitList(args []string) error {
+	fs := c.newFlagSet("kit list")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	kits, err := c.svc.ListKits(c.ctx)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(kits)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "KIT\tTEMPLATE\tASSETS\tSTATE\tHOLDER\tMISSING")
+	for _, k := range kits {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", k.KitTag, valueOrEmpty(k.TemplateName), len(k.Assets),
+			k.State, valueOrEmpty(k.HolderName), strings.Join(k.Missing, ", "))
+	}
+	return tw.Flush()
+}
+
+// kitShow prints a kit and its members
+func (c *CLI) kitShow(args []string) error {
+	fs := c.newFlagSet("kit show")
+	asJSON := fs.Bool("json", false, "print JSON")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	k, err := c.svc.KitByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(k)
+	}
+
+	tw := c.newTable()
+	for _, f := range [][2]string{
+		{"Kit Tag", k.KitTag},
+		{"Template", valueOrEmpty(k.TemplateName)},
+		{"State", k.State},
+		{"Holder", valueOrEmpty(k.HolderName)},
+		{"Missing", strings.Join(k.Missing, ", ")},
+		{"Notes", valueOrEmpty(k.Notes)},
+	} {
+		fmt.Fprintf(tw, "%s:\t%s\n", f[0], f[1])
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "TAG\tTYPE\tMAKE\tMODEL\tSTATUS\tLOCATION\tHOLDER")
+	for _, a := range k.Assets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", a.AssetTag, a.TypeName, a.Maker, a.Model,
+			a.StatusName, a.LocationName, valueOrEmpty(a.HolderName))
+	}
+	return tw.Flush()
+}
+
+// kitAdd groups assets into a kit
+func (c *CLI) kitAdd(args []string) error {
+	fs := c.newFlagSet("kit add")
+	var assets listFlag
+	fs.Var(&assets, "asset", "tag of an asset in the kit (repeatable)")
+	template := fs.String("template", "", "kit template the assets follow")
+	notes := fs.String("notes", "", "free-form notes")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if len(assets) == 0 {
+		return usagef("at least one -asset is required")
+	}
+
+	k := &models.Kit{KitTag: pos[0], Notes: optional(*notes), CreatedBy: c.actor()}
+	if *template != "" {
+		t, err := repo.NewKitRepo(c.db.Conn).FindTemplate(*template)
+		if err != nil {
+			return notFound(err, "kit template", *template)
+		}
+		k.TemplateID = &t.TemplateID
+	}
+	for _, tag := range assets {
+		a, err := c.svc.AssetByTag(c.ctx, tag)
+		if err != nil {
+			return err
+		}
+		k.AssetIDs = append(k.AssetIDs, a.AssetID)
+	}
+	if err := c.svc.CreateKit(c.ctx, k); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Created kit %s with %d asset(s)\n", k.KitTag, len(k.AssetIDs))
+	return nil
+}
+
+// kitAssign hands every asset of a kit to an employee
+func (c *CLI) kitAssign(args []string) error {
+	fs := c.newFlagSet("kit assign")
+	date := fs.String("date", "", "assignment date (YYYY-MM-DD), defaults to now")
+	due := fs.String("due", "", "date the kit is due back (YYYY-MM-DD), for loans")
+	notes := fs.String("notes", "", "free-form notes")
+	pos, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	assignment := models.AssetAssignment{Notes: optional(*notes), AssignedBy: c.actor()}
+	if assignment.AssignmentDate, err = timestampFlag("date", *date); err != nil {
+		return err
+	}
+	if *due != "" {
+		dueDate, err := parseDate("due date", *due)
+		if err != nil {
+			return err
+		}
+		assignment.DueDate = &dueDate
+	}
+
+	k, err := c.svc.KitByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if err := c.svc.AssignKit(c.ctx, k.KitID, assignment, pos[1]); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Assigned kit %s (%d asset(s)) to %s\n", k.KitTag, len(k.Assets), pos[1])
+	return nil
+}
+
+// kitReturn returns the held assets of a kit
+func (c *CLI) kitReturn(args []string) error {
+	fs := c.newFlagSet("kit return")
+	date := fs.String("date", "", "return date (YYYY-MM-DD), defaults to now")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	when, err := timestampFlag("date", *date)
+	if err != nil {
+		return err
+	}
+	k, err := c.svc.KitByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if err := c.svc.ReturnKit(c.ctx, k.KitID, when, c.actor()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Returned kit %s\n", k.KitTag)
+	return nil
+}
+
+// kitTransfer moves the assets of a kit to a location
+func (c *CLI) kitTransfer(args []string) error {
+	fs := c.newFlagSet("kit transfer")
+	pos, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	l, err := repo.NewLocationRepo(c.db.Conn).FindByName(pos[1])
+	if err != nil {
+		return notFound(err, "location", pos[1])
+	}
+	k, err := c.svc.KitByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if err := c.svc.TransferKit(c.ctx, k.KitID, l.LocationID, c.actor()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Moved kit %s to %s\n", k.KitTag, l.Name)
+	return nil
+}
+
+// kitSwap replaces one asset of a kit with another of the same type
+func (c *CLI) kitSwap(args []string) error {
+	fs := c.newFlagSet("kit swap")
+	date := fs.String("date", "", "date of the swap (YYYY-MM-DD), defaults to now")
+	pos, err := parseArgs(fs, args, 3)
+	if err != nil {
+		return err
+	}
+
+	when, err := timestampFlag("date", *date)
+	if err != nil {
+		return err
+	}
+	k, err := c.svc.KitByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	old, err := c.svc.AssetByTag(c.ctx, pos[1])
+	if err != nil {
+		return err
+	}
+	replacement, err := c.svc.AssetByTag(c.ctx, pos[2])
+	if err != nil {
+		return err
+	}
+	if err := c.svc.SwapKitAsset(c.ctx, k.KitID, old.AssetID, replacement.AssetID, when, c.actor()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Replaced %s with %s in kit %s\n", old.AssetTag, replacement.AssetTag, k.KitTag)
+	return nil
+}
+
+// kitDissolve deletes a kit, leaving its assets as they are
+func (c *CLI) kitDissolve(args []string) error {
+	fs := c.newFlagSet("kit dissolve")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	k, err := c.svc.KitByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if err := c.svc.DissolveKit(c.ctx, k.KitID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Dissolved kit %s\n", k.KitTag)
+	return nil
+}
+
+// templateItems describes the items of a kit template, e.g. "2 Monitor, 1 Mouse"
+func templateItems(t *models.KitTemplate) string {
+	parts := make([]string, 0, len(t.Items))
+	for _, i := range t.Items {
+		parts = append(parts, fmt.Sprintf("%d %s", i.Quantity, i.TypeName))
+	}
+	return strings.Join(parts, ", ")
+}
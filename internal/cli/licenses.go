@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// licenseList prints the software licenses and their seat usage
+func (c *CLI) licenseList(args []string) error {
+	fs := c.newFlagSet("license list")
+	filter := fs.String("filter", "", "only licenses whose software name contains this text")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	licenseRepo := repo.NewLicenseRepo(c.db.Conn)
+	var licenses []*models.SoftwareLicense
+	var err error
+	if *filter != "" {
+		licenses, err = licenseRepo.Search(*filter, -1)
+	} else {
+		licenses, err = licenseRepo.List()
+	}
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		if licenses == nil {
+			licenses = []*models.SoftwareLicense{}
+		}
+		auth.HideLicenseKeys(c.user, licenses)
+		return c.printJSON(licenses)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "SOFTWARE\tTYPE\tSEATS USED\tSEATS PURCHASED\tPURCHASED\tEXPIRES")
+	for _, l := range licenses {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%s\n",
+			l.SoftwareName, l.LicenseType, l.SeatsUsed, l.SeatsPurchased,
+			formatDate(&l.PurchaseDate), formatDate(l.ExpirationDate))
+	}
+	return tw.Flush()
+}
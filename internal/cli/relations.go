@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// assetLink makes one asset a child of another
+func (c *CLI) assetLink(args []string) error {
+	fs := c.newFlagSet("asset link")
+	pos, err := parseArgs(fs, args, 3)
+	if err != nil {
+		return err
+	}
+
+	child, err := c.svc.AssetByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	parent, err := c.svc.AssetByTag(c.ctx, pos[2])
+	if err != nil {
+		return err
+	}
+	rel := &models.AssetRelation{ChildID: child.AssetID, ParentID: parent.AssetID, Relation: pos[1], CreatedBy: c.actor()}
+	if err := c.svc.LinkAssets(c.ctx, rel); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Linked %s %s %s\n", rel.ChildTag, rel.Relation, rel.ParentTag)
+	return nil
+}
+
+// assetUnlink removes an asset from its parent
+func (c *CLI) assetUnlink(args []string) error {
+	fs := c.newFlagSet("asset unlink")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	a, err := c.svc.AssetByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if err := c.svc.UnlinkAsset(c.ctx, a.AssetID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Unlinked %s\n", a.AssetTag)
+	return nil
+}
+
+// assetTree prints the tree of relations an asset belongs to
+func (c *CLI) assetTree(args []string) error {
+	fs := c.newFlagSet("asset tree")
+	asJSON := fs.Bool("json", false, "print JSON")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	a, err := c.svc.AssetByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	tree, err := c.svc.AssetTree(c.ctx, a.AssetID)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(tree)
+	}
+
+	var printNode func(n *models.AssetNode, depth int)
+	printNode = func(n *models.AssetNode, depth int) {
+		line := strings.Repeat("  ", depth)
+		if n.Relation != "" {
+			line += n.Relation + " "
+		}
+		line += fmt.Sprintf("%s  %s %s %s (%s)", n.Asset.AssetTag, n.Asset.TypeName, n.Asset.Maker, n.Asset.Model, n.Asset.StatusName)
+		if n.Asset.AssetID == a.AssetID {
+			line += "  <"
+		}
+		fmt.Fprintln(c.stdout, line)
+		for _, child := range n.Children {
+			printNode(child, depth+1)
+		}
+	}
+	printNode(tree, 0)
+	return nil
+}
+
+// assetMove moves an asset, and optionally its components, to a location
+func (c *CLI) assetMove(args []string) error {
+	fs := c.newFlagSet("asset move")
+	withChildren := fs.Bool("with-children", false, "move the assets linked under it too")
+	pos, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	l, err := repo.NewLocationRepo(c.db.Conn).FindByName(pos[1])
+	if err != nil {
+		return notFound(err, "location", pos[1])
+	}
+	a, err := c.svc.AssetByTag(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if err := c.svc.MoveAsset(c.ctx, a.AssetID, l.LocationID, c.actor(), *withChildren); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Moved %s to %s\n", a.AssetTag, l.Name)
+	return nil
+}
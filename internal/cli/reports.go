@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// reportWarranty prints the warranty states by vendor, or the assets whose
+// warranty ends within the configured lead times
+func (c *CLI) reportWarranty(args []string) error {
+	fs := c.newFlagSet("report warranty")
+	upcoming := fs.Bool("upcoming", false, "list the assets whose warranty ends within the longest lead time")
+	within := fs.Int("days", c.cfg.WarrantySoonDays(), "days counted as expiring soon")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if *within < 0 {
+		return usagef("-days cannot be negative")
+	}
+
+	assetRepo := repo.NewAssetRepo(c.db.Conn)
+	day := today()
+	if *upcoming {
+		to := day.AddDate(0, 0, *within)
+		assets, err := assetRepo.ListSummaries(repo.AssetQuery{
+			SortColumn:      "warranty_end_date",
+			WarrantyEndFrom: &day,
+			WarrantyEndTo:   &to,
+			ExcludeRetired:  true,
+		})
+		if err != nil {
+			return err
+		}
+
+		if *asJSON {
+			out := make([]assetOutput, 0, len(assets))
+			for _, a := range assets {
+				out = append(out, c.assetOutput(a))
+			}
+			return c.printJSON(out)
+		}
+
+		tw := c.newTable()
+		fmt.Fprintln(tw, "TAG\tVENDOR\tMODEL\tLOCATION\tHOLDER\tWARRANTY END\tDAYS LEFT")
+		for _, a := range assets {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+				a.AssetTag, a.Maker, a.Model, a.LocationName, valueOrEmpty(a.HolderName),
+				formatDate(a.WarrantyEndDate), int(a.WarrantyEndDate.Sub(day).Hours()/24))
+		}
+		return tw.Flush()
+	}
+
+	report, err := assetRepo.WarrantyReport(day, *within)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if report == nil {
+			report = []*models.WarrantyVendorSummary{}
+		}
+		return c.printJSON(report)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "VENDOR\tACTIVE\tEXPIRING SOON\tEXPIRED\tNONE\tTOTAL")
+	for _, s := range report {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\n",
+			s.Vendor, s.Active, s.ExpiringSoon, s.Expired, s.None, s.Total())
+	}
+	return tw.Flush()
+}
+
+// reportAsOf prints the assets that existed on a past date with the status,
+// location and holder they had at the end of that day
+func (c *CLI) reportAsOf(args []string) error {
+	fs := c.newFlagSet("report as-of")
+	var qf assetQueryFlags
+	qf.register(fs)
+	employee := fs.String("employee", "", "only assets held by this employee (email or name)")
+	location := fs.String("location", "", "only assets at this location")
+	asJSON := fs.Bool("json", false, "print JSON")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	day, err := parseDate("date", pos[0])
+	if err != nil {
+		return err
+	}
+	q, err := c.assetQuery(&qf)
+	if err != nil {
+		return err
+	}
+	q.AsOf = &day
+	if *employee != "" {
+		e, err := repo.NewEmployeeRepo(c.db.Conn).Resolve(*employee)
+		if err != nil {
+			return err
+		}
+		q.HolderID = e.EmployeeID
+	}
+	if *location != "" {
+		l, err := repo.NewLocationRepo(c.db.Conn).FindByName(*location)
+		if err != nil {
+			return notFound(err, "location", *location)
+		}
+		q.LocationID = l.LocationID
+	}
+
+	assets, err := repo.NewAssetRepo(c.db.Conn).ListSummaries(q)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		out := make([]assetOutput, 0, len(assets))
+		for _, a := range assets {
+			state := a.WarrantyStateOn(day, c.cfg.WarrantySoonDays())
+			out = append(out, assetOutput{AssetSummary: a, WarrantyState: state.String()})
+		}
+		return c.printJSON(out)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "TAG\tCATEGORY\tTYPE\tMAKE\tMODEL\tSERIAL\tSTATUS\tLOCATION\tHOLDER")
+	for _, a := range assets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			a.AssetTag, a.CategoryName, a.TypeName, a.Maker, a.Model, a.SerialNumber,
+			a.StatusName, a.LocationName, valueOrEmpty(a.HolderName))
+	}
+	return tw.Flush()
+}
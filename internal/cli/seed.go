@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/seed"
+)
+
+// seed fills an empty database with a generated demo inventory
+func (c *CLI) seed(args []string) error {
+	fs := c.newFlagSet("seed")
+	assets := fs.Int("assets", 500, "number of assets to generate")
+	employees := fs.Int("employees", 50, "number of employees to generate")
+	rngSeed := fs.Uint64("seed", 1, "random seed, the same seed generates the same data")
+	date := fs.String("date", "", "generate history up to this date (YYYY-MM-DD, default today)")
+	asJSON := fs.Bool("json", false, "print the summary as JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if *assets < 0 || *employees < 0 {
+		return usagef("-assets and -employees cannot be negative")
+	}
+	today := today()
+	if *date != "" {
+		var err error
+		if today, err = parseDate("date", *date); err != nil {
+			return err
+		}
+	}
+
+	summary, err := c.svc.Seed(c.ctx, seed.Options{
+		Assets: *assets, Employees: *employees, Seed: *rngSeed, Today: today, Actor: c.actor(),
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(summary)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintf(tw, "Employees\t%d\n", summary.Employees)
+	fmt.Fprintf(tw, "Assets\t%d (%d retired)\n", summary.Assets, summary.Retired)
+	fmt.Fprintf(tw, "Assignments\t%d\n", summary.Assignments)
+	fmt.Fprintf(tw, "Maintenance logs\t%d\n", summary.Maintenance)
+	fmt.Fprintf(tw, "Licenses\t%d (%d installs)\n", summary.Licenses, summary.Installs)
+	fmt.Fprintf(tw, "Consumable types\t%d (%d used)\n", summary.Consumables, summary.Usage)
+	return tw.Flush()
+}
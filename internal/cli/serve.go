@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/api"
+)
+
+// serve runs the REST API server until SIGINT or SIGTERM
+func (c *CLI) serve(args []string) error {
+	fs := c.newFlagSet("serve")
+	addr := fs.String("addr", "127.0.0.1:8080", "address to listen on")
+	quiet := fs.Bool("quiet", false, "do not log requests")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	var logger *log.Logger
+	if !*quiet {
+		logger = log.New(c.stderr, "", log.LstdFlags)
+	}
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           api.New(c.db, c.cfg, logger),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Requests in flight get a few seconds to finish once interrupted
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		done <- srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(c.stderr, "Serving the API on http://%s%s\n", ln.Addr(), api.Prefix)
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-done
+}
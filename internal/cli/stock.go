@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// stockAdd creates a stock item with nothing in stock
+func (c *CLI) stockAdd(args []string) error {
+	fs := c.newFlagSet("stock add")
+	typeName := fs.String("type", "", "asset type the item is a kind of, e.g. Mouse")
+	reorder := fs.Int("reorder", 0, "units on hand at or below which the item is low on stock")
+	notes := fs.String("notes", "", "free-form notes")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	i := &models.StockItem{Name: pos[0], ReorderLevel: *reorder, Notes: optional(*notes), CreatedBy: c.actor()}
+	if *typeName != "" {
+		typ, err := repo.NewAssetRepo(c.db.Conn).FindAssetType(*typeName)
+		if err != nil {
+			return notFound(err, "asset type", *typeName)
+		}
+		i.TypeID = &typ.TypeID
+	}
+	if err := c.svc.CreateStockItem(c.ctx, i); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Created stock item %s\n", i.Name)
+	return nil
+}
+
+// stockList prints the stock items with their totals
+func (c *CLI) stockList(args []string) error {
+	fs := c.newFlagSet("stock list")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	items, err := c.svc.ListStockItems(c.ctx)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(items)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "ITEM\tTYPE\tON HAND\tISSUED\tREORDER AT\t")
+	for _, i := range items {
+		low := ""
+		if i.ReorderLevel > 0 && i.OnHand <= i.ReorderLevel {
+			low = "LOW"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\n", i.Name, valueOrEmpty(i.TypeName), i.OnHand, i.Issued, i.ReorderLevel, low)
+	}
+	return tw.Flush()
+}
+
+// stockShow prints a stock item with its units by location and holder
+func (c *CLI) stockShow(args []string) error {
+	fs := c.newFlagSet("stock show")
+	asJSON := fs.Bool("json", false, "print JSON")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	d, err := c.svc.StockItemByName(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(d)
+	}
+
+	tw := c.newTable()
+	for _, f := range [][2]string{
+		{"Item", d.Name},
+		{"Type", valueOrEmpty(d.TypeName)},
+		{"On Hand", fmt.Sprint(d.OnHand)},
+		{"Issued", fmt.Sprint(d.Issued)},
+		{"Reorder At", fmt.Sprint(d.ReorderLevel)},
+		{"Notes", valueOrEmpty(d.Notes)},
+	} {
+		fmt.Fprintf(tw, "%s:\t%s\n", f[0], f[1])
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "LOCATION\tQUANTITY")
+	for _, l := range d.Levels {
+		fmt.Fprintf(tw, "%s\t%d\n", l.LocationName, l.Quantity)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "HOLDER\tQUANTITY")
+	for _, h := range d.Holdings {
+		fmt.Fprintf(tw, "%s\t%d\n", h.EmployeeName, h.Quantity)
+	}
+	return tw.Flush()
+}
+
+// stockHistory prints the movements of a stock item, newest first
+func (c *CLI) stockHistory(args []string) error {
+	fs := c.newFlagSet("stock history")
+	asJSON := fs.Bool("json", false, "print JSON")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	d, err := c.svc.StockItemByName(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	movements, err := repo.NewStockRepo(c.db.Conn).ListMovements(d.ItemID)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if movements == nil {
+			movements = []*models.StockMovement{}
+		}
+		return c.printJSON(movements)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "DATE\tKIND\tQUANTITY\tLOCATION\tEMPLOYEE\tBY\tNOTES")
+	for _, m := range movements {
+		fmt.Fprintf(tw, "%s\t%s\t%+d\t%s\t%s\t%s\t%s\n", formatDate(&m.MovedAt), m.Kind, m.Quantity, m.LocationName,
+			valueOrEmpty(m.EmployeeName), valueOrEmpty(m.MovedBy), valueOrEmpty(m.Notes))
+	}
+	return tw.Flush()
+}
+
+// stockHeld prints the stock items an employee holds
+func (c *CLI) stockHeld(args []string) error {
+	fs := c.newFlagSet("stock held")
+	asJSON := fs.Bool("json", false, "print JSON")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	holdings, err := c.svc.StockHeldBy(c.ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(holdings)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "ITEM\tQUANTITY")
+	for _, h := range holdings {
+		fmt.Fprintf(tw, "%s\t%d\n", h.ItemName, h.Quantity)
+	}
+	return tw.Flush()
+}
+
+// stockAdjust adds units of an item at a location, or removes them
+func (c *CLI) stockAdjust(args []string) error {
+	fs := c.newFlagSet("stock adjust")
+	quantity := fs.Int("quantity", 0, "units to add, or to remove when negative, e.g. -quantity -2")
+	m, pos, err := c.stockMovementFlags(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	l, err := repo.NewLocationRepo(c.db.Conn).FindByName(pos[1])
+	if err != nil {
+		return notFound(err, "location", pos[1])
+	}
+
+	m.LocationID, m.Quantity = l.LocationID, *quantity
+	if err := c.svc.AdjustStock(c.ctx, m); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Adjusted %s at %s by %+d\n", m.ItemName, l.Name, m.Quantity)
+	return nil
+}
+
+// stockIssue hands units of an item to an employee
+func (c *CLI) stockIssue(args []string) error {
+	fs := c.newFlagSet("stock issue")
+	from := fs.String("from", "", "location the units are taken from (required)")
+	quantity := fs.Int("quantity", 1, "units to issue")
+	m, pos, err := c.stockMovementFlags(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	if *from == "" {
+		return usagef("-from is required")
+	}
+	l, err := repo.NewLocationRepo(c.db.Conn).FindByName(*from)
+	if err != nil {
+		return notFound(err, "location", *from)
+	}
+
+	m.LocationID, m.Quantity = l.LocationID, *quantity
+	if err := c.svc.IssueStock(c.ctx, m, pos[1]); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Issued %d %s to %s\n", *quantity, m.ItemName, *m.EmployeeName)
+	return nil
+}
+
+// stockReturn takes units of an item back from an employee
+func (c *CLI) stockReturn(args []string) error {
+	fs := c.newFlagSet("stock return")
+	to := fs.String("to", "", "location the units go back to (required)")
+	quantity := fs.Int("quantity", 1, "units to return")
+	m, pos, err := c.stockMovementFlags(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	if *to == "" {
+		return usagef("-to is required")
+	}
+	l, err := repo.NewLocationRepo(c.db.Conn).FindByName(*to)
+	if err != nil {
+		return notFound(err, "location", *to)
+	}
+
+	m.LocationID, m.Quantity = l.LocationID, *quantity
+	if err := c.svc.ReturnStock(c.ctx, m, pos[1]); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Returned %d %s from %s\n", *quantity, m.ItemName, *m.EmployeeName)
+	return nil
+}
+
+// stockMovementFlags adds the -date and -notes flags shared by the stock
+// movements, parses args and returns a movement of the item named by the
+// first positional argument
+func (c *CLI) stockMovementFlags(fs *flag.FlagSet, args []string, positional int) (*models.StockMovement, []string, error) {
+	date := fs.String("date", "", "date of the movement (YYYY-MM-DD), defaults to now")
+	notes := fs.String("notes", "", "free-form notes")
+	pos, err := parseArgs(fs, args, positional)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := &models.StockMovement{Notes: optional(*notes), MovedBy: c.actor()}
+	if m.MovedAt, err = timestampFlag("date", *date); err != nil {
+		return nil, nil, err
+	}
+	i, err := repo.NewStockRepo(c.db.Conn).FindItem(pos[0])
+	if err != nil {
+		return nil, nil, notFound(err, "stock item", pos[0])
+	}
+	m.ItemID = i.ItemID
+	return m, pos, nil
+}
@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// userList prints every user
+func (c *CLI) userList(args []string) error {
+	fs := c.newFlagSet("user list")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	users, err := repo.NewUserRepo(c.db.Conn).List()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if users == nil {
+			users = []*models.User{}
+		}
+		return c.printJSON(users)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "USERNAME\tROLE\tCREATED")
+	for _, u := range users {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", u.Username, u.Role, formatDate(&u.CreatedAt))
+	}
+	return tw.Flush()
+}
+
+// userAdd creates a user
+// The first user must be an admin, since creating it enables signing in
+func (c *CLI) userAdd(args []string) error {
+	fs := c.newFlagSet("user add")
+	role := fs.String("role", models.RoleViewer, "role: "+strings.Join(models.Roles, ", "))
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	username := strings.TrimSpace(pos[0])
+	if username == "" {
+		return usagef("username cannot be empty")
+	}
+	if !auth.ValidRole(*role) {
+		return usagef("unknown role %q", *role)
+	}
+	userRepo := repo.NewUserRepo(c.db.Conn)
+	if n, err := userRepo.Count(); err != nil {
+		return err
+	} else if n == 0 && *role != models.RoleAdmin {
+		return usagef("the first user must be an admin (--role admin)")
+	}
+
+	hash, err := c.readPassword()
+	if err != nil {
+		return err
+	}
+	u := models.User{Username: username, Role: *role}
+	if err := userRepo.Create(&u, hash); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("user %q already exists", username)
+		}
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Added %s (%s)\n", u.Username, u.Role)
+	return nil
+}
+
+// userRole changes the role of a user
+func (c *CLI) userRole(args []string) error {
+	fs := c.newFlagSet("user role")
+	pos, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	if !auth.ValidRole(pos[1]) {
+		return usagef("unknown role %q", pos[1])
+	}
+
+	u, err := c.findUser(pos[0])
+	if err != nil {
+		return err
+	}
+	if err := repo.NewUserRepo(c.db.Conn).SetRole(u.UserID, pos[1]); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "%s is now %s\n", u.Username, pos[1])
+	return nil
+}
+
+// userPasswd changes the password of a user
+// Users may change their own password; other users need ManageUsers
+func (c *CLI) userPasswd(args []string) error {
+	fs := c.newFlagSet("user passwd")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	u, err := c.findUser(pos[0])
+	if err != nil {
+		return err
+	}
+	if err := c.checkSelfOr(u, auth.ManageUsers); err != nil {
+		return err
+	}
+	hash, err := c.readPassword()
+	if err != nil {
+		return err
+	}
+	if err := repo.NewUserRepo(c.db.Conn).SetPassword(u.UserID, hash); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Changed the password of %s\n", u.Username)
+	return nil
+}
+
+// userRemove deletes a user and their tokens
+func (c *CLI) userRemove(args []string) error {
+	fs := c.newFlagSet("user remove")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	u, err := c.findUser(pos[0])
+	if err != nil {
+		return err
+	}
+	if err := repo.NewUserRepo(c.db.Conn).Delete(u.UserID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Removed %s\n", u.Username)
+	return nil
+}
+
+// tokenCreate issues an API token and prints it once
+// Users may create their own tokens; other users need ManageUsers
+func (c *CLI) tokenCreate(args []string) error {
+	fs := c.newFlagSet("user token create")
+	name := fs.String("name", "api", "label telling the token apart from the user's others")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	u, err := c.findUser(pos[0])
+	if err != nil {
+		return err
+	}
+	if err := c.checkSelfOr(u, auth.ManageUsers); err != nil {
+		return err
+	}
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+	t := models.APIToken{UserID: u.UserID, Name: *name}
+	if err := repo.NewUserRepo(c.db.Conn).CreateToken(&t, hash); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "Token %d for %s; it is not shown again:\n", t.TokenID, u.Username)
+	fmt.Fprintln(c.stdout, token)
+	return nil
+}
+
+// tokenList prints the API tokens of every user
+func (c *CLI) tokenList(args []string) error {
+	fs := c.newFlagSet("user token list")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	tokens, err := repo.NewUserRepo(c.db.Conn).ListTokens()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if tokens == nil {
+			tokens = []*models.APIToken{}
+		}
+		return c.printJSON(tokens)
+	}
+
+	tw := c.newTable()
+	fmt.Fprintln(tw, "ID\tUSER\tNAME\tCREATED\tLAST USED")
+	for _, t := range tokens {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", t.TokenID, t.Username, t.Name, formatDate(&t.CreatedAt), formatDate(t.LastUsedAt))
+	}
+	return tw.Flush()
+}
+
+// tokenRevoke deletes an API token
+func (c *CLI) tokenRevoke(args []string) error {
+	fs := c.newFlagSet("user token revoke")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(pos[0])
+	if err != nil {
+		return usagef("token id must be a number")
+	}
+
+	if err := repo.NewUserRepo(c.db.Conn).DeleteToken(id); err != nil {
+		return notFound(err, "token", pos[0])
+	}
+	fmt.Fprintf(c.stdout, "Revoked token %d\n", id)
+	return nil
+}
+
+// findUser retrieves a user by username
+func (c *CLI) findUser(username string) (*models.User, error) {
+	u, _, err := repo.NewUserRepo(c.db.Conn).FindByUsername(username)
+	return u, notFound(err, "user", username)
+}
+
+// checkSelfOr allows an operation on the signed in user's own account, or
+// on any account with permission p
+func (c *CLI) checkSelfOr(u *models.User, p auth.Permission) error {
+	if c.user != nil && c.user.UserID == u.UserID {
+		return nil
+	}
+	return auth.Check(c.user, p)
+}
+
+// readPassword reads a password from the first line of stdin and hashes it
+func (c *CLI) readPassword() (string, error) {
+	fmt.Fprint(c.stderr, "Password: ")
+	line, err := bufio.NewReader(c.stdin).ReadString('\n')
+	fmt.Fprintln(c.stderr)
+	if err != nil && line == "" {
+		return "", errors.New("no password given on stdin")
+	}
+	return auth.HashPassword(strings.TrimRight(line, "\r\n"))
+}
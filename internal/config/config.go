@@ -0,0 +1,111 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+// DefaultPath is the configuration file read at startup, next to itroom.db
+const DefaultPath = "itroom.json"
+
+// Config holds the user settings read from the configuration file
+type Config struct {
+	// Keys overrides the keys bound to an action, by action ID
+	// (e.g. "assets.new": ["n", "Ctrl+N"]); an empty list unbinds it
+	Keys map[string][]string `json:"keys"`
+	// VimNavigation binds h/j/k/l, g and G to cursor movement
+	VimNavigation bool `json:"vim_navigation"`
+	// WarrantyLeadDays are the days before a warranty ends at which it is
+	// listed as upcoming; the longest one is the expiring soon window
+	WarrantyLeadDays []int `json:"warranty_lead_days"`
+	// Currency is the ISO 4217 code of purchase costs entered without one
+	Currency string `json:"currency"`
+	// Backup configures the database snapshots
+	Backup BackupConfig `json:"backup"`
+}
+
+// When the database is snapshotted on startup
+const (
+	SnapshotNever   = "never"
+	SnapshotUpgrade = "upgrade" // Only before migrations run
+	SnapshotAlways  = "always"
+)
+
+// BackupConfig holds the snapshot settings
+type BackupConfig struct {
+	// Dir holds the snapshots, relative to the working directory
+	Dir string `json:"dir"`
+	// Keep is the number of snapshots kept when rotating, 0 keeps all
+	Keep int `json:"keep"`
+	// OnStartup is SnapshotNever, SnapshotUpgrade or SnapshotAlways
+	OnStartup string `json:"on_startup"`
+}
+
+// Default returns the settings used when there is no configuration file
+func Default() *Config {
+	return &Config{
+		Keys:             map[string][]string{},
+		WarrantyLeadDays: []int{30, 60, 90},
+		Currency:         "USD",
+		Backup: BackupConfig{
+			Dir:       "backups",
+			Keep:      10,
+			OnStartup: SnapshotUpgrade,
+		},
+	}
+}
+
+// WarrantySoonDays returns the number of days before a warranty ends at
+// which it counts as expiring soon
+func (c *Config) WarrantySoonDays() int {
+	if len(c.WarrantyLeadDays) == 0 {
+		return 0
+	}
+	return c.WarrantyLeadDays[len(c.WarrantyLeadDays)-1]
+}
+
+// Load reads the configuration file at path
+// A missing file is not an error and yields the default settings
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	if cfg.Keys == nil {
+		cfg.Keys = map[string][]string{}
+	}
+	for _, days := range cfg.WarrantyLeadDays {
+		if days <= 0 {
+			return nil, fmt.Errorf("%s: warranty lead days must be positive, got %d", path, days)
+		}
+	}
+	if !models.IsCurrencyCode(cfg.Currency) {
+		return nil, fmt.Errorf("%s: currency must be a three-letter ISO 4217 code such as USD, got %q", path, cfg.Currency)
+	}
+	if cfg.Backup.Keep < 0 {
+		return nil, fmt.Errorf("%s: backup keep cannot be negative, got %d", path, cfg.Backup.Keep)
+	}
+	if !slices.Contains([]string{SnapshotNever, SnapshotUpgrade, SnapshotAlways}, cfg.Backup.OnStartup) {
+		return nil, fmt.Errorf("%s: backup on_startup must be %q, %q or %q, got %q",
+			path, SnapshotNever, SnapshotUpgrade, SnapshotAlways, cfg.Backup.OnStartup)
+	}
+	slices.Sort(cfg.WarrantyLeadDays)
+	cfg.WarrantyLeadDays = slices.Compact(cfg.WarrantyLeadDays)
+
+	return cfg, nil
+}
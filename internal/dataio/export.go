@@ -0,0 +1,321 @@
+package dataio
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// Format is a file format data can be exported to
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+	FormatXLSX  Format = "xlsx"
+)
+
+// Formats lists the supported export formats
+var Formats = []Format{FormatCSV, FormatJSONL, FormatXLSX}
+
+// ParseFormat returns the format with the given name
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(name, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q, expected csv, jsonl or xlsx", name)
+}
+
+// FormatForPath returns the format matching the extension of path, or
+// FormatCSV if the extension is unknown
+func FormatForPath(path string) Format {
+	if f, err := ParseFormat(strings.TrimPrefix(filepath.Ext(path), ".")); err == nil {
+		return f
+	}
+	return FormatCSV
+}
+
+// Table is exported data: named columns and rows of cell values
+// Values are strings, ints, float64s or nil for missing values
+type Table struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Drop removes the named column and its values
+func (t *Table) Drop(column string) {
+	i := slices.Index(t.Columns, column)
+	if i < 0 {
+		return
+	}
+	t.Columns = slices.Delete(t.Columns, i, i+1)
+	for r, row := range t.Rows {
+		t.Rows[r] = slices.Delete(row, i, i+1)
+	}
+}
+
+// Write encodes the table in the given format
+// CSV and XLSX start with a header row; JSON Lines writes one object per
+// row, keyed by column name
+func Write(w io.Writer, format Format, t *Table) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, t)
+	case FormatJSONL:
+		return writeJSONL(w, t)
+	case FormatXLSX:
+		return writeXLSX(w, t)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// writeCSV writes the table as CSV, with missing values left empty
+func writeCSV(w io.Writer, t *Table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, v := range row {
+			record[i] = cellText(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSONL writes the table as one JSON object per line
+func writeJSONL(w io.Writer, t *Table) error {
+	enc := json.NewEncoder(w)
+	for _, row := range t.Rows {
+		// Marshal by hand to keep the column order of the table
+		var b strings.Builder
+		b.WriteByte('{')
+		for i, v := range row {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			key, _ := json.Marshal(t.Columns[i])
+			value, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			b.Write(key)
+			b.WriteByte(':')
+			b.Write(value)
+		}
+		b.WriteByte('}')
+		if err := enc.Encode(json.RawMessage(b.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cellText renders a cell value as text
+func cellText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// date returns a calendar date cell, nil for missing dates
+func date(t *time.Time) any {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t.Format(repo.DateLayout)
+}
+
+// timestamp returns a date and time cell
+func timestamp(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(repo.TimestampLayout)
+}
+
+// text returns an optional text cell
+func text(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+// number returns an optional number cell
+func number(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// AssetsTable exports assets with catalog IDs resolved to names
+// The columns use the importer field keys, followed by a column for each
+// custom field name in fields filled in from values, so the file can be
+// imported back with its custom field values; the importer ignores the
+// asset_id column
+func AssetsTable(assets []*models.AssetSummary, fields []*models.CustomField, values []*models.AssetFieldValue) *Table {
+	t := &Table{Columns: []string{"asset_tag", "category", "type", "make", "model", "serial_number",
+		"status", "location", "holder", "purchase_date", "warranty_end_date", "purchase_cost", "currency",
+		"supplier", "po_number", "invoice_number", "notes", "asset_id"}}
+	fixed := len(t.Columns)
+
+	// Fields of the same name on different types share a column
+	column := map[string]int{}
+	for _, f := range fields {
+		if _, ok := column[strings.ToLower(f.Name)]; !ok {
+			column[strings.ToLower(f.Name)] = len(t.Columns)
+			t.Columns = append(t.Columns, f.Name)
+		}
+	}
+	byAsset := map[string][]*models.AssetFieldValue{}
+	for _, v := range values {
+		byAsset[v.AssetID] = append(byAsset[v.AssetID], v)
+	}
+
+	for _, a := range assets {
+		row := []any{a.AssetTag, a.CategoryName, a.TypeName, a.Maker, a.Model, a.SerialNumber,
+			a.StatusName, a.LocationName, text(a.HolderName), date(&a.PurchaseDate), date(a.WarrantyEndDate),
+			number(a.PurchaseCost), text(a.Currency), text(a.Supplier), text(a.PONumber), text(a.InvoiceNumber),
+			text(a.Notes), a.AssetID}
+		for range t.Columns[fixed:] {
+			row = append(row, "")
+		}
+		for _, v := range byAsset[a.AssetID] {
+			if i, ok := column[strings.ToLower(v.FieldName)]; ok {
+				row[i] = v.Value
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// LoadAssets exports the assets matching q with their custom field values
+func LoadAssets(stores *repo.Stores, q repo.AssetQuery) (*Table, error) {
+	assets, err := stores.Assets.ListSummaries(q)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := stores.Fields.List()
+	if err != nil {
+		return nil, err
+	}
+	values, err := stores.Fields.ListAllValues()
+	if err != nil {
+		return nil, err
+	}
+	return AssetsTable(assets, fields, values), nil
+}
+
+// AssignmentsTable exports asset assignments
+func AssignmentsTable(assignments []*models.AssetAssignment) *Table {
+	t := &Table{Columns: []string{"assignment_id", "asset_tag", "employee", "assignment_date",
+		"due_date", "return_date", "notes"}}
+	for _, a := range assignments {
+		t.Rows = append(t.Rows, []any{a.AssignmentID, a.AssetTag, a.EmployeeName, timestamp(a.AssignmentDate),
+			date(a.DueDate), date(a.ReturnDate), text(a.Notes)})
+	}
+	return t
+}
+
+// LicensesTable exports software licenses with their seat usage
+func LicensesTable(licenses []*models.SoftwareLicense) *Table {
+	t := &Table{Columns: []string{"license_id", "software_name", "license_key", "license_type",
+		"seats_purchased", "seats_used", "purchase_date", "expiration_date", "notes"}}
+	for _, l := range licenses {
+		t.Rows = append(t.Rows, []any{l.LicenseID, l.SoftwareName, l.LicenseKey, l.LicenseType,
+			l.SeatsPurchased, l.SeatsUsed, date(&l.PurchaseDate), date(l.ExpirationDate), text(l.Notes)})
+	}
+	return t
+}
+
+// ConsumablesTable exports consumable types with their stock
+func ConsumablesTable(consumables []*models.ConsumableType) *Table {
+	t := &Table{Columns: []string{"consumable_type_id", "name", "part_number", "manufacturer",
+		"last_purchase_date", "stock_quantity", "reorder_level"}}
+	for _, c := range consumables {
+		t.Rows = append(t.Rows, []any{c.ConsumableTypeID, c.Name, text(c.PartNumber), text(c.Manufacturer),
+			date(c.LastPurchaseDate), c.StockQuantity, c.ReorderLevel})
+	}
+	return t
+}
+
+// MaintenanceTable exports maintenance logs
+func MaintenanceTable(logs []*models.MaintenanceLog) *Table {
+	t := &Table{Columns: []string{"log_id", "asset_tag", "maintenance_type", "maintenance_date",
+		"cost", "description", "performed_by"}}
+	for _, m := range logs {
+		var cost any
+		if m.Cost != nil {
+			cost = *m.Cost
+		}
+		t.Rows = append(t.Rows, []any{m.LogID, m.AssetTag, m.TypeName, timestamp(m.MaintenanceDate),
+			cost, m.Description, text(m.PerformedBy)})
+	}
+	return t
+}
+
+// Entity is a kind of record that can be exported in full
+type Entity struct {
+	Name string
+	Load func(db repo.DBTX) (*Table, error)
+}
+
+// Entities lists the exportable entities
+var Entities = []Entity{
+	{Name: "assets", Load: func(db repo.DBTX) (*Table, error) {
+		return LoadAssets(repo.NewStores(db), repo.AssetQuery{})
+	}},
+	{Name: "assignments", Load: func(db repo.DBTX) (*Table, error) {
+		assignments, err := repo.NewAssignmentRepo(db).List()
+		return AssignmentsTable(assignments), err
+	}},
+	{Name: "licenses", Load: func(db repo.DBTX) (*Table, error) {
+		licenses, err := repo.NewLicenseRepo(db).List()
+		return LicensesTable(licenses), err
+	}},
+	{Name: "consumables", Load: func(db repo.DBTX) (*Table, error) {
+		consumables, err := repo.NewConsumableRepo(db).ListTypes()
+		return ConsumablesTable(consumables), err
+	}},
+	{Name: "maintenance", Load: func(db repo.DBTX) (*Table, error) {
+		logs, err := repo.NewMaintenanceRepo(db).List()
+		return MaintenanceTable(logs), err
+	}},
+}
+
+// FindEntity returns the exportable entity with the given name
+func FindEntity(name string) (Entity, bool) {
+	for _, e := range Entities {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Entity{}, false
+}
@@ -0,0 +1,107 @@
+package dataio_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/dataio"
+	"github.com/MawCeron/it-room/internal/db"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/service"
+)
+
+// Catalog IDs seeded by the schema
+const (
+	laptop   = 1
+	desktop  = 2
+	mainSite = 1
+)
+
+var ctx = context.Background()
+
+// newInventory opens a fresh database with a RAM field on laptops and one
+// employee, as both ends of a round trip need them
+func newInventory(t *testing.T) (*service.Service, *repo.Stores) {
+	t.Helper()
+	d, err := db.New(filepath.Join(t.TempDir(), "itroom.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	stores := repo.NewStores(d.Conn)
+	svc := service.New(stores)
+
+	typeID := laptop
+	if err := svc.CreateCustomField(ctx, &models.CustomField{Name: "RAM (GB)", Kind: models.FieldNumber, TypeID: &typeID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CreateEmployee(ctx, &models.Employee{FullName: "Ana Ruiz", Email: "ana@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	return svc, stores
+}
+
+// exportCSV exports every asset as CSV, without the asset_id column the
+// importer leaves out
+func exportCSV(t *testing.T, stores *repo.Stores) string {
+	t.Helper()
+	table, err := dataio.LoadAssets(stores, repo.AssetQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	table.Drop("asset_id")
+	var b bytes.Buffer
+	if err := dataio.Write(&b, dataio.FormatCSV, table); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestAssetsRoundTrip(t *testing.T) {
+	src, srcStores := newInventory(t)
+	cost, currency, supplier, notes := 1299.5, "USD", "CDW", "Spare charger, in the drawer"
+	warranty := time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC)
+	assets := []*models.Asset{
+		{AssetTag: "EQ-001", TypeID: laptop, SerialNumber: "SN-001", Maker: "Dell", Model: "Latitude 7440",
+			PurchaseDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), WarrantyEndDate: &warranty, LocationID: mainSite,
+			PurchaseCost: &cost, Currency: &currency, Supplier: &supplier, Notes: &notes,
+			Fields: map[string]string{"RAM (GB)": "16"}},
+		{AssetTag: "EQ-002", TypeID: desktop, SerialNumber: "SN-002", Maker: "Dell", Model: "OptiPlex 7010",
+			PurchaseDate: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), LocationID: mainSite,
+			StatusID: models.StatusUnderMaintenance},
+	}
+	for _, a := range assets {
+		if err := src.CreateAsset(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := src.AssignAsset(ctx, &models.AssetAssignment{AssetID: assets[0].AssetID, AssignmentDate: time.Now().UTC()}, "Ana Ruiz"); err != nil {
+		t.Fatal(err)
+	}
+	exported := exportCSV(t, srcStores)
+	if !strings.Contains(exported, "RAM (GB)") || !strings.Contains(exported, "Ana Ruiz") || !strings.Contains(exported, ",16\n") {
+		t.Fatalf("export lacks the holder or custom field value:\n%s", exported)
+	}
+
+	dst, dstStores := newInventory(t)
+	header, records, err := dataio.ReadCSV(bytes.NewBufferString(exported))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := dst.ImportAssets(ctx, header, records, dataio.ImportOptions{Mapping: dataio.GuessMapping(header)})
+	if err != nil {
+		t.Fatalf("ImportAssets: %v", err)
+	}
+	if !report.Committed || report.Imported != len(assets) {
+		t.Fatalf("import report = %+v, want %d assets saved", report, len(assets))
+	}
+
+	if got := exportCSV(t, dstStores); got != exported {
+		t.Errorf("re-exported assets differ\ngot:\n%s\nwant:\n%s", got, exported)
+	}
+}
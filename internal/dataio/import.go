@@ -0,0 +1,601 @@
+// Package dataio imports and exports inventory data as files
+package dataio
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// Field is an asset field that can be read from a CSV column
+type Field struct {
+	Key      string // Stable column name, also used by exports
+	Title    string
+	Required bool
+	Aliases  []string // Other header names matched by GuessMapping
+}
+
+// AssetFields lists the importable asset fields
+var AssetFields = []Field{
+	{Key: "asset_tag", Title: "Asset Tag", Required: true, Aliases: []string{"tag", "asset"}},
+	{Key: "category", Title: "Category", Aliases: []string{"category_name", "asset_category"}},
+	{Key: "type", Title: "Type", Required: true, Aliases: []string{"type_name", "asset_type"}},
+	{Key: "make", Title: "Make", Required: true, Aliases: []string{"manufacturer", "brand", "vendor"}},
+	{Key: "model", Title: "Model", Required: true},
+	{Key: "serial_number", Title: "Serial Number", Required: true, Aliases: []string{"serial", "serial_no", "sn"}},
+	{Key: "status", Title: "Status", Aliases: []string{"status_name", "state"}},
+	{Key: "location", Title: "Location", Required: true, Aliases: []string{"location_name", "site"}},
+	{Key: "holder", Title: "Holder", Aliases: []string{"holder_name", "employee", "assigned_to"}},
+	{Key: "purchase_date", Title: "Purchase Date", Required: true, Aliases: []string{"purchased", "purchased_on"}},
+	{Key: "warranty_end_date", Title: "Warranty End Date", Aliases: []string{"warranty_end", "warranty", "warranty_expiration"}},
+	{Key: "purchase_cost", Title: "Purchase Cost", Aliases: []string{"cost", "price", "purchase_price"}},
+	{Key: "currency", Title: "Currency"},
+	{Key: "supplier", Title: "Supplier", Aliases: []string{"seller", "reseller"}},
+	{Key: "po_number", Title: "PO Number", Aliases: []string{"po", "purchase_order"}},
+	{Key: "invoice_number", Title: "Invoice Number", Aliases: []string{"invoice", "invoice_no"}},
+	{Key: "notes", Title: "Notes", Aliases: []string{"note", "comments"}},
+}
+
+// Mapping maps a field key to the index of the CSV column holding it
+type Mapping map[string]int
+
+// Record is a CSV row with the line it starts on
+type Record struct {
+	Line   int
+	Values []string
+}
+
+// RowError describes why a row cannot be imported
+type RowError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"` // Empty when the error concerns the whole row
+	Message string `json:"message"`
+}
+
+func (e RowError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Field, e.Message)
+}
+
+// ImportOptions controls an import
+type ImportOptions struct {
+	Mapping    Mapping
+	DateLayout string  // Go layout of the date columns, detected when empty
+	DryRun     bool    // Validate every row, then roll back
+	Actor      *string // Username the imported records are attributed to
+	Currency   string  // ISO 4217 code of purchase costs given without one
+}
+
+// ImportReport is the outcome of an import
+type ImportReport struct {
+	Rows           int        `json:"rows"`     // Data rows read
+	Imported       int        `json:"imported"` // Rows inserted, or that would be in a dry run
+	DateLayout     string     `json:"date_layout"`
+	CustomColumns  []string   `json:"custom_columns"`  // Headers read as custom fields
+	IgnoredColumns []string   `json:"ignored_columns"` // Headers matching no field
+	Errors         []RowError `json:"errors"`
+	DryRun         bool       `json:"dry_run"`
+	Committed      bool       `json:"committed"` // The rows were saved
+}
+
+// ReadCSV reads a CSV file, returning its header and data rows
+// The delimiter is detected from the header among comma, semicolon and tab
+func ReadCSV(r io.Reader) ([]string, []Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	text := strings.TrimPrefix(string(data), "\ufeff") // Spreadsheet exports may start with a BOM
+
+	cr := csv.NewReader(strings.NewReader(text))
+	cr.Comma = detectDelimiter(text)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []Record
+	for {
+		values, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if isBlank(values) {
+			continue
+		}
+		records = append(records, Record{Line: line, Values: values})
+	}
+
+	return header, records, nil
+}
+
+// detectDelimiter picks the most frequent candidate delimiter on the first line
+func detectDelimiter(text string) rune {
+	first, _, _ := strings.Cut(text, "\n")
+	best, bestCount := ',', 0
+	for _, d := range []rune{',', ';', '\t'} {
+		if n := strings.Count(first, string(d)); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+// isBlank reports whether every value of a row is empty
+func isBlank(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// GuessMapping matches header names to fields by key, title or alias,
+// ignoring case, spaces and punctuation
+func GuessMapping(header []string) Mapping {
+	m := Mapping{}
+	for i, h := range header {
+		name := normalizeHeader(h)
+		for _, f := range AssetFields {
+			if _, taken := m[f.Key]; taken {
+				continue
+			}
+			if name == normalizeHeader(f.Key) || name == normalizeHeader(f.Title) || matchesAlias(name, f.Aliases) {
+				m[f.Key] = i
+				break
+			}
+		}
+	}
+	return m
+}
+
+// matchesAlias reports whether a normalized header equals one of aliases
+func matchesAlias(name string, aliases []string) bool {
+	for _, a := range aliases {
+		if name == normalizeHeader(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeHeader lowercases s and drops everything but letters and digits
+func normalizeHeader(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// DateLayouts are the date formats recognized by DetectDateLayout, in order
+// of preference when several fit
+var DateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+	"02/01/2006",
+	"1/2/2006",
+	"2/1/2006",
+	"01-02-2006",
+	"02-01-2006",
+	"02.01.2006",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"Jan 2, 2006",
+	"2 Jan 2006",
+}
+
+// DetectDateLayout returns the first of DateLayouts that parses every
+// non-empty value, or the one parsing the most values if none parses all
+func DetectDateLayout(values []string) string {
+	best, bestCount := DateLayouts[0], -1
+	for _, layout := range DateLayouts {
+		count := 0
+		for _, v := range values {
+			if _, err := time.Parse(layout, v); err == nil {
+				count++
+			}
+		}
+		if count == len(values) {
+			return layout
+		}
+		if count > bestCount {
+			best, bestCount = layout, count
+		}
+	}
+	return best
+}
+
+// AssetWriter saves the assets of an import within its unit of work, so
+// they follow the same rules as assets added any other way
+// Errors about a single row are returned as RowError values without a line
+type AssetWriter interface {
+	CreateAsset(tx *repo.Stores, a *models.Asset) error
+	AssignAsset(tx *repo.Stores, a *models.AssetAssignment, employee string) error
+}
+
+// errDiscard rolls back the unit of work of an import that saves nothing
+var errDiscard = errors.New("import discarded")
+
+// ImportAssets validates the records against the mapping and saves them
+// through w in a single unit of work
+// Nothing is saved if any row has an error or when running dry; the report
+// lists every row error found
+func ImportAssets(ctx context.Context, stores *repo.Stores, w AssetWriter, header []string, records []Record, opts ImportOptions) (*ImportReport, error) {
+	for _, f := range AssetFields {
+		i, ok := opts.Mapping[f.Key]
+		if f.Required && !ok {
+			return nil, fmt.Errorf("no column is mapped to %s", f.Title)
+		}
+		if ok && (i < 0 || i >= len(header)) {
+			return nil, fmt.Errorf("%s is mapped to a column the file does not have", f.Title)
+		}
+	}
+
+	report := &ImportReport{Rows: len(records), DateLayout: opts.DateLayout, DryRun: opts.DryRun}
+	if report.DateLayout == "" {
+		report.DateLayout = DetectDateLayout(dateValues(records, opts.Mapping))
+	}
+
+	err := stores.Tx(ctx, func(tx *repo.Stores) error {
+		fields, err := tx.Fields.List()
+		if err != nil {
+			return err
+		}
+		custom := customColumns(header, opts.Mapping, fields)
+		for i, h := range header {
+			if name, ok := custom[i]; ok {
+				report.CustomColumns = append(report.CustomColumns, name)
+			} else if !mapped(opts.Mapping, i) && strings.TrimSpace(h) != "" {
+				report.IgnoredColumns = append(report.IgnoredColumns, h)
+			}
+		}
+
+		im := &assetImporter{
+			tx:       tx,
+			writer:   w,
+			now:      time.Now().UTC(),
+			actor:    opts.Actor,
+			currency: opts.Currency,
+			mapping:  opts.Mapping,
+			custom:   custom,
+			layout:   report.DateLayout,
+			cache:    map[string]any{},
+			tags:     map[string]int{},
+			serials:  map[string]int{},
+		}
+		for _, rec := range records {
+			a, holder, errs := im.parse(rec)
+			if len(errs) == 0 {
+				errs = im.create(rec.Line, a, holder)
+			}
+			if len(errs) > 0 {
+				report.Errors = append(report.Errors, errs...)
+				continue
+			}
+			report.Imported++
+		}
+
+		if len(report.Errors) > 0 || opts.DryRun {
+			return errDiscard
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errDiscard):
+		return report, nil
+	case err != nil:
+		return nil, err
+	}
+	report.Committed = true
+	return report, nil
+}
+
+// customColumns returns the name of the custom field read from each column
+// not mapped to an asset field, matching headers as GuessMapping does
+// Which types the field applies to is checked row by row
+func customColumns(header []string, m Mapping, fields []*models.CustomField) map[int]string {
+	out := map[int]string{}
+	for i, h := range header {
+		if mapped(m, i) {
+			continue
+		}
+		for _, f := range fields {
+			if normalizeHeader(h) == normalizeHeader(f.Name) {
+				out[i] = f.Name
+				break
+			}
+		}
+	}
+	return out
+}
+
+// mapped reports whether column i is mapped to an asset field
+func mapped(m Mapping, i int) bool {
+	for _, j := range m {
+		if i == j {
+			return true
+		}
+	}
+	return false
+}
+
+// createError describes a row the writer refused, at field unless the error
+// names another one
+func createError(line int, field string, err error) RowError {
+	var rowErr RowError
+	if !errors.As(err, &rowErr) {
+		rowErr = RowError{Message: err.Error()}
+	}
+	rowErr.Line = line
+	if rowErr.Field == "" {
+		rowErr.Field = field
+	}
+	return rowErr
+}
+
+// dateValues returns the non-empty values of the mapped date columns
+func dateValues(records []Record, m Mapping) []string {
+	var out []string
+	for _, key := range []string{"purchase_date", "warranty_end_date"} {
+		i, ok := m[key]
+		if !ok {
+			continue
+		}
+		for _, rec := range records {
+			if i < len(rec.Values) {
+				if v := strings.TrimSpace(rec.Values[i]); v != "" {
+					out = append(out, v)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// assetImporter turns records into assets, resolving catalog names
+type assetImporter struct {
+	tx       *repo.Stores
+	writer   AssetWriter
+	mapping  Mapping
+	custom   map[int]string // Custom field name by column
+	layout   string
+	now      time.Time // Assignment date of imported holders
+	actor    *string
+	currency string // Of purchase costs given without one
+
+	cache   map[string]any // Catalog lookups by kind and lowercase name
+	tags    map[string]int // Line of each asset tag seen in the file
+	serials map[string]int // Line of each serial number seen in the file
+}
+
+// create saves a parsed asset and assigns it to its holder, if any
+func (im *assetImporter) create(line int, a *models.Asset, holder *models.Employee) []RowError {
+	a.CreatedBy = im.actor
+	if err := im.writer.CreateAsset(im.tx, a); err != nil {
+		return []RowError{createError(line, "", err)}
+	}
+	if holder == nil {
+		return nil
+	}
+
+	assignment := models.AssetAssignment{AssetID: a.AssetID, AssignmentDate: im.now, AssignedBy: im.actor}
+	if err := im.writer.AssignAsset(im.tx, &assignment, holder.Email); err != nil {
+		return []RowError{createError(line, "holder", err)}
+	}
+	return nil
+}
+
+// parse builds the asset described by a record and resolves its holder
+func (im *assetImporter) parse(rec Record) (*models.Asset, *models.Employee, []RowError) {
+	var errs []RowError
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, RowError{Line: rec.Line, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	value := func(key string) string {
+		i, ok := im.mapping[key]
+		if !ok || i >= len(rec.Values) {
+			return ""
+		}
+		return strings.TrimSpace(rec.Values[i])
+	}
+
+	for _, f := range AssetFields {
+		if f.Required && value(f.Key) == "" {
+			fail(f.Key, "is required")
+		}
+	}
+
+	a := &models.Asset{
+		AssetTag:     value("asset_tag"),
+		Maker:        value("make"),
+		Model:        value("model"),
+		SerialNumber: value("serial_number"),
+		StatusID:     models.StatusAvailable,
+	}
+	for _, f := range []struct {
+		key   string
+		field **string
+	}{{"notes", &a.Notes}, {"supplier", &a.Supplier}, {"po_number", &a.PONumber}, {"invoice_number", &a.InvoiceNumber}} {
+		if v := value(f.key); v != "" {
+			*f.field = &v
+		}
+	}
+
+	if tag := strings.ToLower(a.AssetTag); tag != "" {
+		if line, dup := im.tags[tag]; dup {
+			fail("asset_tag", "%q is repeated from line %d", a.AssetTag, line)
+		} else {
+			im.tags[tag] = rec.Line
+		}
+	}
+	if serial := strings.ToLower(a.SerialNumber); serial != "" {
+		if line, dup := im.serials[serial]; dup {
+			fail("serial_number", "%q is repeated from line %d", a.SerialNumber, line)
+		} else {
+			im.serials[serial] = rec.Line
+		}
+	}
+
+	if name := value("type"); name != "" {
+		if t, err := lookup(im, "type", name, im.tx.Assets.FindAssetType); err != nil {
+			fail("type", "%v", err)
+		} else {
+			a.TypeID = t.TypeID
+			if name := value("category"); name != "" {
+				c, err := lookup(im, "category", name, im.tx.Assets.FindAssetCategory)
+				if err != nil {
+					fail("category", "%v", err)
+				} else if c.CategoryId != t.CategoryID {
+					fail("type", "%s is not in category %s", t.TypeName, c.Description)
+				}
+			}
+		}
+	}
+	if name := value("location"); name != "" {
+		if l, err := lookup(im, "location", name, im.tx.Locations.FindByName); err != nil {
+			fail("location", "%v", err)
+		} else {
+			a.LocationID = l.LocationID
+		}
+	}
+	var holder *models.Employee
+	if name := value("holder"); name != "" {
+		e, err := lookup(im, "employee", name, im.tx.Employees.Resolve)
+		if err != nil {
+			fail("holder", "%v", err)
+		}
+		holder = e
+	}
+	if name := value("status"); name != "" {
+		s, err := lookup(im, "status", name, im.tx.Assets.FindAssetStatus)
+		switch {
+		case err != nil:
+			fail("status", "%v", err)
+		case s.StatusID == models.StatusAssigned && value("holder") == "":
+			fail("status", "assigned assets need a holder")
+		case s.StatusID != models.StatusAssigned && value("holder") != "":
+			fail("holder", "only assigned assets can have a holder, the status is %s", s.StatusName)
+		case s.StatusID != models.StatusAssigned:
+			a.StatusID = s.StatusID
+		}
+	}
+
+	if text := value("purchase_date"); text != "" {
+		if d, err := time.Parse(im.layout, text); err != nil {
+			fail("purchase_date", "%q does not match the date format %s", text, DescribeDateLayout(im.layout))
+		} else {
+			a.PurchaseDate = d
+		}
+	}
+	if text := value("warranty_end_date"); text != "" {
+		if d, err := time.Parse(im.layout, text); err != nil {
+			fail("warranty_end_date", "%q does not match the date format %s", text, DescribeDateLayout(im.layout))
+		} else {
+			a.WarrantyEndDate = &d
+			if !a.PurchaseDate.IsZero() && d.Before(a.PurchaseDate) {
+				fail("warranty_end_date", "is before the purchase date")
+			}
+		}
+	}
+
+	currency := strings.ToUpper(value("currency"))
+	if text := value("purchase_cost"); text != "" {
+		if cost, err := strconv.ParseFloat(text, 64); err != nil || !(cost >= 0) || math.IsInf(cost, 0) {
+			fail("purchase_cost", "%q is not an amount of zero or more", text)
+		} else {
+			a.PurchaseCost = &cost
+		}
+		if currency == "" {
+			currency = im.currency
+		}
+	} else if currency != "" {
+		fail("currency", "is given without a purchase cost")
+	}
+	if currency != "" {
+		if !models.IsCurrencyCode(currency) {
+			fail("currency", "%q is not a three-letter ISO 4217 code", currency)
+		}
+		a.Currency = &currency
+	}
+
+	// Empty custom field columns are left for the writer to check against
+	// the required fields of the type
+	for i, name := range im.custom {
+		if i < len(rec.Values) && strings.TrimSpace(rec.Values[i]) != "" {
+			if a.Fields == nil {
+				a.Fields = map[string]string{}
+			}
+			a.Fields[name] = rec.Values[i]
+		}
+	}
+
+	// Assigned assets are created as available, then assigned to the holder
+	return a, holder, errs
+}
+
+// lookup resolves a catalog entry by name once per file
+func lookup[T any](im *assetImporter, kind, name string, find func(string) (T, error)) (T, error) {
+	key := kind + "\x00" + strings.ToLower(name)
+	if v, ok := im.cache[key]; ok {
+		if err, failed := v.(error); failed {
+			var zero T
+			return zero, err
+		}
+		return v.(T), nil
+	}
+
+	v, err := find(name)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("unknown %s %q", kind, name)
+	}
+	if err != nil {
+		im.cache[key] = err
+		return v, err
+	}
+	im.cache[key] = v
+	return v, nil
+}
+
+// dateFormatTokens translates readable date format tokens to Go layout parts
+var dateFormatTokens = strings.NewReplacer("YYYY", "2006", "MM", "01", "DD", "02")
+
+// ParseDateFormat accepts a readable format such as DD/MM/YYYY or a Go
+// layout, returning the Go layout
+func ParseDateFormat(format string) string {
+	return dateFormatTokens.Replace(format)
+}
+
+// DescribeDateLayout renders a Go layout from DateLayouts in readable form
+func DescribeDateLayout(layout string) string {
+	return strings.NewReplacer(
+		"2006", "YYYY", "01", "MM", "02", "DD", "1/2/", "M/D/", "2/1/", "D/M/",
+		"15:04:05", "hh:mm:ss", "Z07:00", "±hh:mm", "Jan 2,", "Mon D,", "2 Jan", "D Mon",
+	).Replace(layout)
+}
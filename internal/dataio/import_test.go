@@ -0,0 +1,174 @@
+package dataio_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/dataio"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/service"
+)
+
+// importCSV reads text as a CSV file and imports it with the guessed mapping
+func importCSV(t *testing.T, svc *service.Service, text string, dryRun bool) *dataio.ImportReport {
+	t.Helper()
+	header, records, err := dataio.ReadCSV(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := svc.ImportAssets(ctx, header, records, dataio.ImportOptions{Mapping: dataio.GuessMapping(header), DryRun: dryRun})
+	if err != nil {
+		t.Fatalf("ImportAssets: %v", err)
+	}
+	return report
+}
+
+// assetTags lists the tags of every stored asset
+func assetTags(t *testing.T, stores *repo.Stores) []string {
+	t.Helper()
+	assets, err := stores.Assets.ListSummaries(repo.AssetQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tags []string
+	for _, a := range assets {
+		tags = append(tags, a.AssetTag)
+	}
+	return tags
+}
+
+func TestGuessMapping(t *testing.T) {
+	header := []string{"Tag", "Asset Category", "type_name", "Brand", "MODEL", "S/N", "State", "Site",
+		"Assigned To", "Purchased On", "Warranty", "Price", "Color"}
+	want := dataio.Mapping{"asset_tag": 0, "category": 1, "type": 2, "make": 3, "model": 4, "serial_number": 5,
+		"status": 6, "location": 7, "holder": 8, "purchase_date": 9, "warranty_end_date": 10, "purchase_cost": 11}
+
+	got := dataio.GuessMapping(header)
+	if len(got) != len(want) {
+		t.Errorf("GuessMapping = %v, want %v", got, want)
+	}
+	for key, i := range want {
+		if got[key] != i {
+			t.Errorf("%s mapped to column %d, want %d (%s)", key, got[key], i, header[i])
+		}
+	}
+}
+
+func TestDetectDateLayout(t *testing.T) {
+	for _, tt := range []struct {
+		values []string
+		want   string
+	}{
+		{[]string{"2024-01-15", "2023-12-31"}, "2006-01-02"},
+		{[]string{"15/01/2024", "31/12/2023"}, "02/01/2006"},
+		{[]string{"01/02/2024", "03/04/2024"}, "01/02/2006"}, // Ambiguous, month first is preferred
+		{[]string{"1/5/2024", "12/25/2023"}, "1/2/2006"},
+		{[]string{"15.01.2024"}, "02.01.2006"},
+		{[]string{"Jan 15, 2024"}, "Jan 2, 2006"},
+		{[]string{"2024-01-15", "15/01/2024", "2023-12-31"}, "2006-01-02"}, // Most values parse
+	} {
+		if got := dataio.DetectDateLayout(tt.values); got != tt.want {
+			t.Errorf("DetectDateLayout(%q) = %s, want %s", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestImportRowErrors(t *testing.T) {
+	svc, stores := newInventory(t)
+	existing := &models.Asset{AssetTag: "EQ-100", TypeID: laptop, SerialNumber: "SN-100", Maker: "Dell", Model: "Latitude",
+		PurchaseDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), LocationID: mainSite}
+	if err := svc.CreateAsset(ctx, existing); err != nil {
+		t.Fatal(err)
+	}
+
+	report := importCSV(t, svc, `Tag,Category,Type,Make,Model,Serial,Status,Location,Holder,Purchased
+EQ-001,Computer Equipment,Laptop,Dell,Latitude,SN-001,,Main,,15/01/2024
+EQ-001,,Laptop,Dell,Latitude,SN-002,,Main,,15/01/2024
+EQ-003,,Laptop,Dell,Latitude,SN-001,,Main,,15/01/2024
+EQ-100,,Laptop,Dell,Latitude,SN-004,,Main,,15/01/2024
+EQ-005,,Laptop,Dell,Latitude,SN-100,,Main,,15/01/2024
+EQ-006,Furniture,Laptop,Dell,Latitude,SN-006,,Main,,15/01/2024
+EQ-007,,Hovercraft,Dell,Latitude,SN-007,,Main,,15/01/2024
+EQ-008,,Laptop,Dell,Latitude,SN-008,,Atlantis,,15/01/2024
+EQ-009,,Laptop,Dell,Latitude,SN-009,Misplaced,Main,,15/01/2024
+EQ-010,,Laptop,Dell,Latitude,SN-010,Available,Main,Ana Ruiz,15/01/2024
+EQ-011,,Laptop,Dell,Latitude,SN-011,Assigned,Main,,15/01/2024
+EQ-012,,Laptop,Dell,Latitude,SN-012,,Main,Nobody,15/01/2024
+EQ-013,,Laptop,Dell,Latitude,SN-013,,Main,,2024-01-15
+`, false)
+
+	want := []dataio.RowError{
+		{Line: 3, Field: "asset_tag"},
+		{Line: 4, Field: "serial_number"},
+		{Line: 5, Field: "asset_tag"},
+		{Line: 6, Field: "serial_number"},
+		{Line: 7, Field: "category"},
+		{Line: 8, Field: "type"},
+		{Line: 9, Field: "location"},
+		{Line: 10, Field: "status"},
+		{Line: 11, Field: "holder"},
+		{Line: 12, Field: "status"},
+		{Line: 13, Field: "holder"},
+		{Line: 14, Field: "purchase_date"},
+	}
+	if len(report.Errors) != len(want) {
+		t.Fatalf("errors = %v, want one for each of lines 3 to 14", report.Errors)
+	}
+	for i, e := range report.Errors {
+		if e.Line != want[i].Line || e.Field != want[i].Field || e.Message == "" {
+			t.Errorf("error %d = %v, want line %d at %s", i, e, want[i].Line, want[i].Field)
+		}
+	}
+	if report.DateLayout != "02/01/2006" || report.Committed || report.Imported != 1 {
+		t.Errorf("report = %+v, want day-first dates, one valid row and nothing saved", report)
+	}
+	if tags := assetTags(t, stores); len(tags) != 1 || tags[0] != "EQ-100" {
+		t.Errorf("assets after a failed import = %v, want only EQ-100", tags)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	svc, stores := newInventory(t)
+	file := `asset_tag,type,make,model,serial_number,status,location,holder,purchase_date
+EQ-001,Laptop,Dell,Latitude,SN-001,Assigned,Main,Ana Ruiz,2024-01-15
+EQ-002,Desktop,Dell,OptiPlex,SN-002,Under Maintenance,Main,,2024-01-15
+`
+
+	report := importCSV(t, svc, file, true)
+	if len(report.Errors) > 0 || report.Imported != 2 || report.Committed || !report.DryRun {
+		t.Errorf("dry run report = %+v, want two valid rows and nothing saved", report)
+	}
+	if tags := assetTags(t, stores); len(tags) != 0 {
+		t.Errorf("assets after a dry run = %v, want none", tags)
+	}
+
+	report = importCSV(t, svc, file, false)
+	if len(report.Errors) > 0 || !report.Committed {
+		t.Fatalf("import report = %+v, want it saved", report)
+	}
+	a, err := svc.AssetByTag(ctx, "EQ-001")
+	if err != nil || a.StatusID != models.StatusAssigned || a.HolderName == nil || *a.HolderName != "Ana Ruiz" {
+		t.Errorf("imported assigned asset = %+v, %v", a, err)
+	}
+	if a, err := svc.AssetByTag(ctx, "EQ-002"); err != nil || a.StatusID != models.StatusUnderMaintenance {
+		t.Errorf("imported asset under maintenance = %+v, %v", a, err)
+	}
+}
+
+func TestImportIsOneTransaction(t *testing.T) {
+	svc, stores := newInventory(t)
+
+	report := importCSV(t, svc, `asset_tag,type,make,model,serial_number,location,purchase_date
+EQ-001,Laptop,Dell,Latitude,SN-001,Main,2024-01-15
+EQ-002,Laptop,Dell,Latitude,SN-002,Main,not a date
+EQ-003,Laptop,Dell,Latitude,SN-003,Main,2024-01-15
+`, false)
+	if len(report.Errors) != 1 || report.Errors[0].Line != 3 || report.Committed {
+		t.Errorf("report = %+v, want one error on line 3 and nothing saved", report)
+	}
+	if tags := assetTags(t, stores); len(tags) != 0 {
+		t.Errorf("assets after a failed import = %v, want none", tags)
+	}
+}
@@ -0,0 +1,238 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// snapshotPrefix and snapshotLayout name the snapshot files, so sorting
+// them by name sorts them by time
+const (
+	snapshotPrefix = "itroom-"
+	snapshotLayout = "20060102-150405.000"
+)
+
+// Snapshot is a backup file written by DB.Snapshot
+type Snapshot struct {
+	Path  string    `json:"path"`
+	Taken time.Time `json:"taken"`
+	Size  int64     `json:"size"`
+}
+
+// Backup writes a consistent copy of the database to path with VACUUM INTO
+// It is safe while other connections read and write; path must not exist
+func (d *DB) Backup(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	_, err := d.Conn.Exec(`VACUUM INTO ?`, path)
+	return err
+}
+
+// Snapshot backs the database up to a timestamped file in dir and removes
+// the oldest snapshots beyond keep (0 keeps every snapshot)
+// It returns the path of the new snapshot
+func (d *DB) Snapshot(dir string, keep int) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, snapshotPrefix+time.Now().UTC().Format(snapshotLayout)+".db")
+	if err := d.Backup(path); err != nil {
+		return "", err
+	}
+	return path, rotateSnapshots(dir, keep)
+}
+
+// ListSnapshots returns the snapshots in dir, newest first
+// A missing directory holds no snapshots
+func ListSnapshots(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Snapshot
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), snapshotPrefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		taken, err := time.Parse(snapshotLayout, strings.TrimSuffix(stamp, ".db"))
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Snapshot{Path: filepath.Join(dir, e.Name()), Taken: taken, Size: info.Size()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Taken.After(out[j].Taken) })
+	return out, nil
+}
+
+// rotateSnapshots removes the oldest snapshots in dir beyond keep
+func rotateSnapshots(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	snapshots, err := ListSnapshots(dir)
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(snapshots); i++ {
+		if err := os.Remove(snapshots[i].Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckBackup verifies that path holds an intact itroom database this
+// version can open and returns its schema version
+func CheckBackup(path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, err
+	}
+	uri, err := readOnlyURI(path)
+	if err != nil {
+		return 0, err
+	}
+	conn, err := sql.Open("sqlite", uri)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var result string
+	if err := conn.QueryRow("PRAGMA quick_check").Scan(&result); err != nil {
+		return 0, fmt.Errorf("%s is not a readable SQLite database: %w", path, err)
+	}
+	if result != "ok" {
+		return 0, fmt.Errorf("%s is damaged: %s", path, result)
+	}
+
+	var assets int
+	if err := conn.QueryRow("SELECT count(name) FROM sqlite_master WHERE type='table' AND name='assets'").Scan(&assets); err != nil {
+		return 0, err
+	}
+	if assets == 0 {
+		return 0, fmt.Errorf("%s is not an itroom database", path)
+	}
+
+	var version int
+	if err := conn.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	if version > len(migrationFiles) {
+		return 0, fmt.Errorf("%s has schema version %d, newer than the %d this version of itroom supports",
+			path, version, len(migrationFiles))
+	}
+	return version, nil
+}
+
+// Restore replaces the contents of the database with the backup at path
+// using the SQLite online backup API, then applies any migrations the
+// backup predates
+// A database SQLite cannot read is replaced by a copy of the file instead
+func (d *DB) Restore(path string) error {
+	if _, err := CheckBackup(path); err != nil {
+		return err
+	}
+	if _, err := d.Conn.Exec("PRAGMA schema_version"); err != nil {
+		if err := d.replaceFile(path); err != nil {
+			return err
+		}
+		return d.applyMigrations()
+	}
+
+	uri, err := readOnlyURI(path)
+	if err != nil {
+		return err
+	}
+	conn, err := d.Conn.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	err = conn.Raw(func(driverConn any) error {
+		restorer, ok := driverConn.(interface {
+			NewRestore(string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return errors.New("the SQLite driver does not support restoring backups")
+		}
+		b, err := restorer.NewRestore(uri)
+		if err != nil {
+			return err
+		}
+		if _, err := b.Step(-1); err != nil {
+			b.Finish()
+			return err
+		}
+		return b.Finish()
+	})
+	conn.Close()
+	if err != nil {
+		return err
+	}
+
+	return d.applyMigrations()
+}
+
+// readOnlyURI returns the SQLite URI opening the file at path read-only,
+// escaping the characters a URI gives meaning to, such as ? and #
+func readOnlyURI(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	// Windows paths start with the drive letter rather than a slash
+	abs = filepath.ToSlash(abs)
+	if !strings.HasPrefix(abs, "/") {
+		abs = "/" + abs
+	}
+	u := url.URL{Scheme: "file", Path: abs, RawQuery: "mode=ro"}
+	return u.String(), nil
+}
+
+// replaceFile closes the database, overwrites its file with a copy of the
+// backup at path, dropping the write-ahead log of the old contents, and
+// opens the copy
+func (d *DB) replaceFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	d.Conn.Close()
+
+	tmp := d.path + ".restore"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(d.path + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := os.Rename(tmp, d.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	d.Conn, err = sql.Open("sqlite", d.path)
+	return err
+}
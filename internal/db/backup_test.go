@@ -0,0 +1,257 @@
+package db
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newDB opens a fresh database in a temporary directory
+func newDB(t *testing.T) *DB {
+	t.Helper()
+	d, err := New(filepath.Join(t.TempDir(), "itroom.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// addEmployee stores an employee named name
+func addEmployee(t *testing.T, conn *sql.DB, name string) {
+	t.Helper()
+	if _, err := conn.Exec("INSERT INTO employees (full_name, email) VALUES (?, ?)", name, name+"@example.com"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// employeeNames lists the names of the stored employees in insertion order
+func employeeNames(t *testing.T, conn *sql.DB) []string {
+	t.Helper()
+	rows, err := conn.Query("SELECT full_name FROM employees ORDER BY employee_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBackup(t *testing.T) {
+	d := newDB(t)
+	addEmployee(t, d.Conn, "Ana")
+
+	// The characters a URI gives meaning to must not break reading the copy
+	path := filepath.Join(t.TempDir(), "backup 50%?#1.db")
+	if err := d.Backup(path); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if err := d.Backup(path); err == nil {
+		t.Error("Backup over an existing file succeeded, want an error")
+	}
+
+	version, err := CheckBackup(path)
+	if err != nil || version != len(migrationFiles) {
+		t.Fatalf("CheckBackup = %d, %v, want version %d", version, err, len(migrationFiles))
+	}
+	uri, err := readOnlyURI(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup, err := sql.Open("sqlite", uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+	if got := employeeNames(t, backup); !equalNames(got, []string{"Ana"}) {
+		t.Errorf("employees in the backup = %v, want [Ana]", got)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	d := newDB(t)
+	dir := filepath.Join(t.TempDir(), "snapshots")
+
+	path, err := d.Snapshot(dir, 3)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	snapshots, err := ListSnapshots(dir)
+	if err != nil || len(snapshots) != 1 || snapshots[0].Path != path || snapshots[0].Size == 0 {
+		t.Fatalf("ListSnapshots = %+v, %v, want the one taken at %s", snapshots, err, path)
+	}
+	if _, err := CheckBackup(path); err != nil {
+		t.Errorf("CheckBackup(snapshot): %v", err)
+	}
+
+	if snapshots, err := ListSnapshots(filepath.Join(dir, "missing")); err != nil || len(snapshots) != 0 {
+		t.Errorf("ListSnapshots(missing dir) = %v, %v, want none", snapshots, err)
+	}
+}
+
+func TestRotateSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	var names []string
+	for i := range 5 {
+		name := snapshotPrefix + start.Add(time.Duration(i)*time.Hour).Format(snapshotLayout) + ".db"
+		names = append(names, name)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("snapshot"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Files not named as snapshots are never removed
+	if err := os.WriteFile(filepath.Join(dir, "itroom.db"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rotateSnapshots(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	if snapshots, _ := ListSnapshots(dir); len(snapshots) != 5 {
+		t.Errorf("keep 0 left %d snapshots, want all 5", len(snapshots))
+	}
+
+	if err := rotateSnapshots(dir, 2); err != nil {
+		t.Fatal(err)
+	}
+	snapshots, err := ListSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, s := range snapshots {
+		kept = append(kept, filepath.Base(s.Path))
+	}
+	if want := []string{names[4], names[3]}; !equalNames(kept, want) {
+		t.Errorf("kept snapshots = %v, want the newest %v", kept, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "itroom.db")); err != nil {
+		t.Errorf("rotation removed a file that is not a snapshot: %v", err)
+	}
+}
+
+func TestCheckBackupRejects(t *testing.T) {
+	dir := t.TempDir()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte("not a database, just some text that is long enough"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	other := filepath.Join(dir, "other.db")
+	conn, err := sql.Open("sqlite", other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec("CREATE TABLE notes (body TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	newer := filepath.Join(dir, "newer.db")
+	if err := newDB(t).Backup(newer); err != nil {
+		t.Fatal(err)
+	}
+	conn, err = sql.Open("sqlite", newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec("PRAGMA user_version = 99"); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	for name, path := range map[string]string{
+		"missing":         filepath.Join(dir, "missing.db"),
+		"not SQLite":      garbage,
+		"not itroom":      other,
+		"newer versioned": newer,
+	} {
+		if _, err := CheckBackup(path); err == nil {
+			t.Errorf("CheckBackup(%s) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestRestore(t *testing.T) {
+	d := newDB(t)
+	addEmployee(t, d.Conn, "Ana")
+	path := filepath.Join(t.TempDir(), "backup ?#1.db")
+	if err := d.Backup(path); err != nil {
+		t.Fatal(err)
+	}
+	addEmployee(t, d.Conn, "Luis")
+
+	if err := d.Restore(path); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := employeeNames(t, d.Conn); !equalNames(got, []string{"Ana"}) {
+		t.Errorf("employees after restore = %v, want [Ana]", got)
+	}
+	if version, err := d.SchemaVersion(); err != nil || version != len(migrationFiles) {
+		t.Errorf("schema version after restore = %d, %v, want %d", version, err, len(migrationFiles))
+	}
+
+	// A backup that fails the check leaves the database as it was
+	addEmployee(t, d.Conn, "Luis")
+	if err := d.Restore(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("Restore of a missing file succeeded, want an error")
+	}
+	if got := employeeNames(t, d.Conn); !equalNames(got, []string{"Ana", "Luis"}) {
+		t.Errorf("employees after a refused restore = %v, want [Ana Luis]", got)
+	}
+}
+
+func TestRestoreDamaged(t *testing.T) {
+	src := newDB(t)
+	addEmployee(t, src.Conn, "Ana")
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	if err := src.Backup(backup); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "itroom.db")
+	if err := os.WriteFile(path, []byte("overwritten by something else entirely"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := OpenAsIs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { d.Close() }()
+
+	if err := d.Restore(backup); err != nil {
+		t.Fatalf("Restore over a damaged database: %v", err)
+	}
+	if got := employeeNames(t, d.Conn); !equalNames(got, []string{"Ana"}) {
+		t.Errorf("employees after restore = %v, want [Ana]", got)
+	}
+	if version, err := d.SchemaVersion(); err != nil || version != len(migrationFiles) {
+		t.Errorf("schema version after restore = %d, %v, want %d", version, err, len(migrationFiles))
+	}
+}
@@ -3,17 +3,50 @@ package db
 import (
 	"database/sql"
 	"fmt"
-	"io"
-	"os"
+	"strings"
 
+	"github.com/MawCeron/it-room/migrations"
 	_ "modernc.org/sqlite"
 )
 
+// migrationFiles lists the schema files embedded in migrations.FS, applied
+// in order. The position of a file in this list (starting at 1) is the
+// schema version it brings the database to, tracked in PRAGMA user_version.
+var migrationFiles = []string{
+	"schema.sql",
+	"002_saved_views.sql",
+	"003_asset_history.sql",
+	"004_stock_and_loans.sql",
+	"005_users.sql",
+	"006_audit_log.sql",
+	"007_kits.sql",
+	"008_asset_relations.sql",
+	"009_stock_items.sql",
+	"010_custom_fields.sql",
+	"011_financials.sql",
+}
+
 type DB struct {
 	Conn *sql.DB
+	path string
+}
+
+// Options configures how Open prepares the database
+type Options struct {
+	// SnapshotDir receives a snapshot of an existing database before
+	// migrations run; empty takes none
+	SnapshotDir  string
+	SnapshotKeep int  // Snapshots kept in SnapshotDir, 0 keeps all
+	SnapshotAll  bool // Snapshot on every open, not only before migrations
 }
 
 func New(path string) (*DB, error) {
+	return Open(path, Options{})
+}
+
+// Open opens the database at path, taking a snapshot as set in opts, and
+// brings its schema up to date
+func Open(path string, opts Options) (*DB, error) {
 	conn, err := sql.Open("sqlite", path)
 	if err != nil {
 		return nil, err
@@ -31,9 +64,23 @@ func New(path string) (*DB, error) {
 		}
 	}
 
-	db := &DB{Conn: conn}
+	db := &DB{Conn: conn, path: path}
+
+	if opts.SnapshotDir != "" {
+		version, err := db.currentVersion()
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if version > 0 && (opts.SnapshotAll || version < len(migrationFiles)) {
+			if _, err := db.Snapshot(opts.SnapshotDir, opts.SnapshotKeep); err != nil {
+				conn.Close()
+				return nil, fmt.Errorf("could not take startup snapshot: %w", err)
+			}
+		}
+	}
 
-	if err := db.applyInitialMigration(); err != nil {
+	if err := db.applyMigrations(); err != nil {
 		conn.Close()
 		return nil, err
 	}
@@ -41,37 +88,70 @@ func New(path string) (*DB, error) {
 	return db, nil
 }
 
+// OpenAsIs opens the database at path without setting pragmas, taking a
+// snapshot or migrating it, so a damaged database can be restored over
+func OpenAsIs(path string) (*DB, error) {
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{Conn: conn, path: path}, nil
+}
+
 func (d *DB) Close() error { return d.Conn.Close() }
 
-func (d *DB) applyInitialMigration() error {
-	var count int
-	row := d.Conn.QueryRow("SELECT count(name) FROM sqlite_master WHERE type='table'")
-	if err := row.Scan(&count); err != nil {
-		return err
-	}
-	if count > 0 {
-		return nil
+// SchemaVersion returns the schema version recorded in the database
+func (d *DB) SchemaVersion() (int, error) {
+	var version int
+	if err := d.Conn.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, err
 	}
+	return version, nil
+}
 
-	path := "migrations/schema.sql"
+// currentVersion returns the schema version of the database, 0 when empty
+func (d *DB) currentVersion() (int, error) {
+	version, err := d.SchemaVersion()
+	if err != nil {
+		return 0, err
+	}
 
-	var r io.Reader
-	var f *os.File
-	if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
-		ff, err := os.Open(path)
-		if err != nil {
-			return err
+	// Databases created before versioning have tables but no user_version;
+	// they already contain the initial schema
+	if version == 0 {
+		var count int
+		row := d.Conn.QueryRow("SELECT count(name) FROM sqlite_master WHERE type='table'")
+		if err := row.Scan(&count); err != nil {
+			return 0, err
+		}
+		if count > 0 {
+			version = 1
 		}
-		f = ff
-		defer f.Close()
-		r = f
 	}
+	return version, nil
+}
 
-	if r == nil {
-		return fmt.Errorf("migration file not found in %v", path)
+// applyMigrations brings the schema up to date, applying every migration
+// newer than the recorded schema version
+func (d *DB) applyMigrations() error {
+	version, err := d.currentVersion()
+	if err != nil {
+		return err
 	}
 
-	b, err := io.ReadAll(r)
+	for i := version; i < len(migrationFiles); i++ {
+		if err := d.applyMigration(migrationFiles[i], i+1); err != nil {
+			return fmt.Errorf("migration %s: %w", migrationFiles[i], err)
+		}
+	}
+
+	return nil
+}
+
+// applyMigration runs a single migration file inside a transaction and
+// records the schema version it brings the database to
+func (d *DB) applyMigration(name string, version int) error {
+	b, err := migrations.FS.ReadFile(name)
 	if err != nil {
 		return err
 	}
@@ -92,15 +172,22 @@ func (d *DB) applyInitialMigration() error {
 		}
 	}
 
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// splitSQLStatements splits a script on the semicolons ending statements
+// The body of a CREATE TRIGGER statement is kept whole up to its END
 func splitSQLStatements(sqlText string) []string {
 	var out []string
 	cur := ""
 	for _, r := range sqlText {
 		cur += string(r)
-		if r == ';' {
+		if r == ';' && !(isCreateTrigger(cur) && !endsTrigger(cur)) {
 			out = append(out, cur)
 			cur = ""
 		}
@@ -112,3 +199,24 @@ func splitSQLStatements(sqlText string) []string {
 
 	return out
 }
+
+// isCreateTrigger reports whether a statement, after any leading comment
+// lines, creates a trigger
+func isCreateTrigger(stmt string) bool {
+	var code []string
+	for _, line := range strings.Split(stmt, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+			code = append(code, trimmed)
+		}
+	}
+	fields := strings.Fields(strings.ToUpper(strings.Join(code, " ")))
+	return len(fields) >= 2 && fields[0] == "CREATE" && (fields[1] == "TRIGGER" ||
+		len(fields) >= 3 && (fields[1] == "TEMP" || fields[1] == "TEMPORARY") && fields[2] == "TRIGGER")
+}
+
+// endsTrigger reports whether a statement ending in a semicolon closes a
+// trigger body
+func endsTrigger(stmt string) bool {
+	fields := strings.Fields(strings.ToUpper(strings.TrimSuffix(stmt, ";")))
+	return len(fields) > 0 && fields[len(fields)-1] == "END"
+}
@@ -0,0 +1,268 @@
+// Package doctor checks the database for corruption and records that
+// contradict each other, and fixes the ones that have a safe fix
+package doctor
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+// Finding is a problem reported by a check
+type Finding struct {
+	Check   string `json:"check"`
+	Entity  string `json:"entity"` // Table of the offending row
+	ID      string `json:"id"`     // Primary key or rowid of the offending row
+	Message string `json:"message"`
+	Fixable bool   `json:"fixable"` // Fix resolves it
+}
+
+// Check is a consistency rule
+type Check struct {
+	ID          string
+	Description string
+	FixAction   string // What Fix does about the findings, empty when a person must decide
+
+	find func(db *sql.DB) ([]Finding, error)
+	fix  string // Statement resolving the fixable findings
+}
+
+// statusArgs names the status IDs used by the rules
+var statusArgs = []any{
+	sql.Named("assigned", models.StatusAssigned),
+	sql.Named("available", models.StatusAvailable),
+	sql.Named("retired", models.StatusRetired),
+}
+
+// openAssignment matches an open assignment of asset a
+const openAssignment = `EXISTS (SELECT 1 FROM asset_assignments aa
+	WHERE aa.asset_id = a.asset_id AND aa.return_date IS NULL)`
+
+// Checks lists every rule in the order they run
+// Fixes run in the same order, so closing extra assignments comes before
+// the status rules that depend on them
+var Checks = []Check{
+	{
+		ID:          "integrity",
+		Description: "SQLite integrity check of the database file",
+		find:        findIntegrity,
+	},
+	{
+		ID:          "foreign_keys",
+		Description: "Rows referencing records that do not exist",
+		find:        findForeignKeys,
+	},
+	{
+		ID:          "multiple_open_assignments",
+		Description: "Assets with more than one open assignment",
+		FixAction:   "return every assignment but the newest on the newest one's date",
+		find: query("asset_assignments", `SELECT aa.assignment_id,
+	'asset ' || a.asset_tag || ' is also assigned to ' || e.full_name || ' since ' || aa.assignment_date, 1
+FROM asset_assignments aa
+JOIN assets a ON a.asset_id = aa.asset_id
+JOIN employees e ON e.employee_id = aa.employee_id
+WHERE aa.return_date IS NULL AND aa.assignment_id <> (SELECT n.assignment_id FROM asset_assignments n
+	WHERE n.asset_id = aa.asset_id AND n.return_date IS NULL
+	ORDER BY n.assignment_date DESC, n.assignment_id DESC LIMIT 1)
+ORDER BY a.asset_tag;`),
+		fix: `UPDATE asset_assignments SET returned_by = :actor,
+	return_date = (SELECT max(n.assignment_date) FROM asset_assignments n
+		WHERE n.asset_id = asset_assignments.asset_id AND n.return_date IS NULL)
+WHERE return_date IS NULL AND assignment_id <> (SELECT n.assignment_id FROM asset_assignments n
+	WHERE n.asset_id = asset_assignments.asset_id AND n.return_date IS NULL
+	ORDER BY n.assignment_date DESC, n.assignment_id DESC LIMIT 1);`,
+	},
+	{
+		ID:          "retired_assigned",
+		Description: "Retired assets still assigned to someone",
+		FixAction:   "return the assignment now",
+		find: query("assets", `SELECT a.asset_id, 'asset ' || a.asset_tag || ' is retired but still assigned', 1
+FROM assets a
+WHERE a.status_id = :retired AND `+openAssignment+`
+ORDER BY a.asset_tag;`),
+		fix: `UPDATE asset_assignments SET return_date = datetime('now'), returned_by = :actor
+WHERE return_date IS NULL AND asset_id IN (SELECT asset_id FROM assets WHERE status_id = :retired);`,
+	},
+	{
+		ID:          "assigned_without_holder",
+		Description: "Assets with status Assigned but no open assignment",
+		FixAction:   "set the status to Available",
+		find: query("assets", `SELECT a.asset_id, 'asset ' || a.asset_tag || ' is Assigned but has no open assignment', 1
+FROM assets a
+WHERE a.status_id = :assigned AND NOT `+openAssignment+`
+ORDER BY a.asset_tag;`),
+		fix: `UPDATE assets SET status_id = :available, updated_by = :actor
+WHERE status_id = :assigned AND NOT EXISTS (SELECT 1 FROM asset_assignments aa
+	WHERE aa.asset_id = assets.asset_id AND aa.return_date IS NULL);`,
+	},
+	{
+		ID:          "holder_not_assigned",
+		Description: "Assets with an open assignment but a status other than Assigned",
+		FixAction:   "set Available assets to Assigned; assets under maintenance need a person",
+		find: query("assets", `SELECT a.asset_id, 'asset ' || a.asset_tag || ' has an open assignment but is ' || s.status_name,
+	a.status_id = :available
+FROM assets a
+JOIN asset_statuses s ON s.status_id = a.status_id
+WHERE a.status_id NOT IN (:assigned, :retired) AND `+openAssignment+`
+ORDER BY a.asset_tag;`),
+		fix: `UPDATE assets SET status_id = :assigned, updated_by = :actor
+WHERE status_id = :available AND EXISTS (SELECT 1 FROM asset_assignments aa
+	WHERE aa.asset_id = assets.asset_id AND aa.return_date IS NULL);`,
+	},
+	{
+		ID:          "retired_with_licenses",
+		Description: "Software licenses still installed on retired assets",
+		FixAction:   "remove the installations now, freeing their seats",
+		find: query("license_assignments", `SELECT la.assignment_id,
+	'license ' || l.software_name || ' is still installed on retired asset ' || a.asset_tag, 1
+FROM license_assignments la
+JOIN software_licenses l ON l.license_id = la.license_id
+JOIN assets a ON a.asset_id = la.asset_id
+WHERE la.removal_date IS NULL AND a.status_id = :retired
+ORDER BY a.asset_tag, l.software_name;`),
+		fix: `UPDATE license_assignments SET removal_date = datetime('now')
+WHERE removal_date IS NULL AND asset_id IN (SELECT asset_id FROM assets WHERE status_id = :retired);`,
+	},
+	{
+		ID:          "warranty_before_purchase",
+		Description: "Warranties ending before the asset was purchased",
+		find: query("assets", `SELECT a.asset_id,
+	'asset ' || a.asset_tag || ' warranty ends ' || a.warranty_end_date || ', before its purchase on ' || a.purchase_date, 0
+FROM assets a
+WHERE a.warranty_end_date < a.purchase_date
+ORDER BY a.asset_tag;`),
+	},
+	{
+		ID:          "returned_before_assigned",
+		Description: "Assignments returned before they started",
+		find: query("asset_assignments", `SELECT aa.assignment_id,
+	'asset ' || a.asset_tag || ' was returned on ' || date(aa.return_date) || ', before its assignment on ' || date(aa.assignment_date), 0
+FROM asset_assignments aa
+JOIN assets a ON a.asset_id = aa.asset_id
+WHERE date(aa.return_date) < date(aa.assignment_date)
+ORDER BY a.asset_tag;`),
+	},
+	{
+		ID:          "location_mismatch",
+		Description: "Assets whose location differs from where their last transfer took them",
+		find: query("assets", `SELECT a.asset_id,
+	'asset ' || a.asset_tag || ' is at ' || l.name || ' but was last transferred to ' || lt.name, 0
+FROM assets a
+JOIN locations l ON l.location_id = a.location_id
+JOIN asset_transfers t ON t.transfer_id = (SELECT n.transfer_id FROM asset_transfers n
+	WHERE n.asset_id = a.asset_id ORDER BY n.transfer_date DESC, n.transfer_id DESC LIMIT 1)
+JOIN locations lt ON lt.location_id = t.to_location_id
+WHERE t.to_location_id <> a.location_id
+ORDER BY a.asset_tag;`),
+	},
+}
+
+// Run runs every check and returns the findings in check order
+func Run(db *sql.DB) ([]Finding, error) {
+	var out []Finding
+	for _, c := range Checks {
+		findings, err := c.find(db)
+		if err != nil {
+			return nil, fmt.Errorf("check %s: %w", c.ID, err)
+		}
+		for i := range findings {
+			findings[i].Check = c.ID
+		}
+		out = append(out, findings...)
+	}
+	return out, nil
+}
+
+// Fix applies every automatic fix in a single transaction, attributing the
+// changes to actor, and returns the rows changed by check ID
+func Fix(db *sql.DB, actor *string) (map[string]int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	fixed := map[string]int64{}
+	for _, c := range Checks {
+		if c.fix == "" {
+			continue
+		}
+		res, err := tx.Exec(c.fix, append([]any{sql.Named("actor", actor)}, statusArgs...)...)
+		if err != nil {
+			return nil, fmt.Errorf("fix %s: %w", c.ID, err)
+		}
+		if fixed[c.ID], err = res.RowsAffected(); err != nil {
+			return nil, err
+		}
+	}
+	return fixed, tx.Commit()
+}
+
+// query returns a find function for a statement selecting the ID, message
+// and fixability of each offending row of entity
+func query(entity, stmt string) func(db *sql.DB) ([]Finding, error) {
+	return func(db *sql.DB) ([]Finding, error) {
+		rows, err := db.Query(stmt, statusArgs...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		var out []Finding
+		for rows.Next() {
+			f := Finding{Entity: entity}
+			if err := rows.Scan(&f.ID, &f.Message, &f.Fixable); err != nil {
+				return nil, err
+			}
+			out = append(out, f)
+		}
+		return out, rows.Err()
+	}
+}
+
+// findIntegrity reports the problems found by PRAGMA integrity_check
+func findIntegrity(db *sql.DB) ([]Finding, error) {
+	rows, err := db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Finding
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return nil, err
+		}
+		if msg != "ok" {
+			out = append(out, Finding{Entity: "database", Message: msg})
+		}
+	}
+	return out, rows.Err()
+}
+
+// findForeignKeys reports the rows found by PRAGMA foreign_key_check
+func findForeignKeys(db *sql.DB) ([]Finding, error) {
+	rows, err := db.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Finding
+	for rows.Next() {
+		var table, parent string
+		var rowID sql.NullInt64
+		var fkID int
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return nil, err
+		}
+		f := Finding{Entity: table, Message: fmt.Sprintf("%s row references a missing %s row", table, parent)}
+		if rowID.Valid {
+			f.ID = fmt.Sprint(rowID.Int64)
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
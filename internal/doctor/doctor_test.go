@@ -0,0 +1,174 @@
+package doctor_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/MawCeron/it-room/internal/db"
+	"github.com/MawCeron/it-room/internal/doctor"
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+// consistent is an inventory without problems: two assets, one assigned
+// with a license installed, and a transfer the location agrees with
+const consistent = `
+INSERT INTO employees (employee_id, full_name, email) VALUES (1, 'Ana Ruiz', 'ana@example.com'), (2, 'Luis Paz', 'luis@example.com');
+INSERT INTO assets (asset_id, asset_tag, type_id, status_id, serial_number, make, model, purchase_date, warranty_end_date, location_id)
+VALUES ('a1', 'EQ-001', 1, :assigned, 'SN-001', 'Dell', 'Latitude', '2024-01-15', '2027-01-15', 2),
+	('a2', 'EQ-002', 1, :available, 'SN-002', 'Dell', 'Latitude', '2024-01-15', NULL, 1);
+INSERT INTO asset_assignments (asset_id, employee_id, assignment_date, return_date)
+VALUES ('a1', 1, '2024-02-01 09:00:00', NULL), ('a2', 2, '2024-02-01 09:00:00', '2024-03-01 09:00:00');
+INSERT INTO software_licenses (license_id, software_name, license_key, license_type, seats_purchased, purchase_date)
+VALUES (1, 'Office', 'KEY-1', 'Volume', 5, '2024-01-15');
+INSERT INTO license_assignments (license_id, asset_id) VALUES (1, 'a1');
+INSERT INTO asset_transfers (asset_id, from_location_id, to_location_id, transfer_date) VALUES ('a1', 1, 2, '2024-02-01 09:00:00');
+`
+
+var statusArgs = []any{
+	sql.Named("assigned", models.StatusAssigned),
+	sql.Named("available", models.StatusAvailable),
+	sql.Named("retired", models.StatusRetired),
+	sql.Named("maintenance", models.StatusUnderMaintenance),
+}
+
+// newDB opens a fresh database holding the consistent inventory
+func newDB(t *testing.T) *db.DB {
+	t.Helper()
+	d, err := db.New(filepath.Join(t.TempDir(), "itroom.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	if _, err := d.Conn.Exec(consistent, statusArgs...); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestConsistentInventory(t *testing.T) {
+	d := newDB(t)
+	findings, err := doctor.Run(d.Conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) > 0 {
+		t.Errorf("findings on a consistent inventory = %+v", findings)
+	}
+}
+
+func TestChecks(t *testing.T) {
+	for _, tt := range []struct {
+		check   string
+		seed    string
+		entity  string
+		fixable bool
+	}{
+		{"multiple_open_assignments", `INSERT INTO asset_assignments (asset_id, employee_id, assignment_date)
+			VALUES ('a1', 2, '2024-01-20 09:00:00');`, "asset_assignments", true},
+		{"retired_assigned", `UPDATE assets SET status_id = :retired WHERE asset_id = 'a1';
+			UPDATE license_assignments SET removal_date = '2024-06-01';`, "assets", true},
+		{"assigned_without_holder", `UPDATE assets SET status_id = :assigned WHERE asset_id = 'a2';`, "assets", true},
+		{"holder_not_assigned", `UPDATE assets SET status_id = :available WHERE asset_id = 'a1';`, "assets", true},
+		{"holder_not_assigned", `UPDATE assets SET status_id = :maintenance WHERE asset_id = 'a1';`, "assets", false},
+		{"retired_with_licenses", `UPDATE assets SET status_id = :retired WHERE asset_id = 'a1';
+			UPDATE asset_assignments SET return_date = '2024-06-01' WHERE asset_id = 'a1';`, "license_assignments", true},
+		{"warranty_before_purchase", `UPDATE assets SET warranty_end_date = '2023-12-31' WHERE asset_id = 'a1';`, "assets", false},
+		{"returned_before_assigned", `UPDATE asset_assignments SET return_date = '2024-01-31 09:00:00' WHERE asset_id = 'a2';`,
+			"asset_assignments", false},
+		{"location_mismatch", `UPDATE assets SET location_id = 1 WHERE asset_id = 'a1';`, "assets", false},
+	} {
+		t.Run(tt.check, func(t *testing.T) {
+			d := newDB(t)
+			if _, err := d.Conn.Exec(tt.seed, statusArgs...); err != nil {
+				t.Fatal(err)
+			}
+
+			findings, err := doctor.Run(d.Conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(findings) != 1 {
+				t.Fatalf("findings = %+v, want one from %s", findings, tt.check)
+			}
+			f := findings[0]
+			if f.Check != tt.check || f.Entity != tt.entity || f.ID == "" || f.Message == "" || f.Fixable != tt.fixable {
+				t.Errorf("finding = %+v, want %s on %s, fixable %v", f, tt.check, tt.entity, tt.fixable)
+			}
+
+			actor := "doctor"
+			fixed, err := doctor.Fix(d.Conn, &actor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (fixed[tt.check] > 0) != tt.fixable {
+				t.Errorf("fixed = %v, want %s fixed: %v", fixed, tt.check, tt.fixable)
+			}
+			findings, err = doctor.Run(d.Conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.fixable && len(findings) != 0 {
+				t.Errorf("findings after Fix = %+v, want none", findings)
+			} else if !tt.fixable && len(findings) != 1 {
+				t.Errorf("findings after Fix = %+v, want the one a person must resolve", findings)
+			}
+		})
+	}
+}
+
+func TestForeignKeys(t *testing.T) {
+	d := newDB(t)
+	ctx := context.Background()
+	conn, err := d.Conn.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF;
+		INSERT INTO asset_comments (asset_id, body) VALUES ('missing', 'orphaned');
+		PRAGMA foreign_keys = ON;`); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := doctor.Run(d.Conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Check != "foreign_keys" || findings[0].Entity != "asset_comments" || findings[0].ID == "" {
+		t.Errorf("findings = %+v, want the orphaned comment", findings)
+	}
+}
+
+func TestIntegrity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "itroom.db")
+	d, err := db.New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Redefining an index over other values leaves its entries out of step
+	// with the table
+	if _, err := d.Conn.Exec(`CREATE TABLE scratch (a TEXT, b TEXT);
+		CREATE INDEX scratch_a ON scratch (a);
+		INSERT INTO scratch VALUES ('1', 'z'), ('2', 'y'), ('3', 'x');
+		PRAGMA writable_schema = ON;
+		UPDATE sqlite_schema SET sql = 'CREATE INDEX scratch_a ON scratch (b)' WHERE name = 'scratch_a';
+		PRAGMA writable_schema = OFF;`); err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+
+	d, err = db.OpenAsIs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	findings, err := doctor.Run(d.Conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) == 0 || findings[0].Check != "integrity" || findings[0].Entity != "database" {
+		t.Errorf("findings = %+v, want integrity problems", findings)
+	}
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Depreciation methods
+const (
+	DepreciationStraightLine     = "straight-line"
+	DepreciationDecliningBalance = "declining-balance"
+)
+
+// DepreciationMethods lists the accepted depreciation methods
+var DepreciationMethods = []string{DepreciationStraightLine, DepreciationDecliningBalance}
+
+// DepreciationRule is how the assets of a category lose value over their
+// useful life
+type DepreciationRule struct {
+	CategoryID      int       `db:"category_id" json:"category_id"`
+	CategoryName    string    `db:"category_name" json:"category_name"`
+	Method          string    `db:"method" json:"method"`
+	UsefulLifeYears int       `db:"useful_life_years" json:"useful_life_years"`
+	SalvagePercent  float64   `db:"salvage_percent" json:"salvage_percent"` // Value left at the end of the useful life, as a percentage of cost
+	Factor          *float64  `db:"factor" json:"factor"`                   // Nullable, multiple of the straight-line rate for declining balance
+	UpdatedBy       *string   `db:"updated_by" json:"updated_by"`           // Nullable, username
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// BookValueOn returns the value on day of an asset bought for cost on
+// purchased, rounded to cents
+// Depreciation accrues by whole months from the purchase date; at the end of
+// the useful life only the salvage value is left
+func (r *DepreciationRule) BookValueOn(cost float64, purchased, day time.Time) float64 {
+	salvage := cost * r.SalvagePercent / 100
+	life := float64(r.UsefulLifeYears)
+	years := float64(monthsBetween(purchased, day)) / 12
+
+	value := cost
+	switch {
+	case years <= 0:
+	case years >= life:
+		value = salvage
+	case r.Method == DepreciationDecliningBalance && r.Factor != nil:
+		rate := math.Min(*r.Factor/life, 1)
+		value = math.Max(cost*math.Pow(1-rate, years), salvage)
+	default:
+		value = cost - (cost-salvage)*years/life
+	}
+	return math.Round(value*100) / 100
+}
+
+// monthsBetween returns the whole months from one date to a later one, 0
+// when to is before from
+func monthsBetween(from, to time.Time) int {
+	months := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	return max(months, 0)
+}
+
+// BookValue is what an asset is worth on a date after depreciation
+type BookValue struct {
+	AssetID      string    `json:"asset_id"`
+	AssetTag     string    `json:"asset_tag"`
+	CategoryName string    `json:"category_name"`
+	LocationName string    `json:"location_name"`
+	PurchaseDate time.Time `json:"purchase_date"`
+	Currency     string    `json:"currency"`
+	Cost         float64   `json:"cost"`
+	Depreciation float64   `json:"depreciation"` // Accumulated up to the date
+	Value        float64   `json:"book_value"`
+	Method       string    `json:"method"` // Empty when the category has no depreciation rule
+}
+
+// BookValueTotal sums the book values of a group of assets bought in one
+// currency
+type BookValueTotal struct {
+	Group        string  `json:"group"`
+	Currency     string  `json:"currency"`
+	Assets       int     `json:"assets"`
+	Cost         float64 `json:"cost"`
+	Depreciation float64 `json:"depreciation"`
+	Value        float64 `json:"book_value"`
+}
+
+// TotalBookValues sums book values by the group each belongs to and by
+// currency, ordered by group then currency
+func TotalBookValues(values []*BookValue, group func(*BookValue) string) []*BookValueTotal {
+	type key struct{ group, currency string }
+	totals := map[key]*BookValueTotal{}
+	var out []*BookValueTotal
+	for _, v := range values {
+		k := key{group(v), v.Currency}
+		t, ok := totals[k]
+		if !ok {
+			t = &BookValueTotal{Group: k.group, Currency: k.currency}
+			totals[k] = t
+			out = append(out, t)
+		}
+		t.Assets++
+		t.Cost += v.Cost
+		t.Depreciation += v.Depreciation
+		t.Value += v.Value
+	}
+	for _, t := range out {
+		t.Cost = math.Round(t.Cost*100) / 100
+		t.Depreciation = math.Round(t.Depreciation*100) / 100
+		t.Value = math.Round(t.Value*100) / 100
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Group != out[j].Group {
+			return out[i].Group < out[j].Group
+		}
+		return out[i].Currency < out[j].Currency
+	})
+	return out
+}
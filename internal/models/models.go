@@ -1,38 +1,518 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
 	"time"
 )
 
 // Asset represents an IT asset in the inventory
 type Asset struct {
-	AssetID         string     `db:"asset_id"`
-	AssetTag        string     `db:"asset_tag"`
-	TypeID          int        `db:"type_id"`
-	StatusID        int        `db:"status_id"`
-	SerialNumber    string     `db:"serial_number"`
-	Maker           string     `db:"make"`
-	Model           string     `db:"model"`
-	PurchaseDate    time.Time  `db:"purchase_date"`
-	WarrantyEndDate *time.Time `db:"warranty_end_date"` // Nullable
-	LocationID      int        `db:"location_id"`
-	Notes           *string    `db:"notes"` // Nullable
+	AssetID         string     `db:"asset_id" json:"asset_id"`
+	AssetTag        string     `db:"asset_tag" json:"asset_tag"`
+	TypeID          int        `db:"type_id" json:"type_id"`
+	StatusID        int        `db:"status_id" json:"status_id"`
+	SerialNumber    string     `db:"serial_number" json:"serial_number"`
+	Maker           string     `db:"make" json:"make"`
+	Model           string     `db:"model" json:"model"`
+	PurchaseDate    time.Time  `db:"purchase_date" json:"purchase_date"`
+	WarrantyEndDate *time.Time `db:"warranty_end_date" json:"warranty_end_date"` // Nullable
+	LocationID      int        `db:"location_id" json:"location_id"`
+	Notes           *string    `db:"notes" json:"notes"`           // Nullable
+	CreatedBy       *string    `db:"created_by" json:"created_by"` // Nullable, username
+	UpdatedBy       *string    `db:"updated_by" json:"updated_by"` // Nullable, username
+
+	PurchaseCost  *float64 `db:"purchase_cost" json:"purchase_cost"`   // Nullable
+	Currency      *string  `db:"currency" json:"currency"`             // Nullable, ISO 4217 code, set with PurchaseCost
+	Supplier      *string  `db:"supplier" json:"supplier"`             // Nullable
+	PONumber      *string  `db:"po_number" json:"po_number"`           // Nullable, purchase order
+	InvoiceNumber *string  `db:"invoice_number" json:"invoice_number"` // Nullable
+
+	// Custom field values by field name, only filled in where they are
+	// read or saved along with the asset
+	Fields map[string]string `db:"-" json:"fields,omitempty"`
+}
+
+// currencyCode matches an ISO 4217 currency code
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// IsCurrencyCode reports whether s is a three-letter ISO 4217 currency code
+func IsCurrencyCode(s string) bool {
+	return currencyCode.MatchString(s)
 }
 
 type AssetCategory struct {
-	CategoryId  int    `db:"category_id"`
-	CodePrefix  string `db:"code_prefix"`
-	Description string `db:"description"`
+	CategoryId  int    `db:"category_id" json:"category_id"`
+	CodePrefix  string `db:"code_prefix" json:"code_prefix"`
+	Description string `db:"description" json:"description"`
 }
 
 type AssetType struct {
-	TypeID     int    `db:"type_id"`
-	CategoryID int    `db:"category_id"`
-	TypeName   string `db:"type_name"`
+	TypeID     int    `db:"type_id" json:"type_id"`
+	CategoryID int    `db:"category_id" json:"category_id"`
+	TypeName   string `db:"type_name" json:"type_name"`
+}
+
+// Asset status IDs as seeded by the initial schema
+const (
+	StatusAssigned         = 1
+	StatusAvailable        = 2
+	StatusUnderMaintenance = 3
+	StatusRetired          = 4
+)
+
+type AssetStatus struct {
+	StatusID   int    `db:"status_id" json:"status_id"`
+	StatusName string `db:"status_name" json:"status_name"`
 }
 
 type Location struct {
-	LocationID int    `db:"location_id"`
-	Name       string `db:"name"`
-	Type       string `db:"type"`
+	LocationID int    `db:"location_id" json:"location_id"`
+	Name       string `db:"name" json:"name"`
+	Type       string `db:"type" json:"type"`
+}
+
+// Employee is a person who can receive assets or licenses
+type Employee struct {
+	EmployeeID int     `db:"employee_id" json:"employee_id"`
+	FullName   string  `db:"full_name" json:"full_name"`
+	Email      string  `db:"email" json:"email"`
+	CreatedBy  *string `db:"created_by" json:"created_by"` // Nullable, username
+}
+
+// AssetAssignment records an asset handed to an employee
+// The assignment is open while ReturnDate is nil
+type AssetAssignment struct {
+	AssignmentID   int        `db:"assignment_id" json:"assignment_id"`
+	AssetID        string     `db:"asset_id" json:"asset_id"`
+	AssetTag       string     `db:"asset_tag" json:"asset_tag"`
+	EmployeeID     int        `db:"employee_id" json:"employee_id"`
+	EmployeeName   string     `db:"full_name" json:"full_name"`
+	AssignmentDate time.Time  `db:"assignment_date" json:"assignment_date"`
+	DueDate        *time.Time `db:"due_date" json:"due_date"`       // Nullable, set for loans
+	ReturnDate     *time.Time `db:"return_date" json:"return_date"` // Nullable
+	Notes          *string    `db:"notes" json:"notes"`             // Nullable
+	AssignedBy     *string    `db:"assigned_by" json:"assigned_by"` // Nullable, username
+	ReturnedBy     *string    `db:"returned_by" json:"returned_by"` // Nullable, username
+}
+
+// AssetTransfer records an asset moving between locations
+type AssetTransfer struct {
+	TransferID       int       `db:"transfer_id" json:"transfer_id"`
+	AssetID          string    `db:"asset_id" json:"asset_id"`
+	FromLocationID   int       `db:"from_location_id" json:"from_location_id"`
+	FromLocationName string    `db:"from_location_name" json:"from_location_name"`
+	ToLocationID     int       `db:"to_location_id" json:"to_location_id"`
+	ToLocationName   string    `db:"to_location_name" json:"to_location_name"`
+	TransferDate     time.Time `db:"transfer_date" json:"transfer_date"`
+	Notes            *string   `db:"notes" json:"notes"`                   // Nullable
+	TransferredBy    *string   `db:"transferred_by" json:"transferred_by"` // Nullable, username
+}
+
+// MaintenanceLog records maintenance performed on an asset
+type MaintenanceLog struct {
+	LogID             int       `db:"log_id" json:"log_id"`
+	AssetID           string    `db:"asset_id" json:"asset_id"`
+	AssetTag          string    `db:"asset_tag" json:"asset_tag"`
+	MaintenanceTypeID int       `db:"maintenance_type_id" json:"maintenance_type_id"`
+	TypeName          string    `db:"type_name" json:"type_name"`
+	MaintenanceDate   time.Time `db:"maintenance_date" json:"maintenance_date"`
+	Cost              *float64  `db:"cost" json:"cost"` // Nullable
+	Description       string    `db:"description" json:"description"`
+	PerformedBy       *string   `db:"performed_by" json:"performed_by"` // Nullable
+	RecordedBy        *string   `db:"recorded_by" json:"recorded_by"`   // Nullable, username
+}
+
+// SoftwareLicense is a purchased license with a number of seats
+type SoftwareLicense struct {
+	LicenseID      int        `db:"license_id" json:"license_id"`
+	SoftwareName   string     `db:"software_name" json:"software_name"`
+	LicenseKey     string     `db:"license_key" json:"license_key,omitempty"` // Left empty for roles that may not view secrets
+	LicenseType    string     `db:"license_type" json:"license_type"`
+	SeatsPurchased int        `db:"seats_purchased" json:"seats_purchased"`
+	SeatsUsed      int        `db:"seats_used" json:"seats_used"` // Active assignments, computed
+	PurchaseDate   time.Time  `db:"purchase_date" json:"purchase_date"`
+	ExpirationDate *time.Time `db:"expiration_date" json:"expiration_date"` // Nullable
+	Notes          *string    `db:"notes" json:"notes"`                     // Nullable
+}
+
+// LicenseAssignment records a license installed on an asset
+// The assignment is active while RemovalDate is nil
+type LicenseAssignment struct {
+	AssignmentID   int        `db:"assignment_id" json:"assignment_id"`
+	LicenseID      int        `db:"license_id" json:"license_id"`
+	SoftwareName   string     `db:"software_name" json:"software_name"`
+	LicenseType    string     `db:"license_type" json:"license_type"`
+	AssetID        string     `db:"asset_id" json:"asset_id"`
+	AssignmentDate time.Time  `db:"assignment_date" json:"assignment_date"`
+	RemovalDate    *time.Time `db:"removal_date" json:"removal_date"` // Nullable
+	Notes          *string    `db:"notes" json:"notes"`               // Nullable
+}
+
+// ConsumableType is a kind of consumable (toner, drum, ...)
+type ConsumableType struct {
+	ConsumableTypeID int        `db:"consumable_type_id" json:"consumable_type_id"`
+	Name             string     `db:"name" json:"name"`
+	PartNumber       *string    `db:"part_number" json:"part_number"`               // Nullable
+	Manufacturer     *string    `db:"manufacturer" json:"manufacturer"`             // Nullable
+	LastPurchaseDate *time.Time `db:"last_purchase_date" json:"last_purchase_date"` // Nullable
+	StockQuantity    int        `db:"stock_quantity" json:"stock_quantity"`
+	ReorderLevel     int        `db:"reorder_level" json:"reorder_level"`
+}
+
+// ConsumableUsage records a consumable installed in an asset
+type ConsumableUsage struct {
+	UsageID          int       `db:"usage_id" json:"usage_id"`
+	ConsumableTypeID int       `db:"consumable_type_id" json:"consumable_type_id"`
+	ConsumableName   string    `db:"name" json:"name"`
+	PartNumber       *string   `db:"part_number" json:"part_number"` // Nullable
+	AssetID          string    `db:"asset_id" json:"asset_id"`
+	InstallationDate time.Time `db:"installation_date" json:"installation_date"`
+	Notes            *string   `db:"notes" json:"notes"` // Nullable
+}
+
+// AssetComment is a free-form note left on an asset
+type AssetComment struct {
+	CommentID int       `db:"comment_id" json:"comment_id"`
+	AssetID   string    `db:"asset_id" json:"asset_id"`
+	Body      string    `db:"body" json:"body"`
+	Author    *string   `db:"author" json:"author"` // Nullable
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// AssetAttachment references a file related to an asset
+type AssetAttachment struct {
+	AttachmentID int       `db:"attachment_id" json:"attachment_id"`
+	AssetID      string    `db:"asset_id" json:"asset_id"`
+	FileName     string    `db:"file_name" json:"file_name"`
+	FilePath     string    `db:"file_path" json:"file_path"`
+	AddedAt      time.Time `db:"added_at" json:"added_at"`
+	Notes        *string   `db:"notes" json:"notes"`       // Nullable
+	AddedBy      *string   `db:"added_by" json:"added_by"` // Nullable, username
+}
+
+// AssetSummary is an asset joined with the names of its catalog entries and
+// current holder, as listed in the assets table
+type AssetSummary struct {
+	Asset
+	CategoryID   int     `db:"category_id" json:"category_id"`
+	TypeName     string  `db:"type_name" json:"type_name"`
+	CategoryName string  `db:"category_name" json:"category_name"`
+	StatusName   string  `db:"status_name" json:"status_name"`
+	LocationName string  `db:"location_name" json:"location_name"`
+	HolderName   *string `db:"holder_name" json:"holder_name"` // Nullable, set while assigned
+}
+
+// Relations between a child asset and its parent
+const (
+	RelationAttachedTo  = "attached-to"  // e.g. a monitor attached to a desktop
+	RelationInstalledIn = "installed-in" // e.g. a drive installed in a NAS
+	RelationPoweredBy   = "powered-by"   // e.g. a switch powered by a UPS
+)
+
+// AssetRelations lists the relation names in display order
+var AssetRelations = []string{RelationAttachedTo, RelationInstalledIn, RelationPoweredBy}
+
+// AssetRelation links a child asset to its parent
+type AssetRelation struct {
+	ChildID   string    `db:"child_id" json:"child_id"`
+	ChildTag  string    `db:"child_tag" json:"child_tag"`
+	ParentID  string    `db:"parent_id" json:"parent_id"`
+	ParentTag string    `db:"parent_tag" json:"parent_tag"`
+	Relation  string    `db:"relation" json:"relation"`
+	CreatedBy *string   `db:"created_by" json:"created_by"` // Nullable, username
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// AssetNode is an asset in a tree of relations, with the relation to its
+// parent and its own children
+type AssetNode struct {
+	Asset    *AssetSummary `json:"asset"`
+	Relation string        `json:"relation,omitempty"` // Empty for the root
+	Children []*AssetNode  `json:"children"`
+}
+
+// KitTemplate lists the asset types a kit is made of
+type KitTemplate struct {
+	TemplateID  int               `db:"template_id" json:"template_id"`
+	Name        string            `db:"name" json:"name"`
+	Description *string           `db:"description" json:"description"` // Nullable
+	Items       []KitTemplateItem `json:"items"`
+}
+
+// KitTemplateItem is the number of assets of one type in a kit template
+type KitTemplateItem struct {
+	TypeID   int    `db:"type_id" json:"type_id"`
+	TypeName string `db:"type_name" json:"type_name"`
+	Quantity int    `db:"quantity" json:"quantity"`
+}
+
+// Kit groups assets that are assigned, returned and moved as a unit
+type Kit struct {
+	KitID        int       `db:"kit_id" json:"kit_id"`
+	KitTag       string    `db:"kit_tag" json:"kit_tag"`
+	TemplateID   *int      `db:"template_id" json:"template_id"`     // Nullable
+	TemplateName *string   `db:"template_name" json:"template_name"` // Nullable
+	Notes        *string   `db:"notes" json:"notes"`                 // Nullable
+	CreatedBy    *string   `db:"created_by" json:"created_by"`       // Nullable, username
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	AssetIDs     []string  `json:"asset_ids"` // Members, ordered by asset tag
+}
+
+// Kit states, derived from the holders of the members
+const (
+	KitAvailable = "available" // No member is assigned
+	KitAssigned  = "assigned"  // Every member is held by the same employee
+	KitSplit     = "split"     // Members are held by different employees, or only some are held
+)
+
+// KitSummary is a kit with its members and the state they share
+type KitSummary struct {
+	Kit
+	Assets     []*AssetSummary `json:"assets"`
+	State      string          `json:"state"`
+	HolderName *string         `json:"holder_name"` // Nullable, set while the kit is assigned
+	Missing    []string        `json:"missing"`     // Template items short of members, e.g. "1 Mouse"
+}
+
+// StockItem is an accessory kept in bulk and tracked by quantity per
+// location rather than as serialized assets, e.g. mice or cables
+type StockItem struct {
+	ItemID       int       `db:"item_id" json:"item_id"`
+	Name         string    `db:"name" json:"name"`
+	TypeID       *int      `db:"type_id" json:"type_id"`     // Nullable
+	TypeName     *string   `db:"type_name" json:"type_name"` // Nullable
+	ReorderLevel int       `db:"reorder_level" json:"reorder_level"`
+	Notes        *string   `db:"notes" json:"notes"`           // Nullable
+	CreatedBy    *string   `db:"created_by" json:"created_by"` // Nullable, username
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	OnHand       int       `db:"on_hand" json:"on_hand"` // Units in stock across locations
+	Issued       int       `db:"issued" json:"issued"`   // Units held by employees
+}
+
+// Kinds of stock movement
+const (
+	StockAdjust = "adjust" // Units counted in or written off at a location
+	StockIssue  = "issue"  // Units handed to an employee
+	StockReturn = "return" // Units handed back by an employee
+)
+
+// StockMovement is a change to the stock of an item at a location
+// Quantity is the change to the stock there, so issues are negative
+type StockMovement struct {
+	MovementID   int       `db:"movement_id" json:"movement_id"`
+	ItemID       int       `db:"item_id" json:"item_id"`
+	ItemName     string    `db:"item_name" json:"item_name"`
+	LocationID   int       `db:"location_id" json:"location_id"`
+	LocationName string    `db:"location_name" json:"location_name"`
+	Kind         string    `db:"kind" json:"kind"`
+	Quantity     int       `db:"quantity" json:"quantity"`
+	EmployeeID   *int      `db:"employee_id" json:"employee_id"`     // Nullable, set for issues and returns
+	EmployeeName *string   `db:"employee_name" json:"employee_name"` // Nullable
+	MovedAt      time.Time `db:"moved_at" json:"moved_at"`
+	Notes        *string   `db:"notes" json:"notes"`       // Nullable
+	MovedBy      *string   `db:"moved_by" json:"moved_by"` // Nullable, username
+}
+
+// StockLevel is the number of units of an item in stock at a location
+type StockLevel struct {
+	ItemID       int    `db:"item_id" json:"item_id"`
+	ItemName     string `db:"item_name" json:"item_name"`
+	LocationID   int    `db:"location_id" json:"location_id"`
+	LocationName string `db:"location_name" json:"location_name"`
+	Quantity     int    `db:"quantity" json:"quantity"`
+}
+
+// StockHolding is the number of units of an item an employee holds
+type StockHolding struct {
+	ItemID       int    `db:"item_id" json:"item_id"`
+	ItemName     string `db:"item_name" json:"item_name"`
+	EmployeeID   int    `db:"employee_id" json:"employee_id"`
+	EmployeeName string `db:"employee_name" json:"employee_name"`
+	Quantity     int    `db:"quantity" json:"quantity"`
+}
+
+// StockItemDetail is a stock item with where its units are
+type StockItemDetail struct {
+	StockItem
+	Levels   []*StockLevel   `json:"levels"`
+	Holdings []*StockHolding `json:"holdings"`
+}
+
+// CustomField is an admin-defined asset field, belonging either to one
+// asset type or to every type in a category
+type CustomField struct {
+	FieldID      int       `db:"field_id" json:"field_id"`
+	Name         string    `db:"name" json:"name"`
+	Kind         string    `db:"kind" json:"kind"`
+	TypeID       *int      `db:"type_id" json:"type_id"`             // Nullable, set for the fields of one type
+	TypeName     *string   `db:"type_name" json:"type_name"`         // Nullable
+	CategoryID   *int      `db:"category_id" json:"category_id"`     // Nullable, set for the fields of a category
+	CategoryName string    `db:"category_name" json:"category_name"` // Category of the field or of its type
+	Required     bool      `db:"required" json:"required"`
+	Options      []string  `db:"options" json:"options,omitempty"` // Values an enum accepts
+	Pattern      *string   `db:"pattern" json:"pattern"`           // Nullable, regular expression for text
+	Min          *float64  `db:"min_value" json:"min"`             // Nullable, lowest number accepted
+	Max          *float64  `db:"max_value" json:"max"`             // Nullable, highest number accepted
+	CreatedBy    *string   `db:"created_by" json:"created_by"`     // Nullable, username
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// Kinds of custom field
+const (
+	FieldText   = "text"
+	FieldNumber = "number"
+	FieldDate   = "date" // Kept as YYYY-MM-DD
+	FieldEnum   = "enum" // One of the field options
+)
+
+// FieldKinds lists the kinds a custom field can have
+var FieldKinds = []string{FieldText, FieldNumber, FieldDate, FieldEnum}
+
+// AssetFieldValue is the value of a custom field on an asset
+type AssetFieldValue struct {
+	AssetID   string  `db:"asset_id" json:"asset_id"`
+	FieldID   int     `db:"field_id" json:"field_id"`
+	FieldName string  `db:"field_name" json:"field_name"`
+	Kind      string  `db:"kind" json:"kind"`
+	Value     string  `db:"value" json:"value"`
+	UpdatedBy *string `db:"updated_by" json:"updated_by"` // Nullable, username
+}
+
+// SavedView is a named set of visible columns, sort order and filter for the
+// assets table
+type SavedView struct {
+	ViewID     int      `db:"view_id" json:"view_id"`
+	Name       string   `db:"name" json:"name"`
+	Columns    []string `db:"columns" json:"columns"`
+	SortColumn string   `db:"sort_column" json:"sort_column"`
+	SortDesc   bool     `db:"sort_desc" json:"sort_desc"`
+	Filter     string   `db:"filter" json:"filter"`
+}
+
+// CountBy is the number of records grouped under a catalog entry
+type CountBy struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// User roles, from least to most privileged
+const (
+	RoleViewer     = "viewer"
+	RoleTechnician = "technician"
+	RoleAdmin      = "admin"
+)
+
+// Roles lists the user roles from least to most privileged
+var Roles = []string{RoleViewer, RoleTechnician, RoleAdmin}
+
+// User is a local account that can sign in to the app, CLI and API
+type User struct {
+	UserID    int       `db:"user_id" json:"user_id"`
+	Username  string    `db:"username" json:"username"`
+	Role      string    `db:"role" json:"role"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// APIToken is a bearer token issued to a user for the API
+// Only a hash of the token is stored
+type APIToken struct {
+	TokenID    int        `db:"token_id" json:"token_id"`
+	UserID     int        `db:"user_id" json:"user_id"`
+	Username   string     `db:"username" json:"username"`
+	Name       string     `db:"name" json:"name"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at"` // Nullable
+}
+
+// Audit operations recorded in the audit log
+const (
+	AuditInsert = "insert"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry records one inserted, updated or deleted row with its values
+// before and after the change
+type AuditEntry struct {
+	AuditID   int             `db:"audit_id" json:"audit_id"`
+	Entity    string          `db:"entity" json:"entity"` // Table name
+	EntityID  string          `db:"entity_id" json:"entity_id"`
+	AssetID   *string         `db:"asset_id" json:"asset_id"` // Nullable, asset the row belongs to
+	AssetTag  *string         `db:"asset_tag" json:"asset_tag"`
+	Operation string          `db:"operation" json:"operation"`
+	Actor     *string         `db:"actor" json:"actor"` // Nullable, username
+	ChangedAt time.Time       `db:"changed_at" json:"changed_at"`
+	Before    json.RawMessage `db:"before_json" json:"before"` // Nullable, absent for inserts
+	After     json.RawMessage `db:"after_json" json:"after"`   // Nullable, absent for deletes
+}
+
+// FieldChange is the value of a single field before and after a change
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// String formats the change as "field: before -> after", with empty values
+// shown as a dash
+func (c FieldChange) String() string {
+	value := func(v any) string {
+		if v == nil || v == "" {
+			return "-"
+		}
+		return fmt.Sprint(v)
+	}
+	return fmt.Sprintf("%s: %s -> %s", c.Field, value(c.Before), value(c.After))
+}
+
+// Omit removes field from the values recorded before and after the change
+func (e *AuditEntry) Omit(field string) {
+	omit := func(values json.RawMessage) json.RawMessage {
+		var m map[string]json.RawMessage
+		if json.Unmarshal(values, &m) != nil || m == nil {
+			return values
+		}
+		delete(m, field)
+		out, err := json.Marshal(m)
+		if err != nil {
+			return values
+		}
+		return out
+	}
+	e.Before = omit(e.Before)
+	e.After = omit(e.After)
+}
+
+// Changes lists the fields whose value differs between Before and After,
+// ordered by field name
+// Inserts list every field set and deletes every field that was set
+func (e *AuditEntry) Changes() []FieldChange {
+	var before, after map[string]any
+	json.Unmarshal(e.Before, &before)
+	json.Unmarshal(e.After, &after)
+
+	fields := map[string]bool{}
+	for f := range before {
+		fields[f] = true
+	}
+	for f := range after {
+		fields[f] = true
+	}
+
+	var out []FieldChange
+	for f := range fields {
+		b, a := before[f], after[f]
+		bj, _ := json.Marshal(b)
+		aj, _ := json.Marshal(a)
+		if string(bj) == string(aj) {
+			continue
+		}
+		out = append(out, FieldChange{Field: f, Before: b, After: a})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Field < out[j].Field })
+	return out
 }
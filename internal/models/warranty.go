@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// WarrantyState classifies an asset warranty relative to a given day
+type WarrantyState int
+
+const (
+	WarrantyNone         WarrantyState = iota // No warranty end date recorded
+	WarrantyActive                            // Ends after the expiring soon window
+	WarrantyExpiringSoon                      // Ends within the expiring soon window
+	WarrantyExpired                           // Ended before the given day
+)
+
+func (s WarrantyState) String() string {
+	switch s {
+	case WarrantyActive:
+		return "Active"
+	case WarrantyExpiringSoon:
+		return "Expiring soon"
+	case WarrantyExpired:
+		return "Expired"
+	}
+	return "None"
+}
+
+// WarrantyStateOn returns the warranty state of the asset on day, where a
+// warranty ending within soonDays days of day is expiring soon
+func (a *Asset) WarrantyStateOn(day time.Time, soonDays int) WarrantyState {
+	if a.WarrantyEndDate == nil || a.WarrantyEndDate.IsZero() {
+		return WarrantyNone
+	}
+
+	// Stored dates carry no time zone, compare calendar days only
+	y, m, d := day.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	end := *a.WarrantyEndDate
+	switch {
+	case end.Before(today):
+		return WarrantyExpired
+	case !end.After(today.AddDate(0, 0, soonDays)):
+		return WarrantyExpiringSoon
+	}
+	return WarrantyActive
+}
+
+// WarrantyVendorSummary counts the warranties of one vendor by state
+type WarrantyVendorSummary struct {
+	Vendor       string `json:"vendor"`
+	Active       int    `json:"active"`
+	ExpiringSoon int    `json:"expiring_soon"`
+	Expired      int    `json:"expired"`
+	None         int    `json:"none"`
+}
+
+// Total returns the number of assets counted for the vendor
+func (s *WarrantyVendorSummary) Total() int {
+	return s.Active + s.ExpiringSoon + s.Expired + s.None
+}
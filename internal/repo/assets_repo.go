@@ -2,19 +2,25 @@ package repo
 
 import (
 	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/MawCeron/it-room/internal/models"
+	"github.com/google/uuid"
 )
 
-type AssetRepo struct{ db *sql.DB }
+type AssetRepo struct{ db DBTX }
 
-func NewAssetRepo(db *sql.DB) *AssetRepo {
+func NewAssetRepo(db DBTX) *AssetRepo {
 	return &AssetRepo{db: db}
 }
 
 func (r *AssetRepo) List() ([]*models.Asset, error) {
-	rows, err := r.db.Query(`SELECT asset_id, asset_tag, type_id, status_id, serial_number, make, model, purchase_date, warranty_end_date, location_id, notes
+	rows, err := r.db.Query(`SELECT asset_id, asset_tag, type_id, status_id, serial_number, make, model, purchase_date, warranty_end_date, location_id, notes,
+	created_by, updated_by, purchase_cost, currency, supplier, po_number, invoice_number
 FROM assets`)
 	if err != nil {
 		return nil, err
@@ -27,27 +33,345 @@ FROM assets`)
 
 		if err := rows.Scan(&a.AssetID, &a.AssetTag, &a.TypeID, &a.StatusID,
 			&a.SerialNumber, &a.Maker, &a.Model, &purchaseDate, &warrantyEndDate,
-			&a.LocationID, &a.Notes); err != nil {
+			&a.LocationID, &a.Notes, &a.CreatedBy, &a.UpdatedBy,
+			&a.PurchaseCost, &a.Currency, &a.Supplier, &a.PONumber, &a.InvoiceNumber); err != nil {
 			return nil, err
 		}
 
-		// Parsear las fechas
-		if purchaseDate.Valid {
-			if t, err := time.Parse("2006-01-02", purchaseDate.String); err == nil {
-				a.PurchaseDate = t
-			}
+		parseAssetDates(&a, purchaseDate, warrantyEndDate)
+
+		out = append(out, &a)
+	}
+
+	return out, nil
+}
+
+// AssetQuery describes the ordering and filtering of an asset listing
+// Zero values leave a criterion out
+type AssetQuery struct {
+	SortColumn string // One of the AssetSortColumns keys
+	SortDesc   bool
+	Filter     string // Free text matched against tag, serial, make, model, type, location, holder, supplier, PO and invoice numbers and custom field values
+
+	StatusID        int
+	CategoryID      int
+	Make            string // Exact vendor name
+	ExcludeRetired  bool
+	WarrantyEndFrom *time.Time // Warranty ending on or after this date
+	WarrantyEndTo   *time.Time // Warranty ending on or before this date
+	OverdueOn       *time.Time // Open assignment due before this date
+	LocationID      int
+	HolderID        int               // Employee holding the asset
+	Fields          map[string]string // Custom field values by field name, both matched ignoring case
+
+	// AsOf shows the assets that existed at the end of this day with the
+	// status, location and holder they had then
+	AsOf *time.Time
+}
+
+// AssetCursor marks the last row of a page for keyset pagination
+type AssetCursor struct {
+	SortKey string
+	AssetID string
+}
+
+// holderNameExpr selects the name of the employee holding an asset through
+// its open assignment
+const holderNameExpr = `(SELECT e.full_name FROM asset_assignments aa
+	JOIN employees e ON e.employee_id = aa.employee_id
+	WHERE aa.asset_id = a.asset_id AND aa.return_date IS NULL
+	ORDER BY aa.assignment_date DESC LIMIT 1)`
+
+// holderAsOfExpr selects the name of the employee holding an asset at the
+// end of the :as_of day
+const holderAsOfExpr = `(SELECT e.full_name FROM asset_assignments aa
+	JOIN employees e ON e.employee_id = aa.employee_id
+	WHERE aa.asset_id = a.asset_id AND date(aa.assignment_date) <= :as_of
+	AND (aa.return_date IS NULL OR date(aa.return_date) > :as_of)
+	ORDER BY aa.assignment_date DESC LIMIT 1)`
+
+// assetsAsOf reconstructs the assets table at the end of the :as_of day
+// Assets exist from their purchase date and the location comes from the last
+// transfer on or before the day, or else the one the first later transfer
+// left. The status comes from the last audited status change on or before
+// the day; before the audit log starts it is the value the first later
+// change replaced, corrected by the assignments open on that day
+var assetsAsOf = `SELECT a.asset_id, a.asset_tag, a.type_id,
+	COALESCE(
+		(SELECT json_extract(al.after_json, '$.status_id') FROM audit_log al
+		WHERE al.entity = 'assets' AND al.entity_id = a.asset_id AND al.operation = 'update'
+		AND json_extract(al.before_json, '$.status_id') IS NOT json_extract(al.after_json, '$.status_id')
+		AND date(al.changed_at) <= :as_of
+		ORDER BY al.changed_at DESC, al.audit_id DESC LIMIT 1),
+		CASE
+			WHEN ` + holderAsOfExpr + ` IS NOT NULL THEN ` + fmt.Sprint(models.StatusAssigned) + `
+			WHEN s.status_id = ` + fmt.Sprint(models.StatusAssigned) + ` THEN ` + fmt.Sprint(models.StatusAvailable) + `
+			ELSE s.status_id
+		END) AS status_id,
+	a.serial_number, a.make, a.model, a.purchase_date, a.warranty_end_date,
+	COALESCE(
+		(SELECT t.to_location_id FROM asset_transfers t
+		WHERE t.asset_id = a.asset_id AND date(t.transfer_date) <= :as_of
+		ORDER BY t.transfer_date DESC, t.transfer_id DESC LIMIT 1),
+		(SELECT t.from_location_id FROM asset_transfers t
+		WHERE t.asset_id = a.asset_id AND date(t.transfer_date) > :as_of
+		ORDER BY t.transfer_date, t.transfer_id LIMIT 1),
+		a.location_id) AS location_id,
+	a.notes, a.created_by, a.updated_by, a.purchase_cost, a.currency, a.supplier, a.po_number, a.invoice_number
+FROM assets a
+JOIN (SELECT a.asset_id, COALESCE(
+		(SELECT json_extract(al.before_json, '$.status_id') FROM audit_log al
+		WHERE al.entity = 'assets' AND al.entity_id = a.asset_id AND al.operation = 'update'
+		AND json_extract(al.before_json, '$.status_id') IS NOT json_extract(al.after_json, '$.status_id')
+		AND date(al.changed_at) > :as_of
+		ORDER BY al.changed_at, al.audit_id LIMIT 1),
+		a.status_id) AS status_id
+	FROM assets a) s ON s.asset_id = a.asset_id
+WHERE a.purchase_date <= :as_of`
+
+// AssetSortColumns maps the column keys accepted by AssetQuery.SortColumn to
+// their SQL expressions
+var AssetSortColumns = map[string]string{
+	"asset_tag":         "a.asset_tag",
+	"category":          "c.description",
+	"type":              "t.type_name",
+	"make":              "a.make",
+	"model":             "a.make || ' ' || a.model", // The column shows both
+	"serial_number":     "a.serial_number",
+	"status":            "s.status_name",
+	"purchase_date":     "a.purchase_date",
+	"warranty_end_date": "a.warranty_end_date",
+	"warranty":          "a.warranty_end_date", // States follow the end date
+	"location":          "l.name",
+	"holder":            holderNameExpr,
+	"supplier":          "a.supplier",
+}
+
+const assetSummaryColumns = `SELECT a.asset_id, a.asset_tag, a.type_id, a.status_id, a.serial_number, a.make, a.model,
+	a.purchase_date, a.warranty_end_date, a.location_id, a.notes, a.created_by, a.updated_by,
+	a.purchase_cost, a.currency, a.supplier, a.po_number, a.invoice_number,
+	t.category_id, t.type_name, c.description, s.status_name, l.name, `
+
+const assetSummarySelect = assetSummaryColumns + holderNameExpr + ` AS holder_name`
+
+const assetSummaryFrom = `
+FROM assets a
+JOIN asset_types t ON t.type_id = a.type_id
+JOIN asset_categories c ON c.category_id = t.category_id
+JOIN asset_statuses s ON s.status_id = a.status_id
+JOIN locations l ON l.location_id = a.location_id`
+
+// holderExpr returns the expression selecting the holder's name, as of
+// AsOf when set
+func (q AssetQuery) holderExpr() string {
+	if q.AsOf != nil {
+		return holderAsOfExpr
+	}
+	return holderNameExpr
+}
+
+// from returns the FROM clause of the query, reading the assets as of AsOf
+// when set
+func (q AssetQuery) from() string {
+	if q.AsOf != nil {
+		return strings.Replace(assetSummaryFrom, "FROM assets a", "FROM (\n"+assetsAsOf+"\n) a", 1)
+	}
+	return assetSummaryFrom
+}
+
+// sortKey returns the expression the query orders by, with NULLs folded to
+// an empty string so keyset comparisons stay well defined
+func (q AssetQuery) sortKey() string {
+	expr, ok := AssetSortColumns[q.SortColumn]
+	if !ok {
+		expr = AssetSortColumns["asset_tag"]
+	}
+	if q.SortColumn == "holder" {
+		expr = q.holderExpr()
+	}
+	return "COALESCE(" + expr + ", '')"
+}
+
+// containsPattern returns the LIKE pattern, used with ESCAPE '\', matching
+// text containing s literally, so % and _ in s are not wildcards
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// conditions returns the WHERE conditions and arguments for the query filters
+func (q AssetQuery) conditions() ([]string, []any) {
+	var conds []string
+	var args []any
+	if q.AsOf != nil {
+		args = append(args, sql.Named("as_of", q.AsOf.Format(DateLayout)))
+	}
+	if q.Filter != "" {
+		conds = append(conds, `(a.asset_tag LIKE :filter ESCAPE '\' OR a.serial_number LIKE :filter ESCAPE '\'
+	OR a.make LIKE :filter ESCAPE '\' OR a.model LIKE :filter ESCAPE '\' OR t.type_name LIKE :filter ESCAPE '\'
+	OR l.name LIKE :filter ESCAPE '\' OR `+q.holderExpr()+` LIKE :filter ESCAPE '\'
+	OR a.supplier LIKE :filter ESCAPE '\' OR a.po_number LIKE :filter ESCAPE '\' OR a.invoice_number LIKE :filter ESCAPE '\'
+	OR EXISTS (SELECT 1 FROM asset_field_values v WHERE v.asset_id = a.asset_id AND v.value LIKE :filter ESCAPE '\'))`)
+		args = append(args, sql.Named("filter", containsPattern(q.Filter)))
+	}
+	if q.StatusID != 0 {
+		conds = append(conds, "a.status_id = :status_id")
+		args = append(args, sql.Named("status_id", q.StatusID))
+	}
+	if q.CategoryID != 0 {
+		conds = append(conds, "t.category_id = :category_id")
+		args = append(args, sql.Named("category_id", q.CategoryID))
+	}
+	if q.Make != "" {
+		conds = append(conds, "a.make = :make")
+		args = append(args, sql.Named("make", q.Make))
+	}
+	if q.ExcludeRetired {
+		conds = append(conds, "a.status_id <> :retired")
+		args = append(args, sql.Named("retired", models.StatusRetired))
+	}
+	if q.WarrantyEndFrom != nil {
+		conds = append(conds, "a.warranty_end_date >= :warranty_from")
+		args = append(args, sql.Named("warranty_from", q.WarrantyEndFrom.Format(DateLayout)))
+	}
+	if q.WarrantyEndTo != nil {
+		conds = append(conds, "a.warranty_end_date <= :warranty_to")
+		args = append(args, sql.Named("warranty_to", q.WarrantyEndTo.Format(DateLayout)))
+	}
+	if q.LocationID != 0 {
+		conds = append(conds, "a.location_id = :location_id")
+		args = append(args, sql.Named("location_id", q.LocationID))
+	}
+	if q.HolderID != 0 {
+		held := "aa.return_date IS NULL"
+		if q.AsOf != nil {
+			held = "date(aa.assignment_date) <= :as_of AND (aa.return_date IS NULL OR date(aa.return_date) > :as_of)"
 		}
+		conds = append(conds, `EXISTS (SELECT 1 FROM asset_assignments aa
+	WHERE aa.asset_id = a.asset_id AND aa.employee_id = :holder_id AND `+held+`)`)
+		args = append(args, sql.Named("holder_id", q.HolderID))
+	}
+	for i, name := range slices.Sorted(maps.Keys(q.Fields)) {
+		conds = append(conds, fmt.Sprintf(`EXISTS (SELECT 1 FROM asset_field_values v
+	JOIN custom_fields f ON f.field_id = v.field_id
+	WHERE v.asset_id = a.asset_id AND f.name = :field_%[1]d AND v.value = :field_value_%[1]d COLLATE NOCASE)`, i))
+		args = append(args, sql.Named(fmt.Sprintf("field_%d", i), name),
+			sql.Named(fmt.Sprintf("field_value_%d", i), q.Fields[name]))
+	}
+	if q.OverdueOn != nil {
+		conds = append(conds, `EXISTS (SELECT 1 FROM asset_assignments aa
+	WHERE aa.asset_id = a.asset_id AND aa.return_date IS NULL AND aa.due_date < :overdue_on)`)
+		args = append(args, sql.Named("overdue_on", q.OverdueOn.Format(DateLayout)))
+	}
+	return conds, args
+}
 
-		if warrantyEndDate.Valid {
-			if t, err := time.Parse("2006-01-02", warrantyEndDate.String); err == nil {
-				a.WarrantyEndDate = &t
-			}
+// ListSummaries retrieves assets joined with their catalog names and current
+// holder, ordered and filtered as described by q
+func (r *AssetRepo) ListSummaries(q AssetQuery) ([]*models.AssetSummary, error) {
+	out, _, err := r.PageSummaries(q, nil, 0)
+	return out, err
+}
+
+// PageSummaries retrieves up to limit asset summaries following the after
+// cursor (nil for the first page), plus the cursor of the last returned row
+// A limit of zero returns every remaining row
+func (r *AssetRepo) PageSummaries(q AssetQuery, after *AssetCursor, limit int) ([]*models.AssetSummary, *AssetCursor, error) {
+	sortKey := q.sortKey()
+	direction, cmp := "ASC", ">"
+	if q.SortDesc {
+		direction, cmp = "DESC", "<"
+	}
+
+	conds, args := q.conditions()
+	if after != nil {
+		conds = append(conds, fmt.Sprintf("(%[1]s %[2]s :after_key OR (%[1]s = :after_key AND a.asset_id %[2]s :after_id))", sortKey, cmp))
+		args = append(args, sql.Named("after_key", after.SortKey), sql.Named("after_id", after.AssetID))
+	}
+
+	query := assetSummaryColumns + q.holderExpr() + ` AS holder_name,
+	` + sortKey + ` AS sort_key` + q.from()
+	if len(conds) > 0 {
+		query += "\nWHERE " + strings.Join(conds, " AND ")
+	}
+	query += fmt.Sprintf("\nORDER BY sort_key %[1]s, a.asset_id %[1]s", direction)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var out []*models.AssetSummary
+	var cursor *AssetCursor
+	for rows.Next() {
+		var sortValue string
+		a, err := scanAssetSummary(rows, &sortValue)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		out = append(out, &a)
+		out = append(out, a)
+		cursor = &AssetCursor{SortKey: sortValue, AssetID: a.AssetID}
 	}
 
-	return out, nil
+	return out, cursor, rows.Err()
+}
+
+// GetSummary retrieves a single asset summary by asset ID
+// Returns sql.ErrNoRows if the asset does not exist
+func (r *AssetRepo) GetSummary(assetID string) (*models.AssetSummary, error) {
+	row := r.db.QueryRow(assetSummarySelect+assetSummaryFrom+`
+WHERE a.asset_id = ?`, assetID)
+	return scanAssetSummary(row)
+}
+
+// GetSummaryByTag retrieves a single asset summary by asset tag
+// Returns sql.ErrNoRows if the asset does not exist
+func (r *AssetRepo) GetSummaryByTag(assetTag string) (*models.AssetSummary, error) {
+	row := r.db.QueryRow(assetSummarySelect+assetSummaryFrom+`
+WHERE a.asset_tag = ?`, assetTag)
+	return scanAssetSummary(row)
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanAssetSummary scans the columns of assetSummarySelect followed by any
+// extra destinations
+func scanAssetSummary(sc scanner, extra ...any) (*models.AssetSummary, error) {
+	var a models.AssetSummary
+	var purchaseDate, warrantyEndDate sql.NullString
+
+	dest := []any{&a.AssetID, &a.AssetTag, &a.TypeID, &a.StatusID,
+		&a.SerialNumber, &a.Maker, &a.Model, &purchaseDate, &warrantyEndDate,
+		&a.LocationID, &a.Notes, &a.CreatedBy, &a.UpdatedBy,
+		&a.PurchaseCost, &a.Currency, &a.Supplier, &a.PONumber, &a.InvoiceNumber,
+		&a.CategoryID, &a.TypeName, &a.CategoryName, &a.StatusName, &a.LocationName, &a.HolderName}
+	if err := sc.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	parseAssetDates(&a.Asset, purchaseDate, warrantyEndDate)
+	return &a, nil
+}
+
+// CountSummaries returns how many assets match the filters of q
+func (r *AssetRepo) CountSummaries(q AssetQuery) (int, error) {
+	conds, args := q.conditions()
+	query := `SELECT count(*)` + q.from()
+	if len(conds) > 0 {
+		query += "\nWHERE " + strings.Join(conds, " AND ")
+	}
+
+	var count int
+	err := r.db.QueryRow(query, args...).Scan(&count)
+	return count, err
 }
 
 func (r *AssetRepo) GetAssetCategories() ([]*models.AssetCategory, error) {
@@ -91,3 +415,227 @@ FROM asset_types WHERE category_id = ?`, category)
 
 	return out, nil
 }
+
+// Create inserts a new asset, generating its asset ID
+// CreatedBy names the acting user and is also recorded as UpdatedBy
+func (r *AssetRepo) Create(a *models.Asset) error {
+	a.AssetID = uuid.NewString()
+	a.UpdatedBy = a.CreatedBy
+	_, err := r.db.Exec(`INSERT INTO assets (asset_id, asset_tag, type_id, status_id, serial_number, make, model,
+	purchase_date, warranty_end_date, location_id, notes, created_by, updated_by,
+	purchase_cost, currency, supplier, po_number, invoice_number)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+		a.AssetID, a.AssetTag, a.TypeID, a.StatusID, a.SerialNumber, a.Maker, a.Model,
+		a.PurchaseDate.Format(DateLayout), formatNullDate(a.WarrantyEndDate), a.LocationID, a.Notes,
+		a.CreatedBy, a.UpdatedBy,
+		a.PurchaseCost, a.Currency, a.Supplier, a.PONumber, a.InvoiceNumber)
+	return err
+}
+
+// Update stores every field of an existing asset, attributing the change to
+// UpdatedBy
+// A location change is recorded in the asset transfers log in the same
+// transaction
+func (r *AssetRepo) Update(a *models.Asset) error {
+	return inTx(r.db, func(tx DBTX) error {
+		var fromLocation int
+		if err := tx.QueryRow(`SELECT location_id FROM assets WHERE asset_id = ?`, a.AssetID).Scan(&fromLocation); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`UPDATE assets SET asset_tag = ?, type_id = ?, status_id = ?, serial_number = ?, make = ?, model = ?,
+		purchase_date = ?, warranty_end_date = ?, location_id = ?, notes = ?, updated_by = ?,
+		purchase_cost = ?, currency = ?, supplier = ?, po_number = ?, invoice_number = ?
+WHERE asset_id = ?;`,
+			a.AssetTag, a.TypeID, a.StatusID, a.SerialNumber, a.Maker, a.Model,
+			a.PurchaseDate.Format(DateLayout), formatNullDate(a.WarrantyEndDate), a.LocationID, a.Notes, a.UpdatedBy,
+			a.PurchaseCost, a.Currency, a.Supplier, a.PONumber, a.InvoiceNumber,
+			a.AssetID); err != nil {
+			return err
+		}
+
+		if fromLocation != a.LocationID {
+			if _, err := tx.Exec(`INSERT INTO asset_transfers (asset_id, from_location_id, to_location_id, transferred_by)
+VALUES (?, ?, ?, ?);`, a.AssetID, fromLocation, a.LocationID, a.UpdatedBy); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Retire marks an asset as retired on the given date, returning it first if
+// it is assigned; actor is the username of the acting user, if any
+func (r *AssetRepo) Retire(assetID string, date time.Time, actor *string) error {
+	return inTx(r.db, func(tx DBTX) error {
+		if _, err := tx.Exec(`UPDATE asset_assignments SET return_date = ?, returned_by = ?
+WHERE asset_id = ? AND return_date IS NULL;`, date.Format(TimestampLayout), actor, assetID); err != nil {
+			return err
+		}
+
+		res, err := tx.Exec(`UPDATE assets SET status_id = ?, updated_by = ? WHERE asset_id = ?;`,
+			models.StatusRetired, actor, assetID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return sql.ErrNoRows
+		}
+
+		return nil
+	})
+}
+
+// CountByStatus returns how many assets have each status, including
+// statuses without assets
+func (r *AssetRepo) CountByStatus() ([]*models.CountBy, error) {
+	return r.countBy(`SELECT s.status_id, s.status_name, count(a.asset_id)
+FROM asset_statuses s
+LEFT JOIN assets a ON a.status_id = s.status_id
+GROUP BY s.status_id ORDER BY s.status_id;`)
+}
+
+// CountByCategory returns how many assets belong to each category,
+// including categories without assets
+func (r *AssetRepo) CountByCategory() ([]*models.CountBy, error) {
+	return r.countBy(`SELECT c.category_id, c.description, count(a.asset_id)
+FROM asset_categories c
+LEFT JOIN asset_types t ON t.category_id = c.category_id
+LEFT JOIN assets a ON a.type_id = t.type_id
+GROUP BY c.category_id ORDER BY c.category_id;`)
+}
+
+// WarrantyReport counts the warranties of assets in service by vendor
+// Warranties ending within soonDays days of day are counted as expiring soon
+func (r *AssetRepo) WarrantyReport(day time.Time, soonDays int) ([]*models.WarrantyVendorSummary, error) {
+	today := day.Format(DateLayout)
+	soon := day.AddDate(0, 0, soonDays).Format(DateLayout)
+	rows, err := r.db.Query(`SELECT make,
+	count(*) FILTER (WHERE warranty_end_date > :soon),
+	count(*) FILTER (WHERE warranty_end_date >= :today AND warranty_end_date <= :soon),
+	count(*) FILTER (WHERE warranty_end_date <> '' AND warranty_end_date < :today),
+	count(*) FILTER (WHERE warranty_end_date IS NULL OR warranty_end_date = '')
+FROM assets
+WHERE status_id <> :retired
+GROUP BY make ORDER BY make COLLATE NOCASE;`,
+		sql.Named("today", today), sql.Named("soon", soon), sql.Named("retired", models.StatusRetired))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.WarrantyVendorSummary
+	for rows.Next() {
+		var s models.WarrantyVendorSummary
+
+		if err := rows.Scan(&s.Vendor, &s.Active, &s.ExpiringSoon, &s.Expired, &s.None); err != nil {
+			return nil, err
+		}
+
+		out = append(out, &s)
+	}
+
+	return out, rows.Err()
+}
+
+// countBy runs a query returning (id, name, count) rows
+func (r *AssetRepo) countBy(query string) ([]*models.CountBy, error) {
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.CountBy
+	for rows.Next() {
+		var c models.CountBy
+
+		if err := rows.Scan(&c.ID, &c.Name, &c.Count); err != nil {
+			return nil, err
+		}
+
+		out = append(out, &c)
+	}
+
+	return out, rows.Err()
+}
+
+// GetAssetType retrieves a single asset type by ID
+func (r *AssetRepo) GetAssetType(typeID int) (*models.AssetType, error) {
+	var t models.AssetType
+	err := r.db.QueryRow(`SELECT type_id, category_id, type_name
+FROM asset_types WHERE type_id = ?`, typeID).Scan(&t.TypeID, &t.CategoryID, &t.TypeName)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// FindAssetType retrieves an asset type by name, ignoring case
+// Returns sql.ErrNoRows if there is no such type
+func (r *AssetRepo) FindAssetType(name string) (*models.AssetType, error) {
+	var t models.AssetType
+	err := r.db.QueryRow(`SELECT type_id, category_id, type_name
+FROM asset_types WHERE type_name = ? COLLATE NOCASE`, name).Scan(&t.TypeID, &t.CategoryID, &t.TypeName)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// FindAssetCategory retrieves an asset category by code prefix or
+// description, ignoring case
+// Returns sql.ErrNoRows if there is no such category
+func (r *AssetRepo) FindAssetCategory(name string) (*models.AssetCategory, error) {
+	var c models.AssetCategory
+	err := r.db.QueryRow(`SELECT category_id, code_prefix, description
+FROM asset_categories WHERE code_prefix = ?1 COLLATE NOCASE OR description = ?1 COLLATE NOCASE`, name).
+		Scan(&c.CategoryId, &c.CodePrefix, &c.Description)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// FindAssetStatus retrieves an asset status by name, ignoring case
+// Returns sql.ErrNoRows if there is no such status
+func (r *AssetRepo) FindAssetStatus(name string) (*models.AssetStatus, error) {
+	var s models.AssetStatus
+	err := r.db.QueryRow(`SELECT status_id, status_name
+FROM asset_statuses WHERE status_name = ? COLLATE NOCASE`, name).Scan(&s.StatusID, &s.StatusName)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *AssetRepo) GetAssetStatuses() ([]*models.AssetStatus, error) {
+	rows, err := r.db.Query(`SELECT status_id, status_name
+FROM asset_statuses;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.AssetStatus
+	for rows.Next() {
+		var s models.AssetStatus
+
+		if err := rows.Scan(&s.StatusID, &s.StatusName); err != nil {
+			return nil, err
+		}
+
+		out = append(out, &s)
+	}
+
+	return out, nil
+}
+
+// parseAssetDates parses the stored ISO 8601 dates into the asset fields
+// Dates that fail to parse are left at their zero value
+func parseAssetDates(a *models.Asset, purchaseDate, warrantyEndDate sql.NullString) {
+	if purchaseDate.Valid {
+		a.PurchaseDate = parseTime(purchaseDate.String)
+	}
+	a.WarrantyEndDate = parseNullTime(warrantyEndDate)
+}
@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+var (
+	// ErrAssetAssigned is returned when assigning an asset that is already
+	// held by an employee
+	ErrAssetAssigned = errors.New("asset is already assigned")
+	// ErrAssetNotAssigned is returned when returning an asset nobody holds
+	ErrAssetNotAssigned = errors.New("asset is not assigned")
+	// ErrAssetRetired is returned when assigning a retired asset
+	ErrAssetRetired = errors.New("asset is retired")
+	// ErrAssetUnderMaintenance is returned when assigning an asset that is
+	// under maintenance
+	ErrAssetUnderMaintenance = errors.New("asset is under maintenance")
+)
+
+type AssignmentRepo struct{ db DBTX }
+
+func NewAssignmentRepo(db DBTX) *AssignmentRepo {
+	return &AssignmentRepo{db: db}
+}
+
+const assignmentSelect = `SELECT aa.assignment_id, aa.asset_id, a.asset_tag, aa.employee_id, e.full_name,
+	aa.assignment_date, aa.due_date, aa.return_date, aa.notes, aa.assigned_by, aa.returned_by
+FROM asset_assignments aa
+JOIN assets a ON a.asset_id = aa.asset_id
+JOIN employees e ON e.employee_id = aa.employee_id`
+
+// List retrieves every assignment, oldest first
+func (r *AssignmentRepo) List() ([]*models.AssetAssignment, error) {
+	return r.query(assignmentSelect + `
+ORDER BY aa.assignment_date, aa.assignment_id;`)
+}
+
+// ListByAsset retrieves the assignment history of an asset, newest first
+func (r *AssignmentRepo) ListByAsset(assetID string) ([]*models.AssetAssignment, error) {
+	return r.query(assignmentSelect+`
+WHERE aa.asset_id = ?
+ORDER BY aa.assignment_date DESC, aa.assignment_id DESC;`, assetID)
+}
+
+// query runs an assignmentSelect based query
+func (r *AssignmentRepo) query(query string, args ...any) ([]*models.AssetAssignment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.AssetAssignment
+	for rows.Next() {
+		var a models.AssetAssignment
+		var assignmentDate string
+		var dueDate, returnDate sql.NullString
+
+		if err := rows.Scan(&a.AssignmentID, &a.AssetID, &a.AssetTag, &a.EmployeeID, &a.EmployeeName,
+			&assignmentDate, &dueDate, &returnDate, &a.Notes, &a.AssignedBy, &a.ReturnedBy); err != nil {
+			return nil, err
+		}
+		a.AssignmentDate = parseTime(assignmentDate)
+		a.DueDate = parseNullTime(dueDate)
+		a.ReturnDate = parseNullTime(returnDate)
+		out = append(out, &a)
+	}
+
+	return out, rows.Err()
+}
+
+// Assign hands an asset to an employee and marks it as assigned
+// DueDate is the optional date the asset should be returned by and
+// AssignedBy the acting user
+func (r *AssignmentRepo) Assign(a *models.AssetAssignment) error {
+	return inTx(r.db, func(tx DBTX) error {
+		var statusID, open int
+		if err := tx.QueryRow(`SELECT status_id,
+		(SELECT count(*) FROM asset_assignments WHERE asset_id = a.asset_id AND return_date IS NULL)
+FROM assets a WHERE asset_id = ?;`, a.AssetID).Scan(&statusID, &open); err != nil {
+			return err
+		}
+		switch {
+		case statusID == models.StatusRetired:
+			return ErrAssetRetired
+		case statusID == models.StatusUnderMaintenance:
+			return ErrAssetUnderMaintenance
+		case open > 0:
+			return ErrAssetAssigned
+		}
+
+		if err := tx.QueryRow(`INSERT INTO asset_assignments (asset_id, employee_id, assignment_date, due_date, notes, assigned_by)
+VALUES (?, ?, ?, ?, ?, ?) RETURNING assignment_id;`,
+			a.AssetID, a.EmployeeID, a.AssignmentDate.Format(TimestampLayout), formatNullDate(a.DueDate), a.Notes, a.AssignedBy).
+			Scan(&a.AssignmentID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE assets SET status_id = ?, updated_by = ? WHERE asset_id = ?;`,
+			models.StatusAssigned, a.AssignedBy, a.AssetID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Return closes the open assignment of an asset on the given date and marks
+// the asset as available; actor is the username of the acting user, if any
+func (r *AssignmentRepo) Return(assetID string, date time.Time, actor *string) error {
+	return inTx(r.db, func(tx DBTX) error {
+		res, err := tx.Exec(`UPDATE asset_assignments SET return_date = ?, returned_by = ?
+WHERE asset_id = ? AND return_date IS NULL;`, date.Format(TimestampLayout), actor, assetID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrAssetNotAssigned
+		}
+
+		if _, err := tx.Exec(`UPDATE assets SET status_id = ?, updated_by = ? WHERE asset_id = ?;`,
+			models.StatusAvailable, actor, assetID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
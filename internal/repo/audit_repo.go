@@ -0,0 +1,109 @@
+package repo
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type AuditRepo struct{ db DBTX }
+
+func NewAuditRepo(db DBTX) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+// AuditQuery filters the audit log
+// Zero values leave a filter out
+type AuditQuery struct {
+	Entity   string     // Table name
+	EntityID string     // Primary key of the changed row
+	AssetID  string     // Rows belonging to this asset
+	Actor    string     // Username, ignoring case
+	Field    string     // Only changes to this column
+	Since    *time.Time // First day included
+	Until    *time.Time // Last day included
+	Limit    int        // At most this many entries, all when 0
+}
+
+// conditions returns the WHERE conditions and arguments for the query filters
+func (q AuditQuery) conditions() ([]string, []any) {
+	var conds []string
+	var args []any
+	if q.Entity != "" {
+		conds = append(conds, "l.entity = :entity")
+		args = append(args, sql.Named("entity", q.Entity))
+	}
+	if q.EntityID != "" {
+		conds = append(conds, "l.entity_id = :entity_id")
+		args = append(args, sql.Named("entity_id", q.EntityID))
+	}
+	if q.AssetID != "" {
+		conds = append(conds, "l.asset_id = :asset_id")
+		args = append(args, sql.Named("asset_id", q.AssetID))
+	}
+	if q.Actor != "" {
+		conds = append(conds, "l.actor = :actor COLLATE NOCASE")
+		args = append(args, sql.Named("actor", q.Actor))
+	}
+	if q.Field != "" {
+		conds = append(conds, `json_extract(l.before_json, '$.' || :field)
+	IS NOT json_extract(l.after_json, '$.' || :field)`)
+		args = append(args, sql.Named("field", q.Field))
+	}
+	if q.Since != nil {
+		conds = append(conds, "date(l.changed_at) >= :since")
+		args = append(args, sql.Named("since", q.Since.Format(DateLayout)))
+	}
+	if q.Until != nil {
+		conds = append(conds, "date(l.changed_at) <= :until")
+		args = append(args, sql.Named("until", q.Until.Format(DateLayout)))
+	}
+	return conds, args
+}
+
+// List retrieves the audit entries matching q, newest first
+func (r *AuditRepo) List(q AuditQuery) ([]*models.AuditEntry, error) {
+	query := `SELECT l.audit_id, l.entity, l.entity_id, l.asset_id, a.asset_tag, l.operation,
+	l.actor, l.changed_at, l.before_json, l.after_json
+FROM audit_log l
+LEFT JOIN assets a ON a.asset_id = l.asset_id`
+	conds, args := q.conditions()
+	if len(conds) > 0 {
+		query += "\nWHERE " + strings.Join(conds, " AND ")
+	}
+	query += "\nORDER BY l.changed_at DESC, l.audit_id DESC"
+	if q.Limit > 0 {
+		query += "\nLIMIT :limit"
+		args = append(args, sql.Named("limit", q.Limit))
+	}
+
+	rows, err := r.db.Query(query+";", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		var changedAt string
+		var before, after sql.NullString
+
+		if err := rows.Scan(&e.AuditID, &e.Entity, &e.EntityID, &e.AssetID, &e.AssetTag, &e.Operation,
+			&e.Actor, &changedAt, &before, &after); err != nil {
+			return nil, err
+		}
+		e.ChangedAt = parseTime(changedAt)
+		if before.Valid {
+			e.Before = []byte(before.String)
+		}
+		if after.Valid {
+			e.After = []byte(after.String)
+		}
+		out = append(out, &e)
+	}
+
+	return out, rows.Err()
+}
@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"database/sql"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type ConsumableRepo struct{ db DBTX }
+
+func NewConsumableRepo(db DBTX) *ConsumableRepo {
+	return &ConsumableRepo{db: db}
+}
+
+// ListUsageByAsset retrieves the consumables installed in an asset, newest first
+func (r *ConsumableRepo) ListUsageByAsset(assetID string) ([]*models.ConsumableUsage, error) {
+	rows, err := r.db.Query(`SELECT u.usage_id, u.consumable_type_id, ct.name, ct.part_number,
+	u.asset_id, u.installation_date, u.notes
+FROM consumable_usage u
+JOIN consumable_types ct ON ct.consumable_type_id = u.consumable_type_id
+WHERE u.asset_id = ?
+ORDER BY u.installation_date DESC, u.usage_id DESC;`, assetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.ConsumableUsage
+	for rows.Next() {
+		var u models.ConsumableUsage
+		var installationDate string
+
+		if err := rows.Scan(&u.UsageID, &u.ConsumableTypeID, &u.ConsumableName, &u.PartNumber,
+			&u.AssetID, &installationDate, &u.Notes); err != nil {
+			return nil, err
+		}
+		u.InstallationDate = parseTime(installationDate)
+		out = append(out, &u)
+	}
+
+	return out, rows.Err()
+}
+
+// RecordUsage records a consumable installed in an asset, setting the usage
+// ID, and takes the unit out of stock
+func (r *ConsumableRepo) RecordUsage(u *models.ConsumableUsage) error {
+	return inTx(r.db, func(tx DBTX) error {
+		if err := tx.QueryRow(`INSERT INTO consumable_usage (consumable_type_id, asset_id, installation_date, notes)
+VALUES (?, ?, ?, ?) RETURNING usage_id;`,
+			u.ConsumableTypeID, u.AssetID, u.InstallationDate.Format(TimestampLayout), u.Notes).Scan(&u.UsageID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`UPDATE consumable_types SET stock_quantity = max(stock_quantity - 1, 0)
+WHERE consumable_type_id = ?;`, u.ConsumableTypeID)
+		return err
+	})
+}
+
+const consumableTypeSelect = `SELECT consumable_type_id, name, part_number, manufacturer, last_purchase_date,
+	stock_quantity, reorder_level
+FROM consumable_types`
+
+func (r *ConsumableRepo) ListTypes() ([]*models.ConsumableType, error) {
+	return r.queryTypes(consumableTypeSelect + ` ORDER BY name;`)
+}
+
+// CreateType inserts a new consumable type, setting its ID
+func (r *ConsumableRepo) CreateType(c *models.ConsumableType) error {
+	return r.db.QueryRow(`INSERT INTO consumable_types (name, part_number, manufacturer, last_purchase_date,
+	stock_quantity, reorder_level)
+VALUES (?, ?, ?, ?, ?, ?) RETURNING consumable_type_id;`,
+		c.Name, c.PartNumber, c.Manufacturer, formatNullDate(c.LastPurchaseDate),
+		c.StockQuantity, c.ReorderLevel).Scan(&c.ConsumableTypeID)
+}
+
+// ListLowStock retrieves the consumable types at or below their reorder
+// level; types without a reorder level are never low
+func (r *ConsumableRepo) ListLowStock() ([]*models.ConsumableType, error) {
+	return r.queryTypes(consumableTypeSelect + `
+WHERE reorder_level > 0 AND stock_quantity <= reorder_level ORDER BY name;`)
+}
+
+// queryTypes runs a consumableTypeSelect based query
+func (r *ConsumableRepo) queryTypes(query string, args ...any) ([]*models.ConsumableType, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.ConsumableType
+	for rows.Next() {
+		var c models.ConsumableType
+		var lastPurchaseDate sql.NullString
+
+		if err := rows.Scan(&c.ConsumableTypeID, &c.Name, &c.PartNumber, &c.Manufacturer, &lastPurchaseDate,
+			&c.StockQuantity, &c.ReorderLevel); err != nil {
+			return nil, err
+		}
+		c.LastPurchaseDate = parseNullTime(lastPurchaseDate)
+		out = append(out, &c)
+	}
+
+	return out, rows.Err()
+}
@@ -0,0 +1,44 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	// DateLayout is the ISO 8601 date format used for calendar dates
+	DateLayout = "2006-01-02"
+	// TimestampLayout is the format produced by SQLite's datetime('now')
+	TimestampLayout = "2006-01-02 15:04:05"
+)
+
+// parseTime parses a stored date or timestamp
+// Values that fail to parse yield the zero time
+func parseTime(s string) time.Time {
+	for _, layout := range []string{TimestampLayout, DateLayout, time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseNullTime parses a nullable stored date or timestamp
+func parseNullTime(s sql.NullString) *time.Time {
+	if !s.Valid {
+		return nil
+	}
+	t := parseTime(s.String)
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// formatNullDate formats an optional calendar date for storage
+func formatNullDate(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format(DateLayout)
+}
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type DepreciationRepo struct{ db DBTX }
+
+func NewDepreciationRepo(db DBTX) *DepreciationRepo {
+	return &DepreciationRepo{db: db}
+}
+
+const depreciationRuleSelect = `SELECT r.category_id, c.description, r.method, r.useful_life_years, r.salvage_percent,
+	r.factor, r.updated_by, r.updated_at
+FROM depreciation_rules r
+JOIN asset_categories c ON c.category_id = r.category_id`
+
+// List retrieves the depreciation rules, ordered by category
+func (r *DepreciationRepo) List() ([]*models.DepreciationRule, error) {
+	return r.query(depreciationRuleSelect + ` ORDER BY c.description;`)
+}
+
+// Get retrieves the depreciation rule of a category
+// Returns sql.ErrNoRows if the category has none
+func (r *DepreciationRepo) Get(categoryID int) (*models.DepreciationRule, error) {
+	return one(r.query(depreciationRuleSelect+` WHERE r.category_id = ?;`, categoryID))
+}
+
+// query runs a depreciationRuleSelect based query
+func (r *DepreciationRepo) query(query string, args ...any) ([]*models.DepreciationRule, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.DepreciationRule
+	for rows.Next() {
+		var rule models.DepreciationRule
+		var updatedAt string
+		if err := rows.Scan(&rule.CategoryID, &rule.CategoryName, &rule.Method, &rule.UsefulLifeYears,
+			&rule.SalvagePercent, &rule.Factor, &rule.UpdatedBy, &updatedAt); err != nil {
+			return nil, err
+		}
+		rule.UpdatedAt = parseTime(updatedAt)
+		out = append(out, &rule)
+	}
+	return out, rows.Err()
+}
+
+// Set stores the depreciation rule of a category, replacing the one it had,
+// and sets its update time
+// The values are checked by the caller
+func (r *DepreciationRepo) Set(rule *models.DepreciationRule) error {
+	var updatedAt string
+	if err := r.db.QueryRow(`INSERT INTO depreciation_rules (category_id, method, useful_life_years, salvage_percent,
+	factor, updated_by)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (category_id) DO UPDATE SET method = excluded.method, useful_life_years = excluded.useful_life_years,
+	salvage_percent = excluded.salvage_percent, factor = excluded.factor, updated_by = excluded.updated_by,
+	updated_at = datetime('now')
+RETURNING updated_at;`,
+		rule.CategoryID, rule.Method, rule.UsefulLifeYears, rule.SalvagePercent,
+		rule.Factor, rule.UpdatedBy).Scan(&updatedAt); err != nil {
+		return err
+	}
+	rule.UpdatedAt = parseTime(updatedAt)
+	return nil
+}
+
+// Delete removes the depreciation rule of a category, leaving its assets at
+// their cost
+// Returns sql.ErrNoRows if the category has none
+func (r *DepreciationRepo) Delete(categoryID int) error {
+	return execOne(r.db, `DELETE FROM depreciation_rules WHERE category_id = ?;`, categoryID)
+}
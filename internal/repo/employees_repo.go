@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type EmployeeRepo struct{ db DBTX }
+
+func NewEmployeeRepo(db DBTX) *EmployeeRepo {
+	return &EmployeeRepo{db: db}
+}
+
+const employeeSelect = `SELECT employee_id, full_name, email, created_by
+FROM employees`
+
+func (r *EmployeeRepo) List() ([]*models.Employee, error) {
+	return r.query(employeeSelect + ` ORDER BY full_name;`)
+}
+
+// Create inserts a new employee, setting its employee ID
+func (r *EmployeeRepo) Create(e *models.Employee) error {
+	return r.db.QueryRow(`INSERT INTO employees (full_name, email, created_by)
+VALUES (?, ?, ?) RETURNING employee_id;`, e.FullName, e.Email, e.CreatedBy).Scan(&e.EmployeeID)
+}
+
+// Get retrieves a single employee by ID
+// Returns sql.ErrNoRows if the employee does not exist
+func (r *EmployeeRepo) Get(employeeID int) (*models.Employee, error) {
+	return r.queryRow(employeeSelect+` WHERE employee_id = ?;`, employeeID)
+}
+
+// FindByEmail retrieves an employee by email address, ignoring case
+// Returns sql.ErrNoRows if there is no such employee
+func (r *EmployeeRepo) FindByEmail(email string) (*models.Employee, error) {
+	return r.queryRow(employeeSelect+` WHERE email = ? COLLATE NOCASE;`, email)
+}
+
+// FindByName retrieves the employees with the given full name, ignoring case
+func (r *EmployeeRepo) FindByName(name string) ([]*models.Employee, error) {
+	return r.query(employeeSelect+` WHERE full_name = ? COLLATE NOCASE ORDER BY email;`, name)
+}
+
+// Resolve finds an employee by email, or by full name when no other
+// employee has the same name
+func (r *EmployeeRepo) Resolve(nameOrEmail string) (*models.Employee, error) {
+	return ResolveEmployee(r, nameOrEmail)
+}
+
+// ResolveEmployee implements EmployeeStore.Resolve over the store's
+// FindByEmail and FindByName
+func ResolveEmployee(r EmployeeStore, nameOrEmail string) (*models.Employee, error) {
+	if strings.Contains(nameOrEmail, "@") {
+		e, err := r.FindByEmail(nameOrEmail)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("employee %q not found", nameOrEmail)
+		}
+		return e, err
+	}
+
+	matches, err := r.FindByName(nameOrEmail)
+	if err != nil {
+		return nil, err
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("employee %q not found", nameOrEmail)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("%d employees are named %q, use their email instead", len(matches), nameOrEmail)
+}
+
+// Search retrieves up to limit employees whose name or email contains text
+func (r *EmployeeRepo) Search(text string, limit int) ([]*models.Employee, error) {
+	return r.query(employeeSelect+` WHERE full_name LIKE ?1 ESCAPE '\' OR email LIKE ?1 ESCAPE '\'
+ORDER BY full_name LIMIT ?2;`, containsPattern(text), limit)
+}
+
+// query runs an employeeSelect based query
+func (r *EmployeeRepo) query(query string, args ...any) ([]*models.Employee, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.Employee
+	for rows.Next() {
+		var e models.Employee
+
+		if err := rows.Scan(&e.EmployeeID, &e.FullName, &e.Email, &e.CreatedBy); err != nil {
+			return nil, err
+		}
+		out = append(out, &e)
+	}
+
+	return out, rows.Err()
+}
+
+// queryRow runs an employeeSelect based query returning a single employee
+func (r *EmployeeRepo) queryRow(query string, args ...any) (*models.Employee, error) {
+	var e models.Employee
+	err := r.db.QueryRow(query, args...).Scan(&e.EmployeeID, &e.FullName, &e.Email, &e.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
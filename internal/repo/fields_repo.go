@@ -0,0 +1,162 @@
+package repo
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type FieldRepo struct{ db DBTX }
+
+func NewFieldRepo(db DBTX) *FieldRepo {
+	return &FieldRepo{db: db}
+}
+
+const customFieldSelect = `SELECT f.field_id, f.name, f.kind, f.type_id, t.type_name, f.category_id, c.description,
+	f.required, f.options, f.pattern, f.min_value, f.max_value, f.created_by, f.created_at
+FROM custom_fields f
+LEFT JOIN asset_types t ON t.type_id = f.type_id
+JOIN asset_categories c ON c.category_id = COALESCE(f.category_id, t.category_id)`
+
+// customFieldOrder puts the fields of a category before those of its types
+const customFieldOrder = ` ORDER BY c.description, t.type_name IS NOT NULL, t.type_name, f.field_id;`
+
+// List retrieves every custom field, ordered by category, then type, then
+// creation
+func (r *FieldRepo) List() ([]*models.CustomField, error) {
+	return r.query(customFieldSelect + customFieldOrder)
+}
+
+// ListForType retrieves the custom fields that apply to the assets of a
+// type: those of its category first, then its own
+func (r *FieldRepo) ListForType(typeID int) ([]*models.CustomField, error) {
+	return r.query(customFieldSelect+`
+WHERE f.type_id = ? OR f.category_id = (SELECT category_id FROM asset_types WHERE type_id = ?)`+customFieldOrder,
+		typeID, typeID)
+}
+
+// Get retrieves a custom field by ID
+// Returns sql.ErrNoRows if the field does not exist
+func (r *FieldRepo) Get(fieldID int) (*models.CustomField, error) {
+	return one(r.query(customFieldSelect+` WHERE f.field_id = ?;`, fieldID))
+}
+
+// query runs a customFieldSelect based query
+func (r *FieldRepo) query(query string, args ...any) ([]*models.CustomField, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.CustomField
+	for rows.Next() {
+		var f models.CustomField
+		var options sql.NullString
+		var createdAt string
+		if err := rows.Scan(&f.FieldID, &f.Name, &f.Kind, &f.TypeID, &f.TypeName, &f.CategoryID, &f.CategoryName,
+			&f.Required, &options, &f.Pattern, &f.Min, &f.Max, &f.CreatedBy, &createdAt); err != nil {
+			return nil, err
+		}
+		if options.Valid {
+			if err := json.Unmarshal([]byte(options.String), &f.Options); err != nil {
+				return nil, err
+			}
+		}
+		f.CreatedAt = parseTime(createdAt)
+		out = append(out, &f)
+	}
+	return out, rows.Err()
+}
+
+// Create inserts a custom field, setting its ID and creation time
+// The values it accepts are checked by the caller
+func (r *FieldRepo) Create(f *models.CustomField) error {
+	var options *string
+	if f.Options != nil {
+		b, err := json.Marshal(f.Options)
+		if err != nil {
+			return err
+		}
+		s := string(b)
+		options = &s
+	}
+	var createdAt string
+	if err := r.db.QueryRow(`INSERT INTO custom_fields (name, kind, type_id, category_id, required, options,
+	pattern, min_value, max_value, created_by)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING field_id, created_at;`,
+		f.Name, f.Kind, f.TypeID, f.CategoryID, f.Required, options,
+		f.Pattern, f.Min, f.Max, f.CreatedBy).Scan(&f.FieldID, &createdAt); err != nil {
+		return err
+	}
+	f.CreatedAt = parseTime(createdAt)
+	return nil
+}
+
+// Delete removes a custom field along with its values on every asset
+// Returns sql.ErrNoRows if the field does not exist
+func (r *FieldRepo) Delete(fieldID int) error {
+	return inTx(r.db, func(tx DBTX) error {
+		if _, err := tx.Exec(`DELETE FROM asset_field_values WHERE field_id = ?;`, fieldID); err != nil {
+			return err
+		}
+		return execOne(tx, `DELETE FROM custom_fields WHERE field_id = ?;`, fieldID)
+	})
+}
+
+const fieldValueSelect = `SELECT v.asset_id, v.field_id, f.name, f.kind, v.value, v.updated_by
+FROM asset_field_values v
+JOIN custom_fields f ON f.field_id = v.field_id`
+
+// ListValues retrieves the custom field values of an asset, in the order of
+// ListForType
+func (r *FieldRepo) ListValues(assetID string) ([]*models.AssetFieldValue, error) {
+	return r.queryValues(fieldValueSelect+`
+WHERE v.asset_id = ?
+ORDER BY f.type_id IS NOT NULL, f.field_id;`, assetID)
+}
+
+// ListAllValues retrieves the custom field values of every asset, ordered by
+// asset
+func (r *FieldRepo) ListAllValues() ([]*models.AssetFieldValue, error) {
+	return r.queryValues(fieldValueSelect + `
+ORDER BY v.asset_id, f.type_id IS NOT NULL, f.field_id;`)
+}
+
+// queryValues runs a fieldValueSelect based query
+func (r *FieldRepo) queryValues(query string, args ...any) ([]*models.AssetFieldValue, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.AssetFieldValue
+	for rows.Next() {
+		var v models.AssetFieldValue
+		if err := rows.Scan(&v.AssetID, &v.FieldID, &v.FieldName, &v.Kind, &v.Value, &v.UpdatedBy); err != nil {
+			return nil, err
+		}
+		out = append(out, &v)
+	}
+	return out, rows.Err()
+}
+
+// SetValue stores the value of a custom field on an asset, replacing the
+// one it had
+// The value is stored as given; checking it against the field is up to the
+// caller
+func (r *FieldRepo) SetValue(v *models.AssetFieldValue) error {
+	_, err := r.db.Exec(`INSERT INTO asset_field_values (asset_id, field_id, value, updated_by)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (asset_id, field_id) DO UPDATE SET value = excluded.value, updated_by = excluded.updated_by;`,
+		v.AssetID, v.FieldID, v.Value, v.UpdatedBy)
+	return err
+}
+
+// ClearValue removes the value of a custom field from an asset
+// Returns sql.ErrNoRows if the asset has no value for the field
+func (r *FieldRepo) ClearValue(assetID string, fieldID int) error {
+	return execOne(r.db, `DELETE FROM asset_field_values WHERE asset_id = ? AND field_id = ?;`, assetID, fieldID)
+}
@@ -0,0 +1,238 @@
+package repo
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type KitRepo struct{ db DBTX }
+
+func NewKitRepo(db DBTX) *KitRepo {
+	return &KitRepo{db: db}
+}
+
+// ListTemplates retrieves the kit templates with their items, ordered by name
+func (r *KitRepo) ListTemplates() ([]*models.KitTemplate, error) {
+	return r.queryTemplates(`SELECT template_id, name, description FROM kit_templates ORDER BY name;`)
+}
+
+// FindTemplate retrieves a kit template by name, ignoring case
+// Returns sql.ErrNoRows if no template has the name
+func (r *KitRepo) FindTemplate(name string) (*models.KitTemplate, error) {
+	return one(r.queryTemplates(`SELECT template_id, name, description FROM kit_templates
+WHERE name = ? COLLATE NOCASE;`, strings.TrimSpace(name)))
+}
+
+// GetTemplate retrieves a kit template by ID
+// Returns sql.ErrNoRows if the template does not exist
+func (r *KitRepo) GetTemplate(templateID int) (*models.KitTemplate, error) {
+	return one(r.queryTemplates(`SELECT template_id, name, description FROM kit_templates
+WHERE template_id = ?;`, templateID))
+}
+
+// queryTemplates runs a query selecting kit templates and loads their items
+func (r *KitRepo) queryTemplates(query string, args ...any) ([]*models.KitTemplate, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.KitTemplate
+	for rows.Next() {
+		var t models.KitTemplate
+		if err := rows.Scan(&t.TemplateID, &t.Name, &t.Description); err != nil {
+			return nil, err
+		}
+		out = append(out, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for _, t := range out {
+		if t.Items, err = r.templateItems(t.TemplateID); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// templateItems retrieves the items of a kit template, ordered by type name
+func (r *KitRepo) templateItems(templateID int) ([]models.KitTemplateItem, error) {
+	rows, err := r.db.Query(`SELECT i.type_id, t.type_name, i.quantity
+FROM kit_template_items i
+JOIN asset_types t ON t.type_id = i.type_id
+WHERE i.template_id = ?
+ORDER BY t.type_name;`, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.KitTemplateItem
+	for rows.Next() {
+		var i models.KitTemplateItem
+		if err := rows.Scan(&i.TypeID, &i.TypeName, &i.Quantity); err != nil {
+			return nil, err
+		}
+		out = append(out, i)
+	}
+	return out, rows.Err()
+}
+
+// CreateTemplate inserts a kit template with its items, setting its ID
+func (r *KitRepo) CreateTemplate(t *models.KitTemplate) error {
+	return inTx(r.db, func(tx DBTX) error {
+		if err := tx.QueryRow(`INSERT INTO kit_templates (name, description)
+VALUES (?, ?) RETURNING template_id;`, t.Name, t.Description).Scan(&t.TemplateID); err != nil {
+			return err
+		}
+		for _, i := range t.Items {
+			if _, err := tx.Exec(`INSERT INTO kit_template_items (template_id, type_id, quantity)
+VALUES (?, ?, ?);`, t.TemplateID, i.TypeID, i.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+const kitSelect = `SELECT k.kit_id, k.kit_tag, k.template_id, t.name, k.notes, k.created_by, k.created_at
+FROM kits k
+LEFT JOIN kit_templates t ON t.template_id = k.template_id`
+
+// List retrieves every kit with its members, ordered by kit tag
+func (r *KitRepo) List() ([]*models.Kit, error) {
+	return r.query(kitSelect + ` ORDER BY k.kit_tag;`)
+}
+
+// Get retrieves a kit by ID
+// Returns sql.ErrNoRows if the kit does not exist
+func (r *KitRepo) Get(kitID int) (*models.Kit, error) {
+	return one(r.query(kitSelect+` WHERE k.kit_id = ?;`, kitID))
+}
+
+// GetByTag retrieves a kit by its tag
+// Returns sql.ErrNoRows if no kit has the tag
+func (r *KitRepo) GetByTag(kitTag string) (*models.Kit, error) {
+	return one(r.query(kitSelect+` WHERE k.kit_tag = ?;`, kitTag))
+}
+
+// FindByAsset retrieves the kit an asset belongs to
+// Returns sql.ErrNoRows if the asset is in no kit
+func (r *KitRepo) FindByAsset(assetID string) (*models.Kit, error) {
+	return one(r.query(kitSelect+`
+WHERE k.kit_id = (SELECT kit_id FROM kit_assets WHERE asset_id = ?);`, assetID))
+}
+
+// query runs a kitSelect based query and loads the members of the kits
+func (r *KitRepo) query(query string, args ...any) ([]*models.Kit, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.Kit
+	byID := map[int]*models.Kit{}
+	for rows.Next() {
+		var k models.Kit
+		var createdAt string
+		if err := rows.Scan(&k.KitID, &k.KitTag, &k.TemplateID, &k.TemplateName, &k.Notes, &k.CreatedBy, &createdAt); err != nil {
+			return nil, err
+		}
+		k.CreatedAt = parseTime(createdAt)
+		out = append(out, &k)
+		byID[k.KitID] = &k
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	members, err := r.db.Query(`SELECT ka.kit_id, ka.asset_id
+FROM kit_assets ka
+JOIN assets a ON a.asset_id = ka.asset_id
+ORDER BY a.asset_tag;`)
+	if err != nil {
+		return nil, err
+	}
+	defer members.Close()
+	for members.Next() {
+		var kitID int
+		var assetID string
+		if err := members.Scan(&kitID, &assetID); err != nil {
+			return nil, err
+		}
+		if k := byID[kitID]; k != nil {
+			k.AssetIDs = append(k.AssetIDs, assetID)
+		}
+	}
+	return out, members.Err()
+}
+
+// Create inserts a kit with its members, setting its ID
+// CreatedBy names the acting user and is recorded as adding the members
+func (r *KitRepo) Create(k *models.Kit) error {
+	return inTx(r.db, func(tx DBTX) error {
+		var createdAt string
+		if err := tx.QueryRow(`INSERT INTO kits (kit_tag, template_id, notes, created_by)
+VALUES (?, ?, ?, ?) RETURNING kit_id, created_at;`, k.KitTag, k.TemplateID, k.Notes, k.CreatedBy).
+			Scan(&k.KitID, &createdAt); err != nil {
+			return err
+		}
+		k.CreatedAt = parseTime(createdAt)
+		for _, assetID := range k.AssetIDs {
+			if err := addKitAsset(tx, k.KitID, assetID, k.CreatedBy); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddAsset adds an asset to a kit; actor is the username of the acting
+// user, if any
+// An asset already in a kit fails the kit_assets.asset_id unique constraint
+func (r *KitRepo) AddAsset(kitID int, assetID string, actor *string) error {
+	return addKitAsset(r.db, kitID, assetID, actor)
+}
+
+func addKitAsset(db DBTX, kitID int, assetID string, actor *string) error {
+	_, err := db.Exec(`INSERT INTO kit_assets (asset_id, kit_id, added_by) VALUES (?, ?, ?);`, assetID, kitID, actor)
+	return err
+}
+
+// RemoveAsset takes an asset out of a kit
+// Returns sql.ErrNoRows if the asset is not in the kit
+func (r *KitRepo) RemoveAsset(kitID int, assetID string) error {
+	return execOne(r.db, `DELETE FROM kit_assets WHERE kit_id = ? AND asset_id = ?;`, kitID, assetID)
+}
+
+// Delete removes a kit and its memberships, leaving the assets untouched
+func (r *KitRepo) Delete(kitID int) error {
+	return inTx(r.db, func(tx DBTX) error {
+		if _, err := tx.Exec(`DELETE FROM kit_assets WHERE kit_id = ?;`, kitID); err != nil {
+			return err
+		}
+		return execOne(tx, `DELETE FROM kits WHERE kit_id = ?;`, kitID)
+	})
+}
+
+// one returns the only row read by a query, or sql.ErrNoRows for none
+func one[T any](rows []*T, err error) (*T, error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return rows[0], nil
+}
@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"database/sql"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type LicenseRepo struct{ db DBTX }
+
+func NewLicenseRepo(db DBTX) *LicenseRepo {
+	return &LicenseRepo{db: db}
+}
+
+const licenseSelect = `SELECT l.license_id, l.software_name, l.license_key, l.license_type, l.seats_purchased,
+	l.purchase_date, l.expiration_date, l.notes,
+	(SELECT count(*) FROM license_assignments la
+		WHERE la.license_id = l.license_id AND la.removal_date IS NULL) AS seats_used
+FROM software_licenses l`
+
+func (r *LicenseRepo) List() ([]*models.SoftwareLicense, error) {
+	return r.query(licenseSelect + ` ORDER BY l.software_name;`)
+}
+
+// Search retrieves up to limit licenses whose software name contains text
+func (r *LicenseRepo) Search(text string, limit int) ([]*models.SoftwareLicense, error) {
+	return r.query(licenseSelect+` WHERE l.software_name LIKE ? ESCAPE '\' ORDER BY l.software_name LIMIT ?;`, containsPattern(text), limit)
+}
+
+// Create inserts a new license, setting its license ID
+func (r *LicenseRepo) Create(l *models.SoftwareLicense) error {
+	return r.db.QueryRow(`INSERT INTO software_licenses (software_name, license_key, license_type, seats_purchased,
+	purchase_date, expiration_date, notes)
+VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING license_id;`,
+		l.SoftwareName, l.LicenseKey, l.LicenseType, l.SeatsPurchased,
+		l.PurchaseDate.Format(DateLayout), formatNullDate(l.ExpirationDate), l.Notes).Scan(&l.LicenseID)
+}
+
+// Assign installs a license on an asset, setting the assignment ID
+// A license can be installed on an asset once until it is removed
+func (r *LicenseRepo) Assign(a *models.LicenseAssignment) error {
+	return r.db.QueryRow(`INSERT INTO license_assignments (license_id, asset_id, assignment_date, notes)
+VALUES (?, ?, ?, ?) RETURNING assignment_id;`,
+		a.LicenseID, a.AssetID, a.AssignmentDate.Format(TimestampLayout), a.Notes).Scan(&a.AssignmentID)
+}
+
+// query runs a licenseSelect based query
+func (r *LicenseRepo) query(query string, args ...any) ([]*models.SoftwareLicense, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.SoftwareLicense
+	for rows.Next() {
+		var l models.SoftwareLicense
+		var purchaseDate string
+		var expirationDate sql.NullString
+
+		if err := rows.Scan(&l.LicenseID, &l.SoftwareName, &l.LicenseKey, &l.LicenseType, &l.SeatsPurchased,
+			&purchaseDate, &expirationDate, &l.Notes, &l.SeatsUsed); err != nil {
+			return nil, err
+		}
+		l.PurchaseDate = parseTime(purchaseDate)
+		l.ExpirationDate = parseNullTime(expirationDate)
+		out = append(out, &l)
+	}
+
+	return out, rows.Err()
+}
+
+// ListByAsset retrieves the licenses installed on an asset, including
+// removed ones, newest first
+func (r *LicenseRepo) ListByAsset(assetID string) ([]*models.LicenseAssignment, error) {
+	rows, err := r.db.Query(`SELECT la.assignment_id, la.license_id, l.software_name, l.license_type,
+	la.asset_id, la.assignment_date, la.removal_date, la.notes
+FROM license_assignments la
+JOIN software_licenses l ON l.license_id = la.license_id
+WHERE la.asset_id = ?
+ORDER BY la.assignment_date DESC, la.assignment_id DESC;`, assetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.LicenseAssignment
+	for rows.Next() {
+		var a models.LicenseAssignment
+		var assignmentDate string
+		var removalDate sql.NullString
+
+		if err := rows.Scan(&a.AssignmentID, &a.LicenseID, &a.SoftwareName, &a.LicenseType,
+			&a.AssetID, &assignmentDate, &removalDate, &a.Notes); err != nil {
+			return nil, err
+		}
+		a.AssignmentDate = parseTime(assignmentDate)
+		a.RemovalDate = parseNullTime(removalDate)
+		out = append(out, &a)
+	}
+
+	return out, rows.Err()
+}
@@ -1,14 +1,12 @@
 package repo
 
 import (
-	"database/sql"
-
 	"github.com/MawCeron/it-room/internal/models"
 )
 
-type LocationRepo struct{ db *sql.DB }
+type LocationRepo struct{ db DBTX }
 
-func NewLocationRepo(db *sql.DB) *LocationRepo {
+func NewLocationRepo(db DBTX) *LocationRepo {
 	return &LocationRepo{db: db}
 }
 
@@ -32,3 +30,15 @@ FROM locations;`)
 
 	return out, nil
 }
+
+// FindByName retrieves a location by name, ignoring case
+// Returns sql.ErrNoRows if there is no such location
+func (r *LocationRepo) FindByName(name string) (*models.Location, error) {
+	var l models.Location
+	err := r.db.QueryRow(`SELECT location_id, name, "type"
+FROM locations WHERE name = ? COLLATE NOCASE;`, name).Scan(&l.LocationID, &l.Name, &l.Type)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
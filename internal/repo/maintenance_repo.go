@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type MaintenanceRepo struct{ db DBTX }
+
+func NewMaintenanceRepo(db DBTX) *MaintenanceRepo {
+	return &MaintenanceRepo{db: db}
+}
+
+const maintenanceSelect = `SELECT m.log_id, m.asset_id, a.asset_tag, m.maintenance_type_id, mt.type_name,
+	m.maintenance_date, m.cost, m.description, m.performed_by, m.recorded_by
+FROM maintenance_logs m
+JOIN assets a ON a.asset_id = m.asset_id
+JOIN maintenance_types mt ON mt.maintenance_type_id = m.maintenance_type_id`
+
+// List retrieves every maintenance log, oldest first
+func (r *MaintenanceRepo) List() ([]*models.MaintenanceLog, error) {
+	return r.query(maintenanceSelect + `
+ORDER BY m.maintenance_date, m.log_id;`)
+}
+
+// ListByAsset retrieves the maintenance logs of an asset, newest first
+func (r *MaintenanceRepo) ListByAsset(assetID string) ([]*models.MaintenanceLog, error) {
+	return r.query(maintenanceSelect+`
+WHERE m.asset_id = ?
+ORDER BY m.maintenance_date DESC, m.log_id DESC;`, assetID)
+}
+
+// query runs a maintenanceSelect based query
+func (r *MaintenanceRepo) query(query string, args ...any) ([]*models.MaintenanceLog, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.MaintenanceLog
+	for rows.Next() {
+		var m models.MaintenanceLog
+		var maintenanceDate string
+
+		if err := rows.Scan(&m.LogID, &m.AssetID, &m.AssetTag, &m.MaintenanceTypeID, &m.TypeName,
+			&maintenanceDate, &m.Cost, &m.Description, &m.PerformedBy, &m.RecordedBy); err != nil {
+			return nil, err
+		}
+		m.MaintenanceDate = parseTime(maintenanceDate)
+		out = append(out, &m)
+	}
+
+	return out, rows.Err()
+}
+
+// Create inserts a maintenance log, setting its log ID
+func (r *MaintenanceRepo) Create(m *models.MaintenanceLog) error {
+	return r.db.QueryRow(`INSERT INTO maintenance_logs (asset_id, maintenance_type_id, maintenance_date, cost, description, performed_by, recorded_by)
+VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING log_id;`,
+		m.AssetID, m.MaintenanceTypeID, m.MaintenanceDate.Format(TimestampLayout), m.Cost, m.Description, m.PerformedBy, m.RecordedBy).
+		Scan(&m.LogID)
+}
+
+// FindType retrieves a maintenance type by name, ignoring case, returning
+// its ID and stored name
+// Returns sql.ErrNoRows if there is no such type
+func (r *MaintenanceRepo) FindType(name string) (int, string, error) {
+	var id int
+	var typeName string
+	err := r.db.QueryRow(`SELECT maintenance_type_id, type_name
+FROM maintenance_types WHERE type_name = ? COLLATE NOCASE;`, name).Scan(&id, &typeName)
+	return id, typeName, err
+}
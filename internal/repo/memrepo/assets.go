@@ -0,0 +1,543 @@
+package memrepo
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/google/uuid"
+)
+
+// ErrAsOfUnsupported is returned for asset queries with AssetQuery.AsOf set,
+// which need the SQLite audit log to rebuild the past
+var ErrAsOfUnsupported = errors.New("as-of queries need the SQLite repositories")
+
+// AssetRepo is the in-memory repo.AssetStore
+type AssetRepo struct{ s *store }
+
+func (r *AssetRepo) List() ([]*models.Asset, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.Asset
+	for _, a := range r.s.assets {
+		c := *a
+		out = append(out, &c)
+	}
+	return out, nil
+}
+
+func (r *AssetRepo) ListSummaries(q repo.AssetQuery) ([]*models.AssetSummary, error) {
+	out, _, err := r.PageSummaries(q, nil, 0)
+	return out, err
+}
+
+func (r *AssetRepo) PageSummaries(q repo.AssetQuery, after *repo.AssetCursor, limit int) ([]*models.AssetSummary, *repo.AssetCursor, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rows, err := r.s.matchingSummaries(q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type row struct {
+		a   *models.AssetSummary
+		key string
+	}
+	var keyed []row
+	for _, a := range rows {
+		keyed = append(keyed, row{a, sortKey(a, q.SortColumn)})
+	}
+	less := func(x, y row) bool {
+		if x.key != y.key {
+			return x.key < y.key
+		}
+		return x.a.AssetID < y.a.AssetID
+	}
+	sort.Slice(keyed, func(i, j int) bool {
+		if q.SortDesc {
+			return less(keyed[j], keyed[i])
+		}
+		return less(keyed[i], keyed[j])
+	})
+
+	var out []*models.AssetSummary
+	var cursor *repo.AssetCursor
+	for _, k := range keyed {
+		if after != nil {
+			c := row{key: after.SortKey, a: &models.AssetSummary{Asset: models.Asset{AssetID: after.AssetID}}}
+			if (!q.SortDesc && !less(c, k)) || (q.SortDesc && !less(k, c)) {
+				continue
+			}
+		}
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, k.a)
+		cursor = &repo.AssetCursor{SortKey: k.key, AssetID: k.a.AssetID}
+	}
+	return out, cursor, nil
+}
+
+func (r *AssetRepo) GetSummary(assetID string) (*models.AssetSummary, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	a := r.s.asset(assetID)
+	if a == nil {
+		return nil, sql.ErrNoRows
+	}
+	return r.s.summary(a), nil
+}
+
+func (r *AssetRepo) GetSummaryByTag(assetTag string) (*models.AssetSummary, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, a := range r.s.assets {
+		if a.AssetTag == assetTag {
+			return r.s.summary(a), nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *AssetRepo) CountSummaries(q repo.AssetQuery) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rows, err := r.s.matchingSummaries(q)
+	return len(rows), err
+}
+
+func (r *AssetRepo) GetAssetCategories() ([]*models.AssetCategory, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.AssetCategory
+	for _, c := range r.s.categories {
+		cc := *c
+		out = append(out, &cc)
+	}
+	return out, nil
+}
+
+func (r *AssetRepo) GetAssetTypes(category int) ([]*models.AssetType, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.AssetType
+	for _, t := range r.s.types {
+		if t.CategoryID == category {
+			tt := *t
+			out = append(out, &tt)
+		}
+	}
+	return out, nil
+}
+
+func (r *AssetRepo) Create(a *models.Asset) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	a.AssetID = uuid.NewString()
+	a.UpdatedBy = a.CreatedBy
+	if err := r.s.checkAsset(a); err != nil {
+		return err
+	}
+
+	stored := *a
+	stored.Fields = nil
+	stored.PurchaseDate = date(a.PurchaseDate)
+	stored.WarrantyEndDate = nullDate(a.WarrantyEndDate)
+	r.s.assets = append(r.s.assets, &stored)
+	r.s.record("assets", stored.AssetID, stored.AssetID, models.AuditInsert, stored.CreatedBy, nil, assetRow(&stored))
+	return nil
+}
+
+func (r *AssetRepo) Update(a *models.Asset) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := r.s.asset(a.AssetID)
+	if stored == nil {
+		return sql.ErrNoRows
+	}
+	if err := r.s.checkAsset(a); err != nil {
+		return err
+	}
+
+	before := assetRow(stored)
+	fromLocation := stored.LocationID
+	stored.AssetTag, stored.TypeID, stored.StatusID = a.AssetTag, a.TypeID, a.StatusID
+	stored.SerialNumber, stored.Maker, stored.Model = a.SerialNumber, a.Maker, a.Model
+	stored.PurchaseDate, stored.WarrantyEndDate = date(a.PurchaseDate), nullDate(a.WarrantyEndDate)
+	stored.LocationID, stored.Notes, stored.UpdatedBy = a.LocationID, a.Notes, a.UpdatedBy
+	stored.PurchaseCost, stored.Currency, stored.Supplier = a.PurchaseCost, a.Currency, a.Supplier
+	stored.PONumber, stored.InvoiceNumber = a.PONumber, a.InvoiceNumber
+	r.s.record("assets", stored.AssetID, stored.AssetID, models.AuditUpdate, stored.UpdatedBy, before, assetRow(stored))
+
+	if fromLocation != a.LocationID {
+		r.s.addTransfer(&models.AssetTransfer{AssetID: a.AssetID, FromLocationID: fromLocation,
+			ToLocationID: a.LocationID, TransferredBy: a.UpdatedBy})
+	}
+	return nil
+}
+
+func (r *AssetRepo) Retire(assetID string, date time.Time, actor *string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	a := r.s.asset(assetID)
+	if a == nil {
+		return sql.ErrNoRows
+	}
+	r.s.closeAssignments(assetID, date, actor)
+	r.s.setStatus(a, models.StatusRetired, actor)
+	return nil
+}
+
+func (r *AssetRepo) CountByStatus() ([]*models.CountBy, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.CountBy
+	for _, st := range r.s.statuses {
+		c := &models.CountBy{ID: st.StatusID, Name: st.StatusName}
+		for _, a := range r.s.assets {
+			if a.StatusID == st.StatusID {
+				c.Count++
+			}
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (r *AssetRepo) CountByCategory() ([]*models.CountBy, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.CountBy
+	for _, cat := range r.s.categories {
+		c := &models.CountBy{ID: cat.CategoryId, Name: cat.Description}
+		for _, a := range r.s.assets {
+			if t := r.s.assetType(a.TypeID); t != nil && t.CategoryID == cat.CategoryId {
+				c.Count++
+			}
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (r *AssetRepo) WarrantyReport(day time.Time, soonDays int) ([]*models.WarrantyVendorSummary, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	today := day.Format(repo.DateLayout)
+	soon := day.AddDate(0, 0, soonDays).Format(repo.DateLayout)
+	byVendor := map[string]*models.WarrantyVendorSummary{}
+	var out []*models.WarrantyVendorSummary
+	for _, a := range r.s.assets {
+		if a.StatusID == models.StatusRetired {
+			continue
+		}
+		v, ok := byVendor[a.Maker]
+		if !ok {
+			v = &models.WarrantyVendorSummary{Vendor: a.Maker}
+			byVendor[a.Maker] = v
+			out = append(out, v)
+		}
+		switch end := dateString(a.WarrantyEndDate); {
+		case end == "":
+			v.None++
+		case end > soon:
+			v.Active++
+		case end >= today:
+			v.ExpiringSoon++
+		default:
+			v.Expired++
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return strings.ToLower(out[i].Vendor) < strings.ToLower(out[j].Vendor)
+	})
+	return out, nil
+}
+
+func (r *AssetRepo) GetAssetType(typeID int) (*models.AssetType, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t := r.s.assetType(typeID)
+	if t == nil {
+		return nil, sql.ErrNoRows
+	}
+	c := *t
+	return &c, nil
+}
+
+func (r *AssetRepo) FindAssetType(name string) (*models.AssetType, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, t := range r.s.types {
+		if strings.EqualFold(t.TypeName, name) {
+			c := *t
+			return &c, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *AssetRepo) FindAssetCategory(name string) (*models.AssetCategory, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, c := range r.s.categories {
+		if strings.EqualFold(c.CodePrefix, name) || strings.EqualFold(c.Description, name) {
+			cc := *c
+			return &cc, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *AssetRepo) FindAssetStatus(name string) (*models.AssetStatus, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, st := range r.s.statuses {
+		if strings.EqualFold(st.StatusName, name) {
+			c := *st
+			return &c, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *AssetRepo) GetAssetStatuses() ([]*models.AssetStatus, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.AssetStatus
+	for _, st := range r.s.statuses {
+		c := *st
+		out = append(out, &c)
+	}
+	return out, nil
+}
+
+// asset returns the stored asset with the given ID, or nil
+func (s *store) asset(assetID string) *models.Asset {
+	for _, a := range s.assets {
+		if a.AssetID == assetID {
+			return a
+		}
+	}
+	return nil
+}
+
+// assetType returns the asset type with the given ID, or nil
+func (s *store) assetType(typeID int) *models.AssetType {
+	for _, t := range s.types {
+		if t.TypeID == typeID {
+			return t
+		}
+	}
+	return nil
+}
+
+// category returns the asset category with the ID, or nil
+func (s *store) category(categoryID int) *models.AssetCategory {
+	for _, c := range s.categories {
+		if c.CategoryId == categoryID {
+			return c
+		}
+	}
+	return nil
+}
+
+// checkAsset enforces the unique and foreign key constraints of the assets
+// table for a new or changed asset
+func (s *store) checkAsset(a *models.Asset) error {
+	for _, other := range s.assets {
+		if other.AssetID == a.AssetID {
+			continue
+		}
+		if other.AssetTag == a.AssetTag {
+			return uniqueError("assets.asset_tag")
+		}
+		if other.SerialNumber == a.SerialNumber {
+			return uniqueError("assets.serial_number")
+		}
+	}
+	if s.assetType(a.TypeID) == nil || s.location(a.LocationID) == nil ||
+		a.StatusID < 1 || a.StatusID > len(s.statuses) {
+		return ErrForeignKey
+	}
+	if a.PurchaseCost != nil && *a.PurchaseCost < 0 {
+		return errors.New("CHECK constraint failed: purchase_cost >= 0")
+	}
+	return nil
+}
+
+// setStatus changes the status of a stored asset, attributing it to actor
+func (s *store) setStatus(a *models.Asset, statusID int, actor *string) {
+	before := assetRow(a)
+	a.StatusID, a.UpdatedBy = statusID, actor
+	s.record("assets", a.AssetID, a.AssetID, models.AuditUpdate, actor, before, assetRow(a))
+}
+
+// summary joins a stored asset with its catalog names and current holder
+func (s *store) summary(a *models.Asset) *models.AssetSummary {
+	out := &models.AssetSummary{Asset: *a}
+	if t := s.assetType(a.TypeID); t != nil {
+		out.TypeName, out.CategoryID = t.TypeName, t.CategoryID
+		out.CategoryName = s.categories[t.CategoryID-1].Description
+	}
+	out.StatusName = s.statuses[a.StatusID-1].StatusName
+	if l := s.location(a.LocationID); l != nil {
+		out.LocationName = l.Name
+	}
+	if open := s.openAssignment(a.AssetID); open != nil {
+		name := s.employee(open.EmployeeID).FullName
+		out.HolderName = &name
+	}
+	return out
+}
+
+// matchingSummaries returns the summaries of the assets matching the
+// filters of q, unordered
+func (s *store) matchingSummaries(q repo.AssetQuery) ([]*models.AssetSummary, error) {
+	if q.AsOf != nil {
+		return nil, ErrAsOfUnsupported
+	}
+
+	var out []*models.AssetSummary
+	for _, a := range s.assets {
+		sum := s.summary(a)
+		if s.matches(sum, q) {
+			out = append(out, sum)
+		}
+	}
+	return out, nil
+}
+
+// matches reports whether an asset passes the filters of q
+func (s *store) matches(a *models.AssetSummary, q repo.AssetQuery) bool {
+	if q.Filter != "" {
+		found := false
+		for _, v := range []string{a.AssetTag, a.SerialNumber, a.Maker, a.Model, a.TypeName, a.LocationName, valueOf(a.HolderName),
+			valueOf(a.Supplier), valueOf(a.PONumber), valueOf(a.InvoiceNumber)} {
+			if contains(v, q.Filter) {
+				found = true
+			}
+		}
+		for _, v := range s.fieldValues {
+			if v.AssetID == a.AssetID && contains(v.Value, q.Filter) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for name, value := range q.Fields {
+		if !s.hasFieldValue(a.AssetID, name, value) {
+			return false
+		}
+	}
+	warranty := dateString(a.WarrantyEndDate)
+	open := s.openAssignment(a.AssetID)
+	switch {
+	case q.StatusID != 0 && a.StatusID != q.StatusID,
+		q.CategoryID != 0 && a.CategoryID != q.CategoryID,
+		q.Make != "" && a.Maker != q.Make,
+		q.ExcludeRetired && a.StatusID == models.StatusRetired,
+		q.WarrantyEndFrom != nil && (warranty == "" || warranty < q.WarrantyEndFrom.Format(repo.DateLayout)),
+		q.WarrantyEndTo != nil && (warranty == "" || warranty > q.WarrantyEndTo.Format(repo.DateLayout)),
+		q.LocationID != 0 && a.LocationID != q.LocationID,
+		q.HolderID != 0 && (open == nil || open.EmployeeID != q.HolderID),
+		q.OverdueOn != nil && (open == nil || dateString(open.DueDate) == "" ||
+			dateString(open.DueDate) >= q.OverdueOn.Format(repo.DateLayout)):
+		return false
+	}
+	return true
+}
+
+// hasFieldValue reports whether an asset has value, ignoring case, in a
+// custom field with the name
+func (s *store) hasFieldValue(assetID, name, value string) bool {
+	for _, v := range s.fieldValues {
+		if v.AssetID == assetID && strings.EqualFold(v.Value, value) &&
+			strings.EqualFold(s.customField(v.FieldID).Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortKey returns the value an asset is ordered by for one of the
+// AssetSortColumns keys, empty for missing values
+func sortKey(a *models.AssetSummary, column string) string {
+	switch column {
+	case "category":
+		return a.CategoryName
+	case "type":
+		return a.TypeName
+	case "make":
+		return a.Maker
+	case "model":
+		return a.Maker + " " + a.Model
+	case "serial_number":
+		return a.SerialNumber
+	case "status":
+		return a.StatusName
+	case "purchase_date":
+		return a.PurchaseDate.Format(repo.DateLayout)
+	case "warranty_end_date", "warranty":
+		return dateString(a.WarrantyEndDate)
+	case "location":
+		return a.LocationName
+	case "holder":
+		return valueOf(a.HolderName)
+	case "supplier":
+		return valueOf(a.Supplier)
+	}
+	return a.AssetTag
+}
+
+// valueOf returns the value of an optional string, empty when nil
+func valueOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// assetRow returns the columns of an asset as the audit log records them
+func assetRow(a *models.Asset) map[string]any {
+	return map[string]any{
+		"asset_id": a.AssetID, "asset_tag": a.AssetTag, "type_id": a.TypeID, "status_id": a.StatusID,
+		"serial_number": a.SerialNumber, "make": a.Maker, "model": a.Model,
+		"purchase_date": a.PurchaseDate.Format(repo.DateLayout), "warranty_end_date": nullString(dateString(a.WarrantyEndDate)),
+		"location_id": a.LocationID, "notes": a.Notes, "purchase_cost": a.PurchaseCost, "currency": a.Currency,
+		"supplier": a.Supplier, "po_number": a.PONumber, "invoice_number": a.InvoiceNumber,
+		"created_by": a.CreatedBy, "updated_by": a.UpdatedBy,
+	}
+}
+
+// nullString returns nil for an empty string, for JSON null
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,186 @@
+package memrepo
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// AssignmentRepo is the in-memory repo.AssignmentStore
+type AssignmentRepo struct{ s *store }
+
+func (r *AssignmentRepo) List() ([]*models.AssetAssignment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	out := r.s.listAssignments("")
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].AssignmentDate.Equal(out[j].AssignmentDate) {
+			return out[i].AssignmentDate.Before(out[j].AssignmentDate)
+		}
+		return out[i].AssignmentID < out[j].AssignmentID
+	})
+	return out, nil
+}
+
+func (r *AssignmentRepo) ListByAsset(assetID string) ([]*models.AssetAssignment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	out := r.s.listAssignments(assetID)
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].AssignmentDate.Equal(out[j].AssignmentDate) {
+			return out[i].AssignmentDate.After(out[j].AssignmentDate)
+		}
+		return out[i].AssignmentID > out[j].AssignmentID
+	})
+	return out, nil
+}
+
+func (r *AssignmentRepo) Assign(a *models.AssetAssignment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	asset := r.s.asset(a.AssetID)
+	switch {
+	case asset == nil:
+		return sql.ErrNoRows
+	case asset.StatusID == models.StatusRetired:
+		return repo.ErrAssetRetired
+	case asset.StatusID == models.StatusUnderMaintenance:
+		return repo.ErrAssetUnderMaintenance
+	case r.s.openAssignment(a.AssetID) != nil:
+		return repo.ErrAssetAssigned
+	case r.s.employee(a.EmployeeID) == nil:
+		return ErrForeignKey
+	}
+
+	a.AssignmentID = r.s.nextID("asset_assignments")
+	stored := *a
+	stored.AssignmentDate = timestamp(a.AssignmentDate)
+	stored.DueDate = nullDate(a.DueDate)
+	stored.ReturnDate, stored.ReturnedBy = nil, nil
+	r.s.assignments = append(r.s.assignments, &stored)
+	r.s.record("asset_assignments", stored.AssignmentID, stored.AssetID, models.AuditInsert, stored.AssignedBy, nil, assignmentRow(&stored))
+
+	r.s.setStatus(asset, models.StatusAssigned, a.AssignedBy)
+	return nil
+}
+
+func (r *AssignmentRepo) Return(assetID string, date time.Time, actor *string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.closeAssignments(assetID, date, actor) == 0 {
+		return repo.ErrAssetNotAssigned
+	}
+	if a := r.s.asset(assetID); a != nil {
+		r.s.setStatus(a, models.StatusAvailable, actor)
+	}
+	return nil
+}
+
+// listAssignments returns copies of the assignments of an asset, or of
+// every asset when assetID is empty, with the asset tag and holder name
+func (s *store) listAssignments(assetID string) []*models.AssetAssignment {
+	var out []*models.AssetAssignment
+	for _, a := range s.assignments {
+		if assetID != "" && a.AssetID != assetID {
+			continue
+		}
+		c := *a
+		c.AssetTag = s.asset(a.AssetID).AssetTag
+		c.EmployeeName = s.employee(a.EmployeeID).FullName
+		out = append(out, &c)
+	}
+	return out
+}
+
+// openAssignment returns the newest open assignment of an asset, or nil
+func (s *store) openAssignment(assetID string) *models.AssetAssignment {
+	var open *models.AssetAssignment
+	for _, a := range s.assignments {
+		if a.AssetID == assetID && a.ReturnDate == nil && (open == nil || a.AssignmentDate.After(open.AssignmentDate)) {
+			open = a
+		}
+	}
+	return open
+}
+
+// closeAssignments returns every open assignment of an asset on date and
+// reports how many there were
+func (s *store) closeAssignments(assetID string, date time.Time, actor *string) int {
+	closed := 0
+	returned := timestamp(date)
+	for _, a := range s.assignments {
+		if a.AssetID != assetID || a.ReturnDate != nil {
+			continue
+		}
+		before := assignmentRow(a)
+		a.ReturnDate, a.ReturnedBy = &returned, actor
+		by := actor
+		if by == nil {
+			by = a.AssignedBy
+		}
+		s.record("asset_assignments", a.AssignmentID, a.AssetID, models.AuditUpdate, by, before, assignmentRow(a))
+		closed++
+	}
+	return closed
+}
+
+// assignmentRow returns the columns of an assignment as the audit log
+// records them
+func assignmentRow(a *models.AssetAssignment) map[string]any {
+	var returnDate any
+	if a.ReturnDate != nil {
+		returnDate = a.ReturnDate.Format(repo.TimestampLayout)
+	}
+	return map[string]any{
+		"assignment_id": a.AssignmentID, "asset_id": a.AssetID, "employee_id": a.EmployeeID,
+		"assignment_date": a.AssignmentDate.Format(repo.TimestampLayout), "return_date": returnDate,
+		"notes": a.Notes, "due_date": nullString(dateString(a.DueDate)),
+		"assigned_by": a.AssignedBy, "returned_by": a.ReturnedBy,
+	}
+}
+
+// TransferRepo is the in-memory repo.TransferStore
+type TransferRepo struct{ s *store }
+
+func (r *TransferRepo) ListByAsset(assetID string) ([]*models.AssetTransfer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.AssetTransfer
+	for _, t := range r.s.transfers {
+		if t.AssetID != assetID {
+			continue
+		}
+		c := *t
+		c.FromLocationName = r.s.location(t.FromLocationID).Name
+		c.ToLocationName = r.s.location(t.ToLocationID).Name
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].TransferDate.Equal(out[j].TransferDate) {
+			return out[i].TransferDate.After(out[j].TransferDate)
+		}
+		return out[i].TransferID > out[j].TransferID
+	})
+	return out, nil
+}
+
+// addTransfer records an asset moving between locations now
+func (s *store) addTransfer(t *models.AssetTransfer) {
+	t.TransferID = s.nextID("asset_transfers")
+	t.TransferDate = s.timestamp()
+	s.transfers = append(s.transfers, t)
+	s.record("asset_transfers", t.TransferID, t.AssetID, models.AuditInsert, t.TransferredBy, nil, map[string]any{
+		"transfer_id": t.TransferID, "asset_id": t.AssetID,
+		"from_location_id": t.FromLocationID, "to_location_id": t.ToLocationID,
+		"transfer_date": t.TransferDate.Format(repo.TimestampLayout), "notes": t.Notes,
+		"transferred_by": t.TransferredBy,
+	})
+}
@@ -0,0 +1,73 @@
+package memrepo
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// AuditRepo is the in-memory repo.AuditStore
+// It lists the changes made through the other in-memory stores
+type AuditRepo struct{ s *store }
+
+func (r *AuditRepo) List(q repo.AuditQuery) ([]*models.AuditEntry, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.AuditEntry
+	for _, e := range r.s.audit {
+		if !auditMatches(e, q) {
+			continue
+		}
+		c := *e
+		if e.AssetID != nil {
+			if a := r.s.asset(*e.AssetID); a != nil {
+				tag := a.AssetTag
+				c.AssetTag = &tag
+			}
+		}
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].ChangedAt.Equal(out[j].ChangedAt) {
+			return out[i].ChangedAt.After(out[j].ChangedAt)
+		}
+		return out[i].AuditID > out[j].AuditID
+	})
+	if q.Limit > 0 {
+		out = limited(out, q.Limit)
+	}
+	return out, nil
+}
+
+// auditMatches reports whether an entry passes the filters of q
+func auditMatches(e *models.AuditEntry, q repo.AuditQuery) bool {
+	day := e.ChangedAt.Format(repo.DateLayout)
+	switch {
+	case q.Entity != "" && e.Entity != q.Entity,
+		q.EntityID != "" && e.EntityID != q.EntityID,
+		q.AssetID != "" && (e.AssetID == nil || *e.AssetID != q.AssetID),
+		q.Actor != "" && (e.Actor == nil || !strings.EqualFold(*e.Actor, q.Actor)),
+		q.Since != nil && day < q.Since.Format(repo.DateLayout),
+		q.Until != nil && day > q.Until.Format(repo.DateLayout):
+		return false
+	}
+	if q.Field != "" {
+		return !reflect.DeepEqual(field(e.Before, q.Field), field(e.After, q.Field))
+	}
+	return true
+}
+
+// field returns the value of a field of a row recorded as JSON, nil when
+// the row or the field is absent
+func field(row json.RawMessage, name string) any {
+	var values map[string]any
+	if json.Unmarshal(row, &values) != nil {
+		return nil
+	}
+	return values[name]
+}
@@ -0,0 +1,106 @@
+package memrepo
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"sort"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+// DepreciationRepo is the in-memory repo.DepreciationStore
+type DepreciationRepo struct{ s *store }
+
+func (r *DepreciationRepo) List() ([]*models.DepreciationRule, error) {
+	return r.rules(func(*models.DepreciationRule) bool { return true }), nil
+}
+
+func (r *DepreciationRepo) Get(categoryID int) (*models.DepreciationRule, error) {
+	return first(r.rules(func(rule *models.DepreciationRule) bool { return rule.CategoryID == categoryID }))
+}
+
+func (r *DepreciationRepo) Set(rule *models.DepreciationRule) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	switch {
+	case r.s.category(rule.CategoryID) == nil:
+		return ErrForeignKey
+	case !slices.Contains(models.DepreciationMethods, rule.Method):
+		return errors.New("CHECK constraint failed: method IN ('straight-line', 'declining-balance')")
+	case rule.UsefulLifeYears <= 0:
+		return errors.New("CHECK constraint failed: useful_life_years > 0")
+	case rule.SalvagePercent < 0 || rule.SalvagePercent >= 100:
+		return errors.New("CHECK constraint failed: salvage_percent >= 0 AND salvage_percent < 100")
+	case rule.Factor != nil && *rule.Factor <= 0:
+		return errors.New("CHECK constraint failed: factor > 0")
+	case (rule.Method == models.DepreciationDecliningBalance) != (rule.Factor != nil):
+		return errors.New("CHECK constraint failed: (method = 'declining-balance') = (factor IS NOT NULL)")
+	}
+	rule.UpdatedAt = r.s.timestamp()
+	stored := *rule
+	stored.CategoryName = ""
+	for i, other := range r.s.rules {
+		if other.CategoryID != rule.CategoryID {
+			continue
+		}
+		before := ruleRow(other)
+		r.s.rules[i] = &stored
+		if !sameRule(other, &stored) {
+			r.s.record("depreciation_rules", rule.CategoryID, "", models.AuditUpdate, rule.UpdatedBy, before, ruleRow(&stored))
+		}
+		return nil
+	}
+	r.s.rules = append(r.s.rules, &stored)
+	r.s.record("depreciation_rules", rule.CategoryID, "", models.AuditInsert, rule.UpdatedBy, nil, ruleRow(&stored))
+	return nil
+}
+
+func (r *DepreciationRepo) Delete(categoryID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := slices.IndexFunc(r.s.rules, func(rule *models.DepreciationRule) bool { return rule.CategoryID == categoryID })
+	if i < 0 {
+		return sql.ErrNoRows
+	}
+	rule := r.s.rules[i]
+	r.s.rules = slices.Delete(r.s.rules, i, i+1)
+	r.s.record("depreciation_rules", categoryID, "", models.AuditDelete, nil, ruleRow(rule), nil)
+	return nil
+}
+
+// rules returns copies of the rules matching keep with their category
+// names, ordered by category
+func (r *DepreciationRepo) rules(keep func(*models.DepreciationRule) bool) []*models.DepreciationRule {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.DepreciationRule
+	for _, rule := range r.s.rules {
+		if !keep(rule) {
+			continue
+		}
+		c := *rule
+		c.CategoryName = r.s.category(rule.CategoryID).Description
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CategoryName < out[j].CategoryName })
+	return out
+}
+
+// sameRule reports whether two rules depreciate alike, which is when the
+// audit trigger leaves an update out
+func sameRule(a, b *models.DepreciationRule) bool {
+	return a.Method == b.Method && a.UsefulLifeYears == b.UsefulLifeYears && a.SalvagePercent == b.SalvagePercent &&
+		(a.Factor == nil) == (b.Factor == nil) && (a.Factor == nil || *a.Factor == *b.Factor)
+}
+
+// ruleRow is a depreciation rule as its audit triggers record it
+func ruleRow(rule *models.DepreciationRule) map[string]any {
+	return map[string]any{
+		"category_id": rule.CategoryID, "method": rule.Method, "useful_life_years": rule.UsefulLifeYears,
+		"salvage_percent": rule.SalvagePercent, "factor": rule.Factor, "updated_by": rule.UpdatedBy,
+	}
+}
@@ -0,0 +1,96 @@
+package memrepo
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// EmployeeRepo is the in-memory repo.EmployeeStore
+type EmployeeRepo struct{ s *store }
+
+func (r *EmployeeRepo) List() ([]*models.Employee, error) {
+	return r.where(func(*models.Employee) bool { return true }, -1), nil
+}
+
+func (r *EmployeeRepo) Create(e *models.Employee) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, other := range r.s.employees {
+		if other.Email == e.Email {
+			return uniqueError("employees.email")
+		}
+	}
+	e.EmployeeID = r.s.nextID("employees")
+	stored := *e
+	r.s.employees = append(r.s.employees, &stored)
+	r.s.record("employees", e.EmployeeID, "", models.AuditInsert, e.CreatedBy, nil, map[string]any{
+		"employee_id": e.EmployeeID, "full_name": e.FullName, "email": e.Email, "created_by": e.CreatedBy,
+	})
+	return nil
+}
+
+func (r *EmployeeRepo) Get(employeeID int) (*models.Employee, error) {
+	return r.one(func(e *models.Employee) bool { return e.EmployeeID == employeeID })
+}
+
+func (r *EmployeeRepo) FindByEmail(email string) (*models.Employee, error) {
+	return r.one(func(e *models.Employee) bool { return strings.EqualFold(e.Email, email) })
+}
+
+func (r *EmployeeRepo) FindByName(name string) ([]*models.Employee, error) {
+	out := r.where(func(e *models.Employee) bool { return strings.EqualFold(e.FullName, name) }, -1)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Email < out[j].Email })
+	return out, nil
+}
+
+func (r *EmployeeRepo) Resolve(nameOrEmail string) (*models.Employee, error) {
+	return repo.ResolveEmployee(r, nameOrEmail)
+}
+
+func (r *EmployeeRepo) Search(text string, limit int) ([]*models.Employee, error) {
+	return r.where(func(e *models.Employee) bool {
+		return contains(e.FullName, text) || contains(e.Email, text)
+	}, limit), nil
+}
+
+// where returns copies of up to limit employees matching keep, ordered by
+// name
+func (r *EmployeeRepo) where(keep func(*models.Employee) bool, limit int) []*models.Employee {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.Employee
+	for _, e := range r.s.employees {
+		if keep(e) {
+			c := *e
+			out = append(out, &c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].FullName < out[j].FullName })
+	return limited(out, limit)
+}
+
+// one returns the first employee matching keep
+// Returns sql.ErrNoRows if none does
+func (r *EmployeeRepo) one(keep func(*models.Employee) bool) (*models.Employee, error) {
+	out := r.where(keep, 1)
+	if len(out) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return out[0], nil
+}
+
+// employee returns the stored employee with the given ID, or nil
+func (s *store) employee(employeeID int) *models.Employee {
+	for _, e := range s.employees {
+		if e.EmployeeID == employeeID {
+			return e
+		}
+	}
+	return nil
+}
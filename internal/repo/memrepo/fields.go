@@ -0,0 +1,245 @@
+package memrepo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+// FieldRepo is the in-memory repo.FieldStore
+type FieldRepo struct{ s *store }
+
+func (r *FieldRepo) List() ([]*models.CustomField, error) {
+	return r.fields(func(*models.CustomField) bool { return true }), nil
+}
+
+func (r *FieldRepo) ListForType(typeID int) ([]*models.CustomField, error) {
+	r.s.mu.Lock()
+	t := r.s.assetType(typeID)
+	r.s.mu.Unlock()
+	if t == nil {
+		return nil, nil
+	}
+	return r.fields(func(f *models.CustomField) bool {
+		return (f.TypeID != nil && *f.TypeID == typeID) || (f.CategoryID != nil && *f.CategoryID == t.CategoryID)
+	}), nil
+}
+
+func (r *FieldRepo) Get(fieldID int) (*models.CustomField, error) {
+	return first(r.fields(func(f *models.CustomField) bool { return f.FieldID == fieldID }))
+}
+
+func (r *FieldRepo) Create(f *models.CustomField) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	switch {
+	case f.TypeID != nil && r.s.assetType(*f.TypeID) == nil,
+		f.CategoryID != nil && r.s.category(*f.CategoryID) == nil:
+		return ErrForeignKey
+	case !slices.Contains(models.FieldKinds, f.Kind):
+		return errors.New("CHECK constraint failed: kind IN ('text', 'number', 'date', 'enum')")
+	case (f.TypeID == nil) == (f.CategoryID == nil):
+		return errors.New("CHECK constraint failed: (type_id IS NULL) <> (category_id IS NULL)")
+	case (f.Kind == models.FieldEnum) != (f.Options != nil):
+		return errors.New("CHECK constraint failed: (kind = 'enum') = (options IS NOT NULL)")
+	}
+	for _, other := range r.s.customFields {
+		if !strings.EqualFold(other.Name, f.Name) {
+			continue
+		}
+		if f.TypeID != nil && other.TypeID != nil && *other.TypeID == *f.TypeID {
+			return uniqueError("custom_fields.type_id, custom_fields.name")
+		}
+		if f.CategoryID != nil && other.CategoryID != nil && *other.CategoryID == *f.CategoryID {
+			return uniqueError("custom_fields.category_id, custom_fields.name")
+		}
+	}
+	f.FieldID = r.s.nextID("custom_fields")
+	f.CreatedAt = r.s.timestamp()
+	stored := *f
+	stored.TypeName, stored.CategoryName = nil, ""
+	stored.Options = slices.Clone(f.Options)
+	r.s.customFields = append(r.s.customFields, &stored)
+	r.s.record("custom_fields", f.FieldID, "", models.AuditInsert, f.CreatedBy, nil, fieldRow(&stored))
+	return nil
+}
+
+func (r *FieldRepo) Delete(fieldID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := slices.IndexFunc(r.s.customFields, func(f *models.CustomField) bool { return f.FieldID == fieldID })
+	if i < 0 {
+		return sql.ErrNoRows
+	}
+	r.s.fieldValues = slices.DeleteFunc(r.s.fieldValues, func(v *models.AssetFieldValue) bool {
+		if v.FieldID != fieldID {
+			return false
+		}
+		r.s.record("asset_field_values", v.FieldID, v.AssetID, models.AuditDelete, nil, valueRow(v), nil)
+		return true
+	})
+	f := r.s.customFields[i]
+	r.s.customFields = slices.Delete(r.s.customFields, i, i+1)
+	r.s.record("custom_fields", f.FieldID, "", models.AuditDelete, nil, fieldRow(f), nil)
+	return nil
+}
+
+func (r *FieldRepo) ListValues(assetID string) ([]*models.AssetFieldValue, error) {
+	return r.values(func(v *models.AssetFieldValue) bool { return v.AssetID == assetID }), nil
+}
+
+func (r *FieldRepo) ListAllValues() ([]*models.AssetFieldValue, error) {
+	return r.values(func(*models.AssetFieldValue) bool { return true }), nil
+}
+
+func (r *FieldRepo) SetValue(v *models.AssetFieldValue) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.asset(v.AssetID) == nil || r.s.customField(v.FieldID) == nil {
+		return ErrForeignKey
+	}
+	for _, stored := range r.s.fieldValues {
+		if stored.AssetID != v.AssetID || stored.FieldID != v.FieldID {
+			continue
+		}
+		before := valueRow(stored)
+		changed := stored.Value != v.Value
+		stored.Value, stored.UpdatedBy = v.Value, v.UpdatedBy
+		if changed {
+			r.s.record("asset_field_values", v.FieldID, v.AssetID, models.AuditUpdate, v.UpdatedBy, before, valueRow(stored))
+		}
+		return nil
+	}
+	stored := &models.AssetFieldValue{AssetID: v.AssetID, FieldID: v.FieldID, Value: v.Value, UpdatedBy: v.UpdatedBy}
+	r.s.fieldValues = append(r.s.fieldValues, stored)
+	r.s.record("asset_field_values", v.FieldID, v.AssetID, models.AuditInsert, v.UpdatedBy, nil, valueRow(stored))
+	return nil
+}
+
+func (r *FieldRepo) ClearValue(assetID string, fieldID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	i := slices.IndexFunc(r.s.fieldValues, func(v *models.AssetFieldValue) bool {
+		return v.AssetID == assetID && v.FieldID == fieldID
+	})
+	if i < 0 {
+		return sql.ErrNoRows
+	}
+	v := r.s.fieldValues[i]
+	r.s.fieldValues = slices.Delete(r.s.fieldValues, i, i+1)
+	r.s.record("asset_field_values", v.FieldID, v.AssetID, models.AuditDelete, nil, valueRow(v), nil)
+	return nil
+}
+
+// fields returns copies of the custom fields matching keep with their type
+// and category names, ordered like the SQLite repository orders them
+func (r *FieldRepo) fields(keep func(*models.CustomField) bool) []*models.CustomField {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.CustomField
+	for _, f := range r.s.customFields {
+		if !keep(f) {
+			continue
+		}
+		c := *f
+		c.Options = slices.Clone(f.Options)
+		categoryID := 0
+		if f.TypeID != nil {
+			t := r.s.assetType(*f.TypeID)
+			c.TypeName, categoryID = &t.TypeName, t.CategoryID
+		} else {
+			categoryID = *f.CategoryID
+		}
+		c.CategoryName = r.s.category(categoryID).Description
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.CategoryName != b.CategoryName {
+			return a.CategoryName < b.CategoryName
+		}
+		if (a.TypeName == nil) != (b.TypeName == nil) {
+			return a.TypeName == nil
+		}
+		if a.TypeName != nil && *a.TypeName != *b.TypeName {
+			return *a.TypeName < *b.TypeName
+		}
+		return a.FieldID < b.FieldID
+	})
+	return out
+}
+
+// values returns copies of the field values matching keep with their field
+// names and kinds, ordered by asset, then category fields before type
+// fields
+func (r *FieldRepo) values(keep func(*models.AssetFieldValue) bool) []*models.AssetFieldValue {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.AssetFieldValue
+	fields := map[int]*models.CustomField{}
+	for _, v := range r.s.fieldValues {
+		if !keep(v) {
+			continue
+		}
+		c := *v
+		f := r.s.customField(v.FieldID)
+		c.FieldName, c.Kind = f.Name, f.Kind
+		fields[v.FieldID] = f
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.AssetID != b.AssetID {
+			return a.AssetID < b.AssetID
+		}
+		if fa, fb := fields[a.FieldID].TypeID != nil, fields[b.FieldID].TypeID != nil; fa != fb {
+			return fb
+		}
+		return a.FieldID < b.FieldID
+	})
+	return out
+}
+
+// customField returns the stored custom field with the ID, or nil
+func (s *store) customField(fieldID int) *models.CustomField {
+	for _, f := range s.customFields {
+		if f.FieldID == fieldID {
+			return f
+		}
+	}
+	return nil
+}
+
+// fieldRow is a custom field as its audit triggers record it
+func fieldRow(f *models.CustomField) map[string]any {
+	var options any
+	if f.Options != nil {
+		b, _ := json.Marshal(f.Options)
+		options = string(b)
+	}
+	required := 0
+	if f.Required {
+		required = 1
+	}
+	return map[string]any{
+		"field_id": f.FieldID, "name": f.Name, "kind": f.Kind, "type_id": f.TypeID,
+		"category_id": f.CategoryID, "required": required, "options": options, "pattern": f.Pattern,
+		"min_value": f.Min, "max_value": f.Max, "created_by": f.CreatedBy,
+	}
+}
+
+// valueRow is a custom field value as its audit triggers record it
+func valueRow(v *models.AssetFieldValue) map[string]any {
+	return map[string]any{"asset_id": v.AssetID, "field_id": v.FieldID, "value": v.Value, "updated_by": v.UpdatedBy}
+}
@@ -0,0 +1,357 @@
+package memrepo
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// LocationRepo is the in-memory repo.LocationStore
+type LocationRepo struct{ s *store }
+
+func (r *LocationRepo) List() ([]*models.Location, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.Location
+	for _, l := range r.s.locations {
+		c := *l
+		out = append(out, &c)
+	}
+	return out, nil
+}
+
+func (r *LocationRepo) FindByName(name string) (*models.Location, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, l := range r.s.locations {
+		if strings.EqualFold(l.Name, name) {
+			c := *l
+			return &c, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// location returns the location with the given ID, or nil
+func (s *store) location(locationID int) *models.Location {
+	for _, l := range s.locations {
+		if l.LocationID == locationID {
+			return l
+		}
+	}
+	return nil
+}
+
+// MaintenanceRepo is the in-memory repo.MaintenanceStore
+type MaintenanceRepo struct{ s *store }
+
+func (r *MaintenanceRepo) List() ([]*models.MaintenanceLog, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	out := r.s.listMaintenance("")
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].MaintenanceDate.Equal(out[j].MaintenanceDate) {
+			return out[i].MaintenanceDate.Before(out[j].MaintenanceDate)
+		}
+		return out[i].LogID < out[j].LogID
+	})
+	return out, nil
+}
+
+func (r *MaintenanceRepo) ListByAsset(assetID string) ([]*models.MaintenanceLog, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	out := r.s.listMaintenance(assetID)
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].MaintenanceDate.Equal(out[j].MaintenanceDate) {
+			return out[i].MaintenanceDate.After(out[j].MaintenanceDate)
+		}
+		return out[i].LogID > out[j].LogID
+	})
+	return out, nil
+}
+
+func (r *MaintenanceRepo) Create(m *models.MaintenanceLog) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.asset(m.AssetID) == nil || m.MaintenanceTypeID < 1 || m.MaintenanceTypeID > len(r.s.maintenanceTypes) {
+		return ErrForeignKey
+	}
+	m.LogID = r.s.nextID("maintenance_logs")
+	stored := *m
+	stored.MaintenanceDate = timestamp(m.MaintenanceDate)
+	r.s.maintenance = append(r.s.maintenance, &stored)
+	r.s.record("maintenance_logs", m.LogID, m.AssetID, models.AuditInsert, m.RecordedBy, nil, map[string]any{
+		"log_id": m.LogID, "asset_id": m.AssetID,
+		"maintenance_type_id": m.MaintenanceTypeID, "maintenance_date": stored.MaintenanceDate.Format(repo.TimestampLayout),
+		"cost": m.Cost, "description": m.Description, "performed_by": m.PerformedBy,
+		"recorded_by": m.RecordedBy,
+	})
+	return nil
+}
+
+func (r *MaintenanceRepo) FindType(name string) (int, string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i, t := range r.s.maintenanceTypes {
+		if strings.EqualFold(t, name) {
+			return i + 1, t, nil
+		}
+	}
+	return 0, "", sql.ErrNoRows
+}
+
+// listMaintenance returns copies of the maintenance logs of an asset, or of
+// every asset when assetID is empty, with the asset tag and type name
+func (s *store) listMaintenance(assetID string) []*models.MaintenanceLog {
+	var out []*models.MaintenanceLog
+	for _, m := range s.maintenance {
+		if assetID != "" && m.AssetID != assetID {
+			continue
+		}
+		c := *m
+		c.AssetTag = s.asset(m.AssetID).AssetTag
+		c.TypeName = s.maintenanceTypes[m.MaintenanceTypeID-1]
+		out = append(out, &c)
+	}
+	return out
+}
+
+// LicenseRepo is the in-memory repo.LicenseStore
+type LicenseRepo struct{ s *store }
+
+func (r *LicenseRepo) List() ([]*models.SoftwareLicense, error) {
+	return r.where("", -1), nil
+}
+
+func (r *LicenseRepo) Search(text string, limit int) ([]*models.SoftwareLicense, error) {
+	return r.where(text, limit), nil
+}
+
+func (r *LicenseRepo) ListByAsset(assetID string) ([]*models.LicenseAssignment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.LicenseAssignment
+	for _, a := range r.s.installs {
+		if a.AssetID != assetID {
+			continue
+		}
+		c := *a
+		for _, l := range r.s.licenses {
+			if l.LicenseID == a.LicenseID {
+				c.SoftwareName, c.LicenseType = l.SoftwareName, l.LicenseType
+			}
+		}
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].AssignmentDate.Equal(out[j].AssignmentDate) {
+			return out[i].AssignmentDate.After(out[j].AssignmentDate)
+		}
+		return out[i].AssignmentID > out[j].AssignmentID
+	})
+	return out, nil
+}
+
+func (r *LicenseRepo) Create(l *models.SoftwareLicense) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, other := range r.s.licenses {
+		if other.LicenseKey == l.LicenseKey {
+			return uniqueError("software_licenses.license_key")
+		}
+	}
+	l.LicenseID = r.s.nextID("software_licenses")
+	stored := *l
+	stored.SeatsUsed = 0
+	stored.PurchaseDate = date(l.PurchaseDate)
+	stored.ExpirationDate = nullDate(l.ExpirationDate)
+	r.s.licenses = append(r.s.licenses, &stored)
+	r.s.record("software_licenses", l.LicenseID, "", models.AuditInsert, nil, nil, map[string]any{
+		"license_id": l.LicenseID, "software_name": l.SoftwareName, "license_key": l.LicenseKey,
+		"license_type": l.LicenseType, "seats_purchased": l.SeatsPurchased,
+		"purchase_date": stored.PurchaseDate.Format(repo.DateLayout), "expiration_date": nullString(dateString(stored.ExpirationDate)),
+		"notes": l.Notes,
+	})
+	return nil
+}
+
+func (r *LicenseRepo) Assign(a *models.LicenseAssignment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.asset(a.AssetID) == nil || !r.s.hasLicense(a.LicenseID) {
+		return ErrForeignKey
+	}
+	for _, other := range r.s.installs {
+		if other.LicenseID == a.LicenseID && other.AssetID == a.AssetID && other.RemovalDate == nil {
+			return uniqueError("license_assignments.license_id, license_assignments.asset_id")
+		}
+	}
+	a.AssignmentID = r.s.nextID("license_assignments")
+	stored := *a
+	stored.AssignmentDate = timestamp(a.AssignmentDate)
+	stored.RemovalDate = nil
+	r.s.installs = append(r.s.installs, &stored)
+	r.s.record("license_assignments", a.AssignmentID, a.AssetID, models.AuditInsert, nil, nil, map[string]any{
+		"assignment_id": a.AssignmentID, "license_id": a.LicenseID, "asset_id": a.AssetID,
+		"assignment_date": stored.AssignmentDate.Format(repo.TimestampLayout), "removal_date": nil, "notes": a.Notes,
+	})
+	return nil
+}
+
+// hasLicense reports whether a license with the given ID exists
+func (s *store) hasLicense(licenseID int) bool {
+	for _, l := range s.licenses {
+		if l.LicenseID == licenseID {
+			return true
+		}
+	}
+	return false
+}
+
+// where returns copies of up to limit licenses whose software name
+// contains text, ordered by name, with their seats in use
+func (r *LicenseRepo) where(text string, limit int) []*models.SoftwareLicense {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.SoftwareLicense
+	for _, l := range r.s.licenses {
+		if !contains(l.SoftwareName, text) {
+			continue
+		}
+		c := *l
+		c.SeatsUsed = 0
+		for _, a := range r.s.installs {
+			if a.LicenseID == l.LicenseID && a.RemovalDate == nil {
+				c.SeatsUsed++
+			}
+		}
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].SoftwareName < out[j].SoftwareName })
+	return limited(out, limit)
+}
+
+// ConsumableRepo is the in-memory repo.ConsumableStore
+type ConsumableRepo struct{ s *store }
+
+func (r *ConsumableRepo) ListUsageByAsset(assetID string) ([]*models.ConsumableUsage, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.ConsumableUsage
+	for _, u := range r.s.usage {
+		if u.AssetID != assetID {
+			continue
+		}
+		c := *u
+		for _, t := range r.s.consumables {
+			if t.ConsumableTypeID == u.ConsumableTypeID {
+				c.ConsumableName, c.PartNumber = t.Name, t.PartNumber
+			}
+		}
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].InstallationDate.Equal(out[j].InstallationDate) {
+			return out[i].InstallationDate.After(out[j].InstallationDate)
+		}
+		return out[i].UsageID > out[j].UsageID
+	})
+	return out, nil
+}
+
+func (r *ConsumableRepo) ListTypes() ([]*models.ConsumableType, error) {
+	return r.where(func(*models.ConsumableType) bool { return true }), nil
+}
+
+func (r *ConsumableRepo) ListLowStock() ([]*models.ConsumableType, error) {
+	return r.where(func(c *models.ConsumableType) bool {
+		return c.ReorderLevel > 0 && c.StockQuantity <= c.ReorderLevel
+	}), nil
+}
+
+func (r *ConsumableRepo) CreateType(c *models.ConsumableType) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, other := range r.s.consumables {
+		if other.Name == c.Name {
+			return uniqueError("consumable_types.name")
+		}
+	}
+	c.ConsumableTypeID = r.s.nextID("consumable_types")
+	stored := *c
+	stored.LastPurchaseDate = nullDate(c.LastPurchaseDate)
+	r.s.consumables = append(r.s.consumables, &stored)
+	r.s.record("consumable_types", c.ConsumableTypeID, "", models.AuditInsert, nil, nil, consumableRow(&stored))
+	return nil
+}
+
+func (r *ConsumableRepo) RecordUsage(u *models.ConsumableUsage) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var consumable *models.ConsumableType
+	for _, t := range r.s.consumables {
+		if t.ConsumableTypeID == u.ConsumableTypeID {
+			consumable = t
+		}
+	}
+	if r.s.asset(u.AssetID) == nil || consumable == nil {
+		return ErrForeignKey
+	}
+	u.UsageID = r.s.nextID("consumable_usage")
+	stored := *u
+	stored.InstallationDate = timestamp(u.InstallationDate)
+	r.s.usage = append(r.s.usage, &stored)
+	r.s.record("consumable_usage", u.UsageID, u.AssetID, models.AuditInsert, nil, nil, map[string]any{
+		"usage_id": u.UsageID, "consumable_type_id": u.ConsumableTypeID, "asset_id": u.AssetID,
+		"installation_date": stored.InstallationDate.Format(repo.TimestampLayout), "notes": u.Notes,
+	})
+
+	before := consumableRow(consumable)
+	consumable.StockQuantity = max(consumable.StockQuantity-1, 0)
+	r.s.record("consumable_types", consumable.ConsumableTypeID, "", models.AuditUpdate, nil, before, consumableRow(consumable))
+	return nil
+}
+
+// where returns copies of the consumable types matching keep, ordered by
+// name
+func (r *ConsumableRepo) where(keep func(*models.ConsumableType) bool) []*models.ConsumableType {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.ConsumableType
+	for _, t := range r.s.consumables {
+		if keep(t) {
+			c := *t
+			out = append(out, &c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// consumableRow returns the columns of a consumable type as the audit log
+// records them
+func consumableRow(c *models.ConsumableType) map[string]any {
+	return map[string]any{
+		"consumable_type_id": c.ConsumableTypeID, "name": c.Name, "part_number": c.PartNumber,
+		"manufacturer": c.Manufacturer, "last_purchase_date": nullString(dateString(c.LastPurchaseDate)),
+		"stock_quantity": c.StockQuantity, "reorder_level": c.ReorderLevel,
+	}
+}
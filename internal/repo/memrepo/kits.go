@@ -0,0 +1,290 @@
+package memrepo
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// memKitAsset is a member of a kit
+type memKitAsset struct {
+	assetID string
+	kitID   int
+	addedAt time.Time
+	addedBy *string
+}
+
+// KitRepo is the in-memory repo.KitStore
+type KitRepo struct{ s *store }
+
+func (r *KitRepo) ListTemplates() ([]*models.KitTemplate, error) {
+	return r.templates(func(*models.KitTemplate) bool { return true }), nil
+}
+
+func (r *KitRepo) FindTemplate(name string) (*models.KitTemplate, error) {
+	name = strings.TrimSpace(name)
+	return first(r.templates(func(t *models.KitTemplate) bool { return strings.EqualFold(t.Name, name) }))
+}
+
+func (r *KitRepo) GetTemplate(templateID int) (*models.KitTemplate, error) {
+	return first(r.templates(func(t *models.KitTemplate) bool { return t.TemplateID == templateID }))
+}
+
+func (r *KitRepo) CreateTemplate(t *models.KitTemplate) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, other := range r.s.kitTemplates {
+		if other.Name == t.Name {
+			return uniqueError("kit_templates.name")
+		}
+	}
+	seen := map[int]bool{}
+	for _, i := range t.Items {
+		if r.s.assetType(i.TypeID) == nil {
+			return ErrForeignKey
+		}
+		if seen[i.TypeID] {
+			return uniqueError("kit_template_items.template_id, kit_template_items.type_id")
+		}
+		seen[i.TypeID] = true
+	}
+	t.TemplateID = r.s.nextID("kit_templates")
+	stored := *t
+	stored.Items = append([]models.KitTemplateItem(nil), t.Items...)
+	r.s.kitTemplates = append(r.s.kitTemplates, &stored)
+	return nil
+}
+
+func (r *KitRepo) List() ([]*models.Kit, error) {
+	return r.kits(func(*models.Kit) bool { return true }), nil
+}
+
+func (r *KitRepo) Get(kitID int) (*models.Kit, error) {
+	return first(r.kits(func(k *models.Kit) bool { return k.KitID == kitID }))
+}
+
+func (r *KitRepo) GetByTag(kitTag string) (*models.Kit, error) {
+	return first(r.kits(func(k *models.Kit) bool { return k.KitTag == kitTag }))
+}
+
+func (r *KitRepo) FindByAsset(assetID string) (*models.Kit, error) {
+	r.s.mu.Lock()
+	kitID := 0
+	for _, m := range r.s.kitAssets {
+		if m.assetID == assetID {
+			kitID = m.kitID
+		}
+	}
+	r.s.mu.Unlock()
+	return r.Get(kitID)
+}
+
+func (r *KitRepo) Create(k *models.Kit) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, other := range r.s.kits {
+		if other.KitTag == k.KitTag {
+			return uniqueError("kits.kit_tag")
+		}
+	}
+	if k.TemplateID != nil && r.s.kitTemplate(*k.TemplateID) == nil {
+		return ErrForeignKey
+	}
+	for i, assetID := range k.AssetIDs {
+		if err := r.s.checkKitAsset(assetID); err != nil {
+			return err
+		}
+		for _, other := range k.AssetIDs[:i] {
+			if other == assetID {
+				return uniqueError("kit_assets.asset_id")
+			}
+		}
+	}
+
+	k.KitID = r.s.nextID("kits")
+	k.CreatedAt = r.s.timestamp()
+	stored := *k
+	stored.AssetIDs, stored.TemplateName = nil, nil
+	r.s.kits = append(r.s.kits, &stored)
+	r.s.record("kits", k.KitID, "", models.AuditInsert, k.CreatedBy, nil, kitRow(&stored))
+	for _, assetID := range k.AssetIDs {
+		r.s.addKitAsset(k.KitID, assetID, k.CreatedBy)
+	}
+	return nil
+}
+
+func (r *KitRepo) AddAsset(kitID int, assetID string, actor *string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.kit(kitID) == nil {
+		return ErrForeignKey
+	}
+	if err := r.s.checkKitAsset(assetID); err != nil {
+		return err
+	}
+	r.s.addKitAsset(kitID, assetID, actor)
+	return nil
+}
+
+func (r *KitRepo) RemoveAsset(kitID int, assetID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i, m := range r.s.kitAssets {
+		if m.kitID == kitID && m.assetID == assetID {
+			r.s.removeKitAsset(i)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *KitRepo) Delete(kitID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i := len(r.s.kitAssets) - 1; i >= 0; i-- {
+		if r.s.kitAssets[i].kitID == kitID {
+			r.s.removeKitAsset(i)
+		}
+	}
+	for i, k := range r.s.kits {
+		if k.KitID == kitID {
+			r.s.kits = append(r.s.kits[:i], r.s.kits[i+1:]...)
+			r.s.record("kits", kitID, "", models.AuditDelete, nil, kitRow(k), nil)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// templates returns copies of the kit templates matching keep, ordered by
+// name, with their items ordered by type name
+func (r *KitRepo) templates(keep func(*models.KitTemplate) bool) []*models.KitTemplate {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.KitTemplate
+	for _, t := range r.s.kitTemplates {
+		if !keep(t) {
+			continue
+		}
+		c := *t
+		c.Items = nil
+		for _, i := range t.Items {
+			i.TypeName = r.s.assetType(i.TypeID).TypeName
+			c.Items = append(c.Items, i)
+		}
+		sort.SliceStable(c.Items, func(i, j int) bool { return c.Items[i].TypeName < c.Items[j].TypeName })
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// kits returns copies of the kits matching keep, ordered by tag, with their
+// members ordered by asset tag
+func (r *KitRepo) kits(keep func(*models.Kit) bool) []*models.Kit {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.Kit
+	for _, k := range r.s.kits {
+		if !keep(k) {
+			continue
+		}
+		c := *k
+		if k.TemplateID != nil {
+			c.TemplateName = &r.s.kitTemplate(*k.TemplateID).Name
+		}
+		for _, m := range r.s.kitAssets {
+			if m.kitID == k.KitID {
+				c.AssetIDs = append(c.AssetIDs, m.assetID)
+			}
+		}
+		sort.SliceStable(c.AssetIDs, func(i, j int) bool {
+			return r.s.asset(c.AssetIDs[i]).AssetTag < r.s.asset(c.AssetIDs[j]).AssetTag
+		})
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].KitTag < out[j].KitTag })
+	return out
+}
+
+// kit returns the stored kit with the ID, or nil
+func (s *store) kit(kitID int) *models.Kit {
+	for _, k := range s.kits {
+		if k.KitID == kitID {
+			return k
+		}
+	}
+	return nil
+}
+
+// kitTemplate returns the stored kit template with the ID, or nil
+func (s *store) kitTemplate(templateID int) *models.KitTemplate {
+	for _, t := range s.kitTemplates {
+		if t.TemplateID == templateID {
+			return t
+		}
+	}
+	return nil
+}
+
+// checkKitAsset returns the error adding an asset to a kit fails with, if
+// any
+func (s *store) checkKitAsset(assetID string) error {
+	if s.asset(assetID) == nil {
+		return ErrForeignKey
+	}
+	for _, m := range s.kitAssets {
+		if m.assetID == assetID {
+			return uniqueError("kit_assets.asset_id")
+		}
+	}
+	return nil
+}
+
+func (s *store) addKitAsset(kitID int, assetID string, actor *string) {
+	m := &memKitAsset{assetID: assetID, kitID: kitID, addedAt: s.timestamp(), addedBy: actor}
+	s.kitAssets = append(s.kitAssets, m)
+	s.record("kit_assets", assetID, assetID, models.AuditInsert, actor, nil, kitAssetRow(m))
+}
+
+func (s *store) removeKitAsset(i int) {
+	m := s.kitAssets[i]
+	s.kitAssets = append(s.kitAssets[:i], s.kitAssets[i+1:]...)
+	s.record("kit_assets", m.assetID, m.assetID, models.AuditDelete, nil, kitAssetRow(m), nil)
+}
+
+// first returns the first of rows, or sql.ErrNoRows when there are none
+func first[T any](rows []*T) (*T, error) {
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return rows[0], nil
+}
+
+// kitRow returns the columns of a kit as the audit log records them
+func kitRow(k *models.Kit) map[string]any {
+	return map[string]any{
+		"kit_id": k.KitID, "kit_tag": k.KitTag, "template_id": k.TemplateID, "notes": k.Notes,
+		"created_by": k.CreatedBy,
+	}
+}
+
+// kitAssetRow returns the columns of a kit member as the audit log records
+// them
+func kitAssetRow(m *memKitAsset) map[string]any {
+	return map[string]any{
+		"asset_id": m.assetID, "kit_id": m.kitID, "added_at": m.addedAt.Format(repo.TimestampLayout),
+		"added_by": m.addedBy,
+	}
+}
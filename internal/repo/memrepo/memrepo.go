@@ -0,0 +1,309 @@
+// Package memrepo implements the repository interfaces in memory, for tests
+// that should not need a database file
+// The stores follow the SQLite repositories, including their errors, the
+// catalogs seeded by the schema and the audit log kept by the triggers
+package memrepo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// ErrForeignKey mirrors the error SQLite returns for a row referencing a
+// missing record
+var ErrForeignKey = errors.New("FOREIGN KEY constraint failed")
+
+// uniqueError mirrors the error SQLite returns for a duplicate value
+func uniqueError(column string) error {
+	return errors.New("UNIQUE constraint failed: " + column)
+}
+
+// store holds the tables shared by the stores returned by New
+type store struct {
+	mu   sync.Mutex
+	txMu sync.Mutex // Held by a unit of work, which runs alone
+	now  func() time.Time
+
+	categories       []*models.AssetCategory
+	types            []*models.AssetType
+	statuses         []*models.AssetStatus
+	locations        []*models.Location
+	maintenanceTypes []string // Indexed by maintenance type ID - 1
+
+	tables
+}
+
+// tables holds the rows a unit of work can change
+type tables struct {
+	assets       []*models.Asset
+	assignments  []*models.AssetAssignment
+	transfers    []*models.AssetTransfer
+	maintenance  []*models.MaintenanceLog
+	licenses     []*models.SoftwareLicense
+	installs     []*models.LicenseAssignment
+	consumables  []*models.ConsumableType
+	usage        []*models.ConsumableUsage
+	comments     []*models.AssetComment
+	attachments  []*models.AssetAttachment
+	employees    []*models.Employee
+	kitTemplates []*models.KitTemplate
+	kits         []*models.Kit
+	kitAssets    []*memKitAsset
+	relations    []*models.AssetRelation
+	stockItems   []*models.StockItem
+	movements    []*models.StockMovement
+	customFields []*models.CustomField
+	fieldValues  []*models.AssetFieldValue
+	rules        []*models.DepreciationRule
+	views        []*models.SavedView
+	users        []*memUser
+	tokens       []*memToken
+	audit        []*models.AuditEntry
+
+	lastID map[string]int // Last integer key handed out by table
+}
+
+// New returns empty stores holding the catalogs seeded by the schema
+func New() *repo.Stores {
+	s := &store{now: func() time.Time { return time.Now().UTC() }, tables: tables{lastID: map[string]int{}}}
+	s.seed()
+	stores := s.stores()
+	tx := s.stores()
+	tx.Tx = func(ctx context.Context, fn func(tx *repo.Stores) error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(tx)
+	}
+	stores.Tx = func(ctx context.Context, fn func(tx *repo.Stores) error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s.txMu.Lock()
+		defer s.txMu.Unlock()
+
+		saved := s.snapshot()
+		if err := fn(tx); err != nil {
+			s.restore(saved)
+			return err
+		}
+		return nil
+	}
+	return stores
+}
+
+// stores returns a store of each kind over s
+func (s *store) stores() *repo.Stores {
+	return &repo.Stores{
+		Assets:       &AssetRepo{s},
+		Assignments:  &AssignmentRepo{s},
+		Audit:        &AuditRepo{s},
+		Consumables:  &ConsumableRepo{s},
+		Depreciation: &DepreciationRepo{s},
+		Employees:    &EmployeeRepo{s},
+		Fields:       &FieldRepo{s},
+		Kits:         &KitRepo{s},
+		Licenses:     &LicenseRepo{s},
+		Locations:    &LocationRepo{s},
+		Maintenance:  &MaintenanceRepo{s},
+		Notes:        &NoteRepo{s},
+		Relations:    &RelationRepo{s},
+		Stock:        &StockRepo{s},
+		Transfers:    &TransferRepo{s},
+		Users:        &UserRepo{s},
+		Views:        &ViewRepo{s},
+	}
+}
+
+// snapshot returns a copy of the tables that restore can bring back
+func (s *store) snapshot() tables {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := tables{
+		assets:       clone(s.assets),
+		assignments:  clone(s.assignments),
+		transfers:    clone(s.transfers),
+		maintenance:  clone(s.maintenance),
+		licenses:     clone(s.licenses),
+		installs:     clone(s.installs),
+		consumables:  clone(s.consumables),
+		usage:        clone(s.usage),
+		comments:     clone(s.comments),
+		attachments:  clone(s.attachments),
+		employees:    clone(s.employees),
+		kitTemplates: clone(s.kitTemplates),
+		kits:         clone(s.kits),
+		kitAssets:    clone(s.kitAssets),
+		relations:    clone(s.relations),
+		stockItems:   clone(s.stockItems),
+		movements:    clone(s.movements),
+		customFields: clone(s.customFields),
+		fieldValues:  clone(s.fieldValues),
+		rules:        clone(s.rules),
+		views:        clone(s.views),
+		users:        clone(s.users),
+		tokens:       clone(s.tokens),
+		audit:        clone(s.audit),
+		lastID:       map[string]int{},
+	}
+	for table, id := range s.lastID {
+		t.lastID[table] = id
+	}
+	return t
+}
+
+// restore replaces the tables with a snapshot, undoing the changes made
+// since it was taken
+func (s *store) restore(t tables) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tables = t
+}
+
+// clone copies rows, so changes made in place to the originals do not
+// reach the copies
+func clone[T any](rows []*T) []*T {
+	out := make([]*T, len(rows))
+	for i, r := range rows {
+		c := *r
+		out[i] = &c
+	}
+	return out
+}
+
+// nextID returns the next integer key of table
+func (s *store) nextID(table string) int {
+	s.lastID[table]++
+	return s.lastID[table]
+}
+
+// timestamp returns the current time as SQLite's datetime('now') stores it
+func (s *store) timestamp() time.Time {
+	return timestamp(s.now())
+}
+
+// timestamp truncates t the way storing it as a TimestampLayout string does
+func timestamp(t time.Time) time.Time {
+	out, _ := time.Parse(repo.TimestampLayout, t.Format(repo.TimestampLayout))
+	return out
+}
+
+// date truncates t the way storing it as a DateLayout string does
+func date(t time.Time) time.Time {
+	out, _ := time.Parse(repo.DateLayout, t.Format(repo.DateLayout))
+	return out
+}
+
+// nullDate is date for optional dates
+func nullDate(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	d := date(*t)
+	return &d
+}
+
+// dateString formats an optional date as stored, empty when nil
+func dateString(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(repo.DateLayout)
+}
+
+// contains reports whether text contains sub ignoring ASCII case, like a
+// LIKE '%sub%' pattern with % and _ escaped
+func contains(text, sub string) bool {
+	return strings.Contains(strings.ToLower(text), strings.ToLower(sub))
+}
+
+// limited truncates out to limit rows; a negative limit keeps every row,
+// as with SQLite's LIMIT
+func limited[T any](out []T, limit int) []T {
+	if limit >= 0 && len(out) > limit {
+		return out[:limit]
+	}
+	return out
+}
+
+// record appends an audit entry for a row of entity
+// Updates that change nothing are skipped, as the triggers do
+func (s *store) record(entity string, id any, assetID string, operation string, actor *string, before, after map[string]any) {
+	e := &models.AuditEntry{
+		Entity:    entity,
+		EntityID:  fmtID(id),
+		Operation: operation,
+		Actor:     actor,
+		ChangedAt: s.timestamp(),
+	}
+	if assetID != "" {
+		e.AssetID = &assetID
+	}
+	if before != nil {
+		e.Before, _ = json.Marshal(before)
+	}
+	if after != nil {
+		e.After, _ = json.Marshal(after)
+	}
+	if operation == models.AuditUpdate && string(e.Before) == string(e.After) {
+		return
+	}
+	e.AuditID = s.nextID("audit_log")
+	s.audit = append(s.audit, e)
+}
+
+// fmtID formats a primary key as the audit log stores it
+func fmtID(id any) string {
+	switch v := id.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case string:
+		return v
+	}
+	return ""
+}
+
+// seed adds the catalog rows inserted by the initial schema
+func (s *store) seed() {
+	categories := []struct {
+		prefix, description string
+		types               []string
+	}{
+		{"EQ", "Computer Equipment", []string{"Laptop", "Desktop", "All-in-One", "Mini PC", "Workstation", "Thin Client"}},
+		{"PR", "Printers and Multifunction Devices", []string{"Laser Printer", "Inkjet Printer", "Multifunction Printer", "Label Printer", "Plotter"}},
+		{"SC", "Scanners", []string{"Document Scanner", "Flatbed Scanner", "Barcode Scanner", "ID Scanner"}},
+		{"NT", "Network Devices", []string{"Router", "Switch", "Firewall", "Access Point", "Network Appliance", "Modem"}},
+		{"MD", "Mobile Devices", []string{"Smartphone", "Tablet", "Rugged Device", "Handheld Terminal"}},
+		{"MS", "Monitors and Screens", []string{"Monitor", "TV Display", "Digital Signage Display"}},
+		{"SR", "Servers", []string{"Rack Server", "Tower Server", "Blade Server", "Microserver"}},
+		{"SD", "Storage Devices", []string{"External HDD", "External SSD", "Flash Drive", "Memory Card", "NAS"}},
+		{"AC", "Accessories", []string{"Keyboard", "Mouse", "Headset", "Webcam", "Docking Station", "Power Adapter"}},
+		{"AV", "Audio and Video Equipment", []string{"Projector", "Conference Speaker", "Conference Camera", "Microphone", "Mixer", "Amplifier"}},
+		{"TI", "Tools and Infrastructure", []string{"Network Tools", "Electrical Tools", "Server Rack", "Patch Panel", "Cable Tester", "Tool Kit", "Label Maker"}},
+		{"PE", "Power Equipment", []string{"UPS", "NoBreak", "Voltage Regulator", "Surge Protector", "Power Strip", "PDU"}},
+	}
+	for _, c := range categories {
+		id := s.nextID("asset_categories")
+		s.categories = append(s.categories, &models.AssetCategory{CategoryId: id, CodePrefix: c.prefix, Description: c.description})
+		for _, t := range c.types {
+			s.types = append(s.types, &models.AssetType{TypeID: s.nextID("asset_types"), CategoryID: id, TypeName: t})
+		}
+	}
+	for _, name := range []string{"Assigned", "Available", "Under Maintenance", "Retired"} {
+		s.statuses = append(s.statuses, &models.AssetStatus{StatusID: s.nextID("asset_statuses"), StatusName: name})
+	}
+	for _, l := range [][2]string{{"Main", "Local"}, {"Remote", "Remote"}} {
+		s.locations = append(s.locations, &models.Location{LocationID: s.nextID("locations"), Name: l[0], Type: l[1]})
+	}
+	s.maintenanceTypes = []string{"Preventive", "Corrective", "Upgrade"}
+}
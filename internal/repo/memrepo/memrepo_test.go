@@ -0,0 +1,13 @@
+package memrepo_test
+
+import (
+	"testing"
+
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/repo/memrepo"
+	"github.com/MawCeron/it-room/internal/repo/repotest"
+)
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(*testing.T) *repo.Stores { return memrepo.New() })
+}
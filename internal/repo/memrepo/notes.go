@@ -0,0 +1,141 @@
+package memrepo
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// NoteRepo is the in-memory repo.NoteStore
+type NoteRepo struct{ s *store }
+
+func (r *NoteRepo) ListComments(assetID string) ([]*models.AssetComment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.AssetComment
+	for _, c := range r.s.comments {
+		if c.AssetID == assetID {
+			cc := *c
+			out = append(out, &cc)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].CommentID > out[j].CommentID
+	})
+	return out, nil
+}
+
+func (r *NoteRepo) AddComment(c *models.AssetComment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.asset(c.AssetID) == nil {
+		return ErrForeignKey
+	}
+	c.CommentID = r.s.nextID("asset_comments")
+	c.CreatedAt = r.s.timestamp()
+	stored := *c
+	r.s.comments = append(r.s.comments, &stored)
+	r.s.record("asset_comments", c.CommentID, c.AssetID, models.AuditInsert, c.Author, nil, map[string]any{
+		"comment_id": c.CommentID, "asset_id": c.AssetID, "body": c.Body,
+		"author": c.Author, "created_at": c.CreatedAt.Format(repo.TimestampLayout),
+	})
+	return nil
+}
+
+func (r *NoteRepo) ListAttachments(assetID string) ([]*models.AssetAttachment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.AssetAttachment
+	for _, a := range r.s.attachments {
+		if a.AssetID == assetID {
+			c := *a
+			out = append(out, &c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].AddedAt.Equal(out[j].AddedAt) {
+			return out[i].AddedAt.After(out[j].AddedAt)
+		}
+		return out[i].AttachmentID > out[j].AttachmentID
+	})
+	return out, nil
+}
+
+func (r *NoteRepo) AddAttachment(a *models.AssetAttachment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.asset(a.AssetID) == nil {
+		return ErrForeignKey
+	}
+	if a.FileName == "" {
+		a.FileName = filepath.Base(a.FilePath)
+	}
+	a.AttachmentID = r.s.nextID("asset_attachments")
+	a.AddedAt = r.s.timestamp()
+	stored := *a
+	r.s.attachments = append(r.s.attachments, &stored)
+	r.s.record("asset_attachments", a.AttachmentID, a.AssetID, models.AuditInsert, a.AddedBy, nil, map[string]any{
+		"attachment_id": a.AttachmentID, "asset_id": a.AssetID,
+		"file_name": a.FileName, "file_path": a.FilePath, "added_at": a.AddedAt.Format(repo.TimestampLayout),
+		"notes": a.Notes, "added_by": a.AddedBy,
+	})
+	return nil
+}
+
+// ViewRepo is the in-memory repo.ViewStore
+type ViewRepo struct{ s *store }
+
+func (r *ViewRepo) List() ([]*models.SavedView, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.SavedView
+	for _, v := range r.s.views {
+		c := *v
+		c.Columns = append([]string(nil), v.Columns...)
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (r *ViewRepo) Save(v *models.SavedView) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := *v
+	stored.Columns = append([]string(nil), v.Columns...)
+	for i, other := range r.s.views {
+		if other.Name == v.Name {
+			v.ViewID, stored.ViewID = other.ViewID, other.ViewID
+			r.s.views[i] = &stored
+			return nil
+		}
+	}
+	v.ViewID = r.s.nextID("saved_views")
+	stored.ViewID = v.ViewID
+	r.s.views = append(r.s.views, &stored)
+	return nil
+}
+
+func (r *ViewRepo) Delete(viewID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i, v := range r.s.views {
+		if v.ViewID == viewID {
+			r.s.views = append(r.s.views[:i], r.s.views[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
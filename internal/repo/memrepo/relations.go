@@ -0,0 +1,87 @@
+package memrepo
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"sort"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+// RelationRepo is the in-memory repo.RelationStore
+type RelationRepo struct{ s *store }
+
+func (r *RelationRepo) GetParent(childID string) (*models.AssetRelation, error) {
+	return first(r.where(func(rel *models.AssetRelation) bool { return rel.ChildID == childID }))
+}
+
+func (r *RelationRepo) ListChildren(parentID string) ([]*models.AssetRelation, error) {
+	return r.where(func(rel *models.AssetRelation) bool { return rel.ParentID == parentID }), nil
+}
+
+func (r *RelationRepo) Create(rel *models.AssetRelation) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.asset(rel.ChildID) == nil || r.s.asset(rel.ParentID) == nil {
+		return ErrForeignKey
+	}
+	if !slices.Contains(models.AssetRelations, rel.Relation) {
+		return errors.New("CHECK constraint failed: relation IN ('attached-to', 'installed-in', 'powered-by')")
+	}
+	if rel.ChildID == rel.ParentID {
+		return errors.New("CHECK constraint failed: child_id <> parent_id")
+	}
+	for _, other := range r.s.relations {
+		if other.ChildID == rel.ChildID {
+			return uniqueError("asset_relations.child_id")
+		}
+	}
+	rel.CreatedAt = r.s.timestamp()
+	stored := *rel
+	stored.ChildTag, stored.ParentTag = "", ""
+	r.s.relations = append(r.s.relations, &stored)
+	r.s.record("asset_relations", rel.ChildID, rel.ChildID, models.AuditInsert, rel.CreatedBy, nil, relationRow(&stored))
+	return nil
+}
+
+func (r *RelationRepo) Delete(childID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i, rel := range r.s.relations {
+		if rel.ChildID == childID {
+			r.s.relations = append(r.s.relations[:i], r.s.relations[i+1:]...)
+			r.s.record("asset_relations", childID, childID, models.AuditDelete, nil, relationRow(rel), nil)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// where returns copies of the relations matching keep with their asset
+// tags, ordered by child asset tag
+func (r *RelationRepo) where(keep func(*models.AssetRelation) bool) []*models.AssetRelation {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.AssetRelation
+	for _, rel := range r.s.relations {
+		if keep(rel) {
+			c := *rel
+			c.ChildTag, c.ParentTag = r.s.asset(rel.ChildID).AssetTag, r.s.asset(rel.ParentID).AssetTag
+			out = append(out, &c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ChildTag < out[j].ChildTag })
+	return out
+}
+
+// relationRow returns the columns of a relation as the audit log records
+// them
+func relationRow(rel *models.AssetRelation) map[string]any {
+	return map[string]any{
+		"child_id": rel.ChildID, "parent_id": rel.ParentID, "relation": rel.Relation, "created_by": rel.CreatedBy,
+	}
+}
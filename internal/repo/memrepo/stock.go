@@ -0,0 +1,214 @@
+package memrepo
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// StockRepo is the in-memory repo.StockStore
+type StockRepo struct{ s *store }
+
+func (r *StockRepo) ListItems() ([]*models.StockItem, error) {
+	return r.items(func(*models.StockItem) bool { return true }), nil
+}
+
+func (r *StockRepo) GetItem(itemID int) (*models.StockItem, error) {
+	return first(r.items(func(i *models.StockItem) bool { return i.ItemID == itemID }))
+}
+
+func (r *StockRepo) FindItem(name string) (*models.StockItem, error) {
+	name = strings.TrimSpace(name)
+	return first(r.items(func(i *models.StockItem) bool { return strings.EqualFold(i.Name, name) }))
+}
+
+func (r *StockRepo) CreateItem(i *models.StockItem) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, other := range r.s.stockItems {
+		if strings.EqualFold(other.Name, i.Name) {
+			return uniqueError("stock_items.name")
+		}
+	}
+	if i.TypeID != nil && r.s.assetType(*i.TypeID) == nil {
+		return ErrForeignKey
+	}
+	if i.ReorderLevel < 0 {
+		return errors.New("CHECK constraint failed: reorder_level >= 0")
+	}
+	i.ItemID = r.s.nextID("stock_items")
+	i.CreatedAt, i.OnHand, i.Issued = r.s.timestamp(), 0, 0
+	stored := *i
+	stored.TypeName = nil
+	r.s.stockItems = append(r.s.stockItems, &stored)
+	r.s.record("stock_items", i.ItemID, "", models.AuditInsert, i.CreatedBy, nil, map[string]any{
+		"item_id": i.ItemID, "name": i.Name, "type_id": i.TypeID, "reorder_level": i.ReorderLevel,
+		"notes": i.Notes, "created_by": i.CreatedBy,
+	})
+	return nil
+}
+
+func (r *StockRepo) ListLevels(itemID int) ([]*models.StockLevel, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	byLocation := map[int]*models.StockLevel{}
+	var out []*models.StockLevel
+	for _, m := range r.s.movements {
+		if m.ItemID != itemID {
+			continue
+		}
+		l := byLocation[m.LocationID]
+		if l == nil {
+			l = &models.StockLevel{ItemID: itemID, ItemName: r.s.stockItem(itemID).Name,
+				LocationID: m.LocationID, LocationName: r.s.location(m.LocationID).Name}
+			byLocation[m.LocationID] = l
+			out = append(out, l)
+		}
+		l.Quantity += m.Quantity
+	}
+	out = slices.DeleteFunc(out, func(l *models.StockLevel) bool { return l.Quantity == 0 })
+	sort.SliceStable(out, func(i, j int) bool { return out[i].LocationName < out[j].LocationName })
+	return out, nil
+}
+
+func (r *StockRepo) ListHoldings(itemID int) ([]*models.StockHolding, error) {
+	out := r.holdings(func(m *models.StockMovement) bool { return m.ItemID == itemID })
+	sort.SliceStable(out, func(i, j int) bool { return out[i].EmployeeName < out[j].EmployeeName })
+	return out, nil
+}
+
+func (r *StockRepo) ListHoldingsByEmployee(employeeID int) ([]*models.StockHolding, error) {
+	out := r.holdings(func(m *models.StockMovement) bool { return m.EmployeeID != nil && *m.EmployeeID == employeeID })
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ItemName < out[j].ItemName })
+	return out, nil
+}
+
+func (r *StockRepo) ListMovements(itemID int) ([]*models.StockMovement, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.StockMovement
+	for _, m := range r.s.movements {
+		if m.ItemID != itemID {
+			continue
+		}
+		c := *m
+		c.ItemName, c.LocationName = r.s.stockItem(m.ItemID).Name, r.s.location(m.LocationID).Name
+		if m.EmployeeID != nil {
+			c.EmployeeName = &r.s.employee(*m.EmployeeID).FullName
+		}
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].MovedAt.Equal(out[j].MovedAt) {
+			return out[i].MovedAt.After(out[j].MovedAt)
+		}
+		return out[i].MovementID > out[j].MovementID
+	})
+	return out, nil
+}
+
+func (r *StockRepo) RecordMovement(m *models.StockMovement) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.stockItem(m.ItemID) == nil || r.s.location(m.LocationID) == nil ||
+		(m.EmployeeID != nil && r.s.employee(*m.EmployeeID) == nil) {
+		return ErrForeignKey
+	}
+	switch {
+	case m.Kind != models.StockAdjust && m.Kind != models.StockIssue && m.Kind != models.StockReturn:
+		return errors.New("CHECK constraint failed: kind IN ('adjust', 'issue', 'return')")
+	case m.Quantity == 0:
+		return errors.New("CHECK constraint failed: quantity <> 0")
+	case (m.Kind == models.StockAdjust) != (m.EmployeeID == nil):
+		return errors.New("CHECK constraint failed: (kind = 'adjust') = (employee_id IS NULL)")
+	case m.Kind == models.StockIssue && m.Quantity > 0:
+		return errors.New("CHECK constraint failed: kind <> 'issue' OR quantity < 0")
+	case m.Kind == models.StockReturn && m.Quantity < 0:
+		return errors.New("CHECK constraint failed: kind <> 'return' OR quantity > 0")
+	}
+	m.MovementID = r.s.nextID("stock_movements")
+	stored := *m
+	stored.MovedAt = timestamp(m.MovedAt)
+	stored.ItemName, stored.LocationName, stored.EmployeeName = "", "", nil
+	r.s.movements = append(r.s.movements, &stored)
+	r.s.record("stock_movements", m.MovementID, "", models.AuditInsert, m.MovedBy, nil, map[string]any{
+		"movement_id": m.MovementID, "item_id": m.ItemID, "location_id": m.LocationID, "kind": m.Kind,
+		"quantity": m.Quantity, "employee_id": m.EmployeeID,
+		"moved_at": stored.MovedAt.Format(repo.TimestampLayout), "notes": m.Notes, "moved_by": m.MovedBy,
+	})
+	return nil
+}
+
+// items returns copies of the stock items matching keep with their type
+// names and totals, ordered by name
+func (r *StockRepo) items(keep func(*models.StockItem) bool) []*models.StockItem {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.StockItem
+	for _, i := range r.s.stockItems {
+		if !keep(i) {
+			continue
+		}
+		c := *i
+		if i.TypeID != nil {
+			c.TypeName = &r.s.assetType(*i.TypeID).TypeName
+		}
+		for _, m := range r.s.movements {
+			if m.ItemID != i.ItemID {
+				continue
+			}
+			c.OnHand += m.Quantity
+			if m.Kind != models.StockAdjust {
+				c.Issued -= m.Quantity
+			}
+		}
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// holdings sums the issues and returns matching keep by item and employee,
+// keeping the employees left holding units
+func (r *StockRepo) holdings(keep func(*models.StockMovement) bool) []*models.StockHolding {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	type key struct{ item, employee int }
+	byKey := map[key]*models.StockHolding{}
+	var out []*models.StockHolding
+	for _, m := range r.s.movements {
+		if m.EmployeeID == nil || !keep(m) {
+			continue
+		}
+		k := key{m.ItemID, *m.EmployeeID}
+		h := byKey[k]
+		if h == nil {
+			h = &models.StockHolding{ItemID: m.ItemID, ItemName: r.s.stockItem(m.ItemID).Name,
+				EmployeeID: *m.EmployeeID, EmployeeName: r.s.employee(*m.EmployeeID).FullName}
+			byKey[k] = h
+			out = append(out, h)
+		}
+		h.Quantity -= m.Quantity
+	}
+	return slices.DeleteFunc(out, func(h *models.StockHolding) bool { return h.Quantity <= 0 })
+}
+
+// stockItem returns the stored stock item with the ID, or nil
+func (s *store) stockItem(itemID int) *models.StockItem {
+	for _, i := range s.stockItems {
+		if i.ItemID == itemID {
+			return i
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package memrepo
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// memUser is a stored user with their password hash
+type memUser struct {
+	models.User
+	hash string
+}
+
+// memToken is a stored API token with the hash of its secret
+type memToken struct {
+	models.APIToken
+	hash string
+}
+
+// UserRepo is the in-memory repo.UserStore
+type UserRepo struct{ s *store }
+
+func (r *UserRepo) Count() (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return len(r.s.users), nil
+}
+
+func (r *UserRepo) List() ([]*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.User
+	for _, u := range r.s.users {
+		c := u.User
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return strings.ToLower(out[i].Username) < strings.ToLower(out[j].Username)
+	})
+	return out, nil
+}
+
+func (r *UserRepo) FindByUsername(username string) (*models.User, string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, u := range r.s.users {
+		if strings.EqualFold(u.Username, username) {
+			c := u.User
+			return &c, u.hash, nil
+		}
+	}
+	return nil, "", sql.ErrNoRows
+}
+
+func (r *UserRepo) Create(u *models.User, passwordHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if !validRole(u.Role) {
+		return errors.New("CHECK constraint failed: role IN ('viewer', 'technician', 'admin')")
+	}
+	for _, other := range r.s.users {
+		if strings.EqualFold(other.Username, u.Username) {
+			return uniqueError("users.username")
+		}
+	}
+	u.UserID = r.s.nextID("users")
+	u.CreatedAt = r.s.timestamp()
+	r.s.users = append(r.s.users, &memUser{User: *u, hash: passwordHash})
+	return nil
+}
+
+func (r *UserRepo) SetPassword(userID int, passwordHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u := r.s.user(userID)
+	if u == nil {
+		return sql.ErrNoRows
+	}
+	u.hash = passwordHash
+	return nil
+}
+
+func (r *UserRepo) SetRole(userID int, role string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u := r.s.user(userID)
+	if u == nil {
+		return sql.ErrNoRows
+	}
+	if !validRole(role) {
+		return errors.New("CHECK constraint failed: role IN ('viewer', 'technician', 'admin')")
+	}
+	if u.Role == models.RoleAdmin && role != models.RoleAdmin && r.s.admins() == 1 {
+		return repo.ErrLastAdmin
+	}
+	u.Role = role
+	return nil
+}
+
+func (r *UserRepo) Delete(userID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u := r.s.user(userID)
+	if u == nil {
+		return sql.ErrNoRows
+	}
+	if u.Role == models.RoleAdmin && r.s.admins() == 1 && len(r.s.users) > 1 {
+		return repo.ErrLastAdmin
+	}
+
+	var users []*memUser
+	for _, other := range r.s.users {
+		if other != u {
+			users = append(users, other)
+		}
+	}
+	var tokens []*memToken
+	for _, t := range r.s.tokens {
+		if t.UserID != userID {
+			tokens = append(tokens, t)
+		}
+	}
+	r.s.users, r.s.tokens = users, tokens
+	return nil
+}
+
+func (r *UserRepo) CreateToken(t *models.APIToken, tokenHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.user(t.UserID) == nil {
+		return ErrForeignKey
+	}
+	for _, other := range r.s.tokens {
+		if other.hash == tokenHash {
+			return uniqueError("api_tokens.token_hash")
+		}
+	}
+	t.TokenID = r.s.nextID("api_tokens")
+	t.CreatedAt = r.s.timestamp()
+	r.s.tokens = append(r.s.tokens, &memToken{APIToken: *t, hash: tokenHash})
+	return nil
+}
+
+func (r *UserRepo) FindByToken(tokenHash string) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, t := range r.s.tokens {
+		if t.hash == tokenHash {
+			used := r.s.timestamp()
+			t.LastUsedAt = &used
+			c := r.s.user(t.UserID).User
+			return &c, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *UserRepo) ListTokens() ([]*models.APIToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var out []*models.APIToken
+	for _, t := range r.s.tokens {
+		c := t.APIToken
+		c.Username = r.s.user(t.UserID).Username
+		out = append(out, &c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].TokenID < out[j].TokenID
+	})
+	return out, nil
+}
+
+func (r *UserRepo) DeleteToken(tokenID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i, t := range r.s.tokens {
+		if t.TokenID == tokenID {
+			r.s.tokens = append(r.s.tokens[:i], r.s.tokens[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// user returns the stored user with the given ID, or nil
+func (s *store) user(userID int) *memUser {
+	for _, u := range s.users {
+		if u.UserID == userID {
+			return u
+		}
+	}
+	return nil
+}
+
+// admins returns how many users are admins
+func (s *store) admins() int {
+	n := 0
+	for _, u := range s.users {
+		if u.Role == models.RoleAdmin {
+			n++
+		}
+	}
+	return n
+}
+
+// validRole reports whether role passes the CHECK constraint of the users
+// table
+func validRole(role string) bool {
+	for _, r := range models.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
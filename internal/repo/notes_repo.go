@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"path/filepath"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+// NoteRepo stores the comments and attachments left on assets
+type NoteRepo struct{ db DBTX }
+
+func NewNoteRepo(db DBTX) *NoteRepo {
+	return &NoteRepo{db: db}
+}
+
+// ListComments retrieves the comments on an asset, newest first
+func (r *NoteRepo) ListComments(assetID string) ([]*models.AssetComment, error) {
+	rows, err := r.db.Query(`SELECT comment_id, asset_id, body, author, created_at
+FROM asset_comments WHERE asset_id = ?
+ORDER BY created_at DESC, comment_id DESC;`, assetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.AssetComment
+	for rows.Next() {
+		var c models.AssetComment
+		var createdAt string
+
+		if err := rows.Scan(&c.CommentID, &c.AssetID, &c.Body, &c.Author, &createdAt); err != nil {
+			return nil, err
+		}
+		c.CreatedAt = parseTime(createdAt)
+		out = append(out, &c)
+	}
+
+	return out, rows.Err()
+}
+
+func (r *NoteRepo) AddComment(c *models.AssetComment) error {
+	row := r.db.QueryRow(`INSERT INTO asset_comments (asset_id, body, author)
+VALUES (?, ?, ?)
+RETURNING comment_id, created_at;`, c.AssetID, c.Body, c.Author)
+
+	var createdAt string
+	if err := row.Scan(&c.CommentID, &createdAt); err != nil {
+		return err
+	}
+	c.CreatedAt = parseTime(createdAt)
+	return nil
+}
+
+// ListAttachments retrieves the files attached to an asset, newest first
+func (r *NoteRepo) ListAttachments(assetID string) ([]*models.AssetAttachment, error) {
+	rows, err := r.db.Query(`SELECT attachment_id, asset_id, file_name, file_path, added_at, notes, added_by
+FROM asset_attachments WHERE asset_id = ?
+ORDER BY added_at DESC, attachment_id DESC;`, assetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.AssetAttachment
+	for rows.Next() {
+		var a models.AssetAttachment
+		var addedAt string
+
+		if err := rows.Scan(&a.AttachmentID, &a.AssetID, &a.FileName, &a.FilePath, &addedAt, &a.Notes, &a.AddedBy); err != nil {
+			return nil, err
+		}
+		a.AddedAt = parseTime(addedAt)
+		out = append(out, &a)
+	}
+
+	return out, rows.Err()
+}
+
+// AddAttachment records a file attached to an asset
+// The file name defaults to the base name of the path
+func (r *NoteRepo) AddAttachment(a *models.AssetAttachment) error {
+	if a.FileName == "" {
+		a.FileName = filepath.Base(a.FilePath)
+	}
+	row := r.db.QueryRow(`INSERT INTO asset_attachments (asset_id, file_name, file_path, notes, added_by)
+VALUES (?, ?, ?, ?, ?)
+RETURNING attachment_id, added_at;`, a.AssetID, a.FileName, a.FilePath, a.Notes, a.AddedBy)
+
+	var addedAt string
+	if err := row.Scan(&a.AttachmentID, &addedAt); err != nil {
+		return err
+	}
+	a.AddedAt = parseTime(addedAt)
+	return nil
+}
@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type RelationRepo struct{ db DBTX }
+
+func NewRelationRepo(db DBTX) *RelationRepo {
+	return &RelationRepo{db: db}
+}
+
+const relationSelect = `SELECT r.child_id, c.asset_tag, r.parent_id, p.asset_tag, r.relation, r.created_by, r.created_at
+FROM asset_relations r
+JOIN assets c ON c.asset_id = r.child_id
+JOIN assets p ON p.asset_id = r.parent_id`
+
+// GetParent retrieves the relation of an asset to its parent
+// Returns sql.ErrNoRows if the asset has no parent
+func (r *RelationRepo) GetParent(childID string) (*models.AssetRelation, error) {
+	return one(r.query(relationSelect+` WHERE r.child_id = ?;`, childID))
+}
+
+// ListChildren retrieves the relations of the children of an asset,
+// ordered by child asset tag
+func (r *RelationRepo) ListChildren(parentID string) ([]*models.AssetRelation, error) {
+	return r.query(relationSelect+` WHERE r.parent_id = ? ORDER BY c.asset_tag;`, parentID)
+}
+
+// query runs a relationSelect based query
+func (r *RelationRepo) query(query string, args ...any) ([]*models.AssetRelation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.AssetRelation
+	for rows.Next() {
+		var rel models.AssetRelation
+		var createdAt string
+		if err := rows.Scan(&rel.ChildID, &rel.ChildTag, &rel.ParentID, &rel.ParentTag, &rel.Relation,
+			&rel.CreatedBy, &createdAt); err != nil {
+			return nil, err
+		}
+		rel.CreatedAt = parseTime(createdAt)
+		out = append(out, &rel)
+	}
+	return out, rows.Err()
+}
+
+// Create links a child asset to its parent, setting the creation time
+// A child that already has a parent fails the asset_relations.child_id
+// unique constraint
+func (r *RelationRepo) Create(rel *models.AssetRelation) error {
+	var createdAt string
+	if err := r.db.QueryRow(`INSERT INTO asset_relations (child_id, parent_id, relation, created_by)
+VALUES (?, ?, ?, ?) RETURNING created_at;`, rel.ChildID, rel.ParentID, rel.Relation, rel.CreatedBy).Scan(&createdAt); err != nil {
+		return err
+	}
+	rel.CreatedAt = parseTime(createdAt)
+	return nil
+}
+
+// Delete unlinks an asset from its parent
+// Returns sql.ErrNoRows if the asset has no parent
+func (r *RelationRepo) Delete(childID string) error {
+	return execOne(r.db, `DELETE FROM asset_relations WHERE child_id = ?;`, childID)
+}
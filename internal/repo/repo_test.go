@@ -0,0 +1,21 @@
+package repo_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/MawCeron/it-room/internal/db"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/repo/repotest"
+)
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) *repo.Stores {
+		d, err := db.New(filepath.Join(t.TempDir(), "itroom.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { d.Close() })
+		return repo.NewStores(d.Conn)
+	})
+}
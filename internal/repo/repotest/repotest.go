@@ -0,0 +1,1508 @@
+// Package repotest is the contract test suite every implementation of the
+// repository interfaces must pass
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// Run runs the contract tests, calling newStores for empty stores holding
+// the catalogs seeded by the schema in each test
+func Run(t *testing.T, newStores func(t *testing.T) *repo.Stores) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s *repo.Stores)
+	}{
+		{"Catalogs", testCatalogs},
+		{"CreateAndGetAsset", testCreateAndGetAsset},
+		{"UpdateAsset", testUpdateAsset},
+		{"AssetConstraints", testAssetConstraints},
+		{"FilterAssets", testFilterAssets},
+		{"SortAndPageAssets", testSortAndPageAssets},
+		{"AssignAndReturn", testAssignAndReturn},
+		{"RetireAsset", testRetireAsset},
+		{"Reports", testReports},
+		{"Employees", testEmployees},
+		{"Maintenance", testMaintenance},
+		{"Notes", testNotes},
+		{"Licenses", testLicenses},
+		{"Consumables", testConsumables},
+		{"Kits", testKits},
+		{"Relations", testRelations},
+		{"Stock", testStock},
+		{"Fields", testFields},
+		{"Financials", testFinancials},
+		{"Views", testViews},
+		{"Users", testUsers},
+		{"Audit", testAudit},
+		{"EmptyInventory", testEmptyInventory},
+		{"UnitOfWork", testUnitOfWork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStores(t))
+		})
+	}
+}
+
+// Catalog IDs seeded by the schema
+const (
+	laptop     = 1  // Asset type in Computer Equipment
+	printer    = 7  // Laser Printer, in Printers and Multifunction Devices
+	mouse      = 39 // Mouse, in Accessories
+	mainSite   = 1  // Main location
+	remoteSite = 2  // Remote location
+)
+
+var actor = ptr("tester")
+
+func ptr[T any](v T) *T { return &v }
+
+func day(s string) time.Time {
+	t, err := time.Parse(repo.DateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// newAsset returns an available asset at the main location
+func newAsset(tag string, typeID int, make string) *models.Asset {
+	return &models.Asset{
+		AssetTag: tag, TypeID: typeID, StatusID: models.StatusAvailable,
+		SerialNumber: "SN-" + tag, Maker: make, Model: "Model " + tag,
+		PurchaseDate: day("2024-01-15"), LocationID: mainSite, CreatedBy: actor,
+	}
+}
+
+// mustCreate stores assets, failing the test on error
+func mustCreate(t *testing.T, s *repo.Stores, assets ...*models.Asset) {
+	t.Helper()
+	for _, a := range assets {
+		if err := s.Assets.Create(a); err != nil {
+			t.Fatalf("create %s: %v", a.AssetTag, err)
+		}
+	}
+}
+
+// mustEmployee stores an employee, failing the test on error
+func mustEmployee(t *testing.T, s *repo.Stores, name, email string) *models.Employee {
+	t.Helper()
+	e := &models.Employee{FullName: name, Email: email, CreatedBy: actor}
+	if err := s.Employees.Create(e); err != nil {
+		t.Fatalf("create employee %s: %v", name, err)
+	}
+	return e
+}
+
+// tags returns the asset tags of a listing
+func tags(assets []*models.AssetSummary) []string {
+	var out []string
+	for _, a := range assets {
+		out = append(out, a.AssetTag)
+	}
+	return out
+}
+
+// equal reports whether two string slices hold the same values in order
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testCatalogs(t *testing.T, s *repo.Stores) {
+	categories, err := s.Assets.GetAssetCategories()
+	if err != nil || len(categories) != 12 || categories[0].CodePrefix != "EQ" {
+		t.Fatalf("GetAssetCategories = %d categories, %v", len(categories), err)
+	}
+	types, err := s.Assets.GetAssetTypes(1)
+	if err != nil || len(types) == 0 || types[0].TypeName != "Laptop" {
+		t.Fatalf("GetAssetTypes(1) = %v, %v", types, err)
+	}
+	if typ, err := s.Assets.FindAssetType("laser printer"); err != nil || typ.TypeID != printer {
+		t.Errorf("FindAssetType = %v, %v", typ, err)
+	}
+	if _, err := s.Assets.FindAssetType("Toaster"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FindAssetType of a missing type = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := s.Assets.GetAssetType(9999); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetAssetType of a missing type = %v, want sql.ErrNoRows", err)
+	}
+	if c, err := s.Assets.FindAssetCategory("eq"); err != nil || c.Description != "Computer Equipment" {
+		t.Errorf("FindAssetCategory by prefix = %v, %v", c, err)
+	}
+	if c, err := s.Assets.FindAssetCategory("scanners"); err != nil || c.CodePrefix != "SC" {
+		t.Errorf("FindAssetCategory by description = %v, %v", c, err)
+	}
+	if st, err := s.Assets.FindAssetStatus("under maintenance"); err != nil || st.StatusID != models.StatusUnderMaintenance {
+		t.Errorf("FindAssetStatus = %v, %v", st, err)
+	}
+	if statuses, err := s.Assets.GetAssetStatuses(); err != nil || len(statuses) != 4 {
+		t.Errorf("GetAssetStatuses = %d statuses, %v", len(statuses), err)
+	}
+
+	locations, err := s.Locations.List()
+	if err != nil || len(locations) != 2 {
+		t.Fatalf("Locations.List = %d locations, %v", len(locations), err)
+	}
+	if l, err := s.Locations.FindByName("REMOTE"); err != nil || l.LocationID != remoteSite {
+		t.Errorf("Locations.FindByName = %v, %v", l, err)
+	}
+	if id, name, err := s.Maintenance.FindType("upgrade"); err != nil || name != "Upgrade" || id == 0 {
+		t.Errorf("Maintenance.FindType = %d %q, %v", id, name, err)
+	}
+	if _, _, err := s.Maintenance.FindType("Painting"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Maintenance.FindType of a missing type = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testCreateAndGetAsset(t *testing.T, s *repo.Stores) {
+	a := newAsset("EQ-0001", laptop, "Dell")
+	a.WarrantyEndDate = ptr(day("2027-01-15"))
+	a.Notes = ptr("spare")
+	mustCreate(t, s, a)
+	if a.AssetID == "" {
+		t.Fatal("Create did not set the asset ID")
+	}
+	if a.UpdatedBy == nil || *a.UpdatedBy != *actor {
+		t.Errorf("UpdatedBy = %v, want the creator", a.UpdatedBy)
+	}
+
+	got, err := s.Assets.GetSummary(a.AssetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AssetTag != "EQ-0001" || got.TypeName != "Laptop" || got.CategoryID != 1 ||
+		got.CategoryName != "Computer Equipment" || got.StatusName != "Available" || got.LocationName != "Main" {
+		t.Errorf("GetSummary = %+v", got)
+	}
+	if !got.PurchaseDate.Equal(day("2024-01-15")) || got.WarrantyEndDate == nil || !got.WarrantyEndDate.Equal(day("2027-01-15")) {
+		t.Errorf("dates = %v, %v", got.PurchaseDate, got.WarrantyEndDate)
+	}
+	if got.HolderName != nil || got.Notes == nil || *got.Notes != "spare" {
+		t.Errorf("holder = %v, notes = %v", got.HolderName, got.Notes)
+	}
+
+	byTag, err := s.Assets.GetSummaryByTag("EQ-0001")
+	if err != nil || byTag.AssetID != a.AssetID {
+		t.Errorf("GetSummaryByTag = %v, %v", byTag, err)
+	}
+	if _, err := s.Assets.GetSummary("missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetSummary of a missing asset = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := s.Assets.GetSummaryByTag("missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetSummaryByTag of a missing asset = %v, want sql.ErrNoRows", err)
+	}
+
+	all, err := s.Assets.List()
+	if err != nil || len(all) != 1 || all[0].AssetID != a.AssetID {
+		t.Errorf("List = %v, %v", all, err)
+	}
+}
+
+func testUpdateAsset(t *testing.T, s *repo.Stores) {
+	a := newAsset("EQ-0001", laptop, "Dell")
+	mustCreate(t, s, a)
+
+	changed := *a
+	changed.Model = "Latitude 7440"
+	changed.LocationID = remoteSite
+	changed.WarrantyEndDate = ptr(day("2026-06-30"))
+	changed.UpdatedBy = ptr("editor")
+	if err := s.Assets.Update(&changed); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Assets.GetSummary(a.AssetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Model != "Latitude 7440" || got.LocationName != "Remote" || got.WarrantyEndDate == nil ||
+		got.UpdatedBy == nil || *got.UpdatedBy != "editor" || got.CreatedBy == nil || *got.CreatedBy != *actor {
+		t.Errorf("after Update: %+v", got)
+	}
+
+	transfers, err := s.Transfers.ListByAsset(a.AssetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) != 1 || transfers[0].FromLocationName != "Main" || transfers[0].ToLocationName != "Remote" ||
+		transfers[0].TransferredBy == nil || *transfers[0].TransferredBy != "editor" {
+		t.Errorf("transfers = %+v", transfers)
+	}
+
+	// Saving without moving records no transfer
+	changed.Model = "Latitude 7450"
+	if err := s.Assets.Update(&changed); err != nil {
+		t.Fatal(err)
+	}
+	if transfers, _ := s.Transfers.ListByAsset(a.AssetID); len(transfers) != 1 {
+		t.Errorf("%d transfers after an update in place, want 1", len(transfers))
+	}
+
+	missing := *a
+	missing.AssetID = "missing"
+	if err := s.Assets.Update(&missing); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Update of a missing asset = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testAssetConstraints(t *testing.T, s *repo.Stores) {
+	mustCreate(t, s, newAsset("EQ-0001", laptop, "Dell"))
+
+	dupTag := newAsset("EQ-0001", laptop, "Dell")
+	dupTag.SerialNumber = "other"
+	if err := s.Assets.Create(dupTag); err == nil {
+		t.Error("Create with a duplicate tag succeeded")
+	}
+	dupSerial := newAsset("EQ-0002", laptop, "Dell")
+	dupSerial.SerialNumber = "SN-EQ-0001"
+	if err := s.Assets.Create(dupSerial); err == nil {
+		t.Error("Create with a duplicate serial number succeeded")
+	}
+	badType := newAsset("EQ-0003", 9999, "Dell")
+	if err := s.Assets.Create(badType); err == nil {
+		t.Error("Create with a missing type succeeded")
+	}
+	if n, err := s.Assets.CountSummaries(repo.AssetQuery{}); err != nil || n != 1 {
+		t.Errorf("CountSummaries = %d, %v, want 1", n, err)
+	}
+}
+
+// createFleet stores a mix of assets for the listing tests
+func createFleet(t *testing.T, s *repo.Stores) map[string]*models.Asset {
+	t.Helper()
+	fleet := map[string]*models.Asset{
+		"EQ-0001": newAsset("EQ-0001", laptop, "Dell"),
+		"EQ-0002": newAsset("EQ-0002", laptop, "Lenovo"),
+		"EQ-0003": newAsset("EQ-0003", laptop, "Dell"),
+		"PR-0001": newAsset("PR-0001", printer, "HP"),
+		"PR-0002": newAsset("PR-0002", printer, "Brother"),
+	}
+	fleet["EQ-0001"].WarrantyEndDate = ptr(day("2025-03-01"))
+	fleet["EQ-0002"].WarrantyEndDate = ptr(day("2025-09-01"))
+	fleet["EQ-0003"].WarrantyEndDate = ptr(day("2026-12-31"))
+	fleet["PR-0001"].LocationID = remoteSite
+	fleet["PR-0002"].StatusID = models.StatusRetired
+	for _, tag := range []string{"EQ-0001", "EQ-0002", "EQ-0003", "PR-0001", "PR-0002"} {
+		mustCreate(t, s, fleet[tag])
+	}
+	return fleet
+}
+
+func testFilterAssets(t *testing.T, s *repo.Stores) {
+	createFleet(t, s)
+
+	tests := []struct {
+		name string
+		q    repo.AssetQuery
+		want []string
+	}{
+		{"all", repo.AssetQuery{}, []string{"EQ-0001", "EQ-0002", "EQ-0003", "PR-0001", "PR-0002"}},
+		{"text", repo.AssetQuery{Filter: "lenovo"}, []string{"EQ-0002"}},
+		{"text matches type", repo.AssetQuery{Filter: "printer"}, []string{"PR-0001", "PR-0002"}},
+		{"text matches location", repo.AssetQuery{Filter: "remote"}, []string{"PR-0001"}},
+		{"status", repo.AssetQuery{StatusID: models.StatusRetired}, []string{"PR-0002"}},
+		{"category", repo.AssetQuery{CategoryID: 1}, []string{"EQ-0001", "EQ-0002", "EQ-0003"}},
+		{"make", repo.AssetQuery{Make: "Dell"}, []string{"EQ-0001", "EQ-0003"}},
+		{"exclude retired", repo.AssetQuery{ExcludeRetired: true, CategoryID: 2}, []string{"PR-0001"}},
+		{"location", repo.AssetQuery{LocationID: remoteSite}, []string{"PR-0001"}},
+		{"warranty range", repo.AssetQuery{WarrantyEndFrom: ptr(day("2025-06-01")), WarrantyEndTo: ptr(day("2026-12-31"))},
+			[]string{"EQ-0002", "EQ-0003"}},
+		{"no match", repo.AssetQuery{Filter: "nothing like it"}, nil},
+		{"underscore is not a wildcard", repo.AssetQuery{Filter: "eq_0001"}, nil},
+		{"percent is not a wildcard", repo.AssetQuery{Filter: "eq%1"}, nil},
+		{"backslash is not an escape", repo.AssetQuery{Filter: `\e`}, nil},
+	}
+	for _, tt := range tests {
+		got, err := s.Assets.ListSummaries(tt.q)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !equal(tags(got), tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tags(got), tt.want)
+		}
+		n, err := s.Assets.CountSummaries(tt.q)
+		if err != nil || n != len(tt.want) {
+			t.Errorf("%s: CountSummaries = %d, %v, want %d", tt.name, n, err, len(tt.want))
+		}
+	}
+
+	// Wildcard characters match themselves
+	cable := newAsset("AC-0001", mouse, "Belkin")
+	cable.Model = `USB_C 100% \ braided`
+	mustCreate(t, s, cable)
+	for _, filter := range []string{"usb_c", "100%", `% \ b`} {
+		if got, _ := s.Assets.ListSummaries(repo.AssetQuery{Filter: filter}); !equal(tags(got), []string{"AC-0001"}) {
+			t.Errorf("filter %q: got %v, want [AC-0001]", filter, tags(got))
+		}
+	}
+}
+
+func testSortAndPageAssets(t *testing.T, s *repo.Stores) {
+	createFleet(t, s)
+
+	desc, err := s.Assets.ListSummaries(repo.AssetQuery{SortColumn: "asset_tag", SortDesc: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"PR-0002", "PR-0001", "EQ-0003", "EQ-0002", "EQ-0001"}; !equal(tags(desc), want) {
+		t.Errorf("descending = %v, want %v", tags(desc), want)
+	}
+
+	byMake, err := s.Assets.ListSummaries(repo.AssetQuery{SortColumn: "make"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var makes []string
+	for _, a := range byMake {
+		makes = append(makes, a.Maker)
+	}
+	if want := []string{"Brother", "Dell", "Dell", "HP", "Lenovo"}; !equal(makes, want) {
+		t.Errorf("by make = %v, want %v", makes, want)
+	}
+
+	// Models sort as shown, after the make
+	byModel, err := s.Assets.ListSummaries(repo.AssetQuery{SortColumn: "model"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"PR-0002", "EQ-0001", "EQ-0003", "PR-0001", "EQ-0002"}; !equal(tags(byModel), want) {
+		t.Errorf("by model = %v, want %v", tags(byModel), want)
+	}
+
+	// Missing warranties sort first, as empty values
+	byWarranty, err := s.Assets.ListSummaries(repo.AssetQuery{SortColumn: "warranty_end_date"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tags(byWarranty)[2:]; !equal(got, []string{"EQ-0001", "EQ-0002", "EQ-0003"}) {
+		t.Errorf("by warranty = %v", tags(byWarranty))
+	}
+
+	for _, q := range []repo.AssetQuery{
+		{SortColumn: "make"},
+		{SortColumn: "model", SortDesc: true},
+		{SortColumn: "type", SortDesc: true},
+		{SortColumn: "warranty"},
+		{SortColumn: "location", Filter: "0"},
+	} {
+		all, err := s.Assets.ListSummaries(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paged []*models.AssetSummary
+		var cursor *repo.AssetCursor
+		for {
+			page, next, err := s.Assets.PageSummaries(q, cursor, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page) == 0 {
+				if next != nil {
+					t.Errorf("%+v: empty page returned a cursor", q)
+				}
+				break
+			}
+			if len(page) > 2 {
+				t.Fatalf("%+v: page of %d rows, limit 2", q, len(page))
+			}
+			paged = append(paged, page...)
+			cursor = next
+		}
+		if !equal(tags(paged), tags(all)) {
+			t.Errorf("%+v: pages %v, listing %v", q, tags(paged), tags(all))
+		}
+	}
+}
+
+func testAssignAndReturn(t *testing.T, s *repo.Stores) {
+	fleet := createFleet(t, s)
+	ana := mustEmployee(t, s, "Ana Lopez", "ana@example.com")
+	asset := fleet["EQ-0001"]
+
+	a := &models.AssetAssignment{AssetID: asset.AssetID, EmployeeID: ana.EmployeeID,
+		AssignmentDate: day("2025-01-10"), DueDate: ptr(day("2025-02-10")), AssignedBy: actor}
+	if err := s.Assignments.Assign(a); err != nil {
+		t.Fatal(err)
+	}
+	if a.AssignmentID == 0 {
+		t.Error("Assign did not set the assignment ID")
+	}
+
+	got, err := s.Assets.GetSummary(asset.AssetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.StatusID != models.StatusAssigned || got.HolderName == nil || *got.HolderName != "Ana Lopez" {
+		t.Errorf("after Assign: status %d, holder %v", got.StatusID, got.HolderName)
+	}
+	if held, _ := s.Assets.ListSummaries(repo.AssetQuery{HolderID: ana.EmployeeID}); !equal(tags(held), []string{"EQ-0001"}) {
+		t.Errorf("held by Ana: %v", tags(held))
+	}
+	if held, _ := s.Assets.ListSummaries(repo.AssetQuery{Filter: "ana lo"}); !equal(tags(held), []string{"EQ-0001"}) {
+		t.Errorf("filtered by holder: %v", tags(held))
+	}
+	if overdue, _ := s.Assets.ListSummaries(repo.AssetQuery{OverdueOn: ptr(day("2025-03-01"))}); !equal(tags(overdue), []string{"EQ-0001"}) {
+		t.Errorf("overdue: %v", tags(overdue))
+	}
+	if overdue, _ := s.Assets.ListSummaries(repo.AssetQuery{OverdueOn: ptr(day("2025-02-01"))}); len(overdue) != 0 {
+		t.Errorf("overdue before the due date: %v", tags(overdue))
+	}
+	if byHolder, _ := s.Assets.ListSummaries(repo.AssetQuery{SortColumn: "holder", SortDesc: true}); byHolder[0].AssetTag != "EQ-0001" {
+		t.Errorf("by holder descending: %v", tags(byHolder))
+	}
+
+	again := &models.AssetAssignment{AssetID: asset.AssetID, EmployeeID: ana.EmployeeID, AssignmentDate: day("2025-01-11")}
+	if err := s.Assignments.Assign(again); !errors.Is(err, repo.ErrAssetAssigned) {
+		t.Errorf("second Assign = %v, want ErrAssetAssigned", err)
+	}
+	retired := &models.AssetAssignment{AssetID: fleet["PR-0002"].AssetID, EmployeeID: ana.EmployeeID, AssignmentDate: day("2025-01-11")}
+	if err := s.Assignments.Assign(retired); !errors.Is(err, repo.ErrAssetRetired) {
+		t.Errorf("Assign of a retired asset = %v, want ErrAssetRetired", err)
+	}
+	repairing := fleet["EQ-0002"]
+	repairing.StatusID = models.StatusUnderMaintenance
+	if err := s.Assets.Update(repairing); err != nil {
+		t.Fatal(err)
+	}
+	underRepair := &models.AssetAssignment{AssetID: repairing.AssetID, EmployeeID: ana.EmployeeID, AssignmentDate: day("2025-01-11")}
+	if err := s.Assignments.Assign(underRepair); !errors.Is(err, repo.ErrAssetUnderMaintenance) {
+		t.Errorf("Assign of an asset under maintenance = %v, want ErrAssetUnderMaintenance", err)
+	}
+
+	if err := s.Assignments.Return(asset.AssetID, day("2025-02-01"), actor); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Assignments.Return(asset.AssetID, day("2025-02-02"), actor); !errors.Is(err, repo.ErrAssetNotAssigned) {
+		t.Errorf("second Return = %v, want ErrAssetNotAssigned", err)
+	}
+	got, _ = s.Assets.GetSummary(asset.AssetID)
+	if got.StatusID != models.StatusAvailable || got.HolderName != nil {
+		t.Errorf("after Return: status %d, holder %v", got.StatusID, got.HolderName)
+	}
+
+	second := &models.AssetAssignment{AssetID: asset.AssetID, EmployeeID: ana.EmployeeID,
+		AssignmentDate: day("2025-03-01"), Notes: ptr("replacement"), AssignedBy: actor}
+	if err := s.Assignments.Assign(second); err != nil {
+		t.Fatal(err)
+	}
+	history, err := s.Assignments.ListByAsset(asset.AssetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].AssignmentID != second.AssignmentID || history[0].ReturnDate != nil ||
+		history[1].ReturnDate == nil || !history[1].ReturnDate.Equal(day("2025-02-01")) ||
+		history[1].EmployeeName != "Ana Lopez" || history[1].AssetTag != "EQ-0001" ||
+		history[1].DueDate == nil || !history[1].DueDate.Equal(day("2025-02-10")) {
+		t.Errorf("history = %+v", history)
+	}
+	all, err := s.Assignments.List()
+	if err != nil || len(all) != 2 || all[0].AssignmentID != a.AssignmentID {
+		t.Errorf("List = %v, %v", all, err)
+	}
+}
+
+func testRetireAsset(t *testing.T, s *repo.Stores) {
+	a := newAsset("EQ-0001", laptop, "Dell")
+	mustCreate(t, s, a)
+	ana := mustEmployee(t, s, "Ana Lopez", "ana@example.com")
+	if err := s.Assignments.Assign(&models.AssetAssignment{AssetID: a.AssetID, EmployeeID: ana.EmployeeID,
+		AssignmentDate: day("2025-01-10")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Assets.Retire(a.AssetID, day("2025-04-01"), actor); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.Assets.GetSummary(a.AssetID)
+	if got.StatusID != models.StatusRetired || got.HolderName != nil {
+		t.Errorf("after Retire: status %d, holder %v", got.StatusID, got.HolderName)
+	}
+	if err := s.Assets.Retire("missing", day("2025-04-01"), actor); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Retire of a missing asset = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testReports(t *testing.T, s *repo.Stores) {
+	createFleet(t, s)
+
+	byStatus, err := s.Assets.CountByStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[int]int{}
+	for _, c := range byStatus {
+		counts[c.ID] = c.Count
+	}
+	if len(byStatus) != 4 || counts[models.StatusAvailable] != 4 || counts[models.StatusRetired] != 1 || counts[models.StatusAssigned] != 0 {
+		t.Errorf("CountByStatus = %v", counts)
+	}
+
+	byCategory, err := s.Assets.CountByCategory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byCategory) != 12 || byCategory[0].Count != 3 || byCategory[1].Count != 2 || byCategory[2].Count != 0 {
+		t.Errorf("CountByCategory = %+v", byCategory)
+	}
+
+	report, err := s.Assets.WarrantyReport(day("2025-08-15"), 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.WarrantyVendorSummary{
+		{Vendor: "Dell", Active: 1, Expired: 1},
+		{Vendor: "HP", None: 1},
+		{Vendor: "Lenovo", ExpiringSoon: 1},
+	}
+	if len(report) != len(want) {
+		t.Fatalf("WarrantyReport = %d vendors, want %d", len(report), len(want))
+	}
+	for i, w := range want {
+		if *report[i] != w {
+			t.Errorf("WarrantyReport[%d] = %+v, want %+v", i, *report[i], w)
+		}
+	}
+}
+
+func testEmployees(t *testing.T, s *repo.Stores) {
+	ana := mustEmployee(t, s, "Ana Lopez", "ana@example.com")
+	mustEmployee(t, s, "Bruno Diaz", "bruno@example.com")
+	mustEmployee(t, s, "Ana Lopez", "ana.lopez@example.com")
+	if ana.EmployeeID == 0 {
+		t.Error("Create did not set the employee ID")
+	}
+	if err := s.Employees.Create(&models.Employee{FullName: "Copy", Email: "ana@example.com"}); err == nil {
+		t.Error("Create with a duplicate email succeeded")
+	}
+
+	if e, err := s.Employees.Get(ana.EmployeeID); err != nil || e.Email != "ana@example.com" {
+		t.Errorf("Get = %v, %v", e, err)
+	}
+	if _, err := s.Employees.Get(9999); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Get of a missing employee = %v, want sql.ErrNoRows", err)
+	}
+	if e, err := s.Employees.FindByEmail("BRUNO@example.com"); err != nil || e.FullName != "Bruno Diaz" {
+		t.Errorf("FindByEmail = %v, %v", e, err)
+	}
+	if same, err := s.Employees.FindByName("ana lopez"); err != nil || len(same) != 2 || same[0].Email != "ana.lopez@example.com" {
+		t.Errorf("FindByName = %v, %v", same, err)
+	}
+	if e, err := s.Employees.Resolve("Bruno Diaz"); err != nil || e.Email != "bruno@example.com" {
+		t.Errorf("Resolve by name = %v, %v", e, err)
+	}
+	if _, err := s.Employees.Resolve("Ana Lopez"); err == nil {
+		t.Error("Resolve of an ambiguous name succeeded")
+	}
+	if _, err := s.Employees.Resolve("nobody@example.com"); err == nil {
+		t.Error("Resolve of a missing email succeeded")
+	}
+
+	all, err := s.Employees.List()
+	if err != nil || len(all) != 3 || all[2].FullName != "Bruno Diaz" {
+		t.Errorf("List = %v, %v", all, err)
+	}
+	found, err := s.Employees.Search("EXAMPLE", 2)
+	if err != nil || len(found) != 2 || found[0].FullName != "Ana Lopez" {
+		t.Errorf("Search = %v, %v", found, err)
+	}
+	if found, _ := s.Employees.Search("bruno", 10); len(found) != 1 {
+		t.Errorf("Search by name = %v", found)
+	}
+	if found, _ := s.Employees.Search("ana_lopez", 10); len(found) != 0 {
+		t.Errorf("Search with an underscore = %v, want it matched literally", found)
+	}
+}
+
+func testMaintenance(t *testing.T, s *repo.Stores) {
+	a := newAsset("EQ-0001", laptop, "Dell")
+	mustCreate(t, s, a)
+	typeID, _, err := s.Maintenance.FindType("Corrective")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []string{"2025-01-05", "2025-03-05"} {
+		m := &models.MaintenanceLog{AssetID: a.AssetID, MaintenanceTypeID: typeID, MaintenanceDate: day(d),
+			Cost: ptr(120.5), Description: "Replaced fan", RecordedBy: actor}
+		if err := s.Maintenance.Create(m); err != nil {
+			t.Fatal(err)
+		}
+		if m.LogID == 0 {
+			t.Error("Create did not set the log ID")
+		}
+	}
+
+	logs, err := s.Maintenance.ListByAsset(a.AssetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || !logs[0].MaintenanceDate.Equal(day("2025-03-05")) || logs[0].TypeName != "Corrective" ||
+		logs[0].AssetTag != "EQ-0001" || logs[0].Cost == nil || *logs[0].Cost != 120.5 {
+		t.Errorf("ListByAsset = %+v", logs)
+	}
+	if all, err := s.Maintenance.List(); err != nil || len(all) != 2 || !all[0].MaintenanceDate.Equal(day("2025-01-05")) {
+		t.Errorf("List = %v, %v", all, err)
+	}
+	if err := s.Maintenance.Create(&models.MaintenanceLog{AssetID: "missing", MaintenanceTypeID: typeID}); err == nil {
+		t.Error("Create for a missing asset succeeded")
+	}
+}
+
+func testNotes(t *testing.T, s *repo.Stores) {
+	a := newAsset("EQ-0001", laptop, "Dell")
+	mustCreate(t, s, a)
+
+	for _, body := range []string{"first", "second"} {
+		c := &models.AssetComment{AssetID: a.AssetID, Body: body, Author: actor}
+		if err := s.Notes.AddComment(c); err != nil {
+			t.Fatal(err)
+		}
+		if c.CommentID == 0 || c.CreatedAt.IsZero() {
+			t.Errorf("AddComment set ID %d, time %v", c.CommentID, c.CreatedAt)
+		}
+	}
+	comments, err := s.Notes.ListComments(a.AssetID)
+	if err != nil || len(comments) != 2 || comments[0].Body != "second" {
+		t.Errorf("ListComments = %v, %v", comments, err)
+	}
+
+	att := &models.AssetAttachment{AssetID: a.AssetID, FilePath: "/docs/invoice.pdf", AddedBy: actor}
+	if err := s.Notes.AddAttachment(att); err != nil {
+		t.Fatal(err)
+	}
+	if att.FileName != "invoice.pdf" || att.AttachmentID == 0 {
+		t.Errorf("AddAttachment = %+v", att)
+	}
+	attachments, err := s.Notes.ListAttachments(a.AssetID)
+	if err != nil || len(attachments) != 1 || attachments[0].FilePath != "/docs/invoice.pdf" {
+		t.Errorf("ListAttachments = %v, %v", attachments, err)
+	}
+	if other, _ := s.Notes.ListComments("missing"); len(other) != 0 {
+		t.Errorf("comments on a missing asset: %v", other)
+	}
+}
+
+func testLicenses(t *testing.T, s *repo.Stores) {
+	a := newAsset("EQ-0001", laptop, "Dell")
+	mustCreate(t, s, a)
+
+	l := &models.SoftwareLicense{SoftwareName: "Office", LicenseKey: "KEY-1", LicenseType: "Volume",
+		SeatsPurchased: 10, PurchaseDate: day("2024-02-01"), ExpirationDate: ptr(day("2026-02-01"))}
+	if err := s.Licenses.Create(l); err != nil {
+		t.Fatal(err)
+	}
+	if l.LicenseID == 0 {
+		t.Error("Create did not set the license ID")
+	}
+	if err := s.Licenses.Create(&models.SoftwareLicense{SoftwareName: "Copy", LicenseKey: "KEY-1",
+		LicenseType: "Retail", SeatsPurchased: 1, PurchaseDate: day("2024-02-01")}); err == nil {
+		t.Error("Create with a duplicate key succeeded")
+	}
+
+	install := &models.LicenseAssignment{LicenseID: l.LicenseID, AssetID: a.AssetID, AssignmentDate: day("2024-03-01")}
+	if err := s.Licenses.Assign(install); err != nil {
+		t.Fatal(err)
+	}
+	if install.AssignmentID == 0 {
+		t.Error("Assign did not set the assignment ID")
+	}
+	if err := s.Licenses.Assign(&models.LicenseAssignment{LicenseID: l.LicenseID, AssetID: a.AssetID,
+		AssignmentDate: day("2024-03-02")}); err == nil {
+		t.Error("second active install on the same asset succeeded")
+	}
+	if err := s.Licenses.Assign(&models.LicenseAssignment{LicenseID: l.LicenseID, AssetID: "missing",
+		AssignmentDate: day("2024-03-02")}); err == nil {
+		t.Error("Assign to a missing asset succeeded")
+	}
+
+	licenses, err := s.Licenses.List()
+	if err != nil || len(licenses) != 1 || licenses[0].SeatsUsed != 1 || !licenses[0].ExpirationDate.Equal(day("2026-02-01")) {
+		t.Errorf("List = %+v, %v", licenses, err)
+	}
+	installs, err := s.Licenses.ListByAsset(a.AssetID)
+	if err != nil || len(installs) != 1 || installs[0].SoftwareName != "Office" || installs[0].RemovalDate != nil {
+		t.Errorf("ListByAsset = %+v, %v", installs, err)
+	}
+}
+
+func testConsumables(t *testing.T, s *repo.Stores) {
+	a := newAsset("PR-0001", printer, "HP")
+	mustCreate(t, s, a)
+
+	toner := &models.ConsumableType{Name: "HP 58A Toner", PartNumber: ptr("CF258A"), Manufacturer: ptr("HP"),
+		StockQuantity: 3, ReorderLevel: 2}
+	if err := s.Consumables.CreateType(toner); err != nil {
+		t.Fatal(err)
+	}
+	if toner.ConsumableTypeID == 0 {
+		t.Error("CreateType did not set the ID")
+	}
+	if err := s.Consumables.CreateType(&models.ConsumableType{Name: "HP 58A Toner"}); err == nil {
+		t.Error("CreateType with a duplicate name succeeded")
+	}
+	if low, _ := s.Consumables.ListLowStock(); len(low) != 0 {
+		t.Errorf("ListLowStock before use = %v", low)
+	}
+
+	u := &models.ConsumableUsage{ConsumableTypeID: toner.ConsumableTypeID, AssetID: a.AssetID, InstallationDate: day("2025-01-10")}
+	if err := s.Consumables.RecordUsage(u); err != nil {
+		t.Fatal(err)
+	}
+	if u.UsageID == 0 {
+		t.Error("RecordUsage did not set the usage ID")
+	}
+	if err := s.Consumables.RecordUsage(&models.ConsumableUsage{ConsumableTypeID: toner.ConsumableTypeID,
+		AssetID: "missing", InstallationDate: day("2025-01-11")}); err == nil {
+		t.Error("RecordUsage for a missing asset succeeded")
+	}
+
+	usage, err := s.Consumables.ListUsageByAsset(a.AssetID)
+	if err != nil || len(usage) != 1 || usage[0].ConsumableName != "HP 58A Toner" || *usage[0].PartNumber != "CF258A" {
+		t.Errorf("ListUsageByAsset = %+v, %v", usage, err)
+	}
+	low, err := s.Consumables.ListLowStock()
+	if err != nil || len(low) != 1 || low[0].StockQuantity != 2 {
+		t.Errorf("ListLowStock after use = %+v, %v", low, err)
+	}
+}
+
+func testKits(t *testing.T, s *repo.Stores) {
+	laptopAsset, printerAsset, spare := newAsset("EQ-0001", laptop, "Dell"), newAsset("PR-0001", printer, "HP"), newAsset("EQ-0002", laptop, "HP")
+	mustCreate(t, s, laptopAsset, printerAsset, spare)
+
+	tmpl := &models.KitTemplate{Name: "New Hire", Description: ptr("Two laptops and a printer"),
+		Items: []models.KitTemplateItem{{TypeID: printer, Quantity: 1}, {TypeID: laptop, Quantity: 2}}}
+	if err := s.Kits.CreateTemplate(tmpl); err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.TemplateID == 0 {
+		t.Error("CreateTemplate did not set the ID")
+	}
+	if err := s.Kits.CreateTemplate(&models.KitTemplate{Name: "New Hire"}); err == nil {
+		t.Error("CreateTemplate with a duplicate name succeeded")
+	}
+	found, err := s.Kits.FindTemplate(" new hire ")
+	if err != nil || found.TemplateID != tmpl.TemplateID || len(found.Items) != 2 ||
+		found.Items[0].TypeName != "Laptop" || found.Items[0].Quantity != 2 {
+		t.Errorf("FindTemplate = %+v, %v", found, err)
+	}
+	if _, err := s.Kits.GetTemplate(9999); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetTemplate of a missing template = %v, want sql.ErrNoRows", err)
+	}
+
+	k := &models.Kit{KitTag: "KIT-001", TemplateID: &tmpl.TemplateID, CreatedBy: actor,
+		AssetIDs: []string{printerAsset.AssetID, laptopAsset.AssetID}}
+	if err := s.Kits.Create(k); err != nil {
+		t.Fatal(err)
+	}
+	if k.KitID == 0 || k.CreatedAt.IsZero() {
+		t.Errorf("Create did not set the ID and creation time: %+v", k)
+	}
+	if err := s.Kits.Create(&models.Kit{KitTag: "KIT-001"}); err == nil {
+		t.Error("Create with a duplicate tag succeeded")
+	}
+	if err := s.Kits.Create(&models.Kit{KitTag: "KIT-002", AssetIDs: []string{laptopAsset.AssetID}}); err == nil {
+		t.Error("Create with an asset already in a kit succeeded")
+	}
+	if _, err := s.Kits.GetByTag("KIT-002"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByTag after a failed Create = %v, want sql.ErrNoRows", err)
+	}
+
+	got, err := s.Kits.GetByTag("KIT-001")
+	if err != nil || got.KitID != k.KitID || got.TemplateName == nil || *got.TemplateName != "New Hire" ||
+		!equal(got.AssetIDs, []string{laptopAsset.AssetID, printerAsset.AssetID}) {
+		t.Errorf("GetByTag = %+v, %v", got, err)
+	}
+	if err := s.Kits.AddAsset(k.KitID, spare.AssetID, actor); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Kits.AddAsset(k.KitID, "missing", actor); err == nil {
+		t.Error("AddAsset of a missing asset succeeded")
+	}
+	if byAsset, err := s.Kits.FindByAsset(spare.AssetID); err != nil || byAsset.KitID != k.KitID || len(byAsset.AssetIDs) != 3 {
+		t.Errorf("FindByAsset = %+v, %v", byAsset, err)
+	}
+	if err := s.Kits.RemoveAsset(k.KitID, spare.AssetID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Kits.RemoveAsset(k.KitID, spare.AssetID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("RemoveAsset of a non-member = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := s.Kits.FindByAsset(spare.AssetID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FindByAsset of a removed asset = %v, want sql.ErrNoRows", err)
+	}
+
+	loose := &models.Kit{KitTag: "KIT-000", AssetIDs: []string{spare.AssetID}}
+	if err := s.Kits.Create(loose); err != nil {
+		t.Fatal(err)
+	}
+	kits, err := s.Kits.List()
+	if err != nil || len(kits) != 2 || kits[0].KitTag != "KIT-000" || kits[0].TemplateName != nil {
+		t.Errorf("List = %+v, %v", kits, err)
+	}
+
+	if err := s.Kits.Delete(k.KitID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Kits.Delete(k.KitID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Delete of a deleted kit = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := s.Kits.FindByAsset(laptopAsset.AssetID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FindByAsset after Delete = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := s.Assets.GetSummary(laptopAsset.AssetID); err != nil {
+		t.Errorf("Delete removed a member asset: %v", err)
+	}
+	entries, err := s.Audit.List(repo.AuditQuery{AssetID: laptopAsset.AssetID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ops []string
+	for _, e := range entries {
+		if e.Entity == "kit_assets" {
+			ops = append(ops, e.Operation)
+		}
+	}
+	if len(ops) != 2 {
+		t.Errorf("kit membership audit operations = %v, want an insert and a delete", ops)
+	}
+}
+
+func testRelations(t *testing.T, s *repo.Stores) {
+	desktop, monitor, ups := newAsset("EQ-0001", laptop, "Dell"), newAsset("MS-0001", laptop, "LG"), newAsset("PE-0001", printer, "APC")
+	mustCreate(t, s, desktop, monitor, ups)
+
+	rel := &models.AssetRelation{ChildID: monitor.AssetID, ParentID: desktop.AssetID, Relation: models.RelationAttachedTo, CreatedBy: actor}
+	if err := s.Relations.Create(rel); err != nil {
+		t.Fatal(err)
+	}
+	if rel.CreatedAt.IsZero() {
+		t.Error("Create did not set the creation time")
+	}
+	if err := s.Relations.Create(&models.AssetRelation{ChildID: desktop.AssetID, ParentID: ups.AssetID, Relation: models.RelationPoweredBy}); err != nil {
+		t.Fatal(err)
+	}
+	for name, bad := range map[string]*models.AssetRelation{
+		"second parent":    {ChildID: monitor.AssetID, ParentID: ups.AssetID, Relation: models.RelationPoweredBy},
+		"own parent":       {ChildID: ups.AssetID, ParentID: ups.AssetID, Relation: models.RelationPoweredBy},
+		"unknown relation": {ChildID: ups.AssetID, ParentID: desktop.AssetID, Relation: "glued-to"},
+		"missing parent":   {ChildID: ups.AssetID, ParentID: "missing", Relation: models.RelationPoweredBy},
+	} {
+		if err := s.Relations.Create(bad); err == nil {
+			t.Errorf("Create with a %s succeeded", name)
+		}
+	}
+
+	parent, err := s.Relations.GetParent(monitor.AssetID)
+	if err != nil || parent.ParentTag != "EQ-0001" || parent.ChildTag != "MS-0001" || parent.Relation != models.RelationAttachedTo {
+		t.Errorf("GetParent = %+v, %v", parent, err)
+	}
+	if _, err := s.Relations.GetParent(ups.AssetID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetParent of a root = %v, want sql.ErrNoRows", err)
+	}
+	children, err := s.Relations.ListChildren(ups.AssetID)
+	if err != nil || len(children) != 1 || children[0].ChildTag != "EQ-0001" {
+		t.Errorf("ListChildren = %+v, %v", children, err)
+	}
+
+	if err := s.Relations.Delete(monitor.AssetID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Relations.Delete(monitor.AssetID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("second Delete = %v, want sql.ErrNoRows", err)
+	}
+	if children, _ := s.Relations.ListChildren(desktop.AssetID); len(children) != 0 {
+		t.Errorf("ListChildren after Delete = %+v", children)
+	}
+	if entries, _ := s.Audit.List(repo.AuditQuery{Entity: "asset_relations", EntityID: monitor.AssetID}); len(entries) != 2 {
+		t.Errorf("%d audit entries for the relation, want an insert and a delete", len(entries))
+	}
+}
+
+func testStock(t *testing.T, s *repo.Stores) {
+	alice, bob := mustEmployee(t, s, "Alice", "alice@example.com"), mustEmployee(t, s, "Bob", "bob@example.com")
+
+	mice := &models.StockItem{Name: "USB Mouse", TypeID: ptr(mouse), ReorderLevel: 5, CreatedBy: actor}
+	if err := s.Stock.CreateItem(mice); err != nil {
+		t.Fatal(err)
+	}
+	if mice.ItemID == 0 || mice.CreatedAt.IsZero() {
+		t.Errorf("CreateItem did not set the ID and creation time: %+v", mice)
+	}
+	cables := &models.StockItem{Name: "HDMI Cable"}
+	if err := s.Stock.CreateItem(cables); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Stock.CreateItem(&models.StockItem{Name: "usb mouse"}); err == nil {
+		t.Error("CreateItem with a duplicate name succeeded")
+	}
+	if err := s.Stock.CreateItem(&models.StockItem{Name: "Dongle", TypeID: ptr(9999)}); err == nil {
+		t.Error("CreateItem with a missing type succeeded")
+	}
+
+	for _, m := range []*models.StockMovement{
+		{ItemID: mice.ItemID, LocationID: mainSite, Kind: models.StockAdjust, Quantity: 10, MovedAt: day("2025-01-10"), MovedBy: actor},
+		{ItemID: mice.ItemID, LocationID: remoteSite, Kind: models.StockAdjust, Quantity: 4, MovedAt: day("2025-01-10")},
+		{ItemID: mice.ItemID, LocationID: mainSite, Kind: models.StockIssue, Quantity: -3, EmployeeID: &alice.EmployeeID, MovedAt: day("2025-01-11")},
+		{ItemID: mice.ItemID, LocationID: remoteSite, Kind: models.StockIssue, Quantity: -1, EmployeeID: &bob.EmployeeID, MovedAt: day("2025-01-12")},
+		{ItemID: mice.ItemID, LocationID: remoteSite, Kind: models.StockReturn, Quantity: 1, EmployeeID: &alice.EmployeeID, MovedAt: day("2025-01-13")},
+		{ItemID: cables.ItemID, LocationID: mainSite, Kind: models.StockAdjust, Quantity: 2, MovedAt: day("2025-01-10")},
+		{ItemID: cables.ItemID, LocationID: mainSite, Kind: models.StockIssue, Quantity: -2, EmployeeID: &alice.EmployeeID, MovedAt: day("2025-01-14")},
+	} {
+		if err := s.Stock.RecordMovement(m); err != nil {
+			t.Fatal(err)
+		}
+		if m.MovementID == 0 {
+			t.Error("RecordMovement did not set the ID")
+		}
+	}
+	for name, bad := range map[string]*models.StockMovement{
+		"zero quantity":         {ItemID: mice.ItemID, LocationID: mainSite, Kind: models.StockAdjust, MovedAt: day("2025-01-15")},
+		"positive issue":        {ItemID: mice.ItemID, LocationID: mainSite, Kind: models.StockIssue, Quantity: 1, EmployeeID: &alice.EmployeeID, MovedAt: day("2025-01-15")},
+		"return without holder": {ItemID: mice.ItemID, LocationID: mainSite, Kind: models.StockReturn, Quantity: 1, MovedAt: day("2025-01-15")},
+		"adjust to an employee": {ItemID: mice.ItemID, LocationID: mainSite, Kind: models.StockAdjust, Quantity: 1, EmployeeID: &alice.EmployeeID, MovedAt: day("2025-01-15")},
+		"missing item":          {ItemID: 9999, LocationID: mainSite, Kind: models.StockAdjust, Quantity: 1, MovedAt: day("2025-01-15")},
+	} {
+		if err := s.Stock.RecordMovement(bad); err == nil {
+			t.Errorf("RecordMovement with a %s succeeded", name)
+		}
+	}
+
+	items, err := s.Stock.ListItems()
+	if err != nil || len(items) != 2 || items[0].Name != "HDMI Cable" || items[0].OnHand != 0 || items[0].Issued != 2 {
+		t.Errorf("ListItems = %+v, %v", items, err)
+	}
+	got, err := s.Stock.FindItem(" usb mouse ")
+	if err != nil || got.ItemID != mice.ItemID || got.TypeName == nil || *got.TypeName != "Mouse" ||
+		got.OnHand != 11 || got.Issued != 3 {
+		t.Errorf("FindItem = %+v, %v", got, err)
+	}
+	if _, err := s.Stock.GetItem(9999); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetItem of a missing item = %v, want sql.ErrNoRows", err)
+	}
+
+	levels, err := s.Stock.ListLevels(mice.ItemID)
+	if err != nil || len(levels) != 2 || levels[0].LocationName != "Main" || levels[0].Quantity != 7 || levels[1].Quantity != 4 {
+		t.Errorf("ListLevels = %+v, %v", levels, err)
+	}
+	if levels, _ := s.Stock.ListLevels(cables.ItemID); len(levels) != 0 {
+		t.Errorf("ListLevels of an item out of stock = %+v", levels)
+	}
+	holdings, err := s.Stock.ListHoldings(mice.ItemID)
+	if err != nil || len(holdings) != 2 || holdings[0].EmployeeName != "Alice" || holdings[0].Quantity != 2 || holdings[1].Quantity != 1 {
+		t.Errorf("ListHoldings = %+v, %v", holdings, err)
+	}
+	byEmployee, err := s.Stock.ListHoldingsByEmployee(alice.EmployeeID)
+	if err != nil || len(byEmployee) != 2 || byEmployee[0].ItemName != "HDMI Cable" || byEmployee[1].Quantity != 2 {
+		t.Errorf("ListHoldingsByEmployee = %+v, %v", byEmployee, err)
+	}
+
+	movements, err := s.Stock.ListMovements(mice.ItemID)
+	if err != nil || len(movements) != 5 || movements[0].Kind != models.StockReturn ||
+		movements[0].EmployeeName == nil || *movements[0].EmployeeName != "Alice" || movements[0].LocationName != "Remote" {
+		t.Errorf("ListMovements = %+v, %v", movements, err)
+	}
+	if entries, _ := s.Audit.List(repo.AuditQuery{Entity: "stock_movements"}); len(entries) != 7 {
+		t.Errorf("%d audit entries for stock movements, want 7", len(entries))
+	}
+}
+
+func testFields(t *testing.T, s *repo.Stores) {
+	computers := ptr(1) // Computer Equipment, the category of laptops
+	ram := &models.CustomField{Name: "RAM", Kind: models.FieldNumber, TypeID: ptr(laptop), Required: true,
+		Min: ptr(1.0), CreatedBy: actor}
+	plan := &models.CustomField{Name: "Support Plan", Kind: models.FieldEnum, CategoryID: computers,
+		Options: []string{"Basic", "Premium"}}
+	toner := &models.CustomField{Name: "Toner", Kind: models.FieldText, TypeID: ptr(printer), Pattern: ptr("[A-Z]+[0-9]+")}
+	for _, f := range []*models.CustomField{ram, plan, toner} {
+		if err := s.Fields.Create(f); err != nil {
+			t.Fatal(err)
+		}
+		if f.FieldID == 0 || f.CreatedAt.IsZero() {
+			t.Errorf("Create did not set the ID and creation time: %+v", f)
+		}
+	}
+	for name, bad := range map[string]*models.CustomField{
+		"duplicate name":       {Name: "ram", Kind: models.FieldText, TypeID: ptr(laptop)},
+		"type and category":    {Name: "CPU", Kind: models.FieldText, TypeID: ptr(laptop), CategoryID: computers},
+		"no scope":             {Name: "CPU", Kind: models.FieldText},
+		"unknown kind":         {Name: "CPU", Kind: "color", TypeID: ptr(laptop)},
+		"enum without options": {Name: "CPU", Kind: models.FieldEnum, TypeID: ptr(laptop)},
+		"missing type":         {Name: "CPU", Kind: models.FieldText, TypeID: ptr(9999)},
+	} {
+		if err := s.Fields.Create(bad); err == nil {
+			t.Errorf("Create with a %s succeeded", name)
+		}
+	}
+
+	all, err := s.Fields.List()
+	if err != nil || len(all) != 3 || all[0].Name != "Support Plan" || all[1].Name != "RAM" ||
+		all[1].TypeName == nil || all[1].CategoryName != "Computer Equipment" {
+		t.Errorf("List = %+v, %v", all, err)
+	}
+	forLaptops, err := s.Fields.ListForType(laptop)
+	if err != nil || len(forLaptops) != 2 || forLaptops[0].FieldID != plan.FieldID || forLaptops[1].FieldID != ram.FieldID {
+		t.Errorf("ListForType = %+v, %v", forLaptops, err)
+	}
+	got, err := s.Fields.Get(plan.FieldID)
+	if err != nil || !equal(got.Options, []string{"Basic", "Premium"}) || got.Required || got.TypeName != nil {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+	if got, _ := s.Fields.Get(ram.FieldID); got.Min == nil || *got.Min != 1 || got.Max != nil || !got.Required {
+		t.Errorf("Get of a number field = %+v", got)
+	}
+
+	a, b := newAsset("EQ-0001", laptop, "Dell"), newAsset("EQ-0002", laptop, "Lenovo")
+	mustCreate(t, s, a, b)
+	for _, v := range []*models.AssetFieldValue{
+		{AssetID: a.AssetID, FieldID: ram.FieldID, Value: "16", UpdatedBy: actor},
+		{AssetID: a.AssetID, FieldID: plan.FieldID, Value: "Premium"},
+		{AssetID: b.AssetID, FieldID: ram.FieldID, Value: "8"},
+		{AssetID: a.AssetID, FieldID: ram.FieldID, Value: "32", UpdatedBy: actor},
+	} {
+		if err := s.Fields.SetValue(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Fields.SetValue(&models.AssetFieldValue{AssetID: "missing", FieldID: ram.FieldID, Value: "4"}); err == nil {
+		t.Error("SetValue on a missing asset succeeded")
+	}
+
+	values, err := s.Fields.ListValues(a.AssetID)
+	if err != nil || len(values) != 2 || values[0].FieldName != "Support Plan" || values[1].Value != "32" ||
+		values[1].Kind != models.FieldNumber {
+		t.Errorf("ListValues = %+v, %v", values, err)
+	}
+	if all, _ := s.Fields.ListAllValues(); len(all) != 3 {
+		t.Errorf("ListAllValues returned %d values, want 3", len(all))
+	}
+
+	for _, tt := range []struct {
+		name string
+		q    repo.AssetQuery
+		want []string
+	}{
+		{"field value", repo.AssetQuery{Fields: map[string]string{"ram": "8"}}, []string{"EQ-0002"}},
+		{"field values", repo.AssetQuery{Fields: map[string]string{"RAM": "32", "support plan": "premium"}}, []string{"EQ-0001"}},
+		{"other field", repo.AssetQuery{Fields: map[string]string{"Toner": "8"}}, nil},
+		{"text matches field values", repo.AssetQuery{Filter: "premi"}, []string{"EQ-0001"}},
+	} {
+		got, err := s.Assets.ListSummaries(tt.q)
+		if err != nil || !equal(tags(got), tt.want) {
+			t.Errorf("%s: got %v, %v, want %v", tt.name, tags(got), err, tt.want)
+		}
+	}
+
+	if err := s.Fields.ClearValue(a.AssetID, plan.FieldID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Fields.ClearValue(a.AssetID, plan.FieldID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ClearValue of a missing value = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.Fields.Delete(ram.FieldID); err != nil {
+		t.Fatal(err)
+	}
+	if values, _ := s.Fields.ListAllValues(); len(values) != 0 {
+		t.Errorf("values left after deleting the field: %+v", values)
+	}
+	if _, err := s.Fields.Get(ram.FieldID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Get of a deleted field = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.Fields.Delete(ram.FieldID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Delete of a missing field = %v, want sql.ErrNoRows", err)
+	}
+
+	// Inserting 32 over 16 is an update; deleting the field removes the rest
+	entries, _ := s.Audit.List(repo.AuditQuery{Entity: "asset_field_values"})
+	ops := map[string]int{}
+	for _, e := range entries {
+		ops[e.Operation]++
+	}
+	if ops[models.AuditInsert] != 3 || ops[models.AuditUpdate] != 1 || ops[models.AuditDelete] != 3 {
+		t.Errorf("audit operations on field values = %v", ops)
+	}
+}
+
+func testFinancials(t *testing.T, s *repo.Stores) {
+	a, b := newAsset("EQ-0001", laptop, "Dell"), newAsset("EQ-0002", laptop, "Lenovo")
+	a.PurchaseCost, a.Currency, a.Supplier = ptr(1200.5), ptr("USD"), ptr("Acme Supplies")
+	a.PONumber, a.InvoiceNumber = ptr("PO-77"), ptr("INV-2024-031")
+	b.Supplier = ptr("Bits & Co")
+	mustCreate(t, s, a, b)
+
+	got, err := s.Assets.GetSummary(a.AssetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PurchaseCost == nil || *got.PurchaseCost != 1200.5 || got.Currency == nil || *got.Currency != "USD" ||
+		got.Supplier == nil || *got.Supplier != "Acme Supplies" || got.PONumber == nil || *got.PONumber != "PO-77" ||
+		got.InvoiceNumber == nil || *got.InvoiceNumber != "INV-2024-031" {
+		t.Errorf("financial fields = %+v", got)
+	}
+	for _, tt := range []struct {
+		name string
+		q    repo.AssetQuery
+		want []string
+	}{
+		{"text matches invoice", repo.AssetQuery{Filter: "inv-2024"}, []string{"EQ-0001"}},
+		{"text matches supplier", repo.AssetQuery{Filter: "bits"}, []string{"EQ-0002"}},
+		{"sort by supplier", repo.AssetQuery{SortColumn: "supplier", SortDesc: true}, []string{"EQ-0002", "EQ-0001"}},
+	} {
+		got, err := s.Assets.ListSummaries(tt.q)
+		if err != nil || !equal(tags(got), tt.want) {
+			t.Errorf("%s: got %v, %v, want %v", tt.name, tags(got), err, tt.want)
+		}
+	}
+
+	changed := *b
+	changed.PurchaseCost = ptr(-1.0)
+	if err := s.Assets.Update(&changed); err == nil {
+		t.Error("Update with a negative purchase cost succeeded")
+	}
+	changed = got.Asset
+	changed.PurchaseCost, changed.Currency = nil, nil
+	if err := s.Assets.Update(&changed); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Assets.GetSummary(a.AssetID); got.PurchaseCost != nil || got.Currency != nil || got.InvoiceNumber == nil {
+		t.Errorf("after clearing the cost: %+v", got)
+	}
+
+	computers, printers := 1, 2
+	straight := &models.DepreciationRule{CategoryID: computers, Method: models.DepreciationStraightLine,
+		UsefulLifeYears: 4, SalvagePercent: 10, UpdatedBy: actor}
+	declining := &models.DepreciationRule{CategoryID: printers, Method: models.DepreciationDecliningBalance,
+		UsefulLifeYears: 5, Factor: ptr(2.0)}
+	for _, rule := range []*models.DepreciationRule{straight, declining} {
+		if err := s.Depreciation.Set(rule); err != nil {
+			t.Fatal(err)
+		}
+		if rule.UpdatedAt.IsZero() {
+			t.Errorf("Set did not set the update time: %+v", rule)
+		}
+	}
+	for name, bad := range map[string]*models.DepreciationRule{
+		"unknown method":       {CategoryID: computers, Method: "sum-of-years", UsefulLifeYears: 3},
+		"no useful life":       {CategoryID: computers, Method: models.DepreciationStraightLine},
+		"full salvage":         {CategoryID: computers, Method: models.DepreciationStraightLine, UsefulLifeYears: 3, SalvagePercent: 100},
+		"straight with factor": {CategoryID: computers, Method: models.DepreciationStraightLine, UsefulLifeYears: 3, Factor: ptr(2.0)},
+		"declining no factor":  {CategoryID: computers, Method: models.DepreciationDecliningBalance, UsefulLifeYears: 3},
+		"missing category":     {CategoryID: 9999, Method: models.DepreciationStraightLine, UsefulLifeYears: 3},
+	} {
+		if err := s.Depreciation.Set(bad); err == nil {
+			t.Errorf("Set with a %s succeeded", name)
+		}
+	}
+
+	rules, err := s.Depreciation.List()
+	if err != nil || len(rules) != 2 || rules[0].CategoryName != "Computer Equipment" ||
+		rules[1].Factor == nil || *rules[1].Factor != 2 {
+		t.Errorf("List = %+v, %v", rules, err)
+	}
+	straight.UsefulLifeYears = 3
+	if err := s.Depreciation.Set(straight); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Depreciation.Get(computers); err != nil || got.UsefulLifeYears != 3 || got.SalvagePercent != 10 ||
+		got.Factor != nil || got.UpdatedBy == nil {
+		t.Errorf("Get after replacing = %+v, %v", got, err)
+	}
+	if err := s.Depreciation.Delete(printers); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Depreciation.Get(printers); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Get of a deleted rule = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.Depreciation.Delete(printers); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Delete of a missing rule = %v, want sql.ErrNoRows", err)
+	}
+
+	entries, _ := s.Audit.List(repo.AuditQuery{Entity: "depreciation_rules"})
+	ops := map[string]int{}
+	for _, e := range entries {
+		ops[e.Operation]++
+	}
+	if ops[models.AuditInsert] != 2 || ops[models.AuditUpdate] != 1 || ops[models.AuditDelete] != 1 {
+		t.Errorf("audit operations on depreciation rules = %v", ops)
+	}
+	if entries, _ := s.Audit.List(repo.AuditQuery{AssetID: a.AssetID}); len(entries) == 0 ||
+		!strings.Contains(string(entries[len(entries)-1].After), `"invoice_number":"INV-2024-031"`) {
+		t.Errorf("asset audit entries = %+v", entries)
+	}
+}
+
+func testViews(t *testing.T, s *repo.Stores) {
+	v := &models.SavedView{Name: "Laptops", Columns: []string{"asset_tag", "model"}, SortColumn: "model", Filter: "laptop"}
+	if err := s.Views.Save(v); err != nil {
+		t.Fatal(err)
+	}
+	other := &models.SavedView{Name: "All", Columns: []string{"asset_tag"}, SortColumn: "asset_tag"}
+	if err := s.Views.Save(other); err != nil {
+		t.Fatal(err)
+	}
+
+	replaced := &models.SavedView{Name: "Laptops", Columns: []string{"asset_tag"}, SortColumn: "asset_tag", SortDesc: true}
+	if err := s.Views.Save(replaced); err != nil {
+		t.Fatal(err)
+	}
+	if replaced.ViewID != v.ViewID {
+		t.Errorf("saving under an existing name gave ID %d, want %d", replaced.ViewID, v.ViewID)
+	}
+
+	views, err := s.Views.List()
+	if err != nil || len(views) != 2 || views[0].Name != "All" {
+		t.Fatalf("List = %v, %v", views, err)
+	}
+	if got := views[1]; !got.SortDesc || !equal(got.Columns, []string{"asset_tag"}) || got.Filter != "" {
+		t.Errorf("replaced view = %+v", got)
+	}
+
+	if err := s.Views.Delete(other.ViewID); err != nil {
+		t.Fatal(err)
+	}
+	if views, _ := s.Views.List(); len(views) != 1 {
+		t.Errorf("%d views after Delete, want 1", len(views))
+	}
+}
+
+func testUsers(t *testing.T, s *repo.Stores) {
+	if n, err := s.Users.Count(); err != nil || n != 0 {
+		t.Fatalf("Count = %d, %v", n, err)
+	}
+	admin := &models.User{Username: "admin", Role: models.RoleAdmin}
+	if err := s.Users.Create(admin, "hash-a"); err != nil {
+		t.Fatal(err)
+	}
+	viewer := &models.User{Username: "Viewer", Role: models.RoleViewer}
+	if err := s.Users.Create(viewer, "hash-v"); err != nil {
+		t.Fatal(err)
+	}
+	if admin.UserID == 0 || admin.CreatedAt.IsZero() {
+		t.Errorf("Create set ID %d, time %v", admin.UserID, admin.CreatedAt)
+	}
+	if err := s.Users.Create(&models.User{Username: "ADMIN", Role: models.RoleViewer}, "x"); err == nil {
+		t.Error("Create with a username differing only in case succeeded")
+	}
+	if err := s.Users.Create(&models.User{Username: "root", Role: "owner"}, "x"); err == nil {
+		t.Error("Create with an unknown role succeeded")
+	}
+
+	u, hash, err := s.Users.FindByUsername("viewer")
+	if err != nil || u.UserID != viewer.UserID || hash != "hash-v" {
+		t.Errorf("FindByUsername = %v %q, %v", u, hash, err)
+	}
+	if _, _, err := s.Users.FindByUsername("nobody"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FindByUsername of a missing user = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.Users.SetPassword(viewer.UserID, "hash-v2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, hash, _ := s.Users.FindByUsername("Viewer"); hash != "hash-v2" {
+		t.Errorf("hash after SetPassword = %q", hash)
+	}
+
+	if err := s.Users.SetRole(admin.UserID, models.RoleViewer); !errors.Is(err, repo.ErrLastAdmin) {
+		t.Errorf("demoting the last admin = %v, want ErrLastAdmin", err)
+	}
+	if err := s.Users.Delete(admin.UserID); !errors.Is(err, repo.ErrLastAdmin) {
+		t.Errorf("deleting the last admin = %v, want ErrLastAdmin", err)
+	}
+	if u, _, _ := s.Users.FindByUsername("admin"); u == nil || u.Role != models.RoleAdmin {
+		t.Errorf("admin after the refused changes: %v", u)
+	}
+	if err := s.Users.SetRole(viewer.UserID, models.RoleTechnician); err != nil {
+		t.Fatal(err)
+	}
+
+	token := &models.APIToken{UserID: viewer.UserID, Name: "ci"}
+	if err := s.Users.CreateToken(token, "token-hash"); err != nil {
+		t.Fatal(err)
+	}
+	owner, err := s.Users.FindByToken("token-hash")
+	if err != nil || owner.UserID != viewer.UserID || owner.Role != models.RoleTechnician {
+		t.Errorf("FindByToken = %v, %v", owner, err)
+	}
+	tokens, err := s.Users.ListTokens()
+	if err != nil || len(tokens) != 1 || tokens[0].Username != "Viewer" || tokens[0].LastUsedAt == nil {
+		t.Errorf("ListTokens = %+v, %v", tokens, err)
+	}
+	if _, err := s.Users.FindByToken("other"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FindByToken of an unknown token = %v, want sql.ErrNoRows", err)
+	}
+
+	users, err := s.Users.List()
+	if err != nil || len(users) != 2 || users[0].Username != "admin" {
+		t.Errorf("List = %v, %v", users, err)
+	}
+	if err := s.Users.Delete(viewer.UserID); err != nil {
+		t.Fatal(err)
+	}
+	if tokens, _ := s.Users.ListTokens(); len(tokens) != 0 {
+		t.Errorf("%d tokens left after deleting their user", len(tokens))
+	}
+	if err := s.Users.DeleteToken(token.TokenID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeleteToken of a removed token = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.Users.Delete(admin.UserID); err != nil {
+		t.Errorf("deleting the only user = %v", err)
+	}
+}
+
+func testAudit(t *testing.T, s *repo.Stores) {
+	a := newAsset("EQ-0001", laptop, "Dell")
+	mustCreate(t, s, a)
+	changed := *a
+	changed.SerialNumber = "SN-NEW"
+	changed.UpdatedBy = ptr("editor")
+	if err := s.Assets.Update(&changed); err != nil {
+		t.Fatal(err)
+	}
+	// Saving an unchanged asset records nothing
+	if err := s.Assets.Update(&changed); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := s.Audit.List(repo.AuditQuery{Entity: "assets", EntityID: a.AssetID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Operation != models.AuditUpdate || entries[1].Operation != models.AuditInsert {
+		t.Fatalf("entries = %+v", entries)
+	}
+	update := entries[0]
+	if update.Actor == nil || *update.Actor != "editor" || update.AssetTag == nil || *update.AssetTag != "EQ-0001" {
+		t.Errorf("update entry = %+v", update)
+	}
+	changes := update.Changes()
+	if len(changes) != 2 || changes[0].Field != "serial_number" || changes[0].After != "SN-NEW" || changes[1].Field != "updated_by" {
+		t.Errorf("changes = %v", changes)
+	}
+
+	if bySerial, _ := s.Audit.List(repo.AuditQuery{AssetID: a.AssetID, Field: "serial_number"}); len(bySerial) != 2 {
+		t.Errorf("%d entries changing the serial number, want the insert and the update", len(bySerial))
+	}
+	if byModel, _ := s.Audit.List(repo.AuditQuery{AssetID: a.AssetID, Field: "model"}); len(byModel) != 1 {
+		t.Errorf("%d entries changing the model, want the insert", len(byModel))
+	}
+	if byActor, _ := s.Audit.List(repo.AuditQuery{Actor: "EDITOR"}); len(byActor) != 1 {
+		t.Errorf("%d entries by editor, want 1", len(byActor))
+	}
+	if limited, _ := s.Audit.List(repo.AuditQuery{AssetID: a.AssetID, Limit: 1}); len(limited) != 1 {
+		t.Errorf("%d entries with a limit of 1", len(limited))
+	}
+	future := day("2999-01-01")
+	if later, _ := s.Audit.List(repo.AuditQuery{Since: &future}); len(later) != 0 {
+		t.Errorf("%d entries in the future", len(later))
+	}
+}
+
+func testEmptyInventory(t *testing.T, s *repo.Stores) {
+	if page, cursor, err := s.Assets.PageSummaries(repo.AssetQuery{}, nil, 10); err != nil || len(page) != 0 || cursor != nil {
+		t.Errorf("PageSummaries = %v %v, %v", page, cursor, err)
+	}
+	if licenses, err := s.Licenses.List(); err != nil || len(licenses) != 0 {
+		t.Errorf("Licenses.List = %v, %v", licenses, err)
+	}
+	if licenses, err := s.Licenses.Search("office", 5); err != nil || len(licenses) != 0 {
+		t.Errorf("Licenses.Search = %v, %v", licenses, err)
+	}
+	if installs, err := s.Licenses.ListByAsset("missing"); err != nil || len(installs) != 0 {
+		t.Errorf("Licenses.ListByAsset = %v, %v", installs, err)
+	}
+	if types, err := s.Consumables.ListTypes(); err != nil || len(types) != 0 {
+		t.Errorf("Consumables.ListTypes = %v, %v", types, err)
+	}
+	if low, err := s.Consumables.ListLowStock(); err != nil || len(low) != 0 {
+		t.Errorf("Consumables.ListLowStock = %v, %v", low, err)
+	}
+	if usage, err := s.Consumables.ListUsageByAsset("missing"); err != nil || len(usage) != 0 {
+		t.Errorf("Consumables.ListUsageByAsset = %v, %v", usage, err)
+	}
+	if entries, err := s.Audit.List(repo.AuditQuery{}); err != nil || len(entries) != 0 {
+		t.Errorf("Audit.List = %d entries, %v", len(entries), err)
+	}
+	if report, err := s.Assets.WarrantyReport(day("2025-01-01"), 30); err != nil || len(report) != 0 {
+		t.Errorf("WarrantyReport = %v, %v", report, err)
+	}
+}
+
+func testUnitOfWork(t *testing.T, s *repo.Stores) {
+	ctx := context.Background()
+	failed := errors.New("failed")
+	e := mustEmployee(t, s, "Ana Ruiz", "ana@example.com")
+
+	// A failure undoes every change of the unit of work
+	err := s.Tx(ctx, func(tx *repo.Stores) error {
+		a := newAsset("EQ-001", laptop, "Dell")
+		if err := tx.Assets.Create(a); err != nil {
+			return err
+		}
+		if err := tx.Assignments.Assign(&models.AssetAssignment{AssetID: a.AssetID, EmployeeID: e.EmployeeID, AssignmentDate: day("2024-02-01")}); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Tx = %v, want the error of fn", err)
+	}
+	if _, err := s.Assets.GetSummaryByTag("EQ-001"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("asset of a failed unit of work: %v", err)
+	}
+	if assignments, _ := s.Assignments.List(); len(assignments) != 0 {
+		t.Errorf("%d assignments kept by a failed unit of work", len(assignments))
+	}
+
+	// Success keeps them, and a nested unit of work joins the enclosing one
+	err = s.Tx(ctx, func(tx *repo.Stores) error {
+		mustCreate(t, tx, newAsset("EQ-002", laptop, "Dell"))
+		return tx.Tx(ctx, func(inner *repo.Stores) error {
+			a, err := inner.Assets.GetSummaryByTag("EQ-002")
+			if err != nil {
+				return err
+			}
+			return inner.Assignments.Assign(&models.AssetAssignment{AssetID: a.AssetID, EmployeeID: e.EmployeeID, AssignmentDate: day("2024-02-01")})
+		})
+	})
+	if err != nil {
+		t.Fatalf("Tx: %v", err)
+	}
+	if a, err := s.Assets.GetSummaryByTag("EQ-002"); err != nil || a.StatusID != models.StatusAssigned {
+		t.Errorf("asset of a committed unit of work = %+v, %v", a, err)
+	}
+
+	// A nested failure returned by the enclosing unit of work undoes both
+	err = s.Tx(ctx, func(tx *repo.Stores) error {
+		mustCreate(t, tx, newAsset("EQ-003", laptop, "Dell"))
+		return tx.Tx(ctx, func(inner *repo.Stores) error { return failed })
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Tx = %v, want the nested error", err)
+	}
+	if _, err := s.Assets.GetSummaryByTag("EQ-003"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("asset of a failed nested unit of work: %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	ran := false
+	if err := s.Tx(cancelled, func(*repo.Stores) error { ran = true; return nil }); err == nil || ran {
+		t.Errorf("Tx with a cancelled context = %v, ran %v", err, ran)
+	}
+}
@@ -0,0 +1,183 @@
+package repo
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type StockRepo struct{ db DBTX }
+
+func NewStockRepo(db DBTX) *StockRepo {
+	return &StockRepo{db: db}
+}
+
+const stockItemSelect = `SELECT i.item_id, i.name, i.type_id, t.type_name, i.reorder_level, i.notes,
+	i.created_by, i.created_at,
+	coalesce((SELECT sum(m.quantity) FROM stock_movements m WHERE m.item_id = i.item_id), 0),
+	coalesce((SELECT -sum(m.quantity) FROM stock_movements m WHERE m.item_id = i.item_id AND m.kind <> 'adjust'), 0)
+FROM stock_items i
+LEFT JOIN asset_types t ON t.type_id = i.type_id`
+
+// ListItems retrieves the stock items with their totals, ordered by name
+func (r *StockRepo) ListItems() ([]*models.StockItem, error) {
+	return r.queryItems(stockItemSelect + ` ORDER BY i.name;`)
+}
+
+// GetItem retrieves a stock item by ID
+// Returns sql.ErrNoRows if the item does not exist
+func (r *StockRepo) GetItem(itemID int) (*models.StockItem, error) {
+	return one(r.queryItems(stockItemSelect+` WHERE i.item_id = ?;`, itemID))
+}
+
+// FindItem retrieves a stock item by name, ignoring case
+// Returns sql.ErrNoRows if no item has the name
+func (r *StockRepo) FindItem(name string) (*models.StockItem, error) {
+	return one(r.queryItems(stockItemSelect+` WHERE i.name = ? COLLATE NOCASE;`, strings.TrimSpace(name)))
+}
+
+// queryItems runs a stockItemSelect based query
+func (r *StockRepo) queryItems(query string, args ...any) ([]*models.StockItem, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.StockItem
+	for rows.Next() {
+		var i models.StockItem
+		var createdAt string
+		if err := rows.Scan(&i.ItemID, &i.Name, &i.TypeID, &i.TypeName, &i.ReorderLevel, &i.Notes,
+			&i.CreatedBy, &createdAt, &i.OnHand, &i.Issued); err != nil {
+			return nil, err
+		}
+		i.CreatedAt = parseTime(createdAt)
+		out = append(out, &i)
+	}
+	return out, rows.Err()
+}
+
+// CreateItem inserts a stock item with nothing in stock, setting its ID and
+// creation time
+func (r *StockRepo) CreateItem(i *models.StockItem) error {
+	var createdAt string
+	if err := r.db.QueryRow(`INSERT INTO stock_items (name, type_id, reorder_level, notes, created_by)
+VALUES (?, ?, ?, ?, ?) RETURNING item_id, created_at;`,
+		i.Name, i.TypeID, i.ReorderLevel, i.Notes, i.CreatedBy).Scan(&i.ItemID, &createdAt); err != nil {
+		return err
+	}
+	i.CreatedAt, i.OnHand, i.Issued = parseTime(createdAt), 0, 0
+	return nil
+}
+
+// ListLevels retrieves the locations holding units of an item, ordered by
+// location name
+func (r *StockRepo) ListLevels(itemID int) ([]*models.StockLevel, error) {
+	rows, err := r.db.Query(`SELECT m.item_id, i.name, m.location_id, l.name, sum(m.quantity)
+FROM stock_movements m
+JOIN stock_items i ON i.item_id = m.item_id
+JOIN locations l ON l.location_id = m.location_id
+WHERE m.item_id = ?
+GROUP BY m.location_id
+HAVING sum(m.quantity) <> 0
+ORDER BY l.name;`, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.StockLevel
+	for rows.Next() {
+		var l models.StockLevel
+		if err := rows.Scan(&l.ItemID, &l.ItemName, &l.LocationID, &l.LocationName, &l.Quantity); err != nil {
+			return nil, err
+		}
+		out = append(out, &l)
+	}
+	return out, rows.Err()
+}
+
+// ListHoldings retrieves the employees holding units of an item, ordered by
+// employee name
+func (r *StockRepo) ListHoldings(itemID int) ([]*models.StockHolding, error) {
+	return r.queryHoldings(`WHERE m.item_id = ?`, `e.full_name`, itemID)
+}
+
+// ListHoldingsByEmployee retrieves the items an employee holds units of,
+// ordered by item name
+func (r *StockRepo) ListHoldingsByEmployee(employeeID int) ([]*models.StockHolding, error) {
+	return r.queryHoldings(`WHERE m.employee_id = ?`, `i.name`, employeeID)
+}
+
+// queryHoldings sums the issues and returns matching where, keeping the
+// employees left holding units
+func (r *StockRepo) queryHoldings(where, orderBy string, args ...any) ([]*models.StockHolding, error) {
+	rows, err := r.db.Query(`SELECT m.item_id, i.name, m.employee_id, e.full_name, -sum(m.quantity)
+FROM stock_movements m
+JOIN stock_items i ON i.item_id = m.item_id
+JOIN employees e ON e.employee_id = m.employee_id
+`+where+`
+GROUP BY m.item_id, m.employee_id
+HAVING -sum(m.quantity) > 0
+ORDER BY `+orderBy+`;`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.StockHolding
+	for rows.Next() {
+		var h models.StockHolding
+		if err := rows.Scan(&h.ItemID, &h.ItemName, &h.EmployeeID, &h.EmployeeName, &h.Quantity); err != nil {
+			return nil, err
+		}
+		out = append(out, &h)
+	}
+	return out, rows.Err()
+}
+
+// ListMovements retrieves the movements of an item, newest first
+func (r *StockRepo) ListMovements(itemID int) ([]*models.StockMovement, error) {
+	rows, err := r.db.Query(`SELECT m.movement_id, m.item_id, i.name, m.location_id, l.name, m.kind,
+	m.quantity, m.employee_id, e.full_name, m.moved_at, m.notes, m.moved_by
+FROM stock_movements m
+JOIN stock_items i ON i.item_id = m.item_id
+JOIN locations l ON l.location_id = m.location_id
+LEFT JOIN employees e ON e.employee_id = m.employee_id
+WHERE m.item_id = ?
+ORDER BY m.moved_at DESC, m.movement_id DESC;`, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.StockMovement
+	for rows.Next() {
+		var m models.StockMovement
+		var movedAt string
+		var employeeName sql.NullString
+		if err := rows.Scan(&m.MovementID, &m.ItemID, &m.ItemName, &m.LocationID, &m.LocationName, &m.Kind,
+			&m.Quantity, &m.EmployeeID, &employeeName, &movedAt, &m.Notes, &m.MovedBy); err != nil {
+			return nil, err
+		}
+		if employeeName.Valid {
+			m.EmployeeName = &employeeName.String
+		}
+		m.MovedAt = parseTime(movedAt)
+		out = append(out, &m)
+	}
+	return out, rows.Err()
+}
+
+// RecordMovement inserts a stock movement, setting its ID
+// The stock it leaves is not checked here; the schema only enforces the
+// sign of the quantity for each kind
+func (r *StockRepo) RecordMovement(m *models.StockMovement) error {
+	return r.db.QueryRow(`INSERT INTO stock_movements (item_id, location_id, kind, quantity, employee_id,
+	moved_at, notes, moved_by)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING movement_id;`,
+		m.ItemID, m.LocationID, m.Kind, m.Quantity, m.EmployeeID,
+		m.MovedAt.Format(TimestampLayout), m.Notes, m.MovedBy).Scan(&m.MovementID)
+}
@@ -0,0 +1,275 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+// AssetStore is implemented by AssetRepo and its in-memory double
+type AssetStore interface {
+	List() ([]*models.Asset, error)
+	ListSummaries(q AssetQuery) ([]*models.AssetSummary, error)
+	PageSummaries(q AssetQuery, after *AssetCursor, limit int) ([]*models.AssetSummary, *AssetCursor, error)
+	GetSummary(assetID string) (*models.AssetSummary, error)
+	GetSummaryByTag(assetTag string) (*models.AssetSummary, error)
+	CountSummaries(q AssetQuery) (int, error)
+	GetAssetCategories() ([]*models.AssetCategory, error)
+	GetAssetTypes(category int) ([]*models.AssetType, error)
+	Create(a *models.Asset) error
+	Update(a *models.Asset) error
+	Retire(assetID string, date time.Time, actor *string) error
+	CountByStatus() ([]*models.CountBy, error)
+	CountByCategory() ([]*models.CountBy, error)
+	WarrantyReport(day time.Time, soonDays int) ([]*models.WarrantyVendorSummary, error)
+	GetAssetType(typeID int) (*models.AssetType, error)
+	FindAssetType(name string) (*models.AssetType, error)
+	FindAssetCategory(name string) (*models.AssetCategory, error)
+	FindAssetStatus(name string) (*models.AssetStatus, error)
+	GetAssetStatuses() ([]*models.AssetStatus, error)
+}
+
+// AssignmentStore is implemented by AssignmentRepo and its in-memory double
+type AssignmentStore interface {
+	List() ([]*models.AssetAssignment, error)
+	ListByAsset(assetID string) ([]*models.AssetAssignment, error)
+	Assign(a *models.AssetAssignment) error
+	Return(assetID string, date time.Time, actor *string) error
+}
+
+// AuditStore is implemented by AuditRepo and its in-memory double
+type AuditStore interface {
+	List(q AuditQuery) ([]*models.AuditEntry, error)
+}
+
+// ConsumableStore is implemented by ConsumableRepo and its in-memory double
+type ConsumableStore interface {
+	ListUsageByAsset(assetID string) ([]*models.ConsumableUsage, error)
+	ListTypes() ([]*models.ConsumableType, error)
+	ListLowStock() ([]*models.ConsumableType, error)
+	CreateType(c *models.ConsumableType) error
+	RecordUsage(u *models.ConsumableUsage) error
+}
+
+// DepreciationStore is implemented by DepreciationRepo and its in-memory
+// double
+type DepreciationStore interface {
+	List() ([]*models.DepreciationRule, error)
+	Get(categoryID int) (*models.DepreciationRule, error)
+	Set(rule *models.DepreciationRule) error
+	Delete(categoryID int) error
+}
+
+// EmployeeStore is implemented by EmployeeRepo and its in-memory double
+type EmployeeStore interface {
+	List() ([]*models.Employee, error)
+	Create(e *models.Employee) error
+	Get(employeeID int) (*models.Employee, error)
+	FindByEmail(email string) (*models.Employee, error)
+	FindByName(name string) ([]*models.Employee, error)
+	Resolve(nameOrEmail string) (*models.Employee, error)
+	Search(text string, limit int) ([]*models.Employee, error)
+}
+
+// FieldStore is implemented by FieldRepo and its in-memory double
+type FieldStore interface {
+	List() ([]*models.CustomField, error)
+	ListForType(typeID int) ([]*models.CustomField, error)
+	Get(fieldID int) (*models.CustomField, error)
+	Create(f *models.CustomField) error
+	Delete(fieldID int) error
+	ListValues(assetID string) ([]*models.AssetFieldValue, error)
+	ListAllValues() ([]*models.AssetFieldValue, error)
+	SetValue(v *models.AssetFieldValue) error
+	ClearValue(assetID string, fieldID int) error
+}
+
+// KitStore is implemented by KitRepo and its in-memory double
+type KitStore interface {
+	ListTemplates() ([]*models.KitTemplate, error)
+	FindTemplate(name string) (*models.KitTemplate, error)
+	GetTemplate(templateID int) (*models.KitTemplate, error)
+	CreateTemplate(t *models.KitTemplate) error
+	List() ([]*models.Kit, error)
+	Get(kitID int) (*models.Kit, error)
+	GetByTag(kitTag string) (*models.Kit, error)
+	FindByAsset(assetID string) (*models.Kit, error)
+	Create(k *models.Kit) error
+	AddAsset(kitID int, assetID string, actor *string) error
+	RemoveAsset(kitID int, assetID string) error
+	Delete(kitID int) error
+}
+
+// LicenseStore is implemented by LicenseRepo and its in-memory double
+type LicenseStore interface {
+	List() ([]*models.SoftwareLicense, error)
+	Search(text string, limit int) ([]*models.SoftwareLicense, error)
+	ListByAsset(assetID string) ([]*models.LicenseAssignment, error)
+	Create(l *models.SoftwareLicense) error
+	Assign(a *models.LicenseAssignment) error
+}
+
+// LocationStore is implemented by LocationRepo and its in-memory double
+type LocationStore interface {
+	List() ([]*models.Location, error)
+	FindByName(name string) (*models.Location, error)
+}
+
+// MaintenanceStore is implemented by MaintenanceRepo and its in-memory double
+type MaintenanceStore interface {
+	List() ([]*models.MaintenanceLog, error)
+	ListByAsset(assetID string) ([]*models.MaintenanceLog, error)
+	Create(m *models.MaintenanceLog) error
+	FindType(name string) (int, string, error)
+}
+
+// NoteStore is implemented by NoteRepo and its in-memory double
+type NoteStore interface {
+	ListComments(assetID string) ([]*models.AssetComment, error)
+	AddComment(c *models.AssetComment) error
+	ListAttachments(assetID string) ([]*models.AssetAttachment, error)
+	AddAttachment(a *models.AssetAttachment) error
+}
+
+// RelationStore is implemented by RelationRepo and its in-memory double
+type RelationStore interface {
+	GetParent(childID string) (*models.AssetRelation, error)
+	ListChildren(parentID string) ([]*models.AssetRelation, error)
+	Create(rel *models.AssetRelation) error
+	Delete(childID string) error
+}
+
+// StockStore is implemented by StockRepo and its in-memory double
+type StockStore interface {
+	ListItems() ([]*models.StockItem, error)
+	GetItem(itemID int) (*models.StockItem, error)
+	FindItem(name string) (*models.StockItem, error)
+	CreateItem(i *models.StockItem) error
+	ListLevels(itemID int) ([]*models.StockLevel, error)
+	ListHoldings(itemID int) ([]*models.StockHolding, error)
+	ListHoldingsByEmployee(employeeID int) ([]*models.StockHolding, error)
+	ListMovements(itemID int) ([]*models.StockMovement, error)
+	RecordMovement(m *models.StockMovement) error
+}
+
+// TransferStore is implemented by TransferRepo and its in-memory double
+type TransferStore interface {
+	ListByAsset(assetID string) ([]*models.AssetTransfer, error)
+}
+
+// UserStore is implemented by UserRepo and its in-memory double
+type UserStore interface {
+	Count() (int, error)
+	List() ([]*models.User, error)
+	FindByUsername(username string) (*models.User, string, error)
+	Create(u *models.User, passwordHash string) error
+	SetPassword(userID int, passwordHash string) error
+	SetRole(userID int, role string) error
+	Delete(userID int) error
+	CreateToken(t *models.APIToken, tokenHash string) error
+	FindByToken(tokenHash string) (*models.User, error)
+	ListTokens() ([]*models.APIToken, error)
+	DeleteToken(tokenID int) error
+}
+
+// ViewStore is implemented by ViewRepo and its in-memory double
+type ViewStore interface {
+	List() ([]*models.SavedView, error)
+	Save(v *models.SavedView) error
+	Delete(viewID int) error
+}
+
+var (
+	_ AssetStore        = (*AssetRepo)(nil)
+	_ AssignmentStore   = (*AssignmentRepo)(nil)
+	_ AuditStore        = (*AuditRepo)(nil)
+	_ ConsumableStore   = (*ConsumableRepo)(nil)
+	_ DepreciationStore = (*DepreciationRepo)(nil)
+	_ EmployeeStore     = (*EmployeeRepo)(nil)
+	_ FieldStore        = (*FieldRepo)(nil)
+	_ KitStore          = (*KitRepo)(nil)
+	_ LicenseStore      = (*LicenseRepo)(nil)
+	_ LocationStore     = (*LocationRepo)(nil)
+	_ MaintenanceStore  = (*MaintenanceRepo)(nil)
+	_ NoteStore         = (*NoteRepo)(nil)
+	_ RelationStore     = (*RelationRepo)(nil)
+	_ StockStore        = (*StockRepo)(nil)
+	_ TransferStore     = (*TransferRepo)(nil)
+	_ UserStore         = (*UserRepo)(nil)
+	_ ViewStore         = (*ViewRepo)(nil)
+)
+
+// Stores bundles one store of each kind, so callers can be handed either the
+// SQLite repositories or in-memory doubles
+type Stores struct {
+	Assets       AssetStore
+	Assignments  AssignmentStore
+	Audit        AuditStore
+	Consumables  ConsumableStore
+	Depreciation DepreciationStore
+	Employees    EmployeeStore
+	Fields       FieldStore
+	Kits         KitStore
+	Licenses     LicenseStore
+	Locations    LocationStore
+	Maintenance  MaintenanceStore
+	Notes        NoteStore
+	Relations    RelationStore
+	Stock        StockStore
+	Transfers    TransferStore
+	Users        UserStore
+	Views        ViewStore
+
+	// Tx runs fn as one unit of work: the changes made through tx are
+	// committed together when fn returns nil and discarded when it returns
+	// an error
+	// Inside a unit of work, Tx runs fn as part of the enclosing one
+	Tx func(ctx context.Context, fn func(tx *Stores) error) error
+}
+
+// NewStores returns the SQLite repositories over conn, which is either the
+// database or a transaction the stores take part in
+func NewStores(conn DBTX) *Stores {
+	s := &Stores{
+		Assets:       NewAssetRepo(conn),
+		Assignments:  NewAssignmentRepo(conn),
+		Audit:        NewAuditRepo(conn),
+		Consumables:  NewConsumableRepo(conn),
+		Depreciation: NewDepreciationRepo(conn),
+		Employees:    NewEmployeeRepo(conn),
+		Fields:       NewFieldRepo(conn),
+		Kits:         NewKitRepo(conn),
+		Licenses:     NewLicenseRepo(conn),
+		Locations:    NewLocationRepo(conn),
+		Maintenance:  NewMaintenanceRepo(conn),
+		Notes:        NewNoteRepo(conn),
+		Relations:    NewRelationRepo(conn),
+		Stock:        NewStockRepo(conn),
+		Transfers:    NewTransferRepo(conn),
+		Users:        NewUserRepo(conn),
+		Views:        NewViewRepo(conn),
+	}
+	s.Tx = func(ctx context.Context, fn func(tx *Stores) error) error {
+		db, ok := conn.(*sql.DB)
+		if !ok {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fn(s)
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := fn(NewStores(tx)); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	return s
+}
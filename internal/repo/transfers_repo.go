@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type TransferRepo struct{ db DBTX }
+
+func NewTransferRepo(db DBTX) *TransferRepo {
+	return &TransferRepo{db: db}
+}
+
+// ListByAsset retrieves the location transfers of an asset, newest first
+func (r *TransferRepo) ListByAsset(assetID string) ([]*models.AssetTransfer, error) {
+	rows, err := r.db.Query(`SELECT t.transfer_id, t.asset_id, t.from_location_id, lf.name,
+	t.to_location_id, lt.name, t.transfer_date, t.notes, t.transferred_by
+FROM asset_transfers t
+JOIN locations lf ON lf.location_id = t.from_location_id
+JOIN locations lt ON lt.location_id = t.to_location_id
+WHERE t.asset_id = ?
+ORDER BY t.transfer_date DESC, t.transfer_id DESC;`, assetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.AssetTransfer
+	for rows.Next() {
+		var t models.AssetTransfer
+		var transferDate string
+
+		if err := rows.Scan(&t.TransferID, &t.AssetID, &t.FromLocationID, &t.FromLocationName,
+			&t.ToLocationID, &t.ToLocationName, &transferDate, &t.Notes, &t.TransferredBy); err != nil {
+			return nil, err
+		}
+		t.TransferDate = parseTime(transferDate)
+		out = append(out, &t)
+	}
+
+	return out, rows.Err()
+}
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// DBTX is implemented by *sql.DB and *sql.Tx, so repositories can work
+// inside a transaction started by the caller
+type DBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// inTx runs fn atomically
+// On a *sql.DB it runs in a new transaction; inside a caller's transaction
+// it runs in a savepoint, so a failure only undoes fn's own statements
+func inTx(db DBTX, fn func(tx DBTX) error) error {
+	if conn, ok := db.(*sql.DB); ok {
+		tx, err := conn.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if _, err := db.Exec(`SAVEPOINT repo`); err != nil {
+		return err
+	}
+	if err := fn(db); err != nil {
+		if _, rbErr := db.Exec(`ROLLBACK TO repo`); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		db.Exec(`RELEASE repo`)
+		return err
+	}
+	_, err := db.Exec(`RELEASE repo`)
+	return err
+}
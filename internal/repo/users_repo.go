@@ -0,0 +1,195 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+// ErrLastAdmin is returned when removing or demoting the only admin
+var ErrLastAdmin = errors.New("at least one admin is required")
+
+type UserRepo struct{ db DBTX }
+
+func NewUserRepo(db DBTX) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+const userSelect = `SELECT user_id, username, role, created_at
+FROM users`
+
+// Count returns how many users exist
+func (r *UserRepo) Count() (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT count(*) FROM users;`).Scan(&count)
+	return count, err
+}
+
+// List retrieves every user ordered by username
+func (r *UserRepo) List() ([]*models.User, error) {
+	rows, err := r.db.Query(userSelect + ` ORDER BY username;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+
+	return out, rows.Err()
+}
+
+// FindByUsername retrieves a user and their password hash by username,
+// ignoring case
+// Returns sql.ErrNoRows if there is no such user
+func (r *UserRepo) FindByUsername(username string) (*models.User, string, error) {
+	var hash string
+	u, err := scanUser(r.db.QueryRow(`SELECT user_id, username, role, created_at, password_hash
+FROM users WHERE username = ?;`, username), &hash)
+	return u, hash, err
+}
+
+// Create inserts a user with an already hashed password, setting its ID
+func (r *UserRepo) Create(u *models.User, passwordHash string) error {
+	var createdAt string
+	err := r.db.QueryRow(`INSERT INTO users (username, password_hash, role)
+VALUES (?, ?, ?) RETURNING user_id, created_at;`, u.Username, passwordHash, u.Role).Scan(&u.UserID, &createdAt)
+	u.CreatedAt = parseTime(createdAt)
+	return err
+}
+
+// SetPassword replaces the password hash of a user
+func (r *UserRepo) SetPassword(userID int, passwordHash string) error {
+	return execOne(r.db, `UPDATE users SET password_hash = ? WHERE user_id = ?;`, passwordHash, userID)
+}
+
+// SetRole changes the role of a user
+// Returns ErrLastAdmin when demoting the only admin
+func (r *UserRepo) SetRole(userID int, role string) error {
+	return inTx(r.db, func(tx DBTX) error {
+		if err := execOne(tx, `UPDATE users SET role = ? WHERE user_id = ?;`, role, userID); err != nil {
+			return err
+		}
+		return checkAdmins(tx)
+	})
+}
+
+// Delete removes a user and their API tokens
+// Returns ErrLastAdmin when removing the only admin
+func (r *UserRepo) Delete(userID int) error {
+	return inTx(r.db, func(tx DBTX) error {
+		if _, err := tx.Exec(`DELETE FROM api_tokens WHERE user_id = ?;`, userID); err != nil {
+			return err
+		}
+		if err := execOne(tx, `DELETE FROM users WHERE user_id = ?;`, userID); err != nil {
+			return err
+		}
+		return checkAdmins(tx)
+	})
+}
+
+// CreateToken records a new API token by the hash of its secret, setting
+// its ID and creation time
+func (r *UserRepo) CreateToken(t *models.APIToken, tokenHash string) error {
+	var createdAt string
+	err := r.db.QueryRow(`INSERT INTO api_tokens (user_id, name, token_hash)
+VALUES (?, ?, ?) RETURNING token_id, created_at;`, t.UserID, t.Name, tokenHash).Scan(&t.TokenID, &createdAt)
+	t.CreatedAt = parseTime(createdAt)
+	return err
+}
+
+// FindByToken retrieves the user owning the token with the given hash and
+// records the token as used
+// Returns sql.ErrNoRows if no token has this hash
+func (r *UserRepo) FindByToken(tokenHash string) (*models.User, error) {
+	u, err := scanUser(r.db.QueryRow(`SELECT u.user_id, u.username, u.role, u.created_at
+FROM api_tokens t JOIN users u ON u.user_id = t.user_id
+WHERE t.token_hash = ?;`, tokenHash))
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.db.Exec(`UPDATE api_tokens SET last_used_at = ? WHERE token_hash = ?;`,
+		time.Now().UTC().Format(TimestampLayout), tokenHash)
+	return u, err
+}
+
+// ListTokens retrieves the API tokens of every user, oldest first
+func (r *UserRepo) ListTokens() ([]*models.APIToken, error) {
+	rows, err := r.db.Query(`SELECT t.token_id, t.user_id, u.username, t.name, t.created_at, t.last_used_at
+FROM api_tokens t JOIN users u ON u.user_id = t.user_id
+ORDER BY t.created_at, t.token_id;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.APIToken
+	for rows.Next() {
+		var t models.APIToken
+		var createdAt string
+		var lastUsedAt sql.NullString
+
+		if err := rows.Scan(&t.TokenID, &t.UserID, &t.Username, &t.Name, &createdAt, &lastUsedAt); err != nil {
+			return nil, err
+		}
+		t.CreatedAt = parseTime(createdAt)
+		t.LastUsedAt = parseNullTime(lastUsedAt)
+		out = append(out, &t)
+	}
+
+	return out, rows.Err()
+}
+
+// DeleteToken revokes an API token
+// Returns sql.ErrNoRows if there is no such token
+func (r *UserRepo) DeleteToken(tokenID int) error {
+	return execOne(r.db, `DELETE FROM api_tokens WHERE token_id = ?;`, tokenID)
+}
+
+// scanUser scans the columns of userSelect followed by any extra
+// destinations
+func scanUser(sc scanner, extra ...any) (*models.User, error) {
+	var u models.User
+	var createdAt string
+	if err := sc.Scan(append([]any{&u.UserID, &u.Username, &u.Role, &createdAt}, extra...)...); err != nil {
+		return nil, err
+	}
+	u.CreatedAt = parseTime(createdAt)
+	return &u, nil
+}
+
+// checkAdmins returns ErrLastAdmin when users exist but none is an admin
+func checkAdmins(db DBTX) error {
+	var users, admins int
+	if err := db.QueryRow(`SELECT count(*), count(*) FILTER (WHERE role = ?) FROM users;`,
+		models.RoleAdmin).Scan(&users, &admins); err != nil {
+		return err
+	}
+	if users > 0 && admins == 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// execOne runs a statement that must affect exactly one row
+// Returns sql.ErrNoRows when it affects none
+func execOne(db DBTX, query string, args ...any) error {
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+)
+
+type ViewRepo struct{ db DBTX }
+
+func NewViewRepo(db DBTX) *ViewRepo {
+	return &ViewRepo{db: db}
+}
+
+func (r *ViewRepo) List() ([]*models.SavedView, error) {
+	rows, err := r.db.Query(`SELECT view_id, name, columns, sort_column, sort_desc, filter
+FROM saved_views ORDER BY name;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*models.SavedView
+	for rows.Next() {
+		var v models.SavedView
+		var columns string
+
+		if err := rows.Scan(&v.ViewID, &v.Name, &columns, &v.SortColumn, &v.SortDesc, &v.Filter); err != nil {
+			return nil, err
+		}
+		v.Columns = strings.Split(columns, ",")
+		out = append(out, &v)
+	}
+
+	return out, rows.Err()
+}
+
+// Save stores the view under its name, replacing any view with the same name
+func (r *ViewRepo) Save(v *models.SavedView) error {
+	row := r.db.QueryRow(`INSERT INTO saved_views (name, columns, sort_column, sort_desc, filter)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (name) DO UPDATE SET columns = excluded.columns, sort_column = excluded.sort_column,
+	sort_desc = excluded.sort_desc, filter = excluded.filter
+RETURNING view_id;`, v.Name, strings.Join(v.Columns, ","), v.SortColumn, v.SortDesc, v.Filter)
+
+	return row.Scan(&v.ViewID)
+}
+
+func (r *ViewRepo) Delete(viewID int) error {
+	_, err := r.db.Exec(`DELETE FROM saved_views WHERE view_id = ?;`, viewID)
+	return err
+}
@@ -0,0 +1,131 @@
+package seed
+
+// product is a make and model sold as an asset type
+type product struct{ make, model string }
+
+// products lists makes and models by asset type; types not listed get a
+// make of their category and a numbered model
+var products = map[string][]product{
+	"Laptop": {
+		{"Dell", "Latitude 5440"}, {"Dell", "Latitude 7440"}, {"Lenovo", "ThinkPad T14 Gen 4"},
+		{"Lenovo", "ThinkPad X1 Carbon Gen 11"}, {"HP", "EliteBook 840 G10"}, {"HP", "ProBook 450 G10"},
+		{"Apple", "MacBook Air 13 M2"}, {"Apple", "MacBook Pro 14 M3"},
+	},
+	"Desktop":               {{"Dell", "OptiPlex 7010"}, {"HP", "ProDesk 400 G9"}, {"Lenovo", "ThinkCentre M70s Gen 4"}},
+	"All-in-One":            {{"HP", "EliteOne 840 G9"}, {"Dell", "OptiPlex 7410 AIO"}, {"Apple", "iMac 24"}},
+	"Mini PC":               {{"Intel", "NUC 13 Pro"}, {"Lenovo", "ThinkCentre M75q Gen 2"}, {"HP", "Elite Mini 800 G9"}},
+	"Workstation":           {{"Dell", "Precision 3660"}, {"HP", "Z4 G5"}, {"Lenovo", "ThinkStation P3"}},
+	"Thin Client":           {{"HP", "t640"}, {"Dell", "Wyse 5070"}},
+	"Laser Printer":         {{"HP", "LaserJet Pro M404dn"}, {"Brother", "HL-L6200DW"}},
+	"Inkjet Printer":        {{"Epson", "EcoTank L6270"}, {"HP", "OfficeJet Pro 9015e"}},
+	"Multifunction Printer": {{"HP", "LaserJet MFP M528"}, {"Canon", "imageRUNNER 2630i"}, {"Ricoh", "IM 430F"}},
+	"Label Printer":         {{"Zebra", "ZD421"}, {"Brother", "QL-820NWB"}},
+	"Router":                {{"Cisco", "ISR 1111"}, {"MikroTik", "RB5009"}},
+	"Switch":                {{"Cisco", "Catalyst 9200L"}, {"Aruba", "CX 6100"}, {"Ubiquiti", "USW-Pro-48"}},
+	"Firewall":              {{"Fortinet", "FortiGate 60F"}, {"Palo Alto", "PA-440"}},
+	"Access Point":          {{"Ubiquiti", "U6-Pro"}, {"Aruba", "AP-515"}, {"Cisco", "Meraki MR36"}},
+	"Smartphone":            {{"Apple", "iPhone 14"}, {"Apple", "iPhone 15"}, {"Samsung", "Galaxy S23"}, {"Samsung", "Galaxy A54"}},
+	"Tablet":                {{"Apple", "iPad 10th Gen"}, {"Samsung", "Galaxy Tab S9"}},
+	"Monitor": {
+		{"Dell", "P2423D"}, {"Dell", "U2723QE"}, {"LG", "27UP850"}, {"HP", "E24 G5"}, {"Lenovo", "ThinkVision T24i-30"},
+	},
+	"Rack Server":       {{"Dell", "PowerEdge R650"}, {"HPE", "ProLiant DL360 Gen10"}},
+	"Tower Server":      {{"Dell", "PowerEdge T350"}, {"Lenovo", "ThinkSystem ST250 V2"}},
+	"NAS":               {{"Synology", "DS923+"}, {"QNAP", "TS-464"}},
+	"External SSD":      {{"Samsung", "T7 1TB"}, {"SanDisk", "Extreme Portable 1TB"}},
+	"Keyboard":          {{"Logitech", "MX Keys"}, {"Logitech", "K120"}, {"Microsoft", "Wired Keyboard 600"}},
+	"Mouse":             {{"Logitech", "MX Master 3S"}, {"Logitech", "M185"}, {"Microsoft", "Basic Optical Mouse"}},
+	"Headset":           {{"Jabra", "Evolve2 65"}, {"Poly", "Voyager 4320"}, {"Logitech", "Zone Wired"}},
+	"Webcam":            {{"Logitech", "C920"}, {"Logitech", "Brio 4K"}},
+	"Docking Station":   {{"Dell", "WD19S"}, {"Lenovo", "ThinkPad USB-C Dock Gen 2"}, {"HP", "USB-C Dock G5"}},
+	"Projector":         {{"Epson", "EB-L200F"}, {"BenQ", "MH560"}},
+	"Conference Camera": {{"Logitech", "Rally Bar"}, {"Poly", "Studio X30"}},
+	"UPS":               {{"APC", "Smart-UPS 1500"}, {"Eaton", "5SC 1000"}},
+	"PDU":               {{"APC", "AP7900B"}, {"Eaton", "ePDU G3"}},
+}
+
+// categoryMakes lists the makes used for types without listed products, by
+// category prefix
+var categoryMakes = map[string][]string{
+	"EQ": {"Dell", "HP", "Lenovo"},
+	"PR": {"HP", "Brother", "Epson"},
+	"SC": {"Fujitsu", "Epson", "Zebra", "Honeywell"},
+	"NT": {"Cisco", "Ubiquiti", "TP-Link"},
+	"MD": {"Zebra", "Samsung", "Honeywell"},
+	"MS": {"Samsung", "LG"},
+	"SR": {"Dell", "HPE", "Supermicro"},
+	"SD": {"Kingston", "SanDisk", "Seagate", "WD"},
+	"AC": {"Logitech", "Dell", "Belkin"},
+	"AV": {"Shure", "Yamaha", "Bose"},
+	"TI": {"Fluke", "Klein Tools", "Panduit"},
+	"PE": {"APC", "Eaton", "Tripp Lite"},
+}
+
+// categoryWeights sets how common the assets of each category prefix are;
+// other categories weigh 1
+var categoryWeights = map[string]int{
+	"EQ": 30, "MS": 18, "AC": 15, "MD": 10, "PR": 5, "NT": 4, "SD": 4,
+	"AV": 3, "PE": 3, "SC": 2, "SR": 2, "TI": 1,
+}
+
+// heldCategories are the category prefixes of assets handed to employees
+var heldCategories = map[string]bool{"EQ": true, "MD": true, "MS": true, "AC": true}
+
+// maintainedCategories are the category prefixes of assets with maintenance
+// logs
+var maintainedCategories = map[string]bool{"EQ": true, "PR": true, "SR": true, "NT": true, "AV": true, "PE": true}
+
+var firstNames = []string{
+	"Ana", "Bruno", "Carla", "Diego", "Elena", "Fernando", "Gabriela", "Hector", "Irene", "Javier",
+	"Karen", "Luis", "Maria", "Nicolas", "Olga", "Pablo", "Raquel", "Sergio", "Teresa", "Victor",
+	"Adriana", "Carlos", "Daniela", "Eduardo", "Fatima", "Gustavo", "Isabel", "Jorge", "Laura", "Miguel",
+	"Natalia", "Oscar", "Patricia", "Ricardo", "Sofia", "Tomas", "Valeria", "Ximena", "Yolanda", "Andres",
+}
+
+var lastNames = []string{
+	"Garcia", "Martinez", "Lopez", "Hernandez", "Gonzalez", "Perez", "Rodriguez", "Sanchez", "Ramirez", "Cruz",
+	"Flores", "Gomez", "Morales", "Vazquez", "Reyes", "Jimenez", "Torres", "Diaz", "Gutierrez", "Ruiz",
+	"Mendoza", "Aguilar", "Ortiz", "Castillo", "Romero", "Alvarez", "Chavez", "Rivera", "Juarez", "Medina",
+	"Herrera", "Castro", "Vargas", "Moreno", "Silva", "Rojas", "Nunez", "Guerrero", "Soto", "Ceron",
+}
+
+// software lists the licensed programs, with the share of computers each
+// is installed on
+var software = []struct {
+	name, kind string
+	share      float64
+}{
+	{"Microsoft 365 E3", "Subscription", 0.95},
+	{"Windows 11 Pro", "Volume", 0.9},
+	{"ESET Endpoint Security", "Subscription", 0.9},
+	{"Adobe Acrobat Pro", "Subscription", 0.3},
+	{"Zoom Workplace Pro", "Subscription", 0.2},
+	{"WinRAR", "Retail", 0.1},
+	{"JetBrains All Products Pack", "Subscription", 0.08},
+	{"Visual Studio Professional", "Subscription", 0.06},
+	{"Adobe Creative Cloud", "Subscription", 0.05},
+	{"AutoCAD LT", "Subscription", 0.04},
+}
+
+// consumables lists the supplies kept for printers
+var consumables = []struct{ name, partNumber, manufacturer string }{
+	{"HP 58A Black Toner", "CF258A", "HP"},
+	{"HP 26X Black Toner", "CF226X", "HP"},
+	{"HP 32A Imaging Drum", "CF232A", "HP"},
+	{"Brother TN-850 Toner", "TN-850", "Brother"},
+	{"Brother DR-820 Drum", "DR-820", "Brother"},
+	{"Brother DK-2205 Label Roll", "DK-2205", "Brother"},
+	{"Canon C-EXV 42 Toner", "6908B003", "Canon"},
+	{"Ricoh IM 430 Toner", "418126", "Ricoh"},
+	{"Epson 522 Black Ink Bottle", "T522120", "Epson"},
+	{"Zebra 2300 Wax Ribbon", "02300BK11045", "Zebra"},
+}
+
+// maintenanceWork lists the work descriptions by maintenance type
+var maintenanceWork = map[string][]string{
+	"Preventive": {"Cleaned and inspected", "Updated firmware", "Checked battery health", "Replaced thermal paste", "Cleaned fans and vents"},
+	"Corrective": {"Replaced battery", "Replaced keyboard", "Replaced cooling fan", "Reinstalled operating system", "Replaced power supply", "Replaced damaged cable"},
+	"Upgrade":    {"Added 16 GB of RAM", "Replaced hard drive with SSD", "Upgraded to Windows 11", "Installed larger SSD"},
+}
+
+var technicians = []string{"IT Help Desk", "Carlos Mendez", "Laura Rivera", "Dell ProSupport", "HP Care Pack", "Lenovo Premier Support"}
@@ -0,0 +1,529 @@
+// Package seed generates demo inventories: employees, assets across every
+// category with their assignment history, maintenance logs, software
+// licenses and consumable usage
+// The same options always generate the same records, apart from the asset
+// IDs the stores assign
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// ErrNotEmpty is returned when seeding an inventory that already has assets
+// or employees
+var ErrNotEmpty = errors.New("the inventory already has assets or employees, seed an empty database")
+
+// Options sets what Generate adds
+type Options struct {
+	Assets    int
+	Employees int
+	Seed      uint64    // Seeds the random generator
+	Today     time.Time // The generated history ends the day before
+	Actor     *string   // Username the records are attributed to
+}
+
+// Summary counts the records Generate added
+type Summary struct {
+	Employees   int `json:"employees"`
+	Assets      int `json:"assets"`
+	Retired     int `json:"retired"`
+	Assignments int `json:"assignments"`
+	Maintenance int `json:"maintenance"`
+	Licenses    int `json:"licenses"`
+	Installs    int `json:"license_installs"`
+	Consumables int `json:"consumable_types"`
+	Usage       int `json:"consumable_usage"`
+}
+
+// Generate adds a demo inventory to empty stores as one unit of work
+func Generate(ctx context.Context, stores *repo.Stores, opts Options) (*Summary, error) {
+	if opts.Assets < 0 || opts.Employees < 0 {
+		return nil, errors.New("the number of assets and employees cannot be negative")
+	}
+	y, m, d := opts.Today.Date()
+	g := &generator{
+		ctx:   ctx,
+		rng:   rand.New(rand.NewPCG(opts.Seed, opts.Seed)),
+		opts:  opts,
+		today: time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+		sum:   &Summary{},
+	}
+	err := stores.Tx(ctx, func(tx *repo.Stores) error {
+		g.tx = tx
+		for _, step := range []func() error{g.checkEmpty, g.loadCatalogs, g.employees, g.consumables, g.assets, g.licenses} {
+			if err := step(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g.sum, nil
+}
+
+// category is an asset category with its types
+type category struct {
+	*models.AssetCategory
+	types []*models.AssetType
+}
+
+// maintenanceType is a maintenance type found in the catalog
+type maintenanceType struct {
+	id   int
+	name string
+}
+
+// generator holds the state of one Generate call
+type generator struct {
+	ctx   context.Context
+	rng   *rand.Rand
+	opts  Options
+	today time.Time
+	tx    *repo.Stores
+	sum   *Summary
+
+	categories       []category
+	weights          int // Sum of the category weights
+	locations        []*models.Location
+	maintenanceTypes []maintenanceType
+	employeeIDs      []int
+	consumableTypes  []*models.ConsumableType
+	computers        []*models.Asset // Computers in use, for license installs
+}
+
+// checkEmpty fails with ErrNotEmpty unless the stores have no assets and no
+// employees
+func (g *generator) checkEmpty() error {
+	assets, err := g.tx.Assets.CountSummaries(repo.AssetQuery{})
+	if err != nil {
+		return err
+	}
+	employees, err := g.tx.Employees.List()
+	if err != nil {
+		return err
+	}
+	if assets > 0 || len(employees) > 0 {
+		return ErrNotEmpty
+	}
+	return nil
+}
+
+// loadCatalogs reads the categories, types, locations and maintenance types
+// in a stable order
+func (g *generator) loadCatalogs() error {
+	categories, err := g.tx.Assets.GetAssetCategories()
+	if err != nil {
+		return err
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].CategoryId < categories[j].CategoryId })
+	for _, c := range categories {
+		types, err := g.tx.Assets.GetAssetTypes(c.CategoryId)
+		if err != nil {
+			return err
+		}
+		if len(types) == 0 {
+			continue
+		}
+		sort.Slice(types, func(i, j int) bool { return types[i].TypeID < types[j].TypeID })
+		g.categories = append(g.categories, category{c, types})
+		g.weights += weight(c.CodePrefix)
+	}
+	if len(g.categories) == 0 && g.opts.Assets > 0 {
+		return errors.New("no asset types to generate assets of")
+	}
+
+	if g.locations, err = g.tx.Locations.List(); err != nil {
+		return err
+	}
+	sort.Slice(g.locations, func(i, j int) bool { return g.locations[i].LocationID < g.locations[j].LocationID })
+	if len(g.locations) == 0 && g.opts.Assets > 0 {
+		return errors.New("no locations to place assets in")
+	}
+
+	for _, name := range []string{"Preventive", "Corrective", "Upgrade"} {
+		if id, found, err := g.tx.Maintenance.FindType(name); err == nil {
+			g.maintenanceTypes = append(g.maintenanceTypes, maintenanceType{id, found})
+		}
+	}
+	return nil
+}
+
+// weight returns how common the assets of a category are
+func weight(prefix string) int {
+	if w, ok := categoryWeights[prefix]; ok {
+		return w
+	}
+	return 1
+}
+
+// employees adds employees with unique work emails
+func (g *generator) employees() error {
+	seen := map[string]int{}
+	for range g.opts.Employees {
+		first, last := pick(g.rng, firstNames), pick(g.rng, lastNames)
+		local := strings.ToLower(first + "." + last)
+		seen[local]++
+		if n := seen[local]; n > 1 {
+			local += fmt.Sprint(n)
+		}
+		e := &models.Employee{FullName: first + " " + last, Email: local + "@example.com", CreatedBy: g.opts.Actor}
+		if err := g.tx.Employees.Create(e); err != nil {
+			return fmt.Errorf("employee %s: %w", e.Email, err)
+		}
+		g.employeeIDs = append(g.employeeIDs, e.EmployeeID)
+	}
+	g.sum.Employees = len(g.employeeIDs)
+	return nil
+}
+
+// consumables adds the printer supplies with their stock levels
+func (g *generator) consumables() error {
+	for _, c := range consumables {
+		bought := g.daysAgo(10 + g.rng.IntN(200))
+		t := &models.ConsumableType{
+			Name: c.name, PartNumber: &c.partNumber, Manufacturer: &c.manufacturer, LastPurchaseDate: &bought,
+			StockQuantity: 4 + g.rng.IntN(30), ReorderLevel: 3 + g.rng.IntN(4),
+		}
+		if err := g.tx.Consumables.CreateType(t); err != nil {
+			return fmt.Errorf("consumable %s: %w", c.name, err)
+		}
+		g.consumableTypes = append(g.consumableTypes, t)
+	}
+	g.sum.Consumables = len(g.consumableTypes)
+	return nil
+}
+
+// assets adds the assets with their history
+func (g *generator) assets() error {
+	counters := map[string]int{}
+	for i := range g.opts.Assets {
+		if i%100 == 0 {
+			if err := g.ctx.Err(); err != nil {
+				return err
+			}
+		}
+		c := g.pickCategory()
+		counters[c.CodePrefix]++
+		if err := g.asset(i, c, fmt.Sprintf("%s-%05d", c.CodePrefix, counters[c.CodePrefix])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// asset adds one asset, its assignments, maintenance and consumable usage,
+// retiring old assets now and then
+func (g *generator) asset(i int, c category, tag string) error {
+	t := pick(g.rng, c.types)
+	p := g.product(c.CodePrefix, t.TypeName)
+	purchased := g.daysAgo(1 + g.rng.IntN(6*365))
+	a := &models.Asset{
+		AssetTag:        tag,
+		TypeID:          t.TypeID,
+		StatusID:        models.StatusAvailable,
+		SerialNumber:    fmt.Sprintf("%s%s%06d", serialPrefix(p.make), g.letters(2), i+1),
+		Maker:           p.make,
+		Model:           p.model,
+		PurchaseDate:    purchased,
+		WarrantyEndDate: g.warranty(c.CodePrefix, purchased),
+		LocationID:      g.location().LocationID,
+		CreatedBy:       g.opts.Actor,
+	}
+	if err := g.tx.Assets.Create(a); err != nil {
+		return fmt.Errorf("asset %s: %w", tag, err)
+	}
+	g.sum.Assets++
+
+	// Assets past four years are retired half the time
+	end := g.today
+	retired := g.today.Sub(purchased) > 4*365*24*time.Hour && g.rng.IntN(2) == 0
+	if retired {
+		retireFrom := purchased.AddDate(4, 0, 0)
+		end = retireFrom.AddDate(0, 0, g.rng.IntN(int(g.today.Sub(retireFrom).Hours()/24)+1))
+		if !end.Before(g.today) {
+			end = g.today.AddDate(0, 0, -1)
+		}
+	}
+
+	held := false
+	if heldCategories[c.CodePrefix] && len(g.employeeIDs) > 0 {
+		var err error
+		if held, err = g.assignments(a, end, retired); err != nil {
+			return err
+		}
+	}
+	if maintainedCategories[c.CodePrefix] {
+		if err := g.maintenance(a, end); err != nil {
+			return err
+		}
+	}
+	if c.CodePrefix == "PR" {
+		if err := g.usage(a, end); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case retired:
+		if err := g.tx.Assets.Retire(a.AssetID, g.at(end), g.opts.Actor); err != nil {
+			return fmt.Errorf("retire %s: %w", tag, err)
+		}
+		g.sum.Retired++
+	case !held && g.rng.IntN(25) == 0:
+		a.StatusID, a.UpdatedBy = models.StatusUnderMaintenance, g.opts.Actor
+		if err := g.tx.Assets.Update(a); err != nil {
+			return fmt.Errorf("asset %s: %w", tag, err)
+		}
+	case c.CodePrefix == "EQ":
+		g.computers = append(g.computers, a)
+	}
+	return nil
+}
+
+// assignments hands an asset to a succession of employees until end and
+// reports whether the last one still holds it
+func (g *generator) assignments(a *models.Asset, end time.Time, retired bool) (bool, error) {
+	day := a.PurchaseDate.AddDate(0, 0, 1+g.rng.IntN(30))
+	for day.Before(end) {
+		assignment := &models.AssetAssignment{
+			AssetID: a.AssetID, EmployeeID: pick(g.rng, g.employeeIDs),
+			AssignmentDate: g.at(day), AssignedBy: g.opts.Actor,
+		}
+		returned := day.AddDate(0, 0, 60+g.rng.IntN(900))
+		open := !returned.Before(end)
+		if open && !retired && g.rng.IntN(12) == 0 {
+			due := g.today.AddDate(0, 0, g.rng.IntN(60)-20) // Loans, some overdue
+			assignment.DueDate = &due
+		}
+		if err := g.tx.Assignments.Assign(assignment); err != nil {
+			return false, fmt.Errorf("assign %s: %w", a.AssetTag, err)
+		}
+		g.sum.Assignments++
+
+		// Some assets go back to stock before the history ends
+		if open && !retired && g.rng.IntN(7) == 0 {
+			returned, open = end.AddDate(0, 0, -1-g.rng.IntN(30)), false
+			if returned.Before(day) {
+				returned = day
+			}
+		}
+		if open {
+			return true, nil
+		}
+		if err := g.tx.Assignments.Return(a.AssetID, g.at(returned), g.opts.Actor); err != nil {
+			return false, fmt.Errorf("return %s: %w", a.AssetTag, err)
+		}
+		day = returned.AddDate(0, 0, 1+g.rng.IntN(60))
+	}
+	return false, nil
+}
+
+// maintenance logs up to three maintenance jobs on an asset before end
+func (g *generator) maintenance(a *models.Asset, end time.Time) error {
+	if len(g.maintenanceTypes) == 0 {
+		return nil
+	}
+	for range g.rng.IntN(4) {
+		t := pick(g.rng, g.maintenanceTypes)
+		m := &models.MaintenanceLog{
+			AssetID: a.AssetID, MaintenanceTypeID: t.id, TypeName: t.name,
+			MaintenanceDate: g.at(g.between(a.PurchaseDate, end)), Description: pick(g.rng, maintenanceWork[t.name]),
+			PerformedBy: ptr(pick(g.rng, technicians)), RecordedBy: g.opts.Actor,
+		}
+		if g.rng.IntN(4) > 0 {
+			m.Cost = ptr(math.Round((20+g.rng.Float64()*580)*100) / 100)
+		}
+		if err := g.tx.Maintenance.Create(m); err != nil {
+			return fmt.Errorf("maintenance of %s: %w", a.AssetTag, err)
+		}
+		g.sum.Maintenance++
+	}
+	return nil
+}
+
+// usage records supplies installed in a printer before end, preferring
+// those of its make
+func (g *generator) usage(a *models.Asset, end time.Time) error {
+	var fits []*models.ConsumableType
+	for _, t := range g.consumableTypes {
+		if *t.Manufacturer == a.Maker {
+			fits = append(fits, t)
+		}
+	}
+	if len(fits) == 0 {
+		fits = g.consumableTypes
+	}
+	if len(fits) == 0 {
+		return nil
+	}
+	for range g.rng.IntN(6) {
+		u := &models.ConsumableUsage{
+			ConsumableTypeID: pick(g.rng, fits).ConsumableTypeID, AssetID: a.AssetID,
+			InstallationDate: g.at(g.between(a.PurchaseDate, end)),
+		}
+		if err := g.tx.Consumables.RecordUsage(u); err != nil {
+			return fmt.Errorf("consumable usage of %s: %w", a.AssetTag, err)
+		}
+		g.sum.Usage++
+	}
+	return nil
+}
+
+// licenses buys each program for a share of the computers in use and
+// installs it on them
+func (g *generator) licenses() error {
+	for _, sw := range software {
+		installs := int(math.Round(sw.share * float64(len(g.computers))))
+		purchased := g.daysAgo(30 + g.rng.IntN(900))
+		l := &models.SoftwareLicense{
+			SoftwareName: sw.name, LicenseKey: g.licenseKey(), LicenseType: sw.kind,
+			SeatsPurchased: installs + 5 + g.rng.IntN(installs/10+5), PurchaseDate: purchased,
+		}
+		if sw.kind == "Subscription" {
+			expires := g.today.AddDate(0, 0, g.rng.IntN(360)-30)
+			l.ExpirationDate = &expires
+		}
+		if err := g.tx.Licenses.Create(l); err != nil {
+			return fmt.Errorf("license %s: %w", sw.name, err)
+		}
+		g.sum.Licenses++
+
+		for _, i := range g.rng.Perm(len(g.computers))[:installs] {
+			a := g.computers[i]
+			from := purchased
+			if a.PurchaseDate.After(from) {
+				from = a.PurchaseDate
+			}
+			install := &models.LicenseAssignment{LicenseID: l.LicenseID, AssetID: a.AssetID, AssignmentDate: g.at(g.between(from, g.today))}
+			if err := g.tx.Licenses.Assign(install); err != nil {
+				return fmt.Errorf("install %s on %s: %w", sw.name, a.AssetTag, err)
+			}
+			g.sum.Installs++
+		}
+	}
+	return nil
+}
+
+// pickCategory picks a category, the common ones more often
+func (g *generator) pickCategory() category {
+	n := g.rng.IntN(g.weights)
+	for _, c := range g.categories {
+		if n -= weight(c.CodePrefix); n < 0 {
+			return c
+		}
+	}
+	return g.categories[len(g.categories)-1]
+}
+
+// product picks a make and model for an asset type
+func (g *generator) product(prefix, typeName string) product {
+	if listed := products[typeName]; len(listed) > 0 {
+		return pick(g.rng, listed)
+	}
+	makes := categoryMakes[prefix]
+	if len(makes) == 0 {
+		makes = []string{"Generic"}
+	}
+	var initials strings.Builder
+	for word := range strings.FieldsSeq(typeName) {
+		initials.WriteByte(word[0])
+	}
+	return product{pick(g.rng, makes), fmt.Sprintf("%s-%d", strings.ToUpper(initials.String()), 100+g.rng.IntN(900))}
+}
+
+// warranty returns the warranty end date of an asset bought on purchased;
+// small accessories and tools often have none
+func (g *generator) warranty(prefix string, purchased time.Time) *time.Time {
+	years := []int{1, 1, 2}
+	switch prefix {
+	case "EQ", "SR", "NT":
+		years = []int{1, 3, 3, 5}
+	case "AC", "SD", "TI":
+		if g.rng.IntN(3) == 0 {
+			return nil
+		}
+	}
+	end := purchased.AddDate(pick(g.rng, years), 0, 0)
+	return &end
+}
+
+// location picks the main location most of the time
+func (g *generator) location() *models.Location {
+	if len(g.locations) == 1 || g.rng.IntN(100) < 80 {
+		return g.locations[0]
+	}
+	return pick(g.rng, g.locations[1:])
+}
+
+// daysAgo returns the day n days before today
+func (g *generator) daysAgo(n int) time.Time {
+	return g.today.AddDate(0, 0, -n)
+}
+
+// between returns a day from from up to the day before to, or from when
+// to is not later
+func (g *generator) between(from, to time.Time) time.Time {
+	days := int(to.Sub(from).Hours() / 24)
+	if days < 1 {
+		return from
+	}
+	return from.AddDate(0, 0, g.rng.IntN(days))
+}
+
+// at returns a time during office hours on day
+func (g *generator) at(day time.Time) time.Time {
+	return day.Add(time.Duration(8*60+g.rng.IntN(9*60)) * time.Minute)
+}
+
+// letters returns n random capital letters
+func (g *generator) letters(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('A' + g.rng.IntN(26))
+	}
+	return string(b)
+}
+
+// licenseKey returns a key of five groups of five letters and digits
+func (g *generator) licenseKey() string {
+	const chars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	groups := make([]string, 5)
+	for i := range groups {
+		b := make([]byte, 5)
+		for j := range b {
+			b[j] = chars[g.rng.IntN(len(chars))]
+		}
+		groups[i] = string(b)
+	}
+	return strings.Join(groups, "-")
+}
+
+// serialPrefix returns the first three letters of a make in capitals
+func serialPrefix(maker string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(maker) {
+		if r >= 'A' && r <= 'Z' && b.Len() < 3 {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// pick returns a random element of items
+func pick[T any](rng *rand.Rand, items []T) T {
+	return items[rng.IntN(len(items))]
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,119 @@
+package seed_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/repo/memrepo"
+	"github.com/MawCeron/it-room/internal/seed"
+)
+
+var (
+	ctx  = context.Background()
+	opts = seed.Options{Assets: 300, Employees: 30, Seed: 42, Today: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)}
+)
+
+// inventory describes the generated assets and licenses by their fields
+// other than the generated IDs
+func inventory(t *testing.T, s *repo.Stores) []string {
+	t.Helper()
+	assets, err := s.Assets.ListSummaries(repo.AssetQuery{SortColumn: "asset_tag"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, a := range assets {
+		holder := ""
+		if a.HolderName != nil {
+			holder = *a.HolderName
+		}
+		out = append(out, fmt.Sprintf("%s %s %s %s %s %s %v %s", a.AssetTag, a.TypeName, a.Maker, a.Model,
+			a.SerialNumber, a.StatusName, a.PurchaseDate, holder))
+	}
+	licenses, err := s.Licenses.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range licenses {
+		out = append(out, fmt.Sprintf("%s %s %d/%d", l.SoftwareName, l.LicenseKey, l.SeatsUsed, l.SeatsPurchased))
+	}
+	return out
+}
+
+func TestGenerate(t *testing.T) {
+	s := memrepo.New()
+	summary, err := seed.Generate(ctx, s, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Assets != 300 || summary.Employees != 30 || summary.Assignments == 0 || summary.Retired == 0 ||
+		summary.Maintenance == 0 || summary.Licenses == 0 || summary.Installs == 0 || summary.Consumables == 0 {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	counts, err := s.Assets.CountByCategory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range counts {
+		if c.Count == 0 {
+			t.Errorf("no assets in category %s", c.Name)
+		}
+	}
+
+	assets, _ := s.Assets.ListSummaries(repo.AssetQuery{})
+	for _, a := range assets {
+		if !a.PurchaseDate.Before(opts.Today) {
+			t.Errorf("%s bought on %v, not before %v", a.AssetTag, a.PurchaseDate, opts.Today)
+		}
+		if held := a.HolderName != nil; held != (a.StatusID == models.StatusAssigned) {
+			t.Errorf("%s has status %s and holder %v", a.AssetTag, a.StatusName, a.HolderName)
+		}
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	first, second := memrepo.New(), memrepo.New()
+	for _, s := range []*repo.Stores{first, second} {
+		if _, err := seed.Generate(ctx, s, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b := inventory(t, first), inventory(t, second)
+	if len(a) != len(b) {
+		t.Fatalf("runs generated %d and %d records", len(a), len(b))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("runs differ:\n%s\n%s", a[i], b[i])
+		}
+	}
+
+	other := memrepo.New()
+	changed := opts
+	changed.Seed++
+	if _, err := seed.Generate(ctx, other, changed); err != nil {
+		t.Fatal(err)
+	}
+	if c := inventory(t, other); len(c) > 0 && c[0] == a[0] && c[1] == a[1] {
+		t.Errorf("another seed generated the same assets: %s", c[0])
+	}
+}
+
+func TestGenerateNeedsEmptyInventory(t *testing.T) {
+	s := memrepo.New()
+	if err := s.Employees.Create(&models.Employee{FullName: "Ana Ruiz", Email: "ana@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := seed.Generate(ctx, s, opts); !errors.Is(err, seed.ErrNotEmpty) {
+		t.Errorf("Generate = %v, want ErrNotEmpty", err)
+	}
+	if employees, _ := s.Employees.List(); len(employees) != 1 {
+		t.Errorf("%d employees after a failed seed", len(employees))
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// AssetByTag retrieves an asset by its tag
+func (s *Service) AssetByTag(ctx context.Context, tag string) (*models.AssetSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	a, err := s.stores.Assets.GetSummaryByTag(tag)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound("asset %q not found", tag)
+	}
+	return a, err
+}
+
+// CreateAsset adds an asset, which starts out Available unless it is Under
+// Maintenance; the assign and retire operations set the other statuses
+// a.Fields holds its custom field values, which must include every required
+// field of its type
+func (s *Service) CreateAsset(ctx context.Context, a *models.Asset) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		return createAsset(tx, a)
+	})
+}
+
+// UpdateAsset saves the fields of an asset
+// Its status can only change while it is not assigned, and not to a status
+// set by the assign and retire operations
+// Custom field values in a.Fields are merged into the stored ones, which
+// are checked again when given or when the type changes
+func (s *Service) UpdateAsset(ctx context.Context, a *models.Asset) error {
+	if err := checkAsset(a); err != nil {
+		return err
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		current, err := getAsset(tx, a.AssetID)
+		if err != nil {
+			return err
+		}
+		if a.StatusID != current.StatusID {
+			if current.StatusID == models.StatusAssigned {
+				return conflict("asset %s is assigned, return it before changing its status", current.AssetTag)
+			}
+			if err := checkSettableStatus(a.StatusID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Assets.Update(a); err != nil {
+			return err
+		}
+		if a.Fields == nil && a.TypeID == current.TypeID {
+			return nil
+		}
+		return saveAssetFields(tx, a)
+	})
+}
+
+// RetireAsset marks an asset as retired on date, returning it first if it
+// is assigned
+func (s *Service) RetireAsset(ctx context.Context, assetID string, date time.Time, actor *string) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		a, err := getAsset(tx, assetID)
+		if err != nil {
+			return err
+		}
+		if a.StatusID == models.StatusRetired {
+			return conflict("asset %s is already retired", a.AssetTag)
+		}
+		return tx.Assets.Retire(assetID, date, actor)
+	})
+}
+
+// AssignAsset hands an asset to an employee and marks it as assigned
+// The employee is given by email or unique name, and a.EmployeeID and
+// a.EmployeeName are set from it; a.AssetTag is set from a.AssetID
+func (s *Service) AssignAsset(ctx context.Context, a *models.AssetAssignment, employee string) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		return assignAsset(tx, a, employee)
+	})
+}
+
+// ReturnAsset closes the open assignment of an asset on date and marks it
+// as available
+func (s *Service) ReturnAsset(ctx context.Context, assetID string, date time.Time, actor *string) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		return returnAsset(tx, assetID, date, actor)
+	})
+}
+
+// createAsset is CreateAsset within a unit of work
+func createAsset(tx *repo.Stores, a *models.Asset) error {
+	if a.StatusID == 0 {
+		a.StatusID = models.StatusAvailable
+	}
+	if err := checkAsset(a); err != nil {
+		return err
+	}
+	if err := checkSettableStatus(a.StatusID); err != nil {
+		return err
+	}
+	if err := tx.Assets.Create(a); err != nil {
+		return err
+	}
+	return saveAssetFields(tx, a)
+}
+
+// assignAsset is AssignAsset within a unit of work
+func assignAsset(tx *repo.Stores, a *models.AssetAssignment, employee string) error {
+	asset, err := getAsset(tx, a.AssetID)
+	if err != nil {
+		return err
+	}
+	e, err := repo.ResolveEmployee(tx.Employees, employee)
+	if err != nil {
+		return invalid("employee", "%v", err)
+	}
+
+	a.AssetTag, a.EmployeeID, a.EmployeeName = asset.AssetTag, e.EmployeeID, e.FullName
+	return assetConflict(asset.AssetTag, tx.Assignments.Assign(a))
+}
+
+// returnAsset is ReturnAsset within a unit of work
+func returnAsset(tx *repo.Stores, assetID string, date time.Time, actor *string) error {
+	a, err := getAsset(tx, assetID)
+	if err != nil {
+		return err
+	}
+	return assetConflict(a.AssetTag, tx.Assignments.Return(assetID, date, actor))
+}
+
+// getAsset retrieves an asset by ID, reporting a missing one as not found
+func getAsset(stores *repo.Stores, assetID string) (*models.AssetSummary, error) {
+	a, err := stores.Assets.GetSummary(assetID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound("asset %s not found", assetID)
+	}
+	return a, err
+}
+
+// assetConflict reports an assignment rule broken by an asset as a conflict
+// naming the asset
+func assetConflict(tag string, err error) error {
+	if errors.Is(err, repo.ErrAssetAssigned) || errors.Is(err, repo.ErrAssetNotAssigned) || errors.Is(err, repo.ErrAssetRetired) ||
+		errors.Is(err, repo.ErrAssetUnderMaintenance) {
+		return &Error{Kind: ErrConflict, Message: fmt.Sprintf("%s: %v", tag, err), Err: err}
+	}
+	return err
+}
+
+// checkAsset validates the fields every asset needs, upper-casing its
+// currency
+func checkAsset(a *models.Asset) error {
+	if a.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*a.Currency))
+		a.Currency = &currency
+	}
+	switch {
+	case a.AssetTag == "", a.Maker == "", a.Model == "", a.SerialNumber == "":
+		return invalid("", "asset tag, make, model and serial number are required")
+	case a.TypeID == 0:
+		return invalid("type", "asset type is required")
+	case a.LocationID == 0:
+		return invalid("location", "location is required")
+	case a.PurchaseDate.IsZero():
+		return invalid("purchase_date", "purchase date is required")
+	case a.WarrantyEndDate != nil && a.WarrantyEndDate.Before(a.PurchaseDate):
+		return invalid("warranty_end_date", "warranty end date is before the purchase date")
+	case a.PurchaseCost != nil && !(*a.PurchaseCost >= 0 && !math.IsInf(*a.PurchaseCost, 0)):
+		return invalid("purchase_cost", "purchase cost cannot be negative")
+	case (a.PurchaseCost == nil) != (a.Currency == nil):
+		return invalid("currency", "purchase cost and currency are set together")
+	case a.Currency != nil && !models.IsCurrencyCode(*a.Currency):
+		return invalid("currency", "currency must be a three-letter ISO 4217 code, e.g. USD")
+	}
+	return nil
+}
+
+// checkSettableStatus rejects the statuses only the assign and retire
+// operations set
+func checkSettableStatus(statusID int) error {
+	switch statusID {
+	case models.StatusAssigned:
+		return invalid("status", "status Assigned is set by assigning the asset")
+	case models.StatusRetired:
+		return invalid("status", "status Retired is set by retiring the asset")
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// defaultDecliningFactor is the factor of a declining balance rule given
+// without one, making it double declining
+const defaultDecliningFactor = 2.0
+
+// SetDepreciationRule sets how the assets of a category depreciate,
+// replacing the rule it had; rule.UpdatedBy names the acting user
+func (s *Service) SetDepreciationRule(ctx context.Context, rule *models.DepreciationRule) error {
+	if err := checkDepreciationRule(rule); err != nil {
+		return err
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		categories, err := tx.Assets.GetAssetCategories()
+		if err != nil {
+			return err
+		}
+		i := slices.IndexFunc(categories, func(c *models.AssetCategory) bool { return c.CategoryId == rule.CategoryID })
+		if i < 0 {
+			return invalid("category", "category %d not found", rule.CategoryID)
+		}
+		rule.CategoryName = categories[i].Description
+		return tx.Depreciation.Set(rule)
+	})
+}
+
+// DeleteDepreciationRule removes the depreciation rule of a category, whose
+// assets then keep their cost, returning the rule removed
+func (s *Service) DeleteDepreciationRule(ctx context.Context, categoryID int) (*models.DepreciationRule, error) {
+	var rule *models.DepreciationRule
+	err := s.tx(ctx, func(tx *repo.Stores) error {
+		var err error
+		if rule, err = tx.Depreciation.Get(categoryID); errors.Is(err, sql.ErrNoRows) {
+			return notFound("category %d has no depreciation rule", categoryID)
+		} else if err != nil {
+			return err
+		}
+		return tx.Depreciation.Delete(categoryID)
+	})
+	return rule, err
+}
+
+// BookValues computes what the assets matching q that have a purchase cost
+// are worth on day, in the order of q
+// Retired assets and assets bought after day are left out; q decides which
+// state of the assets is read, e.g. with AsOf set to day for a past date
+func (s *Service) BookValues(ctx context.Context, q repo.AssetQuery, day time.Time) ([]*models.BookValue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	rules, err := s.stores.Depreciation.List()
+	if err != nil {
+		return nil, err
+	}
+	byCategory := map[int]*models.DepreciationRule{}
+	for _, r := range rules {
+		byCategory[r.CategoryID] = r
+	}
+	assets, err := s.stores.Assets.ListSummaries(q)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []*models.BookValue{}
+	for _, a := range assets {
+		if a.PurchaseCost == nil || a.StatusID == models.StatusRetired || a.PurchaseDate.After(day) {
+			continue
+		}
+		v := &models.BookValue{AssetID: a.AssetID, AssetTag: a.AssetTag, CategoryName: a.CategoryName,
+			LocationName: a.LocationName, PurchaseDate: a.PurchaseDate, Currency: valueOrEmpty(a.Currency),
+			Cost: *a.PurchaseCost, Value: *a.PurchaseCost}
+		if rule := byCategory[a.CategoryID]; rule != nil {
+			v.Method = rule.Method
+			v.Value = rule.BookValueOn(v.Cost, a.PurchaseDate, day)
+		}
+		v.Depreciation = math.Round((v.Cost-v.Value)*100) / 100
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// checkDepreciationRule validates a depreciation rule, giving declining
+// balance rules without a factor the double declining one
+func checkDepreciationRule(rule *models.DepreciationRule) error {
+	rule.Method = strings.ToLower(strings.TrimSpace(rule.Method))
+	if rule.Method == models.DepreciationDecliningBalance && rule.Factor == nil {
+		factor := defaultDecliningFactor
+		rule.Factor = &factor
+	}
+	switch {
+	case !slices.Contains(models.DepreciationMethods, rule.Method):
+		return invalid("method", "method must be one of %s", strings.Join(models.DepreciationMethods, ", "))
+	case rule.UsefulLifeYears <= 0:
+		return invalid("useful_life_years", "useful life must be at least one year")
+	case !(rule.SalvagePercent >= 0 && rule.SalvagePercent < 100):
+		return invalid("salvage_percent", "salvage value must be from 0 to under 100 percent of the cost")
+	case rule.Factor != nil && rule.Method != models.DepreciationDecliningBalance:
+		return invalid("factor", "only declining balance rules have a factor")
+	case rule.Factor != nil && !(*rule.Factor > 0 && !math.IsInf(*rule.Factor, 0)):
+		return invalid("factor", "factor must be above zero")
+	}
+	return nil
+}
+
+// valueOrEmpty returns the value of an optional string, empty when nil
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
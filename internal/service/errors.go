@@ -0,0 +1,96 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/seed"
+)
+
+// Kinds of domain errors, matched with errors.Is
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+// Error is a domain error the caller can report as is
+// Kind is ErrNotFound, ErrConflict or ErrValidation; Err is the repository
+// error behind it, if any
+type Error struct {
+	Kind    error
+	Field   string // Field at fault, when known
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Unwrap lets errors.Is match both the kind and the repository error
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+func notFound(format string, args ...any) *Error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...any) *Error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func invalid(field, format string, args ...any) *Error {
+	return &Error{Kind: ErrValidation, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// classify turns the repository errors a caller can act on into domain
+// errors and returns the others unchanged
+func classify(err error) error {
+	var domain *Error
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	switch {
+	case err == nil, errors.As(err, &domain):
+		return err
+	case errors.Is(err, sql.ErrNoRows):
+		return &Error{Kind: ErrNotFound, Message: "not found", Err: err}
+	case errors.Is(err, repo.ErrAssetAssigned), errors.Is(err, repo.ErrAssetNotAssigned),
+		errors.Is(err, repo.ErrAssetRetired), errors.Is(err, repo.ErrAssetUnderMaintenance), errors.Is(err, repo.ErrLastAdmin),
+		errors.Is(err, seed.ErrNotEmpty):
+		return &Error{Kind: ErrConflict, Message: msg, Err: err}
+	case strings.Contains(msg, "UNIQUE constraint failed: "):
+		column := uniqueColumn(msg)
+		return &Error{Kind: ErrConflict, Field: column, Message: column + " already exists", Err: err}
+	case strings.Contains(msg, "FOREIGN KEY constraint failed"), strings.Contains(msg, "CHECK constraint failed"):
+		return &Error{Kind: ErrValidation, Message: msg, Err: err}
+	}
+	return err
+}
+
+// uniqueColumn returns the column named by a SQLite unique constraint error,
+// e.g. asset_tag for "UNIQUE constraint failed: assets.asset_tag (2067)"
+func uniqueColumn(msg string) string {
+	_, column, _ := strings.Cut(msg, "UNIQUE constraint failed: ")
+	column, _, _ = strings.Cut(column, " ")
+	if _, name, ok := strings.Cut(column, "."); ok {
+		return name
+	}
+	return "a record with the same value"
+}
+
+// lookup reports a catalog or record name that matched nothing as a
+// validation error of field
+func lookup(err error, field, what, name string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return invalid(field, "%s %q not found", what, name)
+	}
+	return err
+}
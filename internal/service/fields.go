@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// CreateCustomField adds a custom field to an asset type or to every type
+// in a category; f.CreatedBy names the acting user
+// A field cannot share its name with another field of the same assets
+func (s *Service) CreateCustomField(ctx context.Context, f *models.CustomField) error {
+	if err := checkCustomField(f); err != nil {
+		return err
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		var others []*models.CustomField
+		if f.TypeID != nil {
+			typ, err := tx.Assets.GetAssetType(*f.TypeID)
+			if err != nil {
+				return lookup(err, "type", "asset type", fmt.Sprint(*f.TypeID))
+			}
+			if others, err = tx.Fields.ListForType(typ.TypeID); err != nil {
+				return err
+			}
+		} else {
+			var err error
+			if others, err = categoryFields(tx, *f.CategoryID); err != nil {
+				return err
+			}
+		}
+		for _, other := range others {
+			if strings.EqualFold(other.Name, f.Name) {
+				return conflict("custom field %q already applies to these assets", other.Name)
+			}
+		}
+		return tx.Fields.Create(f)
+	})
+}
+
+// DeleteCustomField removes a custom field along with its values on every
+// asset, returning the field removed
+func (s *Service) DeleteCustomField(ctx context.Context, fieldID int) (*models.CustomField, error) {
+	var f *models.CustomField
+	err := s.tx(ctx, func(tx *repo.Stores) error {
+		var err error
+		if f, err = tx.Fields.Get(fieldID); errors.Is(err, sql.ErrNoRows) {
+			return notFound("custom field %d not found", fieldID)
+		} else if err != nil {
+			return err
+		}
+		return tx.Fields.Delete(fieldID)
+	})
+	return f, err
+}
+
+// checkCustomField validates the definition of a custom field, trimming its
+// name and options
+func checkCustomField(f *models.CustomField) error {
+	f.Name = strings.TrimSpace(f.Name)
+	for i := range f.Options {
+		f.Options[i] = strings.TrimSpace(f.Options[i])
+	}
+	switch {
+	case f.Name == "":
+		return invalid("name", "field name is required")
+	case !slices.Contains(models.FieldKinds, f.Kind):
+		return invalid("kind", "kind must be one of %s", strings.Join(models.FieldKinds, ", "))
+	case (f.TypeID == nil) == (f.CategoryID == nil):
+		return invalid("type", "a custom field belongs to either an asset type or a category")
+	case f.Kind == models.FieldEnum && len(f.Options) == 0:
+		return invalid("options", "an enum field needs options")
+	case f.Kind != models.FieldEnum && len(f.Options) > 0:
+		return invalid("options", "only enum fields have options")
+	case f.Pattern != nil && f.Kind != models.FieldText:
+		return invalid("pattern", "only text fields have a pattern")
+	case (f.Min != nil || f.Max != nil) && f.Kind != models.FieldNumber:
+		return invalid("min", "only number fields have a minimum and maximum")
+	case !finite(f.Min) || !finite(f.Max):
+		return invalid("min", "minimum and maximum must be numbers")
+	case f.Min != nil && f.Max != nil && *f.Min > *f.Max:
+		return invalid("min", "minimum is above the maximum")
+	}
+	for i, o := range f.Options {
+		if o == "" {
+			return invalid("options", "options cannot be empty")
+		}
+		if slices.IndexFunc(f.Options[:i], func(prev string) bool { return strings.EqualFold(prev, o) }) >= 0 {
+			return invalid("options", "option %q is repeated", o)
+		}
+	}
+	if f.Pattern != nil {
+		if _, err := regexp.Compile(*f.Pattern); err != nil {
+			return invalid("pattern", "invalid pattern: %v", err)
+		}
+	}
+	return nil
+}
+
+// categoryFields returns the custom fields of a category and of its types
+func categoryFields(tx *repo.Stores, categoryID int) ([]*models.CustomField, error) {
+	categories, err := tx.Assets.GetAssetCategories()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(categories, func(c *models.AssetCategory) bool { return c.CategoryId == categoryID }) {
+		return nil, invalid("category", "category %d not found", categoryID)
+	}
+	types, err := tx.Assets.GetAssetTypes(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	all, err := tx.Fields.List()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(all, func(f *models.CustomField) bool {
+		if f.CategoryID != nil {
+			return *f.CategoryID != categoryID
+		}
+		return !slices.ContainsFunc(types, func(t *models.AssetType) bool { return t.TypeID == *f.TypeID })
+	}), nil
+}
+
+// saveAssetFields checks a.Fields, the custom field values given for an
+// asset by field name, and stores them
+// An empty value clears a field, and values of fields that do not apply to
+// the asset's type are dropped; required fields must be left with a value
+func saveAssetFields(tx *repo.Stores, a *models.Asset) error {
+	fields, err := tx.Fields.ListForType(a.TypeID)
+	if err != nil {
+		return err
+	}
+	current, err := tx.Fields.ListValues(a.AssetID)
+	if err != nil {
+		return err
+	}
+
+	values := map[int]string{}
+	for _, v := range current {
+		values[v.FieldID] = v.Value
+	}
+	for name, raw := range a.Fields {
+		i := slices.IndexFunc(fields, func(f *models.CustomField) bool { return strings.EqualFold(f.Name, strings.TrimSpace(name)) })
+		if i < 0 {
+			typ, err := tx.Assets.GetAssetType(a.TypeID)
+			if err != nil {
+				return err
+			}
+			return invalid(name, "%q is not a custom field of %s assets", name, typ.TypeName)
+		}
+		f := fields[i]
+		if strings.TrimSpace(raw) == "" {
+			delete(values, f.FieldID)
+			continue
+		}
+		if values[f.FieldID], err = fieldValue(f, raw); err != nil {
+			return err
+		}
+	}
+	for _, f := range fields {
+		if _, ok := values[f.FieldID]; f.Required && !ok {
+			return invalid(f.Name, "%s is required", f.Name)
+		}
+	}
+
+	actor := a.UpdatedBy
+	if actor == nil {
+		actor = a.CreatedBy
+	}
+	for _, v := range current {
+		applies := slices.ContainsFunc(fields, func(f *models.CustomField) bool { return f.FieldID == v.FieldID })
+		if _, kept := values[v.FieldID]; !kept || !applies {
+			if err := tx.Fields.ClearValue(a.AssetID, v.FieldID); err != nil {
+				return err
+			}
+		}
+	}
+	for _, f := range fields {
+		value, ok := values[f.FieldID]
+		unchanged := slices.ContainsFunc(current, func(v *models.AssetFieldValue) bool {
+			return v.FieldID == f.FieldID && v.Value == value
+		})
+		if !ok || unchanged {
+			continue
+		}
+		if err := tx.Fields.SetValue(&models.AssetFieldValue{AssetID: a.AssetID, FieldID: f.FieldID,
+			Value: value, UpdatedBy: actor}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finite reports whether an optional bound is unset or a finite number
+func finite(n *float64) bool {
+	return n == nil || !math.IsInf(*n, 0) && !math.IsNaN(*n)
+}
+
+// fieldValue checks a value against a custom field and returns it in the
+// form it is stored in: numbers without trailing zeros, dates as
+// YYYY-MM-DD and enum values spelled as the option they match
+func fieldValue(f *models.CustomField, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	switch f.Kind {
+	case models.FieldNumber:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return "", invalid(f.Name, "%s must be a number", f.Name)
+		}
+		if f.Min != nil && n < *f.Min {
+			return "", invalid(f.Name, "%s must be at least %v", f.Name, *f.Min)
+		}
+		if f.Max != nil && n > *f.Max {
+			return "", invalid(f.Name, "%s must be at most %v", f.Name, *f.Max)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case models.FieldDate:
+		d, err := time.Parse(repo.DateLayout, raw)
+		if err != nil {
+			return "", invalid(f.Name, "%s must be a date (YYYY-MM-DD)", f.Name)
+		}
+		return d.Format(repo.DateLayout), nil
+	case models.FieldEnum:
+		for _, o := range f.Options {
+			if strings.EqualFold(o, raw) {
+				return o, nil
+			}
+		}
+		return "", invalid(f.Name, "%s must be one of %s", f.Name, strings.Join(f.Options, ", "))
+	}
+	if f.Pattern != nil {
+		re, err := regexp.Compile(`^(?:` + *f.Pattern + `)$`)
+		if err != nil {
+			return "", err
+		}
+		if !re.MatchString(raw) {
+			return "", invalid(f.Name, "%s does not match the pattern %s", f.Name, *f.Pattern)
+		}
+	}
+	return raw, nil
+}
@@ -0,0 +1,444 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// CreateKitTemplate adds a kit template; its items name asset types by ID
+func (s *Service) CreateKitTemplate(ctx context.Context, t *models.KitTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return invalid("name", "template name is required")
+	}
+	if len(t.Items) == 0 {
+		return invalid("items", "a template needs at least one item")
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		seen := map[int]bool{}
+		for i, item := range t.Items {
+			if item.Quantity <= 0 {
+				return invalid("items", "quantity must be positive")
+			}
+			typ, err := tx.Assets.GetAssetType(item.TypeID)
+			if err != nil {
+				return lookup(err, "items", "asset type", fmt.Sprint(item.TypeID))
+			}
+			if seen[item.TypeID] {
+				return invalid("items", "asset type %s is listed twice", typ.TypeName)
+			}
+			seen[item.TypeID] = true
+			t.Items[i].TypeName = typ.TypeName
+		}
+		return tx.Kits.CreateTemplate(t)
+	})
+}
+
+// KitByTag retrieves a kit with its members by tag
+func (s *Service) KitByTag(ctx context.Context, tag string) (*models.KitSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	k, err := s.stores.Kits.GetByTag(tag)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound("kit %q not found", tag)
+	} else if err != nil {
+		return nil, err
+	}
+	return summarizeKit(s.stores, k)
+}
+
+// ListKits retrieves every kit with its members, ordered by tag
+func (s *Service) ListKits(ctx context.Context) ([]*models.KitSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	kits, err := s.stores.Kits.List()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*models.KitSummary, 0, len(kits))
+	for _, k := range kits {
+		summary, err := summarizeKit(s.stores, k)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, summary)
+	}
+	return out, nil
+}
+
+// CreateKit groups assets into a kit; k.CreatedBy names the acting user
+// The members must not be retired or in another kit, and are either all
+// unassigned or all held by the same employee; with a template, they must
+// be of its types and no more than its quantities, though fewer are allowed
+func (s *Service) CreateKit(ctx context.Context, k *models.Kit) error {
+	k.KitTag = strings.TrimSpace(k.KitTag)
+	if k.KitTag == "" {
+		return invalid("kit_tag", "kit tag is required")
+	}
+	if len(k.AssetIDs) == 0 {
+		return invalid("assets", "a kit needs at least one asset")
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		var template *models.KitTemplate
+		if k.TemplateID != nil {
+			t, err := tx.Kits.GetTemplate(*k.TemplateID)
+			if err != nil {
+				return lookup(err, "template", "kit template", fmt.Sprint(*k.TemplateID))
+			}
+			template = t
+		}
+
+		var holder *models.AssetAssignment
+		counts := map[int]int{}
+		for i, assetID := range k.AssetIDs {
+			a, err := kitCandidate(tx, assetID)
+			if err != nil {
+				return err
+			}
+			open, err := openAssignment(tx, assetID)
+			if err != nil {
+				return err
+			}
+			if i > 0 && !sameHolder(holder, open) {
+				return conflict("the assets of a kit must all be available or all held by the same employee")
+			}
+			holder = open
+			counts[a.TypeID]++
+			if template != nil && counts[a.TypeID] > templateQuantity(template, a.TypeID) {
+				return invalid("assets", "template %s has no room for %s, a %s", template.Name, a.AssetTag, a.TypeName)
+			}
+		}
+		return tx.Kits.Create(k)
+	})
+}
+
+// AssignKit hands every member of a kit to an employee on the date of a,
+// with its due date, notes and acting user
+// The employee is given by email or unique name; no member may be held
+func (s *Service) AssignKit(ctx context.Context, kitID int, a models.AssetAssignment, employee string) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		k, members, err := getKit(tx, kitID)
+		if err != nil {
+			return err
+		}
+		e, err := repo.ResolveEmployee(tx.Employees, employee)
+		if err != nil {
+			return invalid("employee", "%v", err)
+		}
+		for _, m := range members {
+			if m.StatusID == models.StatusRetired {
+				return conflict("kit %s holds retired asset %s, swap it before assigning the kit", k.KitTag, m.AssetTag)
+			}
+			assignment := a
+			assignment.AssetID, assignment.AssetTag = m.AssetID, m.AssetTag
+			assignment.EmployeeID, assignment.EmployeeName = e.EmployeeID, e.FullName
+			if err := assetConflict(m.AssetTag, tx.Assignments.Assign(&assignment)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReturnKit returns every held member of a kit on date
+func (s *Service) ReturnKit(ctx context.Context, kitID int, date time.Time, actor *string) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		k, members, err := getKit(tx, kitID)
+		if err != nil {
+			return err
+		}
+		returned := 0
+		for _, m := range members {
+			if m.StatusID != models.StatusAssigned {
+				continue
+			}
+			if err := assetConflict(m.AssetTag, tx.Assignments.Return(m.AssetID, date, actor)); err != nil {
+				return err
+			}
+			returned++
+		}
+		if returned == 0 {
+			return conflict("kit %s is not assigned", k.KitTag)
+		}
+		return nil
+	})
+}
+
+// TransferKit moves every member of a kit to a location, logging a
+// transfer for each member not already there
+func (s *Service) TransferKit(ctx context.Context, kitID, locationID int, actor *string) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		_, members, err := getKit(tx, kitID)
+		if err != nil {
+			return err
+		}
+		for _, m := range members {
+			if m.LocationID == locationID {
+				continue
+			}
+			a := m.Asset
+			a.LocationID, a.UpdatedBy = locationID, actor
+			if err := tx.Assets.Update(&a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SwapKitAsset replaces one member of a kit with another asset of the same
+// type, which must not be retired, held or in a kit
+// The new member moves to the kit's location, where the other members are
+// or else the old one was, logging a transfer
+// When the old member is held, it is returned on date and the new one is
+// assigned to the same employee with the same due date; otherwise the new
+// one goes to whoever holds the other members, if a single employee does
+func (s *Service) SwapKitAsset(ctx context.Context, kitID int, oldID, newID string, date time.Time, actor *string) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		k, _, err := getKit(tx, kitID)
+		if err != nil {
+			return err
+		}
+		old, err := getAsset(tx, oldID)
+		if err != nil {
+			return err
+		}
+		replacement, err := kitCandidate(tx, newID)
+		if err != nil {
+			return err
+		}
+		if replacement.TypeID != old.TypeID {
+			return invalid("asset", "%s is a %s, not a %s like %s", replacement.AssetTag, replacement.TypeName, old.TypeName, old.AssetTag)
+		}
+		if replacement.StatusID == models.StatusAssigned {
+			return conflict("asset %s is assigned, return it before adding it to a kit", replacement.AssetTag)
+		}
+
+		if err := tx.Kits.RemoveAsset(kitID, oldID); errors.Is(err, sql.ErrNoRows) {
+			return invalid("asset", "asset %s is not in kit %s", old.AssetTag, k.KitTag)
+		} else if err != nil {
+			return err
+		}
+		if err := tx.Kits.AddAsset(kitID, newID, actor); err != nil {
+			return err
+		}
+		locationID, err := kitLocation(tx, k, oldID)
+		if err != nil {
+			return err
+		}
+		if locationID == 0 {
+			locationID = old.LocationID
+		}
+		if replacement.LocationID != locationID {
+			a := replacement.Asset
+			a.LocationID, a.UpdatedBy = locationID, actor
+			if err := tx.Assets.Update(&a); err != nil {
+				return err
+			}
+		}
+
+		held, err := openAssignment(tx, oldID)
+		if err != nil {
+			return err
+		}
+		if held != nil {
+			if err := tx.Assignments.Return(oldID, date, actor); err != nil {
+				return assetConflict(old.AssetTag, err)
+			}
+		} else if held, err = kitHolder(tx, k, oldID); err != nil || held == nil {
+			return err
+		}
+		return assetConflict(replacement.AssetTag, tx.Assignments.Assign(&models.AssetAssignment{
+			AssetID: newID, AssetTag: replacement.AssetTag, EmployeeID: held.EmployeeID, EmployeeName: held.EmployeeName,
+			AssignmentDate: date, DueDate: held.DueDate, Notes: held.Notes, AssignedBy: actor,
+		}))
+	})
+}
+
+// DissolveKit deletes a kit, leaving its members and their assignments as
+// they are
+func (s *Service) DissolveKit(ctx context.Context, kitID int) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		if _, _, err := getKit(tx, kitID); err != nil {
+			return err
+		}
+		return tx.Kits.Delete(kitID)
+	})
+}
+
+// getKit retrieves a kit and its members by ID, reporting a missing kit as
+// not found
+func getKit(stores *repo.Stores, kitID int) (*models.Kit, []*models.AssetSummary, error) {
+	k, err := stores.Kits.Get(kitID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, notFound("kit %d not found", kitID)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	members := make([]*models.AssetSummary, 0, len(k.AssetIDs))
+	for _, assetID := range k.AssetIDs {
+		a, err := getAsset(stores, assetID)
+		if err != nil {
+			return nil, nil, err
+		}
+		members = append(members, a)
+	}
+	return k, members, nil
+}
+
+// kitCandidate retrieves an asset about to join a kit, which must not be
+// retired or in a kit already
+func kitCandidate(stores *repo.Stores, assetID string) (*models.AssetSummary, error) {
+	a, err := getAsset(stores, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if a.StatusID == models.StatusRetired {
+		return nil, conflict("asset %s is retired", a.AssetTag)
+	}
+	if other, err := stores.Kits.FindByAsset(assetID); err == nil {
+		return nil, conflict("asset %s is already in kit %s", a.AssetTag, other.KitTag)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return a, nil
+}
+
+// openAssignment returns the assignment an asset is held under, or nil
+func openAssignment(stores *repo.Stores, assetID string) (*models.AssetAssignment, error) {
+	assignments, err := stores.Assignments.ListByAsset(assetID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range assignments {
+		if a.ReturnDate == nil {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+// kitHolder returns the assignment the members of a kit other than skipID
+// are held under when a single employee holds them, or nil
+func kitHolder(stores *repo.Stores, k *models.Kit, skipID string) (*models.AssetAssignment, error) {
+	var holder *models.AssetAssignment
+	for _, assetID := range k.AssetIDs {
+		if assetID == skipID {
+			continue
+		}
+		open, err := openAssignment(stores, assetID)
+		if err != nil {
+			return nil, err
+		}
+		if open == nil {
+			continue
+		}
+		if holder != nil && !sameHolder(holder, open) {
+			return nil, nil
+		}
+		holder = open
+	}
+	return holder, nil
+}
+
+// kitLocation returns the location of the members of a kit other than
+// skipID when they are all in one place, or 0
+func kitLocation(stores *repo.Stores, k *models.Kit, skipID string) (int, error) {
+	locationID := 0
+	for _, assetID := range k.AssetIDs {
+		if assetID == skipID {
+			continue
+		}
+		a, err := getAsset(stores, assetID)
+		if err != nil {
+			return 0, err
+		}
+		if locationID != 0 && a.LocationID != locationID {
+			return 0, nil
+		}
+		locationID = a.LocationID
+	}
+	return locationID, nil
+}
+
+// sameHolder reports whether two open assignments, either of which may be
+// nil, leave the assets with the same employee or both unassigned
+func sameHolder(a, b *models.AssetAssignment) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.EmployeeID == b.EmployeeID
+}
+
+// templateQuantity returns the number of assets of a type in a template
+func templateQuantity(t *models.KitTemplate, typeID int) int {
+	for _, i := range t.Items {
+		if i.TypeID == typeID {
+			return i.Quantity
+		}
+	}
+	return 0
+}
+
+// summarizeKit loads the members of a kit and works out the state they
+// share and the template items they fall short of
+// Retired members count toward neither
+func summarizeKit(stores *repo.Stores, k *models.Kit) (*models.KitSummary, error) {
+	summary := &models.KitSummary{Kit: *k, State: models.KitAvailable}
+	var holder *models.AssetAssignment
+	held, active := 0, 0
+	counts := map[int]int{}
+	for _, assetID := range k.AssetIDs {
+		a, err := getAsset(stores, assetID)
+		if err != nil {
+			return nil, err
+		}
+		summary.Assets = append(summary.Assets, a)
+		if a.StatusID == models.StatusRetired {
+			continue
+		}
+		active++
+		counts[a.TypeID]++
+		open, err := openAssignment(stores, assetID)
+		if err != nil {
+			return nil, err
+		}
+		if open == nil {
+			continue
+		}
+		if held > 0 && !sameHolder(holder, open) {
+			summary.State = models.KitSplit
+		}
+		holder = open
+		held++
+	}
+	switch {
+	case held > 0 && held < active:
+		summary.State = models.KitSplit
+	case held > 0 && summary.State != models.KitSplit:
+		summary.State = models.KitAssigned
+		summary.HolderName = &holder.EmployeeName
+	}
+
+	if k.TemplateID != nil {
+		t, err := stores.Kits.GetTemplate(*k.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range t.Items {
+			if short := i.Quantity - counts[i.TypeID]; short > 0 {
+				summary.Missing = append(summary.Missing, fmt.Sprintf("%d %s", short, i.TypeName))
+			}
+		}
+	}
+	return summary, nil
+}
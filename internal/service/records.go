@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// CreateEmployee adds an employee with a name and a unique email
+func (s *Service) CreateEmployee(ctx context.Context, e *models.Employee) error {
+	e.FullName, e.Email = strings.TrimSpace(e.FullName), strings.TrimSpace(e.Email)
+	switch {
+	case e.FullName == "" || e.Email == "":
+		return invalid("", "full name and email are required")
+	case !strings.Contains(e.Email, "@"):
+		return invalid("email", "email %q is not an email address", e.Email)
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		return tx.Employees.Create(e)
+	})
+}
+
+// LogMaintenance records maintenance performed on an asset
+// The maintenance type is looked up by m.TypeName when m.MaintenanceTypeID
+// is zero; m.AssetTag and m.TypeName are set from the stored records
+func (s *Service) LogMaintenance(ctx context.Context, m *models.MaintenanceLog) error {
+	m.Description = strings.TrimSpace(m.Description)
+	switch {
+	case m.Description == "":
+		return invalid("description", "description is required")
+	case m.Cost != nil && *m.Cost < 0:
+		return invalid("cost", "cost cannot be negative")
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		a, err := getAsset(tx, m.AssetID)
+		if err != nil {
+			return err
+		}
+		if m.MaintenanceTypeID == 0 {
+			typeID, typeName, err := tx.Maintenance.FindType(m.TypeName)
+			if err != nil {
+				return lookup(err, "type", "maintenance type", m.TypeName)
+			}
+			m.MaintenanceTypeID, m.TypeName = typeID, typeName
+		}
+		m.AssetTag = a.AssetTag
+		return tx.Maintenance.Create(m)
+	})
+}
+
+// AddComment adds a comment to an asset
+func (s *Service) AddComment(ctx context.Context, c *models.AssetComment) error {
+	if c.Body = strings.TrimSpace(c.Body); c.Body == "" {
+		return invalid("body", "comment is empty")
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		if _, err := getAsset(tx, c.AssetID); err != nil {
+			return err
+		}
+		return tx.Notes.AddComment(c)
+	})
+}
+
+// AddAttachment records a file attached to an asset by path
+func (s *Service) AddAttachment(ctx context.Context, a *models.AssetAttachment) error {
+	if a.FilePath = strings.TrimSpace(a.FilePath); a.FilePath == "" {
+		return invalid("file_path", "file path is required")
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		if _, err := getAsset(tx, a.AssetID); err != nil {
+			return err
+		}
+		return tx.Notes.AddAttachment(a)
+	})
+}
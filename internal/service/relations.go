@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// LinkAssets makes rel.ChildID a child of rel.ParentID; rel.CreatedBy names
+// the acting user
+// An asset has at most one parent, and no asset may end up its own
+// ancestor
+func (s *Service) LinkAssets(ctx context.Context, rel *models.AssetRelation) error {
+	if !slices.Contains(models.AssetRelations, rel.Relation) {
+		return invalid("relation", "relation must be one of %s", strings.Join(models.AssetRelations, ", "))
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		child, err := getAsset(tx, rel.ChildID)
+		if err != nil {
+			return err
+		}
+		parent, err := getAsset(tx, rel.ParentID)
+		if err != nil {
+			return err
+		}
+		if child.AssetID == parent.AssetID {
+			return invalid("parent", "asset %s cannot be its own parent", child.AssetTag)
+		}
+		if current, err := tx.Relations.GetParent(child.AssetID); err == nil {
+			return conflict("asset %s is already %s %s, unlink it first", child.AssetTag, current.Relation, current.ParentTag)
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		ancestors, err := ancestors(tx, parent.AssetID)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(ancestors, child.AssetID) {
+			return conflict("asset %s contains %s, linking them would make a cycle", child.AssetTag, parent.AssetTag)
+		}
+
+		rel.ChildTag, rel.ParentTag = child.AssetTag, parent.AssetTag
+		return tx.Relations.Create(rel)
+	})
+}
+
+// UnlinkAsset removes an asset from its parent, keeping its own children
+func (s *Service) UnlinkAsset(ctx context.Context, childID string) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		a, err := getAsset(tx, childID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Relations.Delete(childID); errors.Is(err, sql.ErrNoRows) {
+			return conflict("asset %s has no parent", a.AssetTag)
+		} else if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// AssetTree retrieves the tree of relations an asset belongs to, from its
+// topmost ancestor down
+func (s *Service) AssetTree(ctx context.Context, assetID string) (*models.AssetNode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := getAsset(s.stores, assetID); err != nil {
+		return nil, err
+	}
+	ancestors, err := ancestors(s.stores, assetID)
+	if err != nil {
+		return nil, err
+	}
+	root := assetID
+	if len(ancestors) > 0 {
+		root = ancestors[len(ancestors)-1]
+	}
+	return assetTree(s.stores, root, "")
+}
+
+// MoveAsset moves an asset to a location, logging a transfer; with
+// withChildren its descendants not already there move with it
+func (s *Service) MoveAsset(ctx context.Context, assetID string, locationID int, actor *string, withChildren bool) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		a, err := getAsset(tx, assetID)
+		if err != nil {
+			return err
+		}
+		moving := []*models.AssetSummary{a}
+		if withChildren {
+			children, err := descendants(tx, assetID)
+			if err != nil {
+				return err
+			}
+			moving = append(moving, children...)
+		}
+		for _, m := range moving {
+			if m.LocationID == locationID {
+				continue
+			}
+			changed := m.Asset
+			changed.LocationID, changed.UpdatedBy = locationID, actor
+			if err := tx.Assets.Update(&changed); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AssignAssetWithChildren assigns an asset as AssignAsset does, along with
+// its descendants that are not retired or held by the employee already
+// A descendant held by someone else is a conflict
+func (s *Service) AssignAssetWithChildren(ctx context.Context, a *models.AssetAssignment, employee string) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		if err := assignAsset(tx, a, employee); err != nil {
+			return err
+		}
+		children, err := descendants(tx, a.AssetID)
+		if err != nil {
+			return err
+		}
+		for _, c := range children {
+			if c.StatusID == models.StatusRetired {
+				continue
+			}
+			open, err := openAssignment(tx, c.AssetID)
+			if err != nil {
+				return err
+			}
+			if open != nil {
+				if open.EmployeeID == a.EmployeeID {
+					continue
+				}
+				return conflict("component %s of %s is held by %s, return it first", c.AssetTag, a.AssetTag, open.EmployeeName)
+			}
+			child := *a
+			child.AssignmentID, child.AssetID, child.AssetTag = 0, c.AssetID, c.AssetTag
+			if err := assetConflict(c.AssetTag, tx.Assignments.Assign(&child)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReturnAssetWithChildren returns an asset as ReturnAsset does, along with
+// its descendants held by the same employee
+func (s *Service) ReturnAssetWithChildren(ctx context.Context, assetID string, date time.Time, actor *string) error {
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		held, err := openAssignment(tx, assetID)
+		if err != nil {
+			return err
+		}
+		if err := returnAsset(tx, assetID, date, actor); err != nil {
+			return err
+		}
+		children, err := descendants(tx, assetID)
+		if err != nil {
+			return err
+		}
+		for _, c := range children {
+			open, err := openAssignment(tx, c.AssetID)
+			if err != nil {
+				return err
+			}
+			if open == nil || open.EmployeeID != held.EmployeeID {
+				continue
+			}
+			if err := assetConflict(c.AssetTag, tx.Assignments.Return(c.AssetID, date, actor)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ancestors returns the IDs of the parent of an asset, its parent and so
+// on up to the root
+func ancestors(stores *repo.Stores, assetID string) ([]string, error) {
+	var out []string
+	for {
+		rel, err := stores.Relations.GetParent(assetID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return out, nil
+		} else if err != nil {
+			return nil, err
+		}
+		assetID = rel.ParentID
+		out = append(out, assetID)
+	}
+}
+
+// descendants returns the children of an asset, their children and so on,
+// depth first
+func descendants(stores *repo.Stores, assetID string) ([]*models.AssetSummary, error) {
+	node, err := assetTree(stores, assetID, "")
+	if err != nil {
+		return nil, err
+	}
+	var out []*models.AssetSummary
+	var walk func(n *models.AssetNode)
+	walk = func(n *models.AssetNode) {
+		for _, c := range n.Children {
+			out = append(out, c.Asset)
+			walk(c)
+		}
+	}
+	walk(node)
+	return out, nil
+}
+
+// assetTree builds the node of an asset and its descendants
+func assetTree(stores *repo.Stores, assetID, relation string) (*models.AssetNode, error) {
+	a, err := getAsset(stores, assetID)
+	if err != nil {
+		return nil, err
+	}
+	node := &models.AssetNode{Asset: a, Relation: relation, Children: []*models.AssetNode{}}
+	children, err := stores.Relations.ListChildren(assetID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range children {
+		child, err := assetTree(stores, rel.ChildID, rel.Relation)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
@@ -0,0 +1,74 @@
+// Package service implements the inventory operations shared by the TUI,
+// the CLI and the API
+// Each operation takes a context and runs as one unit of work over the
+// stores, so a change spanning several tables is saved whole or not at all
+// Failures the caller can report are returned as *Error values of kind
+// ErrNotFound, ErrConflict or ErrValidation
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MawCeron/it-room/internal/dataio"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/seed"
+)
+
+// Service runs inventory operations against a set of stores
+type Service struct {
+	stores *repo.Stores
+}
+
+// New returns a service over stores
+func New(stores *repo.Stores) *Service {
+	return &Service{stores: stores}
+}
+
+// Stores returns the stores behind the service, for reads that need no
+// business rules
+func (s *Service) Stores() *repo.Stores {
+	return s.stores
+}
+
+// tx runs fn as one unit of work and classifies the error it returns
+func (s *Service) tx(ctx context.Context, fn func(tx *repo.Stores) error) error {
+	return classify(s.stores.Tx(ctx, fn))
+}
+
+// ImportAssets validates and inserts the records of a CSV file as one unit
+// of work, each row going through the rules of CreateAsset and AssignAsset;
+// see dataio.ImportAssets
+func (s *Service) ImportAssets(ctx context.Context, header []string, records []dataio.Record, opts dataio.ImportOptions) (*dataio.ImportReport, error) {
+	report, err := dataio.ImportAssets(ctx, s.stores, importWriter{}, header, records, opts)
+	return report, classify(err)
+}
+
+// importWriter saves the rows of an import as CreateAsset and AssignAsset
+// do, reporting domain errors as row errors
+type importWriter struct{}
+
+func (importWriter) CreateAsset(tx *repo.Stores, a *models.Asset) error {
+	return rowError(createAsset(tx, a))
+}
+
+func (importWriter) AssignAsset(tx *repo.Stores, a *models.AssetAssignment, employee string) error {
+	return rowError(assignAsset(tx, a, employee))
+}
+
+// rowError turns a domain error into a dataio.RowError naming its field
+func rowError(err error) error {
+	var domain *Error
+	if errors.As(classify(err), &domain) {
+		return dataio.RowError{Field: domain.Field, Message: domain.Message}
+	}
+	return err
+}
+
+// Seed fills an empty inventory with generated demo records as one unit of
+// work; see seed.Generate
+func (s *Service) Seed(ctx context.Context, opts seed.Options) (*seed.Summary, error) {
+	summary, err := seed.Generate(ctx, s.stores, opts)
+	return summary, classify(err)
+}
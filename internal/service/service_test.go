@@ -0,0 +1,572 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/dataio"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/repo/memrepo"
+	"github.com/MawCeron/it-room/internal/service"
+)
+
+var ctx = context.Background()
+
+func newAsset(tag string) *models.Asset {
+	return &models.Asset{
+		AssetTag: tag, TypeID: 1, SerialNumber: "SN-" + tag, Maker: "Dell", Model: "Latitude",
+		PurchaseDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), LocationID: 1,
+	}
+}
+
+// newService returns a service over empty in-memory stores holding one
+// available asset and one employee
+func newService(t *testing.T) (*service.Service, *models.Asset) {
+	t.Helper()
+	svc := service.New(memrepo.New())
+	a := newAsset("EQ-001")
+	if err := svc.CreateAsset(ctx, a); err != nil {
+		t.Fatalf("CreateAsset: %v", err)
+	}
+	if err := svc.CreateEmployee(ctx, &models.Employee{FullName: "Ana Ruiz", Email: "ana@example.com"}); err != nil {
+		t.Fatalf("CreateEmployee: %v", err)
+	}
+	return svc, a
+}
+
+func TestErrorKinds(t *testing.T) {
+	svc, a := newService(t)
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"missing tag", svc.CreateAsset(ctx, &models.Asset{TypeID: 1}), service.ErrValidation},
+		{"duplicate tag", svc.CreateAsset(ctx, newAsset("EQ-001")), service.ErrConflict},
+		{"retired on create", svc.CreateAsset(ctx, &models.Asset{AssetTag: "EQ-002", StatusID: models.StatusRetired}), service.ErrValidation},
+		{"unknown asset", svc.RetireAsset(ctx, "missing", now, nil), service.ErrNotFound},
+		{"unknown tag", func() error { _, err := svc.AssetByTag(ctx, "EQ-999"); return err }(), service.ErrNotFound},
+		{"unknown employee", svc.AssignAsset(ctx, &models.AssetAssignment{AssetID: a.AssetID, AssignmentDate: now}, "nobody@example.com"), service.ErrValidation},
+		{"not assigned", svc.ReturnAsset(ctx, a.AssetID, now, nil), service.ErrConflict},
+		{"bad email", svc.CreateEmployee(ctx, &models.Employee{FullName: "Bo", Email: "bo"}), service.ErrValidation},
+		{"empty comment", svc.AddComment(ctx, &models.AssetComment{AssetID: a.AssetID, Body: " "}), service.ErrValidation},
+		{"unknown maintenance type", svc.LogMaintenance(ctx, &models.MaintenanceLog{AssetID: a.AssetID, TypeName: "Polish", Description: "x"}), service.ErrValidation},
+	}
+	for _, tt := range tests {
+		var domain *service.Error
+		if !errors.Is(tt.err, tt.kind) || !errors.As(tt.err, &domain) {
+			t.Errorf("%s: error %v, want a %v *service.Error", tt.name, tt.err, tt.kind)
+		}
+	}
+}
+
+func TestImportFollowsAssetRules(t *testing.T) {
+	svc, _ := newService(t)
+	header, records, err := dataio.ReadCSV(strings.NewReader(`asset_tag,type,make,model,serial_number,status,location,purchase_date
+EQ-010,Laptop,Dell,Latitude,SN-010,Retired,Main,2024-01-15
+EQ-011,Laptop,Dell,Latitude,SN-011,Available,Main,2024-01-15
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := svc.ImportAssets(ctx, header, records, dataio.ImportOptions{Mapping: dataio.GuessMapping(header)})
+	if err != nil {
+		t.Fatalf("ImportAssets: %v", err)
+	}
+	want := dataio.RowError{Line: 2, Field: "status", Message: "status Retired is set by retiring the asset"}
+	if report.Committed || len(report.Errors) != 1 || report.Errors[0] != want {
+		t.Errorf("import of a retired asset = %+v, want only %v", report, want)
+	}
+}
+
+func TestImportCustomFields(t *testing.T) {
+	stores := memrepo.New()
+	svc := service.New(stores)
+	laptop := 1
+	ram := &models.CustomField{Name: "RAM", Kind: models.FieldNumber, TypeID: &laptop, Required: true}
+	if err := svc.CreateCustomField(ctx, ram); err != nil {
+		t.Fatal(err)
+	}
+	csv := func(rows string) ([]string, []dataio.Record) {
+		t.Helper()
+		header, records, err := dataio.ReadCSV(strings.NewReader("asset_tag,type,make,model,serial_number,location,purchase_date,ram,Color\n" + rows))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return header, records
+	}
+
+	header, records := csv("EQ-010,Laptop,Dell,Latitude,SN-010,Main,2024-01-15,,red\n")
+	report, err := svc.ImportAssets(ctx, header, records, dataio.ImportOptions{Mapping: dataio.GuessMapping(header)})
+	if err != nil {
+		t.Fatalf("ImportAssets: %v", err)
+	}
+	want := dataio.RowError{Line: 2, Field: "RAM", Message: "RAM is required"}
+	if report.Committed || len(report.Errors) != 1 || report.Errors[0] != want {
+		t.Errorf("import without a required field = %+v, want only %v", report, want)
+	}
+	if len(report.CustomColumns) != 1 || report.CustomColumns[0] != "RAM" ||
+		len(report.IgnoredColumns) != 1 || report.IgnoredColumns[0] != "Color" {
+		t.Errorf("columns = custom %v, ignored %v, want RAM and Color", report.CustomColumns, report.IgnoredColumns)
+	}
+
+	header, records = csv("EQ-010,Laptop,Dell,Latitude,SN-010,Main,2024-01-15,16.0,\n")
+	if report, err := svc.ImportAssets(ctx, header, records, dataio.ImportOptions{Mapping: dataio.GuessMapping(header)}); err != nil || !report.Committed {
+		t.Fatalf("ImportAssets = %+v, %v", report, err)
+	}
+	a, _ := svc.AssetByTag(ctx, "EQ-010")
+	if values, _ := stores.Fields.ListValues(a.AssetID); len(values) != 1 || values[0].Value != "16" {
+		t.Errorf("imported values = %+v, want RAM 16", values)
+	}
+}
+
+func TestAssignmentRules(t *testing.T) {
+	svc, a := newService(t)
+	now := time.Now().UTC()
+
+	assignment := &models.AssetAssignment{AssetID: a.AssetID, AssignmentDate: now}
+	if err := svc.AssignAsset(ctx, assignment, "Ana Ruiz"); err != nil {
+		t.Fatalf("AssignAsset: %v", err)
+	}
+	if assignment.AssetTag != "EQ-001" || assignment.EmployeeName != "Ana Ruiz" {
+		t.Errorf("assignment = %+v, want the tag and employee filled in", assignment)
+	}
+
+	err := svc.AssignAsset(ctx, &models.AssetAssignment{AssetID: a.AssetID, AssignmentDate: now}, "ana@example.com")
+	if !errors.Is(err, service.ErrConflict) || !errors.Is(err, repo.ErrAssetAssigned) {
+		t.Errorf("second AssignAsset = %v, want a conflict wrapping ErrAssetAssigned", err)
+	}
+
+	changed := *a
+	changed.StatusID = models.StatusUnderMaintenance
+	if err := svc.UpdateAsset(ctx, &changed); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("status change of an assigned asset = %v, want a conflict", err)
+	}
+
+	if err := svc.RetireAsset(ctx, a.AssetID, now, nil); err != nil {
+		t.Fatalf("RetireAsset: %v", err)
+	}
+	if err := svc.RetireAsset(ctx, a.AssetID, now, nil); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("second RetireAsset = %v, want a conflict", err)
+	}
+}
+
+func TestKitRules(t *testing.T) {
+	svc, a := newService(t)
+	now := time.Now().UTC()
+	mouse, spare := newAsset("AC-001"), newAsset("EQ-002")
+	mouse.TypeID = 39 // Mouse
+	for _, asset := range []*models.Asset{mouse, spare} {
+		if err := svc.CreateAsset(ctx, asset); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tmpl := &models.KitTemplate{Name: "New Hire", Items: []models.KitTemplateItem{{TypeID: 1, Quantity: 1}, {TypeID: 39, Quantity: 1}, {TypeID: 38, Quantity: 1}}}
+	if err := svc.CreateKitTemplate(ctx, tmpl); err != nil {
+		t.Fatalf("CreateKitTemplate: %v", err)
+	}
+	tooMany := &models.Kit{KitTag: "KIT-000", TemplateID: &tmpl.TemplateID, AssetIDs: []string{a.AssetID, spare.AssetID}}
+	if err := svc.CreateKit(ctx, tooMany); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("CreateKit beyond the template = %v, want a validation error", err)
+	}
+	k := &models.Kit{KitTag: "KIT-001", TemplateID: &tmpl.TemplateID, AssetIDs: []string{a.AssetID, mouse.AssetID}}
+	if err := svc.CreateKit(ctx, k); err != nil {
+		t.Fatalf("CreateKit: %v", err)
+	}
+	if err := svc.CreateKit(ctx, &models.Kit{KitTag: "KIT-002", AssetIDs: []string{a.AssetID}}); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("CreateKit with a member of another kit = %v, want a conflict", err)
+	}
+
+	if err := svc.AssignKit(ctx, k.KitID, models.AssetAssignment{AssignmentDate: now}, "ana@example.com"); err != nil {
+		t.Fatalf("AssignKit: %v", err)
+	}
+	summary, err := svc.KitByTag(ctx, "KIT-001")
+	if err != nil || summary.State != models.KitAssigned || summary.HolderName == nil || *summary.HolderName != "Ana Ruiz" ||
+		len(summary.Missing) != 1 || summary.Missing[0] != "1 Keyboard" {
+		t.Errorf("assigned kit = %+v, %v", summary, err)
+	}
+
+	if err := svc.SwapKitAsset(ctx, k.KitID, a.AssetID, mouse.AssetID, now, nil); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("swap for a member = %v, want a conflict", err)
+	}
+	if err := svc.SwapKitAsset(ctx, k.KitID, a.AssetID, spare.AssetID, now, nil); err != nil {
+		t.Fatalf("SwapKitAsset: %v", err)
+	}
+	if old, _ := svc.AssetByTag(ctx, "EQ-001"); old.StatusID != models.StatusAvailable {
+		t.Errorf("swapped out asset has status %s, want Available", old.StatusName)
+	}
+	if summary, _ := svc.KitByTag(ctx, "KIT-001"); summary.State != models.KitAssigned || summary.Assets[1].AssetTag != "EQ-002" {
+		t.Errorf("kit after swap = %+v", summary)
+	}
+
+	// A member returned on its own is replaced with an asset going to the
+	// kit's holder
+	newMouse := newAsset("AC-002")
+	newMouse.TypeID = 39
+	if err := svc.CreateAsset(ctx, newMouse); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ReturnAsset(ctx, mouse.AssetID, now, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SwapKitAsset(ctx, k.KitID, mouse.AssetID, newMouse.AssetID, now, nil); err != nil {
+		t.Fatalf("SwapKitAsset of a returned member: %v", err)
+	}
+	if m, _ := svc.AssetByTag(ctx, "AC-002"); m.StatusID != models.StatusAssigned || m.HolderName == nil || *m.HolderName != "Ana Ruiz" {
+		t.Errorf("replacement of a returned member = %+v, want it assigned to Ana Ruiz", m)
+	}
+	if summary, _ := svc.KitByTag(ctx, "KIT-001"); summary.State != models.KitAssigned {
+		t.Errorf("kit after swapping a returned member is %s, want assigned", summary.State)
+	}
+
+	if err := svc.TransferKit(ctx, k.KitID, 2, nil); err != nil {
+		t.Fatalf("TransferKit: %v", err)
+	}
+
+	// A replacement joins the other members where the kit now is
+	remoteMouse := newAsset("AC-003")
+	remoteMouse.TypeID = 39
+	if err := svc.CreateAsset(ctx, remoteMouse); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SwapKitAsset(ctx, k.KitID, newMouse.AssetID, remoteMouse.AssetID, now, nil); err != nil {
+		t.Fatalf("SwapKitAsset after TransferKit: %v", err)
+	}
+	if m, _ := svc.AssetByTag(ctx, "AC-003"); m.LocationName != "Remote" || m.StatusID != models.StatusAssigned {
+		t.Errorf("replacement is %s at %s, want Assigned at Remote with the kit", m.StatusName, m.LocationName)
+	}
+	if err := svc.ReturnKit(ctx, k.KitID, now, nil); err != nil {
+		t.Fatalf("ReturnKit: %v", err)
+	}
+	if err := svc.ReturnKit(ctx, k.KitID, now, nil); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("second ReturnKit = %v, want a conflict", err)
+	}
+	summary, _ = svc.KitByTag(ctx, "KIT-001")
+	for _, m := range summary.Assets {
+		if m.LocationName != "Remote" || m.StatusID != models.StatusAvailable {
+			t.Errorf("member %s is %s at %s, want Available at Remote", m.AssetTag, m.StatusName, m.LocationName)
+		}
+	}
+
+	if err := svc.DissolveKit(ctx, k.KitID); err != nil {
+		t.Fatalf("DissolveKit: %v", err)
+	}
+	if _, err := svc.KitByTag(ctx, "KIT-001"); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("KitByTag after DissolveKit = %v, want not found", err)
+	}
+}
+
+func TestAssetRelations(t *testing.T) {
+	svc, desktop := newService(t)
+	now := time.Now().UTC()
+	monitor, drive := newAsset("MS-001"), newAsset("SD-001")
+	for _, asset := range []*models.Asset{monitor, drive} {
+		if err := svc.CreateAsset(ctx, asset); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := func(child, parent *models.Asset, relation string) error {
+		return svc.LinkAssets(ctx, &models.AssetRelation{ChildID: child.AssetID, ParentID: parent.AssetID, Relation: relation})
+	}
+
+	if err := link(monitor, desktop, models.RelationAttachedTo); err != nil {
+		t.Fatalf("LinkAssets: %v", err)
+	}
+	if err := link(drive, monitor, models.RelationInstalledIn); err != nil {
+		t.Fatalf("LinkAssets: %v", err)
+	}
+	if err := link(desktop, drive, models.RelationPoweredBy); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("link making a cycle = %v, want a conflict", err)
+	}
+	if err := link(drive, desktop, models.RelationInstalledIn); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("link to a second parent = %v, want a conflict", err)
+	}
+	if err := link(desktop, desktop, "glued-to"); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("link with an unknown relation = %v, want a validation error", err)
+	}
+
+	tree, err := svc.AssetTree(ctx, drive.AssetID)
+	if err != nil || tree.Asset.AssetTag != "EQ-001" || len(tree.Children) != 1 ||
+		tree.Children[0].Relation != models.RelationAttachedTo || tree.Children[0].Children[0].Asset.AssetTag != "SD-001" {
+		t.Errorf("AssetTree = %+v, %v", tree, err)
+	}
+
+	if err := svc.MoveAsset(ctx, desktop.AssetID, 2, nil, true); err != nil {
+		t.Fatalf("MoveAsset: %v", err)
+	}
+	if err := svc.AssignAssetWithChildren(ctx, &models.AssetAssignment{AssetID: desktop.AssetID, AssignmentDate: now}, "Ana Ruiz"); err != nil {
+		t.Fatalf("AssignAssetWithChildren: %v", err)
+	}
+	for _, tag := range []string{"EQ-001", "MS-001", "SD-001"} {
+		if a, _ := svc.AssetByTag(ctx, tag); a.StatusID != models.StatusAssigned || a.LocationName != "Remote" {
+			t.Errorf("%s is %s at %s, want Assigned at Remote", tag, a.StatusName, a.LocationName)
+		}
+	}
+
+	if err := svc.UnlinkAsset(ctx, drive.AssetID); err != nil {
+		t.Fatalf("UnlinkAsset: %v", err)
+	}
+	if err := svc.ReturnAssetWithChildren(ctx, desktop.AssetID, now, nil); err != nil {
+		t.Fatalf("ReturnAssetWithChildren: %v", err)
+	}
+	if a, _ := svc.AssetByTag(ctx, "MS-001"); a.StatusID != models.StatusAvailable {
+		t.Errorf("returned child is %s, want Available", a.StatusName)
+	}
+	if a, _ := svc.AssetByTag(ctx, "SD-001"); a.StatusID != models.StatusAssigned {
+		t.Errorf("unlinked asset is %s, want it still assigned", a.StatusName)
+	}
+}
+
+func TestStockRules(t *testing.T) {
+	svc, _ := newService(t)
+	now := time.Now().UTC()
+	mouse := 39
+	mice := &models.StockItem{Name: " USB Mouse ", TypeID: &mouse, ReorderLevel: 2}
+	if err := svc.CreateStockItem(ctx, mice); err != nil || mice.Name != "USB Mouse" || *mice.TypeName != "Mouse" {
+		t.Fatalf("CreateStockItem = %+v, %v", mice, err)
+	}
+	if err := svc.CreateStockItem(ctx, &models.StockItem{Name: "usb mouse"}); err == nil {
+		t.Error("CreateStockItem with a duplicate name succeeded")
+	}
+	move := func(quantity, location int) *models.StockMovement {
+		return &models.StockMovement{ItemID: mice.ItemID, LocationID: location, Quantity: quantity, MovedAt: now}
+	}
+
+	if err := svc.AdjustStock(ctx, move(5, 1)); err != nil {
+		t.Fatalf("AdjustStock: %v", err)
+	}
+	if err := svc.AdjustStock(ctx, move(-6, 1)); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("adjusting below zero = %v, want a conflict", err)
+	}
+	if err := svc.IssueStock(ctx, move(3, 2), "Ana Ruiz"); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("issuing from a location out of stock = %v, want a conflict", err)
+	}
+	if err := svc.IssueStock(ctx, move(0, 1), "Ana Ruiz"); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("issuing no units = %v, want a validation error", err)
+	}
+	issue := move(3, 1)
+	if err := svc.IssueStock(ctx, issue, "ana@example.com"); err != nil {
+		t.Fatalf("IssueStock: %v", err)
+	}
+	if issue.Kind != models.StockIssue || issue.Quantity != -3 {
+		t.Errorf("issue recorded as %s %d, want issue -3", issue.Kind, issue.Quantity)
+	}
+	if err := svc.ReturnStock(ctx, move(4, 2), "Ana Ruiz"); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("returning more than held = %v, want a conflict", err)
+	}
+	if err := svc.ReturnStock(ctx, move(1, 2), "Ana Ruiz"); err != nil {
+		t.Fatalf("ReturnStock: %v", err)
+	}
+
+	d, err := svc.StockItemByName(ctx, "usb mouse")
+	if err != nil || d.OnHand != 3 || d.Issued != 2 || len(d.Levels) != 2 || d.Levels[0].Quantity != 2 ||
+		len(d.Holdings) != 1 || d.Holdings[0].Quantity != 2 {
+		t.Errorf("StockItemByName = %+v, %v", d, err)
+	}
+	if held, err := svc.StockHeldBy(ctx, "Ana Ruiz"); err != nil || len(held) != 1 || held[0].ItemName != "USB Mouse" {
+		t.Errorf("StockHeldBy = %+v, %v", held, err)
+	}
+	if _, err := svc.StockItemByName(ctx, "Cable"); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("StockItemByName of a missing item = %v, want not found", err)
+	}
+}
+
+func TestCustomFields(t *testing.T) {
+	stores := memrepo.New()
+	svc := service.New(stores)
+	laptop, desktop, computers, missing := 1, 2, 1, 999
+	minimum, infinite, paren := 1.0, math.Inf(1), "("
+	ram := &models.CustomField{Name: " RAM ", Kind: models.FieldNumber, TypeID: &laptop, Required: true, Min: &minimum}
+	if err := svc.CreateCustomField(ctx, ram); err != nil || ram.Name != "RAM" {
+		t.Fatalf("CreateCustomField = %+v, %v", ram, err)
+	}
+	plan := &models.CustomField{Name: "Plan", Kind: models.FieldEnum, CategoryID: &computers, Options: []string{"Basic", "Premium"}}
+	if err := svc.CreateCustomField(ctx, plan); err != nil {
+		t.Fatalf("CreateCustomField: %v", err)
+	}
+	for name, tt := range map[string]struct {
+		f    *models.CustomField
+		kind error
+	}{
+		"name of a type field":     {&models.CustomField{Name: "ram", Kind: models.FieldText, CategoryID: &computers}, service.ErrConflict},
+		"name of a category field": {&models.CustomField{Name: "PLAN", Kind: models.FieldText, TypeID: &desktop}, service.ErrConflict},
+		"enum without options":     {&models.CustomField{Name: "OS", Kind: models.FieldEnum, TypeID: &laptop}, service.ErrValidation},
+		"repeated option":          {&models.CustomField{Name: "OS", Kind: models.FieldEnum, TypeID: &laptop, Options: []string{"Linux", "linux"}}, service.ErrValidation},
+		"bad pattern":              {&models.CustomField{Name: "OS", Kind: models.FieldText, TypeID: &laptop, Pattern: &paren}, service.ErrValidation},
+		"bounds on text":           {&models.CustomField{Name: "OS", Kind: models.FieldText, TypeID: &laptop, Max: &minimum}, service.ErrValidation},
+		"infinite maximum":         {&models.CustomField{Name: "OS", Kind: models.FieldNumber, TypeID: &laptop, Max: &infinite}, service.ErrValidation},
+		"unknown category":         {&models.CustomField{Name: "OS", Kind: models.FieldText, CategoryID: &missing}, service.ErrValidation},
+	} {
+		if err := svc.CreateCustomField(ctx, tt.f); !errors.Is(err, tt.kind) {
+			t.Errorf("CreateCustomField with a %s = %v, want %v", name, err, tt.kind)
+		}
+	}
+
+	a := newAsset("EQ-001")
+	if err := svc.CreateAsset(ctx, a); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("CreateAsset without a required field = %v, want a validation error", err)
+	}
+	for _, bad := range []map[string]string{{"RAM": "lots"}, {"RAM": "NaN"}, {"RAM": "Inf"}, {"RAM": "0"}, {"RAM": "8", "Plan": "Gold"}, {"RAM": "8", "Color": "red"}} {
+		a.Fields = bad
+		if err := svc.CreateAsset(ctx, a); !errors.Is(err, service.ErrValidation) {
+			t.Errorf("CreateAsset with fields %v = %v, want a validation error", bad, err)
+		}
+	}
+	a.Fields = map[string]string{"ram": "16.0", "plan": "premium"}
+	if err := svc.CreateAsset(ctx, a); err != nil {
+		t.Fatalf("CreateAsset: %v", err)
+	}
+	values, _ := stores.Fields.ListValues(a.AssetID)
+	if len(values) != 2 || values[0].Value != "Premium" || values[1].Value != "16" {
+		t.Errorf("stored values = %+v, want Premium and 16", values)
+	}
+
+	// Values given on update are merged, and an empty one clears the field
+	a.Fields = map[string]string{"Plan": ""}
+	if err := svc.UpdateAsset(ctx, a); err != nil {
+		t.Fatalf("UpdateAsset: %v", err)
+	}
+	a.Fields = map[string]string{"RAM": ""}
+	if err := svc.UpdateAsset(ctx, a); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("clearing a required field = %v, want a validation error", err)
+	}
+	// Moving to a type without the field drops its value
+	a.Fields, a.TypeID = nil, desktop
+	if err := svc.UpdateAsset(ctx, a); err != nil {
+		t.Fatalf("UpdateAsset: %v", err)
+	}
+	if values, _ := stores.Fields.ListValues(a.AssetID); len(values) != 0 {
+		t.Errorf("values left after the type change: %+v", values)
+	}
+
+	if _, err := svc.DeleteCustomField(ctx, 999); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("DeleteCustomField of a missing field = %v, want not found", err)
+	}
+	if f, err := svc.DeleteCustomField(ctx, ram.FieldID); err != nil || f.Name != "RAM" {
+		t.Errorf("DeleteCustomField = %+v, %v", f, err)
+	}
+}
+
+func TestDepreciation(t *testing.T) {
+	svc, _ := newService(t)
+	computers, printers, laserPrinter := 1, 2, 7
+	day := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	laptop := newAsset("EQ-002")
+	cost, currency := 1200.0, " usd "
+	laptop.PurchaseCost, laptop.Currency = &cost, &currency
+	if err := svc.CreateAsset(ctx, laptop); err != nil || *laptop.Currency != "USD" {
+		t.Fatalf("CreateAsset = %v, currency %q", err, *laptop.Currency)
+	}
+	printer := newAsset("PR-001")
+	printerCost, euros := 1000.0, "EUR"
+	printer.TypeID, printer.PurchaseCost, printer.Currency = laserPrinter, &printerCost, &euros
+	retired := newAsset("EQ-003")
+	retired.PurchaseCost, retired.Currency = &cost, &currency
+	for _, a := range []*models.Asset{printer, retired} {
+		if err := svc.CreateAsset(ctx, a); err != nil {
+			t.Fatalf("CreateAsset: %v", err)
+		}
+	}
+	if err := svc.RetireAsset(ctx, retired.AssetID, day, nil); err != nil {
+		t.Fatalf("RetireAsset: %v", err)
+	}
+
+	negative, dollars, code := -1.0, "USD", "US"
+	for name, a := range map[string]*models.Asset{
+		"negative cost":         {PurchaseCost: &negative, Currency: &dollars},
+		"cost without currency": {PurchaseCost: &cost},
+		"currency without cost": {Currency: &dollars},
+		"bad currency":          {PurchaseCost: &cost, Currency: &code},
+	} {
+		asset := newAsset("EQ-099")
+		asset.PurchaseCost, asset.Currency = a.PurchaseCost, a.Currency
+		if err := svc.CreateAsset(ctx, asset); !errors.Is(err, service.ErrValidation) {
+			t.Errorf("%s: CreateAsset = %v, want a validation error", name, err)
+		}
+	}
+
+	factor := 1.5
+	for name, rule := range map[string]*models.DepreciationRule{
+		"unknown method":       {CategoryID: computers, Method: "sum-of-years", UsefulLifeYears: 3},
+		"no useful life":       {CategoryID: computers, Method: models.DepreciationStraightLine},
+		"full salvage":         {CategoryID: computers, Method: models.DepreciationStraightLine, UsefulLifeYears: 3, SalvagePercent: 100},
+		"straight with factor": {CategoryID: computers, Method: models.DepreciationStraightLine, UsefulLifeYears: 3, Factor: &factor},
+		"unknown category":     {CategoryID: 999, Method: models.DepreciationStraightLine, UsefulLifeYears: 3},
+	} {
+		if err := svc.SetDepreciationRule(ctx, rule); !errors.Is(err, service.ErrValidation) {
+			t.Errorf("%s: SetDepreciationRule = %v, want a validation error", name, err)
+		}
+	}
+	straight := &models.DepreciationRule{CategoryID: computers, Method: "Straight-Line", UsefulLifeYears: 4, SalvagePercent: 10}
+	declining := &models.DepreciationRule{CategoryID: printers, Method: models.DepreciationDecliningBalance, UsefulLifeYears: 5}
+	for _, rule := range []*models.DepreciationRule{straight, declining} {
+		if err := svc.SetDepreciationRule(ctx, rule); err != nil {
+			t.Fatalf("SetDepreciationRule: %v", err)
+		}
+	}
+	if declining.Factor == nil || *declining.Factor != 2 {
+		t.Errorf("declining balance factor = %v, want double declining", declining.Factor)
+	}
+
+	// Straight-line: two of four years of 1200 above a 120 salvage value;
+	// declining balance: two years at 40% of what is left
+	values, err := svc.BookValues(ctx, repo.AssetQuery{SortColumn: "asset_tag"}, day)
+	if err != nil {
+		t.Fatalf("BookValues: %v", err)
+	}
+	if len(values) != 2 || values[0].AssetTag != "EQ-002" || values[0].Value != 660 || values[0].Depreciation != 540 ||
+		values[1].AssetTag != "PR-001" || values[1].Value != 360 || values[1].Currency != "EUR" {
+		t.Fatalf("BookValues = %+v", values)
+	}
+	for _, tt := range []struct {
+		day  time.Time
+		want float64
+	}{
+		{time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), 1200},
+		{time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC), 952.5},
+		{time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC), 120},
+	} {
+		if got := straight.BookValueOn(1200, laptop.PurchaseDate, tt.day); got != tt.want {
+			t.Errorf("straight-line value on %s = %v, want %v", tt.day.Format(time.DateOnly), got, tt.want)
+		}
+	}
+
+	totals := models.TotalBookValues(values, func(v *models.BookValue) string { return v.CategoryName })
+	if len(totals) != 2 || totals[0].Group != "Computer Equipment" || totals[0].Assets != 1 || totals[0].Cost != 1200 ||
+		totals[1].Currency != "EUR" || totals[1].Value != 360 {
+		t.Errorf("TotalBookValues = %+v", totals)
+	}
+
+	if _, err := svc.DeleteDepreciationRule(ctx, printers); err != nil {
+		t.Fatalf("DeleteDepreciationRule: %v", err)
+	}
+	if _, err := svc.DeleteDepreciationRule(ctx, printers); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("DeleteDepreciationRule of a missing rule = %v, want not found", err)
+	}
+	if values, _ := svc.BookValues(ctx, repo.AssetQuery{}, day); len(values) != 2 || values[1].Value != 1000 || values[1].Method != "" {
+		t.Errorf("BookValues without a rule = %+v", values)
+	}
+}
+
+func TestCancelledContext(t *testing.T) {
+	svc, _ := newService(t)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if err := svc.CreateAsset(cancelled, newAsset("EQ-002")); !errors.Is(err, context.Canceled) {
+		t.Errorf("CreateAsset = %v, want context.Canceled", err)
+	}
+	if _, err := svc.AssetByTag(ctx, "EQ-002"); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("asset created despite the cancelled context: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+)
+
+// CreateStockItem adds a stock item with nothing in stock; i.CreatedBy
+// names the acting user
+func (s *Service) CreateStockItem(ctx context.Context, i *models.StockItem) error {
+	i.Name = strings.TrimSpace(i.Name)
+	switch {
+	case i.Name == "":
+		return invalid("name", "item name is required")
+	case i.ReorderLevel < 0:
+		return invalid("reorder_level", "reorder level cannot be negative")
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		if i.TypeID != nil {
+			typ, err := tx.Assets.GetAssetType(*i.TypeID)
+			if err != nil {
+				return lookup(err, "type", "asset type", fmt.Sprint(*i.TypeID))
+			}
+			i.TypeName = &typ.TypeName
+		}
+		return tx.Stock.CreateItem(i)
+	})
+}
+
+// StockItemByName retrieves a stock item, ignoring case, with the locations
+// and employees holding its units
+func (s *Service) StockItemByName(ctx context.Context, name string) (*models.StockItemDetail, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	i, err := s.stores.Stock.FindItem(name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound("stock item %q not found", name)
+	} else if err != nil {
+		return nil, err
+	}
+	d := &models.StockItemDetail{StockItem: *i}
+	if d.Levels, err = s.stores.Stock.ListLevels(i.ItemID); err != nil {
+		return nil, err
+	}
+	if d.Holdings, err = s.stores.Stock.ListHoldings(i.ItemID); err != nil {
+		return nil, err
+	}
+	if d.Levels == nil {
+		d.Levels = []*models.StockLevel{}
+	}
+	if d.Holdings == nil {
+		d.Holdings = []*models.StockHolding{}
+	}
+	return d, nil
+}
+
+// ListStockItems retrieves the stock items with their totals, ordered by
+// name
+func (s *Service) ListStockItems(ctx context.Context) ([]*models.StockItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	items, err := s.stores.Stock.ListItems()
+	if items == nil {
+		items = []*models.StockItem{}
+	}
+	return items, err
+}
+
+// StockHeldBy retrieves the stock items an employee holds units of; the
+// employee is given by email or unique name
+func (s *Service) StockHeldBy(ctx context.Context, employee string) ([]*models.StockHolding, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e, err := repo.ResolveEmployee(s.stores.Employees, employee)
+	if err != nil {
+		return nil, invalid("employee", "%v", err)
+	}
+	holdings, err := s.stores.Stock.ListHoldingsByEmployee(e.EmployeeID)
+	if holdings == nil {
+		holdings = []*models.StockHolding{}
+	}
+	return holdings, err
+}
+
+// AdjustStock adds m.Quantity units of an item at a location, or removes
+// them when negative, e.g. after a delivery or a count
+// The stock at the location cannot go below zero
+func (s *Service) AdjustStock(ctx context.Context, m *models.StockMovement) error {
+	if m.Quantity == 0 {
+		return invalid("quantity", "quantity must not be zero")
+	}
+	m.Kind, m.EmployeeID = models.StockAdjust, nil
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		i, err := getStockItem(tx, m.ItemID)
+		if err != nil {
+			return err
+		}
+		level, err := stockLevel(tx, m.ItemID, m.LocationID)
+		if err != nil {
+			return err
+		}
+		if level+m.Quantity < 0 {
+			return conflict("only %d %s in stock there, cannot remove %d", level, i.Name, -m.Quantity)
+		}
+		m.ItemName = i.Name
+		return tx.Stock.RecordMovement(m)
+	})
+}
+
+// IssueStock hands m.Quantity units of an item from a location to an
+// employee, given by email or unique name
+// m.Quantity is stored negated, as the change to the stock at the location
+func (s *Service) IssueStock(ctx context.Context, m *models.StockMovement, employee string) error {
+	if m.Quantity <= 0 {
+		return invalid("quantity", "quantity must be positive")
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		i, e, err := stockHandover(tx, m, employee)
+		if err != nil {
+			return err
+		}
+		level, err := stockLevel(tx, m.ItemID, m.LocationID)
+		if err != nil {
+			return err
+		}
+		if level < m.Quantity {
+			return conflict("only %d %s in stock there, cannot issue %d", level, i.Name, m.Quantity)
+		}
+		m.Kind, m.Quantity, m.EmployeeID = models.StockIssue, -m.Quantity, &e.EmployeeID
+		return tx.Stock.RecordMovement(m)
+	})
+}
+
+// ReturnStock takes m.Quantity units of an item back from an employee,
+// given by email or unique name, into stock at a location
+func (s *Service) ReturnStock(ctx context.Context, m *models.StockMovement, employee string) error {
+	if m.Quantity <= 0 {
+		return invalid("quantity", "quantity must be positive")
+	}
+	return s.tx(ctx, func(tx *repo.Stores) error {
+		i, e, err := stockHandover(tx, m, employee)
+		if err != nil {
+			return err
+		}
+		held, err := stockHeld(tx, m.ItemID, e.EmployeeID)
+		if err != nil {
+			return err
+		}
+		if held < m.Quantity {
+			return conflict("%s holds %d %s, cannot return %d", e.FullName, held, i.Name, m.Quantity)
+		}
+		m.Kind, m.EmployeeID = models.StockReturn, &e.EmployeeID
+		return tx.Stock.RecordMovement(m)
+	})
+}
+
+// stockHandover resolves the item and employee of an issue or return,
+// setting m.ItemName and m.EmployeeName
+func stockHandover(tx *repo.Stores, m *models.StockMovement, employee string) (*models.StockItem, *models.Employee, error) {
+	i, err := getStockItem(tx, m.ItemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	e, err := repo.ResolveEmployee(tx.Employees, employee)
+	if err != nil {
+		return nil, nil, invalid("employee", "%v", err)
+	}
+	m.ItemName, m.EmployeeName = i.Name, &e.FullName
+	return i, e, nil
+}
+
+// getStockItem retrieves a stock item by ID, reporting a missing one as not
+// found
+func getStockItem(stores *repo.Stores, itemID int) (*models.StockItem, error) {
+	i, err := stores.Stock.GetItem(itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound("stock item %d not found", itemID)
+	}
+	return i, err
+}
+
+// stockLevel returns the units of an item in stock at a location
+func stockLevel(stores *repo.Stores, itemID, locationID int) (int, error) {
+	levels, err := stores.Stock.ListLevels(itemID)
+	if err != nil {
+		return 0, err
+	}
+	for _, l := range levels {
+		if l.LocationID == locationID {
+			return l.Quantity, nil
+		}
+	}
+	return 0, nil
+}
+
+// stockHeld returns the units of an item an employee holds
+func stockHeld(stores *repo.Stores, itemID, employeeID int) (int, error) {
+	holdings, err := stores.Stock.ListHoldings(itemID)
+	if err != nil {
+		return 0, err
+	}
+	for _, h := range holdings {
+		if h.EmployeeID == employeeID {
+			return h.Quantity, nil
+		}
+	}
+	return 0, nil
+}
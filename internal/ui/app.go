@@ -2,73 +2,222 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/MawCeron/it-room/internal/db"
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/config"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/service"
 	"github.com/MawCeron/it-room/internal/ui/assets"
+	"github.com/MawCeron/it-room/internal/ui/keymap"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
 type App struct {
-	app *tview.Application
-	db  *db.DB
+	app    *tview.Application
+	svc    *service.Service
+	stores *repo.Stores // Stores behind svc
+	cfg    *config.Config
+	keys   *keymap.Registry
+	root   *tview.Pages // Main layout plus overlays such as the help screen
+	user   *models.User // Signed in user, nil while authentication is not enabled
+
+	menu        *tview.List
+	frame       *tview.Frame // Menu frame, showing the signed in user
+	pages       *tview.Pages
+	menuPages   []string // Page name of each menu entry
+	dashboard   *DashboardPage
+	warranty    *WarrantyPage
+	assets      *assets.AssetsPage
+	licenses    *LicensesPage
+	consumables *ConsumablesPage
+	stock       *StockPage
 }
 
-func NewApp(d *db.DB) *App {
+// NewApp creates the application over svc, whose stores are the SQLite
+// repositories in production and in-memory doubles in tests
+func NewApp(svc *service.Service, cfg *config.Config) *App {
 	a := tview.NewApplication()
-	app := &App{app: a, db: d}
+	app := &App{app: a, svc: svc, stores: svc.Stores(), cfg: cfg, keys: keymap.New(cfg.Keys)}
 	return app
 }
 
+// SetScreen makes the application draw on s instead of the terminal
+func (a *App) SetScreen(s tcell.Screen) {
+	a.app.SetScreen(s)
+}
+
+// QueueUpdate runs f on the event loop and waits for it to return
+func (a *App) QueueUpdate(f func()) {
+	a.app.QueueUpdate(f)
+}
+
 func (a *App) Run() error {
 
-	pages := tview.NewPages()
+	a.pages = tview.NewPages()
 
-	assetsPage := assets.New(a.db, pages)
-	licensesPage := NewLicensesPage(a.db)
+	a.dashboard = NewDashboardPage(a)
+	a.assets = assets.New(a.app, a.svc, a.pages, a.keys, a.cfg)
+	a.licenses = NewLicensesPage(a.stores.Licenses, a.keys)
+	a.consumables = NewConsumablesPage(a.stores.Consumables, a.keys)
+	a.stock = NewStockPage(a.stores.Stock, a.keys)
+	a.warranty = NewWarrantyPage(a)
 
-	pages.AddPage(assetsPage.Name(), assetsPage.View(), true, true)
-	pages.AddPage(licensesPage.Name(), licensesPage.View(), true, false)
+	a.pages.AddPage(a.dashboard.Name(), a.dashboard.View(), true, true)
+	a.pages.AddPage(a.assets.Name(), a.assets.View(), true, false)
+	a.pages.AddPage(a.licenses.Name(), a.licenses.View(), true, false)
+	a.pages.AddPage(a.consumables.Name(), a.consumables.View(), true, false)
+	a.pages.AddPage(a.stock.Name(), a.stock.View(), true, false)
+	a.pages.AddPage(a.warranty.Name(), a.warranty.View(), true, false)
 
-	menu := tview.NewList()
+	a.menu = tview.NewList()
 	menuWidth := 20
-	menu.AddItem("Assets", "", 0, func() {
-		pages.SwitchToPage(assetsPage.Name())
-	})
-	menu.AddItem("Licenses", "", 0, func() {
-		pages.SwitchToPage(licensesPage.Name())
-	})
-	menu.AddItem("Consumables", "", 0, nil)
-	menu.ShowSecondaryText(false)
+	for _, name := range []string{a.dashboard.Name(), a.assets.Name(), a.licenses.Name(), a.consumables.Name(), a.stock.Name(), a.warranty.Name()} {
+		a.menuPages = append(a.menuPages, name)
+		a.menu.AddItem(name, "", 0, func() {
+			a.pages.SwitchToPage(name)
+		})
+	}
+	a.menu.ShowSecondaryText(false)
 
-	frame := tview.NewFrame(menu)
-	frame.SetBorder(true)
-	frame.SetBorders(1, 0, 1, 1, 1, 1)
-	frame.SetTitle(" IT Room ")
+	a.frame = tview.NewFrame(a.menu)
+	a.frame.SetBorder(true)
+	a.frame.SetBorders(1, 0, 1, 1, 1, 1)
+	a.frame.SetTitle(" IT Room ")
 
 	flex := tview.NewFlex()
-	flex.AddItem(frame, menuWidth+3, 1, false)
-	flex.AddItem(pages, 0, 1, true)
-
-	a.app.SetRoot(flex, true).EnableMouse(true)
-	a.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyCtrlQ {
-			a.app.Stop()
-			return nil
+	flex.AddItem(a.frame, menuWidth+3, 1, false)
+	flex.AddItem(a.pages, 0, 1, true)
+
+	a.root = tview.NewPages().AddPage("main", flex, true, true)
+
+	a.registerGlobalActions()
+	if err := a.keys.Err(); err != nil {
+		return fmt.Errorf("key bindings: %w", err)
+	}
+
+	// Once users exist, nothing is shown until one signs in
+	enabled, err := auth.Enabled(a.stores.Users)
+	if err != nil {
+		return fmt.Errorf("users: %w", err)
+	}
+	if enabled {
+		a.showLogin()
+	}
+
+	a.app.SetRoot(a.root, true).EnableMouse(true)
+	a.app.SetInputCapture(a.captureGlobalKeys)
+	if err := a.app.Run(); err != nil {
+		return fmt.Errorf("tview run: %w", err)
+	}
+
+	return nil
+}
+
+// switchTo shows the named page, selects its menu entry and focuses it
+func (a *App) switchTo(name string) {
+	for i, page := range a.menuPages {
+		if page == name {
+			a.menu.SetCurrentItem(i)
 		}
-		if event.Key() == tcell.KeyTab {
-			if a.app.GetFocus() == menu {
-				a.app.SetFocus(pages)
+	}
+	a.pages.SwitchToPage(name)
+	a.app.SetFocus(a.pages)
+}
+
+// registerGlobalActions registers the actions available on every page,
+// including the optional vim-style navigation keys
+// Only quitting and navigation work on the sign in form
+func (a *App) registerGlobalActions() {
+	signedIn := func() bool { return !a.signingIn() }
+
+	a.keys.Register(keymap.Action{
+		ID: "app.quit", Scope: keymap.GlobalScope, Description: "Quit",
+		Keys:    []string{"Ctrl+Q"},
+		Handler: a.app.Stop,
+	})
+	a.keys.Register(keymap.Action{
+		ID: "app.focus", Scope: keymap.GlobalScope, Description: "Switch between menu and page",
+		Keys: []string{"Tab"}, Available: signedIn,
+		Handler: func() {
+			if a.app.GetFocus() == a.menu {
+				a.app.SetFocus(a.pages)
 			} else {
-				a.app.SetFocus(menu)
+				a.app.SetFocus(a.menu)
 			}
-			return nil
+		},
+	})
+	a.keys.Register(keymap.Action{
+		ID: "app.help", Scope: keymap.GlobalScope, Description: "Help",
+		Keys:    []string{"?"},
+		Handler: a.showHelp, Available: signedIn,
+	})
+	a.keys.Register(keymap.Action{
+		ID: "app.palette", Scope: keymap.GlobalScope, Description: "Command palette",
+		Keys:    []string{"Ctrl+P"},
+		Handler: a.showPalette, Available: signedIn,
+	})
+
+	for _, name := range a.menuPages {
+		a.keys.Register(keymap.Action{
+			ID: "go." + strings.ToLower(name), Scope: keymap.GlobalScope, Description: "Go to " + name,
+			Handler: func() { a.switchTo(name) }, Available: signedIn,
+		})
+	}
+
+	navigation := []struct {
+		id, description, vimKey string
+		target                  tcell.Key
+	}{
+		{"nav.down", "Move down", "j", tcell.KeyDown},
+		{"nav.up", "Move up", "k", tcell.KeyUp},
+		{"nav.left", "Move left", "h", tcell.KeyLeft},
+		{"nav.right", "Move right", "l", tcell.KeyRight},
+		{"nav.top", "Go to top", "g", tcell.KeyHome},
+		{"nav.bottom", "Go to bottom", "G", tcell.KeyEnd},
+	}
+	for _, n := range navigation {
+		var keys []string
+		if a.cfg.VimNavigation {
+			keys = []string{n.vimKey}
 		}
+		a.keys.Register(keymap.Action{
+			ID: n.id, Scope: keymap.GlobalScope, Description: n.description,
+			Keys: keys, Remap: n.target,
+		})
+	}
+}
+
+// captureGlobalKeys dispatches global actions before the focused page sees
+// the event
+// While a text field has focus, printable keys are left for typing
+func (a *App) captureGlobalKeys(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyRune && a.editingText() {
+		return event
+	}
+	// Tab moves between the fields of a form rather than to the menu
+	if (event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab) && a.inForm() {
 		return event
-	})
-	if err := a.app.Run(); err != nil {
-		return fmt.Errorf("tview run: %w", err)
 	}
+	return a.keys.Handle(keymap.GlobalScope, event)
+}
 
-	return nil
+// inForm reports whether the focused primitive is a form item or button
+func (a *App) inForm() bool {
+	switch a.app.GetFocus().(type) {
+	case tview.FormItem, *tview.Button:
+		return true
+	}
+	return false
+}
+
+// editingText reports whether the focused primitive accepts typed text
+func (a *App) editingText() bool {
+	switch a.app.GetFocus().(type) {
+	case *tview.InputField, *tview.TextArea:
+		return true
+	}
+	return false
 }
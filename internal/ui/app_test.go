@@ -0,0 +1,41 @@
+package ui_test
+
+import (
+	"testing"
+
+	"github.com/MawCeron/it-room/internal/ui/uitest"
+)
+
+func TestMenuNavigation(t *testing.T) {
+	h := uitest.New(t)
+	h.Start()
+	h.WaitFor("Dashboard", "Inventory at a glance")
+
+	h.Press("Tab")
+	for _, page := range []string{
+		"Assets - IT equipment inventory management",
+		"License management and assignments",
+		"Toner, drum, and other consumables tracking",
+		"Accessories tracked by quantity instead of serial number",
+	} {
+		h.Press("Down", "Enter")
+		h.WaitFor(page)
+	}
+
+	// The warranty report loads once the page has focus
+	h.Press("Down", "Enter", "Tab")
+	h.WaitFor("Upcoming expirations", "By vendor")
+
+	h.Press("Tab", "Home", "Enter")
+	h.WaitFor("Inventory at a glance")
+}
+
+func TestHelpOverlay(t *testing.T) {
+	h := uitest.New(t)
+	h.Start()
+
+	h.Press("?")
+	h.WaitFor("Keyboard Shortcuts", "app.quit", "Ctrl+Q")
+	h.Press("Esc")
+	h.WaitGone("Keyboard Shortcuts")
+}
@@ -0,0 +1,101 @@
+package assets
+
+import (
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/ui/keymap"
+)
+
+const (
+	// tableScope holds the actions of the assets table
+	tableScope = "Assets"
+	// detailScope holds the actions of the asset detail screen
+	detailScope = "Asset Details"
+)
+
+// registerActions registers the assets table and detail screen actions
+func (p *AssetsPage) registerActions() {
+	table := []keymap.Action{
+		{ID: "assets.open", Description: "View details", Keys: []string{"Enter"}, Handler: func() {
+			if asset := p.selectedAsset(); asset != nil {
+				p.showAssetDetail(asset.AssetID)
+			}
+		}},
+		{ID: "assets.new", Description: "New Asset", Keys: []string{"n", "N"}, Handler: p.showNewAssetForm,
+			Available: p.allows(auth.EditAssets)},
+		{ID: "assets.edit", Description: "Edit Asset", Keys: []string{"e", "E"}, Handler: func() {
+			if asset := p.selectedAsset(); asset != nil {
+				p.showEditAssetForm(&asset.Asset)
+			}
+		}, Available: p.allows(auth.EditAssets)},
+		{ID: "assets.assign", Description: "Assign asset to...", Keys: []string{"Ctrl+A"}, Handler: func() {
+			if asset := p.selectedAsset(); asset != nil {
+				p.showAssignForm(asset)
+			}
+		}, Available: p.allows(auth.AssignAssets)},
+		{ID: "assets.filter", Description: "Filters", Keys: []string{"f", "F"}, Handler: p.showFilterForm},
+		{ID: "assets.clear_filters", Description: "Clear filters", Keys: []string{"x", "X"}, Handler: p.clearFilters},
+		{ID: "assets.as_of", Description: "Inventory as of date", Keys: []string{"a", "A"}, Handler: p.showAsOfForm},
+		{ID: "assets.import", Description: "Import CSV", Keys: []string{"i", "I"}, Handler: p.showImportForm,
+			Available: p.allows(auth.ImportData)},
+		{ID: "assets.export", Description: "Export view", Keys: []string{"Ctrl+E"}, Handler: p.showExportForm},
+		{ID: "assets.columns", Description: "Columns", Keys: []string{"c", "C"}, Handler: p.showColumnsForm},
+		{ID: "assets.views", Description: "Views", Keys: []string{"v", "V"}, Handler: p.showViewsList},
+		{ID: "assets.sort_prev", Description: "Sort by previous column", Keys: []string{"<"}, Handler: func() {
+			p.shiftSortColumn(false)
+		}},
+		{ID: "assets.sort_next", Description: "Sort by next column", Keys: []string{">"}, Handler: func() {
+			p.shiftSortColumn(true)
+		}},
+		{ID: "assets.sort_reverse", Description: "Reverse sort", Keys: []string{"s", "S"}, Handler: func() {
+			p.state.SortDesc = !p.state.SortDesc
+			p.reloadTable()
+		}},
+	}
+	for _, a := range table {
+		a.Scope = tableScope
+		p.keys.Register(a)
+	}
+
+	// Actions changing the asset also depend on the user's role
+	editing := map[string]bool{"details.edit": true, "details.comment": true, "details.attach": true}
+	detail := []keymap.Action{
+		{ID: "details.back", Description: "Back", Keys: []string{"Esc", "q", "Q"}, Handler: p.closeAssetDetail},
+		{ID: "details.prev_tab", Description: "Previous tab", Keys: []string{"Left"}, Handler: func() {
+			p.detail.switchTab((p.detail.current - 1 + len(detailTabs)) % len(detailTabs))
+		}},
+		{ID: "details.next_tab", Description: "Next tab", Keys: []string{"Right"}, Handler: func() {
+			p.detail.switchTab((p.detail.current + 1) % len(detailTabs))
+		}},
+		{ID: "details.edit", Description: "Edit", Keys: []string{"e", "E"}, Handler: func() {
+			p.showEditAssetForm(&p.detail.asset.Asset)
+		}},
+		{ID: "details.comment", Description: "Add comment", Keys: []string{"m", "M"}, Handler: func() {
+			p.detail.showCommentForm()
+		}},
+		{ID: "details.attach", Description: "Attach file", Keys: []string{"t", "T"}, Handler: func() {
+			p.detail.showAttachmentForm()
+		}},
+	}
+	for i, tab := range detailTabs {
+		index := i
+		detail = append(detail, keymap.Action{
+			ID:          "details.tab_" + tab.ID,
+			Description: "Go to " + tab.Title,
+			Keys:        []string{tabKey(i)},
+			Handler:     func() { p.detail.switchTab(index) },
+		})
+	}
+	for _, a := range detail {
+		a.Scope = detailScope
+		a.Available = func() bool { return p.detail != nil }
+		if editing[a.ID] {
+			a.Available = func() bool { return p.detail != nil && p.can(auth.EditAssets) }
+		}
+		p.keys.Register(a)
+	}
+}
+
+// allows returns an availability check for actions requiring perm
+func (p *AssetsPage) allows(perm auth.Permission) func() bool {
+	return func() bool { return p.can(perm) }
+}
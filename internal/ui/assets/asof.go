@@ -0,0 +1,67 @@
+package assets
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showAsOfForm displays a date picker to show the inventory as it was at the
+// end of a past day
+// Up and Down change the day, PgUp and PgDn the month
+func (p *AssetsPage) showAsOfForm() {
+	initial := time.Now()
+	if p.asOf != nil {
+		initial = *p.asOf
+	}
+
+	input := tview.NewInputField().
+		SetLabel("Date (YYYY-MM-DD)").
+		SetText(initial.Format(DateLayout)).
+		SetAcceptanceFunc(p.dateAcceptanceFunc).
+		SetFieldWidth(12)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		day, err := time.Parse(DateLayout, input.GetText())
+		if err != nil {
+			return event
+		}
+		switch event.Key() {
+		case tcell.KeyUp:
+			day = day.AddDate(0, 0, 1)
+		case tcell.KeyDown:
+			day = day.AddDate(0, 0, -1)
+		case tcell.KeyPgUp:
+			day = day.AddDate(0, 1, 0)
+		case tcell.KeyPgDn:
+			day = day.AddDate(0, -1, 0)
+		default:
+			return event
+		}
+		input.SetText(day.Format(DateLayout))
+		return nil
+	})
+
+	form := tview.NewForm()
+	form.AddFormItem(input)
+	form.AddTextView("", "[gray]↑↓ day  PgUp/PgDn month[-]", 30, 1, true, false)
+	form.AddButton("Show", func() {
+		day, err := time.Parse(DateLayout, input.GetText())
+		if err != nil {
+			p.showError(err)
+			return
+		}
+		p.asOf = &day
+		p.closeDialog("assetAsOf")
+	})
+	form.AddButton("Today", func() {
+		p.asOf = nil
+		p.closeDialog("assetAsOf")
+	})
+	form.AddButton("Cancel", func() {
+		p.pages.RemovePage("assetAsOf")
+	})
+	form.SetBorder(true).SetTitle(" Inventory As Of ")
+
+	p.pages.AddPage("assetAsOf", p.createDialogLayout(form, 50, 9), true, true)
+}
@@ -0,0 +1,284 @@
+package assets_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/ui/uitest"
+)
+
+// Catalog IDs seeded by the schema
+const (
+	laptop   = 1
+	desktop  = 2
+	mainSite = 1
+)
+
+var ctx = context.Background()
+
+// seedAsset adds an available asset through the harness's service
+func seedAsset(t *testing.T, h *uitest.Harness, tag string, typeID int, model string) *models.Asset {
+	t.Helper()
+	a := &models.Asset{
+		AssetTag: tag, TypeID: typeID, SerialNumber: "SN-" + tag, Maker: "Dell", Model: model,
+		PurchaseDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), LocationID: mainSite,
+	}
+	if err := h.Service().CreateAsset(ctx, a); err != nil {
+		t.Fatalf("create %s: %v", tag, err)
+	}
+	return a
+}
+
+// openAssets starts the app and focuses the assets table
+func openAssets(h *uitest.Harness) {
+	h.Start()
+	h.Press("Tab", "Down", "Enter", "Tab")
+	h.WaitFor("Assets - IT equipment inventory management")
+}
+
+// above reports whether the row showing a is drawn above the one showing b
+func above(h *uitest.Harness, a, b string) bool {
+	screen := h.Screen()
+	i, j := strings.Index(screen, a), strings.Index(screen, b)
+	return i >= 0 && j >= 0 && i < j
+}
+
+func TestAssetsTable(t *testing.T) {
+	h := uitest.New(t)
+	laptopAsset := seedAsset(t, h, "EQ-001", laptop, "Latitude 7440")
+	seedAsset(t, h, "EQ-002", desktop, "OptiPlex 7010")
+	employee := &models.Employee{FullName: "Ana Ruiz", Email: "ana@example.com"}
+	if err := h.Service().CreateEmployee(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	assignment := &models.AssetAssignment{AssetID: laptopAsset.AssetID, AssignmentDate: time.Now().UTC()}
+	if err := h.Service().AssignAsset(ctx, assignment, "ana@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	openAssets(h)
+	h.WaitFor("EQ-001", "EQ-002", "Asset Tag ▲")
+	if row := h.Row("EQ-001"); !strings.Contains(row, "Laptop") || !strings.Contains(row, "Assigned") {
+		t.Errorf("EQ-001 row = %q, want a laptop shown as assigned", row)
+	}
+	if row := h.Row("EQ-002"); !strings.Contains(row, "Desktop") || !strings.Contains(row, "Available") {
+		t.Errorf("EQ-002 row = %q, want a desktop shown as available", row)
+	}
+	if !above(h, "EQ-001", "EQ-002") {
+		t.Errorf("rows not sorted by tag:\n%s", h.Screen())
+	}
+
+	// Sorting by type puts the desktop first
+	h.Press(">")
+	h.WaitFor("Type ▲")
+	if !above(h, "EQ-002", "EQ-001") {
+		t.Errorf("rows not sorted by type:\n%s", h.Screen())
+	}
+	h.Press("s")
+	h.WaitFor("Type ▼")
+	if !above(h, "EQ-001", "EQ-002") {
+		t.Errorf("rows not sorted by type descending:\n%s", h.Screen())
+	}
+}
+
+func TestAssetsTablePages(t *testing.T) {
+	h := uitest.New(t)
+	h.Seed(600, 40)
+	all, err := h.Service().Stores().Assets.ListSummaries(repo.AssetQuery{SortColumn: "asset_tag"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	openAssets(h)
+	h.WaitFor(all[0].AssetTag, all[1].AssetTag)
+	// Reaching the end loads the later pages
+	h.Press("End")
+	h.WaitFor(all[len(all)-1].AssetTag)
+}
+
+func TestAssetFormCreates(t *testing.T) {
+	h := uitest.New(t)
+	openAssets(h)
+
+	h.Press("n")
+	h.WaitFor("Purchase Date", "Computer Equipment", "EQ-")
+	h.Press("Tab", "Tab") // Category, then type, to the asset tag
+	h.Type("101")
+	h.Press("Tab")
+	h.Type("Lenovo")
+	h.Press("Tab")
+	h.Type("ThinkPad X1")
+	h.Press("Tab")
+	h.Type("PF-101")
+	h.Press("Tab", "Tab", "Tab", "Tab") // Past dates and location to the purchase cost
+	h.Type("1299.50")
+	h.Press("Tab", "Tab") // Past the configured currency to the supplier
+	h.Type("CDW")
+	h.Press("Tab", "Tab", "Tab", "Tab", "Enter") // Past PO, invoice and notes to Save
+
+	h.WaitGone("Purchase Date")
+	h.WaitFor("EQ-101", "ThinkPad X1")
+	a, err := h.Service().AssetByTag(ctx, "EQ-101")
+	if err != nil {
+		t.Fatalf("saved asset: %v", err)
+	}
+	if a.Maker != "Lenovo" || a.SerialNumber != "PF-101" || a.StatusID != models.StatusAvailable || a.LocationName != "Main" {
+		t.Errorf("saved asset = %+v", a)
+	}
+	if a.PurchaseCost == nil || *a.PurchaseCost != 1299.5 || a.Currency == nil || *a.Currency != "USD" ||
+		a.Supplier == nil || *a.Supplier != "CDW" {
+		t.Errorf("saved purchase = %v %v from %v", a.PurchaseCost, a.Currency, a.Supplier)
+	}
+}
+
+func TestAssetFormValidates(t *testing.T) {
+	h := uitest.New(t)
+	openAssets(h)
+
+	h.Press("n")
+	h.WaitFor("Purchase Date")
+	for range 15 {
+		h.Press("Tab")
+	}
+	h.Press("Enter")
+	h.WaitFor("Error: asset tag, make, model and serial number")
+
+	// Dismissing the error keeps the form open
+	h.Press("Enter")
+	h.WaitGone("Error:")
+	h.WaitFor("Purchase Date")
+	if _, err := h.Service().AssetByTag(ctx, "EQ-"); err == nil {
+		t.Error("an incomplete asset was saved")
+	}
+}
+
+func TestAssetFormEdits(t *testing.T) {
+	h := uitest.New(t)
+	seedAsset(t, h, "EQ-001", laptop, "Latitude 7440")
+	openAssets(h)
+	h.WaitFor("EQ-001")
+
+	h.Press("e")
+	h.WaitFor("Purchase Date")
+	h.Press("Tab", "Tab", "Tab", "Tab", "Ctrl+U") // To the model, clearing it
+	h.Type("Latitude 9450")
+	for range 11 {
+		h.Press("Tab")
+	}
+	h.Press("Enter")
+
+	h.WaitGone("Purchase Date")
+	h.WaitFor("Latitude 9450")
+	a, err := h.Service().AssetByTag(ctx, "EQ-001")
+	if err != nil || a.Model != "Latitude 9450" {
+		t.Errorf("edited asset = %+v, %v", a, err)
+	}
+}
+
+func TestAssetFormCustomFields(t *testing.T) {
+	h := uitest.New(t)
+	typeID := laptop
+	ram := &models.CustomField{Name: "RAM (GB)", Kind: models.FieldNumber, TypeID: &typeID, Required: true}
+	if err := h.Service().CreateCustomField(ctx, ram); err != nil {
+		t.Fatal(err)
+	}
+	openAssets(h)
+
+	h.Press("n")
+	h.WaitFor("Purchase Date", "RAM (GB) *")
+	h.Press("Tab", "Tab")
+	h.Type("101")
+	h.Press("Tab")
+	h.Type("Lenovo")
+	h.Press("Tab")
+	h.Type("ThinkPad X1")
+	h.Press("Tab")
+	h.Type("PF-101")
+	for range 10 { // Past dates, location, purchase and notes to the custom field
+		h.Press("Tab")
+	}
+	h.Type("16.0")
+	h.Press("Tab", "Enter")
+
+	h.WaitGone("Purchase Date")
+	h.WaitFor("EQ-101")
+	h.Press("Enter")
+	h.WaitFor("Asset Details", "RAM (GB)        16 ") // Stored without the trailing zero
+}
+
+func TestAssetAssign(t *testing.T) {
+	h := uitest.New(t)
+	seedAsset(t, h, "EQ-001", laptop, "Latitude 7440")
+	for _, e := range []*models.Employee{
+		{FullName: "Ana Ruiz", Email: "ana@example.com"},
+		{FullName: "Luis Ortega", Email: "luis@example.com"},
+	} {
+		if err := h.Service().CreateEmployee(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	openAssets(h)
+	h.WaitFor("EQ-001")
+	h.Press("Ctrl+P")
+	h.Type("assign asset")
+	h.WaitFor("Assign asset to...")
+	h.Press("Enter")
+	h.WaitFor("Assign EQ-001 to", "Ana Ruiz", "Luis Ortega")
+	h.Type("ort")
+	h.WaitGone("Ana Ruiz")
+	h.Press("Enter")
+
+	h.WaitGone("Assign EQ-001 to")
+	a, err := h.Service().AssetByTag(ctx, "EQ-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.StatusID != models.StatusAssigned || a.HolderName == nil || *a.HolderName != "Luis Ortega" {
+		t.Errorf("asset after assigning = status %d, held by %v", a.StatusID, a.HolderName)
+	}
+}
+
+func TestAssetDetailShowsKit(t *testing.T) {
+	h := uitest.New(t)
+	laptopAsset := seedAsset(t, h, "EQ-001", laptop, "Latitude 7440")
+	desktopAsset := seedAsset(t, h, "EQ-002", desktop, "OptiPlex 7010")
+	kit := &models.Kit{KitTag: "KIT-001", AssetIDs: []string{laptopAsset.AssetID, desktopAsset.AssetID}}
+	if err := h.Service().CreateKit(ctx, kit); err != nil {
+		t.Fatal(err)
+	}
+
+	openAssets(h)
+	h.WaitFor("EQ-001")
+	h.Press("Enter")
+	h.WaitFor("Asset Details", "KIT-001")
+	h.Press("Right", "Right")
+	h.WaitFor("OptiPlex 7010")
+	if row := h.Row("EQ-002"); !strings.Contains(row, "KIT-001") || !strings.Contains(row, "Available") {
+		t.Errorf("kit row = %q, want the desktop listed as available in KIT-001", row)
+	}
+}
+
+func TestAssetDetailShowsComponents(t *testing.T) {
+	h := uitest.New(t)
+	desktopAsset := seedAsset(t, h, "EQ-001", desktop, "OptiPlex 7010")
+	monitor := seedAsset(t, h, "EQ-002", laptop, "Latitude 7440")
+	rel := &models.AssetRelation{ChildID: monitor.AssetID, ParentID: desktopAsset.AssetID, Relation: models.RelationAttachedTo}
+	if err := h.Service().LinkAssets(ctx, rel); err != nil {
+		t.Fatal(err)
+	}
+
+	openAssets(h)
+	h.WaitFor("EQ-002")
+	h.Press("Down", "Enter")
+	h.WaitFor("Asset Details", "Latitude 7440")
+	h.Press("Right", "Right", "Right")
+	h.WaitFor("attached-to  EQ-002")
+	if !above(h, "EQ-001  Desktop", "attached-to  EQ-002") {
+		t.Errorf("desktop not shown above its monitor:\n%s", h.Screen())
+	}
+}
@@ -0,0 +1,73 @@
+package assets
+
+import (
+	"context"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// assignSearchLimit caps the employees listed while searching for whom to
+// assign an asset to
+const assignSearchLimit = 8
+
+// showAssignForm asks for the employee to assign the asset to, searching
+// them by name or email as they are typed
+// Enter assigns the highlighted employee, Esc closes the dialog
+func (p *AssetsPage) showAssignForm(asset *models.AssetSummary) {
+	input := tview.NewInputField().
+		SetLabel("Employee: ").
+		SetFieldWidth(0)
+	list := tview.NewList().
+		ShowSecondaryText(true).
+		SetHighlightFullLine(true)
+
+	var employees []*models.Employee
+	update := func(text string) {
+		employees, _ = p.stores.Employees.Search(text, assignSearchLimit)
+		list.Clear()
+		for _, e := range employees {
+			list.AddItem(e.FullName, e.Email, 0, nil)
+		}
+	}
+	assign := func(index int) {
+		if index < 0 || index >= len(employees) {
+			return
+		}
+		a := &models.AssetAssignment{AssetID: asset.AssetID, AssignmentDate: time.Now().UTC(), AssignedBy: auth.Actor(p.user)}
+		if err := p.svc.AssignAsset(context.Background(), a, employees[index].Email); err != nil {
+			p.showError(err)
+			return
+		}
+		p.pages.RemovePage("assetAssign")
+		p.assetSaved(asset.AssetID)
+	}
+
+	input.SetChangedFunc(update)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			p.pages.RemovePage("assetAssign")
+			return nil
+		case tcell.KeyEnter:
+			assign(list.GetCurrentItem())
+			return nil
+		case tcell.KeyDown, tcell.KeyUp, tcell.KeyPgDn, tcell.KeyPgUp:
+			list.InputHandler()(event, nil)
+			return nil
+		}
+		return event
+	})
+	update("")
+
+	box := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+	box.SetBorder(true).SetTitle(" Assign " + asset.AssetTag + " to ")
+
+	p.pages.AddPage("assetAssign", p.createDialogLayout(box, 60, assignSearchLimit*2+3), true, true)
+}
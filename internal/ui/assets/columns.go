@@ -0,0 +1,89 @@
+package assets
+
+import (
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/rivo/tview"
+)
+
+// assetColumn describes a column the assets table can display
+// Key matches the sort keys understood by repo.AssetQuery
+type assetColumn struct {
+	Key   string
+	Title string
+	Cell  func(p *AssetsPage, a *models.AssetSummary) *tview.TableCell
+}
+
+// assetColumns lists every available column in their display order
+var assetColumns = []assetColumn{
+	{Key: "asset_tag", Title: "Asset Tag", Cell: func(_ *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		return tview.NewTableCell(a.AssetTag)
+	}},
+	{Key: "category", Title: "Category", Cell: func(_ *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		return tview.NewTableCell(a.CategoryName)
+	}},
+	{Key: "type", Title: "Type", Cell: func(_ *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		return tview.NewTableCell(a.TypeName)
+	}},
+	{Key: "make", Title: "Make", Cell: func(_ *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		return tview.NewTableCell(a.Maker)
+	}},
+	{Key: "model", Title: "Model", Cell: func(_ *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		return tview.NewTableCell(fmt.Sprintf("%s %s", a.Maker, a.Model))
+	}},
+	{Key: "serial_number", Title: "Serial Number", Cell: func(_ *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		return tview.NewTableCell(a.SerialNumber)
+	}},
+	{Key: "status", Title: "Status", Cell: func(p *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		return p.statusCell(a.StatusID)
+	}},
+	{Key: "purchase_date", Title: "Purchase Date", Cell: func(_ *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		return tview.NewTableCell(formatDate(&a.PurchaseDate))
+	}},
+	{Key: "warranty_end_date", Title: "Warranty End", Cell: func(p *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		return tview.NewTableCell(formatDate(a.WarrantyEndDate)).
+			SetTextColor(WarrantyColor(p.warrantyState(&a.Asset)))
+	}},
+	{Key: "warranty", Title: "Warranty", Cell: func(p *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		state := p.warrantyState(&a.Asset)
+		return tview.NewTableCell(state.String()).SetTextColor(WarrantyColor(state))
+	}},
+	{Key: "location", Title: "Location", Cell: func(_ *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		return tview.NewTableCell(a.LocationName)
+	}},
+	{Key: "holder", Title: "Holder", Cell: func(_ *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		if a.HolderName == nil {
+			return tview.NewTableCell("")
+		}
+		return tview.NewTableCell(*a.HolderName)
+	}},
+	{Key: "supplier", Title: "Supplier", Cell: func(_ *AssetsPage, a *models.AssetSummary) *tview.TableCell {
+		return tview.NewTableCell(valueOrEmpty(a.Supplier))
+	}},
+}
+
+// defaultColumns are the columns shown until the user picks others
+var defaultColumns = []string{"asset_tag", "type", "model", "serial_number", "status"}
+
+// findColumn returns the column with the given key
+func findColumn(key string) (assetColumn, bool) {
+	for _, c := range assetColumns {
+		if c.Key == key {
+			return c, true
+		}
+	}
+	return assetColumn{}, false
+}
+
+// visibleColumns returns the columns currently shown in the table
+// Unknown keys, e.g. from a view saved by a newer version, are skipped
+func (p *AssetsPage) visibleColumns() []assetColumn {
+	var out []assetColumn
+	for _, key := range p.state.Columns {
+		if c, ok := findColumn(key); ok {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+package assets
+
+import (
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// pageSize is the number of assets fetched per round trip to the database
+const pageSize = 200
+
+// assetContent is a tview.TableContent that fetches asset rows lazily
+// Rows are requested in pages as the table draws them and loaded in a
+// background goroutine; results are applied on the UI goroutine through
+// QueueUpdateDraw, so every field is only touched from the UI goroutine
+type assetContent struct {
+	tview.TableContentReadOnly
+
+	page   *AssetsPage
+	query  repo.AssetQuery
+	total  int                    // Matching assets, known once the first page loads
+	rows   []*models.AssetSummary // Rows loaded so far, in display order
+	cursor *repo.AssetCursor      // Position after the last loaded row
+	done   bool                   // All matching rows are loaded
+
+	loading    bool
+	generation int // Incremented on reset to discard loads for an older query
+	err        error
+}
+
+// newAssetContent creates an empty content for the given page
+func newAssetContent(p *AssetsPage) *assetContent {
+	return &assetContent{page: p}
+}
+
+// reset discards the loaded rows and starts loading the first page for q
+func (c *assetContent) reset(q repo.AssetQuery) {
+	c.generation++
+	c.query = q
+	c.total = 0
+	c.rows = nil
+	c.cursor = nil
+	c.done = false
+	c.loading = false
+	c.err = nil
+	c.loadNext(true)
+}
+
+// rowAt returns the asset on the given table row, or nil for the header and
+// rows that are not loaded yet
+func (c *assetContent) rowAt(row int) *models.AssetSummary {
+	if row <= 0 || row > len(c.rows) {
+		return nil
+	}
+	return c.rows[row-1]
+}
+
+// GetCell returns the header cell, the cell of a loaded row, or a placeholder
+// while the row's page is still loading
+func (c *assetContent) GetCell(row, column int) *tview.TableCell {
+	columns := c.page.visibleColumns()
+	if column < 0 || column >= len(columns) {
+		return nil
+	}
+	if row == 0 {
+		return c.page.headerCell(columns[column])
+	}
+	if asset := c.rowAt(row); asset != nil {
+		return columns[column].Cell(c.page, asset)
+	}
+
+	if c.err != nil {
+		return tview.NewTableCell("error").SetTextColor(tcell.ColorRed)
+	}
+	c.loadNext(false)
+	return tview.NewTableCell("…").SetTextColor(tcell.ColorGray)
+}
+
+// GetRowCount returns the header plus every matching asset
+func (c *assetContent) GetRowCount() int {
+	return c.total + 1
+}
+
+// GetColumnCount returns the number of visible columns
+func (c *assetContent) GetColumnCount() int {
+	return len(c.page.visibleColumns())
+}
+
+// loadNext fetches the page following the loaded rows in the background
+// The first page also counts the matching assets
+func (c *assetContent) loadNext(first bool) {
+	if c.loading || c.done {
+		return
+	}
+	c.loading = true
+
+	generation, query, cursor := c.generation, c.query, c.cursor
+	assetRepo := c.page.stores.Assets
+	go func() {
+		total := -1
+		var err error
+		if first {
+			total, err = assetRepo.CountSummaries(query)
+		}
+		var rows []*models.AssetSummary
+		next := cursor
+		if err == nil {
+			rows, next, err = assetRepo.PageSummaries(query, cursor, pageSize)
+		}
+
+		c.page.app.QueueUpdateDraw(func() {
+			if generation != c.generation {
+				return
+			}
+			c.loading = false
+			if err != nil {
+				c.err = err
+				return
+			}
+			if total >= 0 {
+				// While empty the table tracks its end; start again from the top
+				c.total = total
+				c.page.table.ScrollToBeginning()
+			}
+			c.rows = append(c.rows, rows...)
+			c.cursor = next
+			if len(rows) < pageSize {
+				c.done = true
+				c.total = len(c.rows)
+			}
+		})
+	}()
+}
@@ -0,0 +1,468 @@
+package assets
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// detailTab describes a tab of the asset detail screen
+type detailTab struct {
+	ID    string
+	Title string
+	Build func(d *assetDetail) tview.Primitive
+}
+
+// detailTabs lists the tabs of the asset detail screen in display order
+var detailTabs = []detailTab{
+	{ID: "overview", Title: "Overview", Build: (*assetDetail).overviewTab},
+	{ID: "assignments", Title: "Assignments", Build: (*assetDetail).assignmentsTab},
+	{ID: "kit", Title: "Kit", Build: (*assetDetail).kitTab},
+	{ID: "components", Title: "Components", Build: (*assetDetail).componentsTab},
+	{ID: "transfers", Title: "Transfers", Build: (*assetDetail).transfersTab},
+	{ID: "maintenance", Title: "Maintenance", Build: (*assetDetail).maintenanceTab},
+	{ID: "licenses", Title: "Licenses", Build: (*assetDetail).licensesTab},
+	{ID: "consumables", Title: "Consumables", Build: (*assetDetail).consumablesTab},
+	{ID: "notes", Title: "Notes", Build: (*assetDetail).notesTab},
+	{ID: "audit", Title: "Audit", Build: (*assetDetail).auditTab},
+}
+
+// tabKey returns the digit key of the tab at index, 1 to 9 and then 0 for
+// the tenth
+func tabKey(index int) string {
+	return strconv.Itoa((index + 1) % 10)
+}
+
+// assetDetail is the full-screen detail view of a single asset
+// Each tab is rebuilt from the database on refresh
+type assetDetail struct {
+	page    *AssetsPage
+	assetID string
+	asset   *models.AssetSummary
+	kit     *models.Kit // Kit the asset belongs to, if any
+	fields  []*models.AssetFieldValue
+	view    *tview.Flex
+	header  *tview.TextView
+	tabBar  *tview.TextView
+	tabs    *tview.Pages
+	current int
+}
+
+// showAssetDetail opens the detail screen for an asset on top of the table
+func (p *AssetsPage) showAssetDetail(assetID string) {
+	d := &assetDetail{page: p, assetID: assetID}
+	d.build()
+	if err := d.refresh(); err != nil {
+		p.showError(err)
+		return
+	}
+
+	p.detail = d
+	p.pages.AddPage("assetDetail", d.view, true, true)
+}
+
+// closeAssetDetail closes the detail screen and returns to the table
+func (p *AssetsPage) closeAssetDetail() {
+	p.detail = nil
+	p.pages.RemovePage("assetDetail")
+}
+
+// assetSaved refreshes the views showing an asset after it was changed
+func (p *AssetsPage) assetSaved(assetID string) {
+	p.reloadTable()
+	if p.detail != nil && p.detail.assetID == assetID {
+		if err := p.detail.refresh(); err != nil {
+			p.showError(err)
+		}
+	}
+}
+
+// build constructs the detail layout: asset header, tab bar, tab content
+// and a status bar listing the available actions
+func (d *assetDetail) build() {
+	d.header = tview.NewTextView().SetDynamicColors(true)
+
+	d.tabBar = tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(true).
+		SetWrap(false)
+	d.tabBar.SetHighlightedFunc(func(added, removed, remaining []string) {
+		if len(added) == 0 {
+			return
+		}
+		for i, tab := range detailTabs {
+			if tab.ID == added[0] {
+				d.switchTab(i)
+			}
+		}
+	})
+
+	var titles []string
+	for i, tab := range detailTabs {
+		titles = append(titles, fmt.Sprintf(`["%s"] %s %s [""]`, tab.ID, tabKey(i), tab.Title))
+	}
+	d.tabBar.SetText(strings.Join(titles, "│"))
+
+	d.tabs = tview.NewPages()
+
+	statusBar := tview.NewTextView().
+		SetText(" " + d.page.keys.Hints(
+			"details.prev_tab", "details.next_tab", "details.edit",
+			"details.comment", "details.attach", "details.back")).
+		SetDynamicColors(true)
+
+	content := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(d.header, 2, 0, false).
+		AddItem(d.tabBar, 1, 0, false).
+		AddItem(d.tabs, 0, 1, true)
+	content.SetBorder(true).SetTitle(" [::b]Asset Details[::-] ")
+
+	d.view = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(content, 0, 1, true).
+		AddItem(statusBar, 1, 0, false)
+
+	d.view.SetInputCapture(d.page.keys.Capture(detailScope))
+}
+
+// refresh reloads the asset and rebuilds every tab, keeping the current one
+func (d *assetDetail) refresh() error {
+	asset, err := d.page.stores.Assets.GetSummary(d.assetID)
+	if err != nil {
+		return err
+	}
+	d.asset = asset
+	d.kit, err = d.page.stores.Kits.FindByAsset(d.assetID)
+	if errors.Is(err, sql.ErrNoRows) {
+		d.kit, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+	if d.fields, err = d.page.stores.Fields.ListValues(d.assetID); err != nil {
+		return err
+	}
+
+	d.header.SetText(fmt.Sprintf("[::b]%s[::-]  %s %s  (%s)\n%s · %s",
+		asset.AssetTag, asset.Maker, asset.Model, asset.SerialNumber,
+		asset.CategoryName, asset.TypeName))
+
+	for _, tab := range detailTabs {
+		d.tabs.RemovePage(tab.ID)
+		d.tabs.AddPage(tab.ID, tab.Build(d), true, false)
+	}
+	d.switchTab(d.current)
+	return nil
+}
+
+// switchTab shows the tab at the given index and highlights its title
+// Highlighting calls back into switchTab only when the highlight changes
+func (d *assetDetail) switchTab(index int) {
+	d.current = index
+	d.tabs.SwitchToPage(detailTabs[index].ID)
+	d.tabBar.Highlight(detailTabs[index].ID)
+}
+
+// overviewTab lists every asset field with catalog IDs resolved to names,
+// custom fields after the fixed ones
+func (d *assetDetail) overviewTab() tview.Primitive {
+	a := d.asset
+	holder := "-"
+	if a.HolderName != nil {
+		holder = *a.HolderName
+	}
+	kit := "-"
+	if d.kit != nil {
+		kit = d.kit.KitTag
+	}
+
+	fields := [][2]string{
+		{"Asset Tag", a.AssetTag},
+		{"Category", a.CategoryName},
+		{"Type", a.TypeName},
+		{"Make", a.Maker},
+		{"Model", a.Model},
+		{"Serial Number", a.SerialNumber},
+		{"Status", a.StatusName},
+		{"Location", a.LocationName},
+		{"Holder", holder},
+		{"Kit", kit},
+		{"Purchase Date", formatDate(&a.PurchaseDate)},
+		{"Warranty End", formatDate(a.WarrantyEndDate)},
+		{"Warranty", d.page.warrantyState(&a.Asset).String()},
+		{"Purchase Cost", formatCost(a.PurchaseCost, a.Currency)},
+		{"Book Value", d.bookValue()},
+		{"Supplier", valueOrEmpty(a.Supplier)},
+		{"PO Number", valueOrEmpty(a.PONumber)},
+		{"Invoice Number", valueOrEmpty(a.InvoiceNumber)},
+	}
+	for _, v := range d.fields {
+		fields = append(fields, [2]string{v.FieldName, v.Value})
+	}
+	fields = append(fields, [][2]string{
+		{"Notes", valueOrEmpty(a.Notes)},
+		{"Created By", valueOrEmpty(a.CreatedBy)},
+		{"Updated By", valueOrEmpty(a.UpdatedBy)},
+	}...)
+
+	var b strings.Builder
+	for _, f := range fields {
+		fmt.Fprintf(&b, "[yellow]%-15s[white] %s\n", f[0], tview.Escape(f[1]))
+	}
+
+	return tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(b.String())
+}
+
+// assignmentsTab lists who held the asset and when
+func (d *assetDetail) assignmentsTab() tview.Primitive {
+	assignments, err := d.page.stores.Assignments.ListByAsset(d.assetID)
+	rows := make([][]string, len(assignments))
+	for i, a := range assignments {
+		rows[i] = []string{a.EmployeeName, formatDate(&a.AssignmentDate), formatDate(a.DueDate), formatDate(a.ReturnDate), valueOrEmpty(a.Notes)}
+	}
+	return historyTable([]string{"Employee", "Assigned", "Due", "Returned", "Notes"}, rows, err)
+}
+
+// kitTab lists the assets of the kit the asset belongs to
+func (d *assetDetail) kitTab() tview.Primitive {
+	var rows [][]string
+	var err error
+	if d.kit != nil {
+		for _, assetID := range d.kit.AssetIDs {
+			var a *models.AssetSummary
+			if a, err = d.page.stores.Assets.GetSummary(assetID); err != nil {
+				break
+			}
+			rows = append(rows, []string{d.kit.KitTag, a.AssetTag, a.TypeName, a.Model, a.StatusName, valueOrEmpty(a.HolderName)})
+		}
+	}
+	return historyTable([]string{"Kit", "Asset Tag", "Type", "Model", "Status", "Holder"}, rows, err)
+}
+
+// componentsTab shows the tree of linked assets the asset belongs to, from
+// its topmost parent down, with the asset itself highlighted
+func (d *assetDetail) componentsTab() tview.Primitive {
+	tree, err := d.page.svc.AssetTree(context.Background(), d.assetID)
+	if err != nil {
+		return historyTable([]string{"Components"}, nil, err)
+	}
+
+	var build func(n *models.AssetNode) *tview.TreeNode
+	build = func(n *models.AssetNode) *tview.TreeNode {
+		text := fmt.Sprintf("%s  %s %s %s · %s", n.Asset.AssetTag, n.Asset.TypeName, n.Asset.Maker, n.Asset.Model, n.Asset.StatusName)
+		if n.Relation != "" {
+			text = n.Relation + "  " + text
+		}
+		node := tview.NewTreeNode(text).SetSelectable(true)
+		if n.Asset.AssetID == d.assetID {
+			node.SetColor(tcell.ColorYellow)
+		}
+		for _, c := range n.Children {
+			node.AddChild(build(c))
+		}
+		return node
+	}
+	root := build(tree)
+	view := tview.NewTreeView().SetRoot(root).SetCurrentNode(root)
+	if len(tree.Children) == 0 {
+		root.AddChild(tview.NewTreeNode("No linked assets").SetColor(tcell.ColorGray).SetSelectable(false))
+	}
+	return view
+}
+
+// transfersTab lists the location changes of the asset
+func (d *assetDetail) transfersTab() tview.Primitive {
+	transfers, err := d.page.stores.Transfers.ListByAsset(d.assetID)
+	rows := make([][]string, len(transfers))
+	for i, t := range transfers {
+		rows[i] = []string{formatDate(&t.TransferDate), t.FromLocationName, t.ToLocationName, valueOrEmpty(t.TransferredBy), valueOrEmpty(t.Notes)}
+	}
+	return historyTable([]string{"Date", "From", "To", "By", "Notes"}, rows, err)
+}
+
+// bookValue formats what the asset is worth today after the depreciation of
+// its category, empty without a purchase cost
+func (d *assetDetail) bookValue() string {
+	a := d.asset
+	if a.PurchaseCost == nil {
+		return ""
+	}
+	value := *a.PurchaseCost
+	if rule, err := d.page.stores.Depreciation.Get(a.CategoryID); err == nil {
+		value = rule.BookValueOn(value, a.PurchaseDate, time.Now().UTC())
+	}
+	return formatCost(&value, a.Currency)
+}
+
+// maintenanceTab lists the maintenance performed on the asset
+func (d *assetDetail) maintenanceTab() tview.Primitive {
+	logs, err := d.page.stores.Maintenance.ListByAsset(d.assetID)
+	rows := make([][]string, len(logs))
+	for i, m := range logs {
+		cost := ""
+		if m.Cost != nil {
+			cost = strconv.FormatFloat(*m.Cost, 'f', 2, 64)
+		}
+		rows[i] = []string{formatDate(&m.MaintenanceDate), m.TypeName, m.Description, cost, valueOrEmpty(m.PerformedBy)}
+	}
+	return historyTable([]string{"Date", "Type", "Description", "Cost", "Performed By"}, rows, err)
+}
+
+// licensesTab lists the software licenses installed on the asset
+func (d *assetDetail) licensesTab() tview.Primitive {
+	licenses, err := d.page.stores.Licenses.ListByAsset(d.assetID)
+	rows := make([][]string, len(licenses))
+	for i, l := range licenses {
+		rows[i] = []string{l.SoftwareName, l.LicenseType, formatDate(&l.AssignmentDate), formatDate(l.RemovalDate)}
+	}
+	return historyTable([]string{"Software", "License Type", "Installed", "Removed"}, rows, err)
+}
+
+// consumablesTab lists the consumables used by the asset
+func (d *assetDetail) consumablesTab() tview.Primitive {
+	usage, err := d.page.stores.Consumables.ListUsageByAsset(d.assetID)
+	rows := make([][]string, len(usage))
+	for i, u := range usage {
+		rows[i] = []string{u.ConsumableName, valueOrEmpty(u.PartNumber), formatDate(&u.InstallationDate), valueOrEmpty(u.Notes)}
+	}
+	return historyTable([]string{"Consumable", "Part Number", "Installed", "Notes"}, rows, err)
+}
+
+// notesTab lists the comments and attached files of the asset
+func (d *assetDetail) notesTab() tview.Primitive {
+	noteRepo := d.page.stores.Notes
+
+	comments, err := noteRepo.ListComments(d.assetID)
+	commentRows := make([][]string, len(comments))
+	for i, c := range comments {
+		commentRows[i] = []string{formatDate(&c.CreatedAt), valueOrEmpty(c.Author), c.Body}
+	}
+	commentsTable := historyTable([]string{"Date", "Author", "Comment"}, commentRows, err)
+
+	attachments, err := noteRepo.ListAttachments(d.assetID)
+	attachmentRows := make([][]string, len(attachments))
+	for i, a := range attachments {
+		attachmentRows[i] = []string{formatDate(&a.AddedAt), a.FileName, a.FilePath, valueOrEmpty(a.Notes)}
+	}
+	attachmentsTable := historyTable([]string{"Date", "File", "Path", "Notes"}, attachmentRows, err)
+
+	commentsBox := tview.NewFlex().AddItem(commentsTable, 0, 1, true)
+	commentsBox.SetBorder(true).SetTitle(" Comments ")
+	attachmentsBox := tview.NewFlex().AddItem(attachmentsTable, 0, 1, false)
+	attachmentsBox.SetBorder(true).SetTitle(" Attachments ")
+
+	return tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(commentsBox, 0, 2, true).
+		AddItem(attachmentsBox, 0, 1, false)
+}
+
+// showCommentForm displays a form to add a comment to the asset
+func (d *assetDetail) showCommentForm() {
+	form := tview.NewForm()
+	form.AddTextArea("Comment", "", 50, 5, 0, nil)
+	form.AddButton("Save", func() {
+		body := strings.TrimSpace(form.GetFormItemByLabel("Comment").(*tview.TextArea).GetText())
+		if body == "" {
+			return
+		}
+		comment := &models.AssetComment{AssetID: d.assetID, Body: body, Author: auth.Actor(d.page.user)}
+		if err := d.page.svc.AddComment(context.Background(), comment); err != nil {
+			d.page.showError(err)
+			return
+		}
+		d.page.pages.RemovePage("assetComment")
+		d.page.assetSaved(d.assetID)
+	})
+	form.AddButton("Cancel", func() {
+		d.page.pages.RemovePage("assetComment")
+	})
+	form.SetBorder(true).SetTitle(" Add Comment ")
+
+	d.page.pages.AddPage("assetComment", d.page.createDialogLayout(form, 70, 11), true, true)
+}
+
+// showAttachmentForm displays a form to attach a file to the asset by path
+func (d *assetDetail) showAttachmentForm() {
+	form := tview.NewForm()
+	form.AddInputField("File Path", "", 50, nil, nil)
+	form.AddInputField("Notes", "", 50, nil, nil)
+	form.AddButton("Save", func() {
+		path := strings.TrimSpace(form.GetFormItemByLabel("File Path").(*tview.InputField).GetText())
+		if path == "" {
+			return
+		}
+		attachment := &models.AssetAttachment{AssetID: d.assetID, FilePath: path, AddedBy: auth.Actor(d.page.user)}
+		if notes := strings.TrimSpace(form.GetFormItemByLabel("Notes").(*tview.InputField).GetText()); notes != "" {
+			attachment.Notes = &notes
+		}
+		if err := d.page.svc.AddAttachment(context.Background(), attachment); err != nil {
+			d.page.showError(err)
+			return
+		}
+		d.page.pages.RemovePage("assetAttachment")
+		d.page.assetSaved(d.assetID)
+	})
+	form.AddButton("Cancel", func() {
+		d.page.pages.RemovePage("assetAttachment")
+	})
+	form.SetBorder(true).SetTitle(" Attach File ")
+
+	d.page.pages.AddPage("assetAttachment", d.page.createDialogLayout(form, 70, 9), true, true)
+}
+
+// auditTab lists every recorded change to the asset and its related records
+func (d *assetDetail) auditTab() tview.Primitive {
+	entries, err := d.page.stores.Audit.List(repo.AuditQuery{AssetID: d.assetID})
+	rows := make([][]string, len(entries))
+	for i, e := range entries {
+		var changes []string
+		for _, c := range e.Changes() {
+			changes = append(changes, c.String())
+		}
+		rows[i] = []string{e.ChangedAt.Format(repo.TimestampLayout), valueOrEmpty(e.Actor), e.Entity, e.Operation, strings.Join(changes, ", ")}
+	}
+	return historyTable([]string{"Changed", "By", "Record", "Operation", "Changes"}, rows, err)
+}
+
+// historyTable builds a read-only table with yellow headers
+// A load error or an empty result is shown as a single message row
+func historyTable(headers []string, rows [][]string, err error) *tview.Table {
+	t := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+
+	for col, h := range headers {
+		t.SetCell(0, col, tview.NewTableCell(h).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+
+	switch {
+	case err != nil:
+		t.SetCell(1, 0, tview.NewTableCell("Error: "+err.Error()).SetTextColor(tcell.ColorRed))
+	case len(rows) == 0:
+		t.SetCell(1, 0, tview.NewTableCell("No records").SetTextColor(tcell.ColorGray))
+	}
+	for r, row := range rows {
+		for col, value := range row {
+			t.SetCell(r+1, col, tview.NewTableCell(value))
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,70 @@
+package assets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/dataio"
+	"github.com/rivo/tview"
+)
+
+// exportFormatNames are the labels of dataio.Formats in the export form
+var exportFormatNames = []string{"CSV", "JSON Lines", "XLSX"}
+
+// showExportForm asks for a file and format, then exports every asset
+// matching the current filters and sort order, not only the loaded rows
+func (p *AssetsPage) showExportForm() {
+	form := tview.NewForm()
+	fileInput := tview.NewInputField().
+		SetLabel("File").
+		SetText("assets-" + time.Now().Format(DateLayout) + ".csv").
+		SetFieldWidth(50)
+	form.AddFormItem(fileInput)
+	form.AddDropDown("Format", exportFormatNames, 0, func(_ string, index int) {
+		// Keep the extension in line with the chosen format
+		path := fileInput.GetText()
+		fileInput.SetText(strings.TrimSuffix(path, filepath.Ext(path)) + "." + string(dataio.Formats[index]))
+	})
+	form.AddButton("Export", func() {
+		path := strings.TrimSpace(fileInput.GetText())
+		if path == "" {
+			return
+		}
+		index, _ := form.GetFormItemByLabel("Format").(*tview.DropDown).GetCurrentOption()
+
+		count, err := p.exportAssets(path, dataio.Formats[index])
+		if err != nil {
+			p.showError(err)
+			return
+		}
+		p.pages.RemovePage("assetExport")
+		p.showMessage(fmt.Sprintf("Exported %d assets to %s", count, path))
+	})
+	form.AddButton("Cancel", func() {
+		p.pages.RemovePage("assetExport")
+	})
+	form.SetBorder(true).SetTitle(" Export Assets ")
+
+	p.pages.AddPage("assetExport", p.createDialogLayout(form, 70, 9), true, true)
+}
+
+// exportAssets writes the assets of the current view to path
+func (p *AssetsPage) exportAssets(path string, format dataio.Format) (int, error) {
+	table, err := dataio.LoadAssets(p.stores, p.currentQuery())
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	if err := dataio.Write(f, format, table); err != nil {
+		f.Close()
+		return 0, err
+	}
+	return len(table.Rows), f.Close()
+}
@@ -0,0 +1,127 @@
+package assets
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/rivo/tview"
+)
+
+// customFieldItems keeps the inputs of the custom fields of the selected
+// asset type at the end of the asset form
+type customFieldItems struct {
+	page   *AssetsPage
+	form   *tview.Form // Nil until the fixed fields are on the form
+	base   int         // Number of fixed form items before the custom ones
+	fields []*models.CustomField
+	items  []tview.FormItem  // One per field
+	values map[string]string // Entered values by lowercased field name, kept across type changes
+}
+
+// newCustomFieldItems starts the custom field inputs of an asset form from
+// the stored values of the asset being edited, if any
+func (p *AssetsPage) newCustomFieldItems(asset *models.Asset) *customFieldItems {
+	c := &customFieldItems{page: p, values: map[string]string{}}
+	if asset != nil {
+		values, _ := p.stores.Fields.ListValues(asset.AssetID)
+		for _, v := range values {
+			c.values[strings.ToLower(v.FieldName)] = v.Value
+		}
+	}
+	return c
+}
+
+// attach adds the inputs after the items already on form
+func (c *customFieldItems) attach(form *tview.Form) {
+	c.form, c.base = form, form.GetFormItemCount()
+	c.add()
+}
+
+// showType replaces the inputs with those of the fields of an asset type
+func (c *customFieldItems) showType(typeID int) {
+	c.keep()
+	if c.form != nil {
+		for range c.items {
+			c.form.RemoveFormItem(c.base)
+		}
+	}
+	c.items = nil
+	c.fields, _ = c.page.stores.Fields.ListForType(typeID)
+	if c.form != nil {
+		c.add()
+	}
+}
+
+// add puts an input for each field on the form
+// Required fields are marked with an asterisk, and enums that are not
+// required can be left blank
+func (c *customFieldItems) add() {
+	for _, f := range c.fields {
+		value := c.values[strings.ToLower(f.Name)]
+		label := f.Name
+		if f.Required {
+			label += " *"
+		}
+
+		var item tview.FormItem
+		switch f.Kind {
+		case models.FieldEnum:
+			options := f.Options
+			if !f.Required {
+				options = append([]string{""}, options...)
+			}
+			dropDown := tview.NewDropDown().
+				SetLabel(label).
+				SetOptions(options, nil).
+				SetFieldWidth(40)
+			if i := slices.IndexFunc(options, func(o string) bool { return strings.EqualFold(o, value) }); i >= 0 {
+				dropDown.SetCurrentOption(i)
+			}
+			item = dropDown
+		case models.FieldDate:
+			item = tview.NewInputField().
+				SetLabel(label + " (YYYY-MM-DD)").
+				SetText(value).
+				SetAcceptanceFunc(c.page.dateAcceptanceFunc).
+				SetFieldWidth(40)
+		case models.FieldNumber:
+			item = tview.NewInputField().
+				SetLabel(label).
+				SetText(value).
+				SetAcceptanceFunc(tview.InputFieldFloat).
+				SetFieldWidth(40)
+		default:
+			item = tview.NewInputField().
+				SetLabel(label).
+				SetText(value).
+				SetFieldWidth(40)
+		}
+		c.items = append(c.items, item)
+		c.form.AddFormItem(item)
+	}
+}
+
+// keep remembers what was entered in the current inputs
+func (c *customFieldItems) keep() {
+	for i, item := range c.items {
+		switch item := item.(type) {
+		case *tview.InputField:
+			c.values[strings.ToLower(c.fields[i].Name)] = item.GetText()
+		case *tview.DropDown:
+			_, text := item.GetCurrentOption()
+			c.values[strings.ToLower(c.fields[i].Name)] = text
+		}
+	}
+}
+
+// collect returns the values of the fields of the selected type by field
+// name, empty for the ones left blank
+func (c *customFieldItems) collect() map[string]string {
+	c.keep()
+	out := map[string]string{}
+	for _, f := range c.fields {
+		out[f.Name] = strings.TrimSpace(c.values[strings.ToLower(f.Name)])
+	}
+	return out
+}
@@ -1,8 +1,13 @@
 package assets
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/MawCeron/it-room/internal/auth"
 	"github.com/MawCeron/it-room/internal/models"
 	"github.com/MawCeron/it-room/internal/repo"
 	"github.com/gdamore/tcell/v2"
@@ -42,7 +47,7 @@ func (p *AssetsPage) showAssetForm(asset *models.Asset) {
 // buildAssetForm builds the complete form with all its fields
 func (p *AssetsPage) buildAssetForm(asset *models.Asset) *tview.Form {
 	form := tview.NewForm()
-	assetsRepo := repo.NewAssetRepo(p.db.Conn)
+	assetsRepo := p.stores.Assets
 
 	// Load categories
 	categories, _ := assetsRepo.GetAssetCategories()
@@ -53,18 +58,26 @@ func (p *AssetsPage) buildAssetForm(asset *models.Asset) *tview.Form {
 	typeData := p.prepareTypeData(types)
 
 	// Load locations
-	locationsRepo := repo.NewLocationRepo(p.db.Conn)
+	locationsRepo := p.stores.Locations
 	locations, _ := locationsRepo.List()
 	locationData := p.prepareLocationData(locations)
 
 	// Initialize default values
 	defaultValues := p.getDefaultFormValues(asset, categoryData.Prefixes[0])
 
+	// The custom fields follow the selected type
+	custom := p.newCustomFieldItems(asset)
+	onType := func(_ string, index int) {
+		if index >= 0 && index < len(typeData.IDs) {
+			custom.showType(typeData.IDs[index])
+		}
+	}
+
 	// Create form fields
 	assetTagInput := p.createAssetTagInput(defaultValues.AssetCode)
 	purchaseDateInput := p.createPurchaseDateInput(defaultValues.PurchaseDate)
 	warrantyEndInput := p.createWarrantyEndInput(defaultValues.WarrantyEndDate)
-	typeDropDown := p.createTypeDropDown(typeData.Options)
+	typeDropDown := p.createTypeDropDown(typeData.Options, onType)
 	locationDropDown := p.createLocationDropDown(locationData.Options)
 
 	// Link purchase date to warranty
@@ -77,21 +90,33 @@ func (p *AssetsPage) buildAssetForm(asset *models.Asset) *tview.Form {
 		typeDropDown,
 		&typeData,
 		assetTagInput,
+		onType,
 	)
 
-	// Add all fields to the form
-	p.addFormFields(form, formFields{
+	fields := formFields{
 		categoryDropDown:  categoryDropDown,
 		typeDropDown:      typeDropDown,
 		assetTagInput:     assetTagInput,
 		purchaseDateInput: purchaseDateInput,
 		warrantyEndInput:  warrantyEndInput,
 		locationDropDown:  locationDropDown,
+		custom:            custom,
 		defaultValues:     defaultValues,
-	})
+	}
+
+	// Select the current category, type and location when editing
+	if asset != nil {
+		p.selectAssetOptions(asset, assetsRepo, fields, categoryData, &typeData, locationData)
+	}
+
+	// Add all fields to the form
+	p.addFormFields(form, fields)
+	custom.attach(form)
 
 	// Add buttons
-	p.addFormButtons(form)
+	p.addFormButtons(form, func() {
+		p.saveAssetForm(form, asset, fields, &typeData, locationData)
+	})
 
 	return form
 }
@@ -104,6 +129,7 @@ type formFields struct {
 	purchaseDateInput *tview.InputField
 	warrantyEndInput  *tview.InputField
 	locationDropDown  *tview.DropDown
+	custom            *customFieldItems
 	defaultValues     formDefaultValues
 }
 
@@ -115,6 +141,12 @@ type formDefaultValues struct {
 	Model           string
 	PurchaseDate    string
 	WarrantyEndDate string
+	PurchaseCost    string
+	Currency        string
+	Supplier        string
+	PONumber        string
+	InvoiceNumber   string
+	Notes           string
 }
 
 // categoryData contains category information
@@ -192,6 +224,7 @@ func (p *AssetsPage) getDefaultFormValues(asset *models.Asset, defaultPrefix str
 		AssetCode:       defaultPrefix + "-",
 		PurchaseDate:    time.Now().Format(DateLayout),
 		WarrantyEndDate: time.Now().AddDate(1, 0, 0).Format(DateLayout),
+		Currency:        p.cfg.Currency,
 	}
 
 	if asset != nil {
@@ -199,7 +232,20 @@ func (p *AssetsPage) getDefaultFormValues(asset *models.Asset, defaultPrefix str
 		defaults.SerialNumber = asset.SerialNumber
 		defaults.Maker = asset.Maker
 		defaults.Model = asset.Model
-		// Add more fields as needed
+		defaults.PurchaseDate = formatDate(&asset.PurchaseDate)
+		defaults.WarrantyEndDate = formatDate(asset.WarrantyEndDate)
+		if asset.PurchaseCost != nil {
+			defaults.PurchaseCost = strconv.FormatFloat(*asset.PurchaseCost, 'f', -1, 64)
+		}
+		if asset.Currency != nil {
+			defaults.Currency = *asset.Currency
+		}
+		defaults.Supplier = valueOrEmpty(asset.Supplier)
+		defaults.PONumber = valueOrEmpty(asset.PONumber)
+		defaults.InvoiceNumber = valueOrEmpty(asset.InvoiceNumber)
+		if asset.Notes != nil {
+			defaults.Notes = *asset.Notes
+		}
 	}
 
 	return defaults
@@ -230,11 +276,12 @@ func (p *AssetsPage) createWarrantyEndInput(defaultValue string) *tview.InputFie
 		SetFieldWidth(40)
 }
 
-// createTypeDropDown creates the type dropdown
-func (p *AssetsPage) createTypeDropDown(options []string) *tview.DropDown {
+// createTypeDropDown creates the type dropdown, calling onType when a type
+// is selected
+func (p *AssetsPage) createTypeDropDown(options []string, onType func(string, int)) *tview.DropDown {
 	return tview.NewDropDown().
 		SetLabel("Type").
-		SetOptions(options, nil).
+		SetOptions(options, onType).
 		SetFieldWidth(40)
 }
 
@@ -243,6 +290,7 @@ func (p *AssetsPage) createLocationDropDown(options []string) *tview.DropDown {
 	return tview.NewDropDown().
 		SetLabel("Location").
 		SetOptions(options, nil).
+		SetCurrentOption(0).
 		SetFieldWidth(40)
 }
 
@@ -274,10 +322,11 @@ func (p *AssetsPage) linkPurchaseDateToWarranty(purchaseInput, warrantyInput *tv
 // createCategoryDropDown creates the category dropdown with update logic
 func (p *AssetsPage) createCategoryDropDown(
 	catData categoryData,
-	assetsRepo *repo.AssetRepo,
+	assetsRepo repo.AssetStore,
 	typeDropDown *tview.DropDown,
 	typeData *typeData,
 	assetTagInput *tview.InputField,
+	onType func(string, int),
 ) *tview.DropDown {
 	return tview.NewDropDown().
 		SetLabel("Category").
@@ -286,7 +335,7 @@ func (p *AssetsPage) createCategoryDropDown(
 			types, _ := assetsRepo.GetAssetTypes(catData.IDs[optionIndex])
 			*typeData = p.prepareTypeData(types)
 
-			typeDropDown.SetOptions(typeData.Options, nil)
+			typeDropDown.SetOptions(typeData.Options, onType)
 			typeDropDown.SetCurrentOption(0)
 
 			// Update asset tag prefix
@@ -307,24 +356,151 @@ func (p *AssetsPage) addFormFields(form *tview.Form, fields formFields) {
 	form.AddFormItem(fields.purchaseDateInput)
 	form.AddFormItem(fields.warrantyEndInput)
 	form.AddFormItem(fields.locationDropDown)
-	form.AddTextArea("Notes", "", 40, 0, 0, nil)
+	form.AddInputField("Purchase Cost", fields.defaultValues.PurchaseCost, 40, nil, nil)
+	form.AddInputField("Currency", fields.defaultValues.Currency, 40, nil, nil)
+	form.AddInputField("Supplier", fields.defaultValues.Supplier, 40, nil, nil)
+	form.AddInputField("PO Number", fields.defaultValues.PONumber, 40, nil, nil)
+	form.AddInputField("Invoice Number", fields.defaultValues.InvoiceNumber, 40, nil, nil)
+	form.AddTextArea("Notes", fields.defaultValues.Notes, 40, 0, 0, nil)
+}
+
+// selectAssetOptions points the dropdowns at the category, type and location
+// of the asset being edited
+// Changing the category resets the types and the asset tag, so both are
+// restored afterwards
+func (p *AssetsPage) selectAssetOptions(
+	asset *models.Asset,
+	assetsRepo repo.AssetStore,
+	fields formFields,
+	catData categoryData,
+	typeData *typeData,
+	locData locationData,
+) {
+	if assetType, err := assetsRepo.GetAssetType(asset.TypeID); err == nil {
+		if i := indexOf(catData.IDs, assetType.CategoryID); i >= 0 {
+			fields.categoryDropDown.SetCurrentOption(i)
+		}
+	}
+	if i := indexOf(typeData.IDs, asset.TypeID); i >= 0 {
+		fields.typeDropDown.SetCurrentOption(i)
+	}
+	if i := indexOf(locData.IDs, asset.LocationID); i >= 0 {
+		fields.locationDropDown.SetCurrentOption(i)
+	}
+	fields.assetTagInput.SetText(asset.AssetTag)
 }
 
 // addFormButtons adds buttons to the form
-func (p *AssetsPage) addFormButtons(form *tview.Form) {
-	form.AddButton("Save", nil) // TODO: Implement save logic
+func (p *AssetsPage) addFormButtons(form *tview.Form, save func()) {
+	form.AddButton("Save", save)
 	form.AddButton("Cancel", func() {
 		p.pages.RemovePage("assetForm")
 	})
 }
 
+// saveAssetForm validates the form and creates or updates the asset
+// New assets start as Available; validation and database errors are shown
+// in a modal and keep the form open
+func (p *AssetsPage) saveAssetForm(form *tview.Form, asset *models.Asset, fields formFields, typeData *typeData, locData locationData) {
+	a := models.Asset{StatusID: models.StatusAvailable}
+	if asset != nil {
+		a = *asset
+	}
+
+	typeIndex, _ := fields.typeDropDown.GetCurrentOption()
+	locationIndex, _ := fields.locationDropDown.GetCurrentOption()
+	if typeIndex < 0 || typeIndex >= len(typeData.IDs) || locationIndex < 0 || locationIndex >= len(locData.IDs) {
+		p.showError(fmt.Errorf("type and location are required"))
+		return
+	}
+	a.TypeID = typeData.IDs[typeIndex]
+	a.LocationID = locData.IDs[locationIndex]
+
+	a.AssetTag = strings.TrimSpace(fields.assetTagInput.GetText())
+	a.Maker = strings.TrimSpace(form.GetFormItemByLabel("Make").(*tview.InputField).GetText())
+	a.Model = strings.TrimSpace(form.GetFormItemByLabel("Model").(*tview.InputField).GetText())
+	a.SerialNumber = strings.TrimSpace(form.GetFormItemByLabel("Serial Number").(*tview.InputField).GetText())
+	if strings.HasSuffix(a.AssetTag, "-") || a.AssetTag == "" || a.Maker == "" || a.Model == "" || a.SerialNumber == "" {
+		p.showError(fmt.Errorf("asset tag, make, model and serial number are required"))
+		return
+	}
+
+	purchaseDate, err := time.Parse(DateLayout, fields.purchaseDateInput.GetText())
+	if err != nil {
+		p.showError(fmt.Errorf("invalid purchase date: %w", err))
+		return
+	}
+	a.PurchaseDate = purchaseDate
+
+	a.WarrantyEndDate = nil
+	if text := strings.TrimSpace(fields.warrantyEndInput.GetText()); text != "" {
+		warrantyEnd, err := time.Parse(DateLayout, text)
+		if err != nil {
+			p.showError(fmt.Errorf("invalid warranty end date: %w", err))
+			return
+		}
+		a.WarrantyEndDate = &warrantyEnd
+	}
+
+	// The currency only goes with a purchase cost
+	a.PurchaseCost, a.Currency = nil, nil
+	if text := strings.TrimSpace(form.GetFormItemByLabel("Purchase Cost").(*tview.InputField).GetText()); text != "" {
+		cost, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			p.showError(fmt.Errorf("invalid purchase cost %q", text))
+			return
+		}
+		a.PurchaseCost = &cost
+		a.Currency = optionalText(form.GetFormItemByLabel("Currency").(*tview.InputField).GetText())
+	}
+	a.Supplier = optionalText(form.GetFormItemByLabel("Supplier").(*tview.InputField).GetText())
+	a.PONumber = optionalText(form.GetFormItemByLabel("PO Number").(*tview.InputField).GetText())
+	a.InvoiceNumber = optionalText(form.GetFormItemByLabel("Invoice Number").(*tview.InputField).GetText())
+
+	a.Notes = nil
+	if notes := strings.TrimSpace(form.GetFormItemByLabel("Notes").(*tview.TextArea).GetText()); notes != "" {
+		a.Notes = &notes
+	}
+	a.Fields = fields.custom.collect()
+
+	if asset == nil {
+		a.CreatedBy = auth.Actor(p.user)
+		err = p.svc.CreateAsset(context.Background(), &a)
+	} else {
+		a.UpdatedBy = auth.Actor(p.user)
+		err = p.svc.UpdateAsset(context.Background(), &a)
+	}
+	if err != nil {
+		p.showError(err)
+		return
+	}
+
+	p.pages.RemovePage("assetForm")
+	p.assetSaved(a.AssetID)
+}
+
+// indexOf returns the position of id in ids, or -1 if absent
+func indexOf(ids []int, id int) int {
+	for i, v := range ids {
+		if v == id {
+			return i
+		}
+	}
+	return -1
+}
+
 // createCenteredLayout creates a centered layout for the form
 func (p *AssetsPage) createCenteredLayout(content tview.Primitive) *tview.Flex {
+	return p.createDialogLayout(content, 80, 44)
+}
+
+// createDialogLayout centers content in a box of the given size
+func (p *AssetsPage) createDialogLayout(content tview.Primitive, width, height int) *tview.Flex {
 	return tview.NewFlex().
 		AddItem(nil, 0, 1, false).
 		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
 			AddItem(nil, 0, 1, false).
-			AddItem(content, 30, 1, true).
-			AddItem(nil, 0, 1, false), 80, 1, true).
+			AddItem(content, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
 		AddItem(nil, 0, 1, false)
 }
@@ -1,6 +1,11 @@
 package assets
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -20,3 +25,55 @@ func (p *AssetsPage) statusCell(statusID int) *tview.TableCell {
 	}
 	return tview.NewTableCell("Unknown").SetTextColor(tcell.ColorGray)
 }
+
+// warrantyState returns the warranty state of an asset as of today
+func (p *AssetsPage) warrantyState(a *models.Asset) models.WarrantyState {
+	return a.WarrantyStateOn(time.Now(), p.cfg.WarrantySoonDays())
+}
+
+// WarrantyColor returns the color used to display a warranty state
+func WarrantyColor(state models.WarrantyState) tcell.Color {
+	switch state {
+	case models.WarrantyActive:
+		return tcell.ColorGreen
+	case models.WarrantyExpiringSoon:
+		return tcell.ColorOrange
+	case models.WarrantyExpired:
+		return tcell.ColorRed
+	}
+	return tcell.ColorGray
+}
+
+// formatDate formats an optional date using DateLayout
+// Nil and zero dates are rendered as an empty string
+func formatDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(DateLayout)
+}
+
+// valueOrEmpty dereferences an optional string
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// optionalText trims s, returning nil when it is empty
+func optionalText(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// formatCost formats an optional amount with two decimals and its currency
+func formatCost(v *float64, currency *string) string {
+	if v == nil {
+		return ""
+	}
+	return strings.TrimSpace(strconv.FormatFloat(*v, 'f', 2, 64) + " " + valueOrEmpty(currency))
+}
@@ -0,0 +1,144 @@
+package assets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/dataio"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// notMapped is the mapping option for a field read from no column
+const notMapped = "(not mapped)"
+
+// showImportForm starts the CSV import wizard by asking for the file
+func (p *AssetsPage) showImportForm() {
+	form := tview.NewForm()
+	form.AddInputField("CSV file", "", 50, nil, nil)
+	form.AddButton("Next", func() {
+		path := strings.TrimSpace(form.GetFormItemByLabel("CSV file").(*tview.InputField).GetText())
+		if path == "" {
+			return
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			p.showError(err)
+			return
+		}
+		defer f.Close()
+		header, records, err := dataio.ReadCSV(f)
+		if err != nil {
+			p.showError(fmt.Errorf("%s: %w", path, err))
+			return
+		}
+
+		p.pages.RemovePage("assetImport")
+		p.showImportMapping(path, header, records)
+	})
+	form.AddButton("Cancel", func() {
+		p.pages.RemovePage("assetImport")
+	})
+	form.SetBorder(true).SetTitle(" Import Assets (1/2) ")
+
+	p.pages.AddPage("assetImport", p.createDialogLayout(form, 70, 7), true, true)
+}
+
+// showImportMapping lets the user pick the CSV column of each field and the
+// date format, then runs a dry run or the import
+func (p *AssetsPage) showImportMapping(path string, header []string, records []dataio.Record) {
+	guessed := dataio.GuessMapping(header)
+	columns := append([]string{notMapped}, header...)
+
+	form := tview.NewForm().SetItemPadding(0)
+	for _, f := range dataio.AssetFields {
+		label := f.Title
+		if f.Required {
+			label += " *"
+		}
+		selected := 0
+		if i, ok := guessed[f.Key]; ok {
+			selected = i + 1
+		}
+		form.AddDropDown(label, columns, selected, nil)
+	}
+
+	formats := []string{"Detect"}
+	for _, layout := range dataio.DateLayouts {
+		formats = append(formats, dataio.DescribeDateLayout(layout))
+	}
+	form.AddDropDown("Date format", formats, 0, nil)
+
+	run := func(dryRun bool) {
+		opts := dataio.ImportOptions{Mapping: dataio.Mapping{}, DryRun: dryRun, Actor: auth.Actor(p.user), Currency: p.cfg.Currency}
+		for i, f := range dataio.AssetFields {
+			if column, _ := form.GetFormItem(i).(*tview.DropDown).GetCurrentOption(); column > 0 {
+				opts.Mapping[f.Key] = column - 1
+			}
+		}
+		if format, _ := form.GetFormItemByLabel("Date format").(*tview.DropDown).GetCurrentOption(); format > 0 {
+			opts.DateLayout = dataio.DateLayouts[format-1]
+		}
+
+		report, err := p.svc.ImportAssets(context.Background(), header, records, opts)
+		if err != nil {
+			p.showError(err)
+			return
+		}
+		if report.Committed {
+			p.pages.RemovePage("assetImport")
+			p.reloadTable()
+		}
+		p.showImportReport(path, report)
+	}
+	form.AddButton("Dry run", func() { run(true) })
+	form.AddButton("Import", func() { run(false) })
+	form.AddButton("Cancel", func() {
+		p.pages.RemovePage("assetImport")
+	})
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Import Assets (2/2): %d rows ", len(records)))
+
+	p.pages.AddPage("assetImport", p.createDialogLayout(form, 70, len(dataio.AssetFields)+7), true, true)
+}
+
+// showImportReport shows the outcome of an import or dry run with every row
+// error
+func (p *AssetsPage) showImportReport(path string, report *dataio.ImportReport) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d rows, date format %s\n\n", path, report.Rows, dataio.DescribeDateLayout(report.DateLayout))
+	if len(report.CustomColumns) > 0 {
+		fmt.Fprintf(&b, "Custom fields: %s\n", tview.Escape(strings.Join(report.CustomColumns, ", ")))
+	}
+	if len(report.IgnoredColumns) > 0 {
+		fmt.Fprintf(&b, "[yellow]Ignored columns matching no field: %s[-]\n", tview.Escape(strings.Join(report.IgnoredColumns, ", ")))
+	}
+	if len(report.CustomColumns) > 0 || len(report.IgnoredColumns) > 0 {
+		b.WriteString("\n")
+	}
+	switch {
+	case len(report.Errors) > 0:
+		fmt.Fprintf(&b, "[red]%d error(s), nothing was imported[-]\n\n", len(report.Errors))
+		for _, e := range report.Errors {
+			fmt.Fprintln(&b, tview.Escape(e.Error()))
+		}
+	case report.DryRun:
+		fmt.Fprintf(&b, "[green]Dry run: all %d rows are valid, nothing was saved[-]\n", report.Imported)
+	default:
+		fmt.Fprintf(&b, "[green]Imported %d assets[-]\n", report.Imported)
+	}
+
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(b.String())
+	text.SetDoneFunc(func(key tcell.Key) {
+		p.pages.RemovePage("assetImportReport")
+	})
+	text.SetBorder(true).SetTitle(" Import Report (Enter or Esc to close) ")
+
+	p.pages.AddPage("assetImportReport", p.createDialogLayout(text, 90, 24), true, true)
+}
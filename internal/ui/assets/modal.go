@@ -1,17 +1,20 @@
 package assets
 
 import (
-	"github.com/MawCeron/it-room/internal/models"
 	"github.com/rivo/tview"
 )
 
-// showAssetModal displays a modal with basic asset information
-// The modal shows the asset code and model, and can be dismissed with OK button
-func (p *AssetsPage) showAssetModal(asset *models.Asset) {
+// showError displays a modal with an error message on top of the current page
+// The modal is dismissed with the OK button
+func (p *AssetsPage) showError(err error) {
+	p.showMessage("Error: " + err.Error())
+}
+
+// showMessage displays a modal with a message on top of the current page
+// The modal is dismissed with the OK button
+func (p *AssetsPage) showMessage(text string) {
 	modal := tview.NewModal().
-		SetText("Asset Details:\n" +
-			"Code: " + asset.AssetTag + "\n" +
-			"Model: " + asset.Model).
+		SetText(text).
 		AddButtons([]string{"OK"}).
 		SetDoneFunc(func(idx int, label string) {
 			p.pages.RemovePage("assetModal")
@@ -1,22 +1,49 @@
 package assets
 
 import (
-	"github.com/MawCeron/it-room/internal/db"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/config"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/service"
+	"github.com/MawCeron/it-room/internal/ui/keymap"
 	"github.com/rivo/tview"
 )
 
 // AssetsPage represents the main assets management page
-// It contains the view layout, database connection, and page manager
+// It contains the view layout, data stores, and page manager
 type AssetsPage struct {
-	view  *tview.Flex
-	db    *db.DB
-	pages *tview.Pages
+	app     *tview.Application
+	view    *tview.Flex
+	svc     *service.Service
+	stores  *repo.Stores // Stores behind svc, for reads
+	pages   *tview.Pages
+	keys    *keymap.Registry
+	cfg     *config.Config
+	user    *models.User // Signed in user, nil while authentication is not enabled
+	table   *tview.Table
+	content *assetContent
+	detail  *assetDetail     // Open asset detail screen, if any
+	state   models.SavedView // Columns, sort and filter applied to the table
+	box     *tview.Flex      // Bordered container of the table
+
+	preset      repo.AssetQuery // Criteria set by a drill-down, on top of the filter
+	presetLabel string
+	asOf        *time.Time // Past day the table shows the inventory of, nil for now
 }
 
 // New creates and initializes a new AssetsPage instance
-// It builds the page layout and returns the configured page
-func New(db *db.DB, pages *tview.Pages) *AssetsPage {
-	p := &AssetsPage{db: db, pages: pages}
+// It registers the page actions in keys, builds the page layout and returns
+// the configured page; rows are loaded in the background and drawn through app
+func New(app *tview.Application, svc *service.Service, pages *tview.Pages, keys *keymap.Registry, cfg *config.Config) *AssetsPage {
+	p := &AssetsPage{app: app, svc: svc, stores: svc.Stores(), pages: pages, keys: keys, cfg: cfg}
+	p.state = models.SavedView{
+		Columns:    append([]string(nil), defaultColumns...),
+		SortColumn: "asset_tag",
+	}
+	p.registerActions()
 	p.build()
 	return p
 }
@@ -56,3 +83,48 @@ func (p *AssetsPage) build() {
 			0, 1, true).
 		AddItem(nil, 1, 0, false)
 }
+
+// SetUser sets the user whose role decides the available actions and whom
+// changes are attributed to
+func (p *AssetsPage) SetUser(u *models.User) {
+	p.user = u
+}
+
+// can reports whether the signed in user's role allows permission perm
+func (p *AssetsPage) can(perm auth.Permission) bool {
+	return auth.Can(p.user, perm)
+}
+
+// OpenAsset opens the detail screen of an asset
+func (p *AssetsPage) OpenAsset(assetID string) {
+	p.closeAssetDetail()
+	p.showAssetDetail(assetID)
+}
+
+// ShowFiltered replaces the table filter with the given text
+func (p *AssetsPage) ShowFiltered(filter string) {
+	p.closeAssetDetail()
+	p.state.Filter = filter
+	p.reloadTable()
+}
+
+// ShowPreset restricts the table to the criteria of q (sorting and free text
+// are kept from the current view) and names the restriction in the title
+// Used by drill-downs from other pages; clearing the filters removes it
+func (p *AssetsPage) ShowPreset(label string, q repo.AssetQuery) {
+	p.closeAssetDetail()
+	q.SortColumn, q.SortDesc, q.Filter = "", false, ""
+	p.preset = q
+	p.presetLabel = label
+	p.reloadTable()
+}
+
+// clearFilters removes the free text filter, any drill-down criteria and the
+// as-of date
+func (p *AssetsPage) clearFilters() {
+	p.state.Filter = ""
+	p.asOf = nil
+	p.preset = repo.AssetQuery{}
+	p.presetLabel = ""
+	p.reloadTable()
+}
@@ -1,8 +1,6 @@
 package assets
 
 import (
-	"fmt"
-
 	"github.com/MawCeron/it-room/internal/models"
 	"github.com/MawCeron/it-room/internal/repo"
 	"github.com/gdamore/tcell/v2"
@@ -12,99 +10,162 @@ import (
 // buildAssetsTable creates the main assets table with headers, data, and event bindings
 // Returns a flex container with the bordered table inside
 func (p *AssetsPage) buildAssetsTable() *tview.Flex {
+	p.content = newAssetContent(p)
+	p.box = tview.NewFlex()
 	table := tview.NewTable().
 		SetBorders(false).
 		SetSelectable(true, false).
-		SetFixed(1, 0)
+		SetFixed(1, 0).
+		SetContent(p.content)
+	p.table = table
+
+	p.reloadTable()
+
+	// Always bind events, even if assets is nil or empty
+	p.bindTableEvents(table)
+
+	p.box.AddItem(table, 0, 1, true)
+	p.box.SetBorder(true)
+	p.updateTitle()
 
-	p.addTableHeaders(table)
+	return p.box
+}
 
-	assets := p.loadAssets()
-	if len(assets) > 0 {
-		p.fillTableRows(table, assets)
+// updateTitle shows the drill-down label and the as-of date, if any, in the
+// table border
+func (p *AssetsPage) updateTitle() {
+	title := " [::b]Assets[::-] - IT equipment inventory management "
+	if p.presetLabel != "" {
+		title = " [::b]Assets[::-] - " + tview.Escape(p.presetLabel) + " "
+	}
+	if p.asOf != nil {
+		title = " [::b]Assets[::-] as of " + p.asOf.Format(DateLayout) + " "
+		if p.presetLabel != "" {
+			title = " [::b]Assets[::-] - " + tview.Escape(p.presetLabel) + " - as of " + p.asOf.Format(DateLayout) + " "
+		}
 	}
+	p.box.SetTitle(title)
+}
 
-	// Always bind events, even if assets is nil or empty
-	p.bindTableEvents(table, assets)
+// reloadTable discards the loaded rows and starts loading the assets that
+// match the current view state
+func (p *AssetsPage) reloadTable() {
+	p.content.reset(p.currentQuery())
+	p.table.Select(1, 0)
+	p.updateTitle()
+}
 
-	box := tview.NewFlex().AddItem(table, 0, 1, true)
-	box.SetBorder(true).
-		SetTitle(" [::b]Assets[::-] - IT equipment inventory management ")
+// currentQuery merges the drill-down preset with the view state and the
+// as-of date
+func (p *AssetsPage) currentQuery() repo.AssetQuery {
+	q := p.preset
+	q.SortColumn = p.state.SortColumn
+	q.SortDesc = p.state.SortDesc
+	q.Filter = p.state.Filter
+	q.AsOf = p.asOf
+	return q
+}
 
-	return box
+// selectedAsset returns the asset on the selected row, or nil on the header,
+// an empty table or a row that is still loading
+func (p *AssetsPage) selectedAsset() *models.AssetSummary {
+	row, _ := p.table.GetSelection()
+	return p.content.rowAt(row)
 }
 
 // bindTableEvents attaches event handlers for table interactions
-// Handles row selection (Enter) and keyboard shortcuts (n=new, e=edit)
-func (p *AssetsPage) bindTableEvents(t *tview.Table, assets []*models.Asset) {
+// Handles row selection (mouse double click opens the detail screen), header
+// clicks (sort) and keyboard shortcuts
+func (p *AssetsPage) bindTableEvents(t *tview.Table) {
 	// Handle row selection (Enter key)
 	t.SetSelectedFunc(func(row, _ int) {
-		if row == 0 || assets == nil || row > len(assets) {
-			return
+		if asset := p.selectedAsset(); asset != nil {
+			p.showAssetDetail(asset.AssetID)
 		}
-		asset := assets[row-1]
-		p.showAssetModal(asset)
 	})
 
-	// Handle keyboard shortcuts
-	t.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		row, _ := t.GetSelection()
-
-		switch event.Rune() {
-		case 'n', 'N':
-			p.showNewAssetForm()
-			return nil
-		case 'e', 'E':
-			if assets != nil && row > 0 && row <= len(assets) {
-				p.showEditAssetForm(assets[row-1])
-			}
-			return nil
+	// Clicking a header sorts by that column, clicking it again reverses the order
+	t.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action != tview.MouseLeftClick {
+			return action, event
+		}
+		row, col := t.CellAt(event.Position())
+		if row != 0 || col < 0 {
+			return action, event
 		}
-		return event
+		p.sortByColumn(col)
+		return action, nil
 	})
+
+	// Handle keyboard shortcuts registered in the keymap
+	t.SetInputCapture(p.keys.Capture(tableScope))
 }
 
-// addTableHeaders sets up the column headers for the assets table
-// Headers are displayed in yellow and are not selectable
-func (p *AssetsPage) addTableHeaders(t *tview.Table) {
-	headers := []string{"Asset Tag", "Type", "Model", "Serial Number", "Status"}
-	for col, h := range headers {
-		cell := tview.NewTableCell(h).
-			SetTextColor(tcell.ColorYellow).
-			SetSelectable(false).
-			SetExpansion(1)
-		t.SetCell(0, col, cell)
+// sortByColumn sorts by the visible column at the given index
+// Selecting the current sort column toggles between ascending and descending
+func (p *AssetsPage) sortByColumn(index int) {
+	columns := p.visibleColumns()
+	if index >= len(columns) {
+		return
+	}
+	key := columns[index].Key
+	if p.state.SortColumn == key {
+		p.state.SortDesc = !p.state.SortDesc
+	} else {
+		p.state.SortColumn = key
+		p.state.SortDesc = false
 	}
+	p.reloadTable()
 }
 
-// loadAssets retrieves all assets from the database
-// Returns nil if there's an error loading assets
-func (p *AssetsPage) loadAssets() []*models.Asset {
-	assetRepo := repo.NewAssetRepo(p.db.Conn)
-	assets, err := assetRepo.List()
-	if err != nil {
-		return nil
+// shiftSortColumn moves the sort to the next (or previous) visible column
+func (p *AssetsPage) shiftSortColumn(forward bool) {
+	columns := p.visibleColumns()
+	if len(columns) == 0 {
+		return
 	}
-	return assets
+	current := -1
+	for i, c := range columns {
+		if c.Key == p.state.SortColumn {
+			current = i
+		}
+	}
+	next := 0
+	switch {
+	case current >= 0 && forward:
+		next = (current + 1) % len(columns)
+	case current >= 0:
+		next = (current - 1 + len(columns)) % len(columns)
+	}
+	p.state.SortColumn = columns[next].Key
+	p.state.SortDesc = false
+	p.reloadTable()
 }
 
-// fillTableRows populates the table with asset data
-// Each row displays: asset tag, type ID, maker+model, serial number, and colored status
-func (p *AssetsPage) fillTableRows(t *tview.Table, assets []*models.Asset) {
-	for row, asset := range assets {
-		r := row + 1
-		t.SetCell(r, 0, tview.NewTableCell(asset.AssetTag))
-		t.SetCell(r, 1, tview.NewTableCell(fmt.Sprintf("%d", asset.TypeID)))
-		t.SetCell(r, 2, tview.NewTableCell(fmt.Sprintf("%s %s", asset.Maker, asset.Model)))
-		t.SetCell(r, 3, tview.NewTableCell(asset.SerialNumber))
-		t.SetCell(r, 4, p.statusCell(asset.StatusID))
+// headerCell returns the header cell for a column
+// Headers are displayed in yellow and are not selectable; the sorted column
+// carries an arrow showing the direction
+func (p *AssetsPage) headerCell(c assetColumn) *tview.TableCell {
+	title := c.Title
+	if c.Key == p.state.SortColumn {
+		if p.state.SortDesc {
+			title += " ▼"
+		} else {
+			title += " ▲"
+		}
 	}
+	return tview.NewTableCell(title).
+		SetTextColor(tcell.ColorYellow).
+		SetSelectable(false).
+		SetExpansion(1)
 }
 
 // buildStatusBar creates the bottom status bar showing available keyboard shortcuts
-// Displays navigation keys and action shortcuts with color formatting
+// Displays navigation keys and the bound action shortcuts with color formatting
 func (p *AssetsPage) buildStatusBar() *tview.TextView {
 	return tview.NewTextView().
-		SetText(" [yellow]↑↓[white] Navigate  [yellow]Enter[white] View details [yellow]f[white] Filters  [yellow]n[white] New Asset  [yellow]a[white] Change Assignation  [red]r[white] Retire Asset  [yellow]?[white] Help").
+		SetText(" [yellow]↑↓[white] Navigate  " + p.keys.Hints(
+			"assets.open", "assets.filter", "assets.sort_next", "assets.sort_reverse",
+			"assets.columns", "assets.views", "assets.new", "assets.edit", "app.help")).
 		SetDynamicColors(true)
 }
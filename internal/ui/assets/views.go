@@ -0,0 +1,146 @@
+package assets
+
+import (
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showFilterForm displays a form to filter the table by free text
+// An empty filter shows every asset
+func (p *AssetsPage) showFilterForm() {
+	form := tview.NewForm()
+	form.AddInputField("Contains", p.state.Filter, 40, nil, nil)
+	form.AddButton("Apply", func() {
+		p.state.Filter = form.GetFormItemByLabel("Contains").(*tview.InputField).GetText()
+		p.closeDialog("assetFilter")
+	})
+	form.AddButton("Clear", func() {
+		p.pages.RemovePage("assetFilter")
+		p.clearFilters()
+	})
+	form.AddButton("Cancel", func() {
+		p.pages.RemovePage("assetFilter")
+	})
+	form.SetBorder(true).SetTitle(" Filter Assets ")
+
+	p.pages.AddPage("assetFilter", p.createDialogLayout(form, 60, 7), true, true)
+}
+
+// showColumnsForm displays a checkbox per available column to choose which
+// ones the table shows
+func (p *AssetsPage) showColumnsForm() {
+	visible := make(map[string]bool)
+	for _, key := range p.state.Columns {
+		visible[key] = true
+	}
+
+	form := tview.NewForm()
+	for _, c := range assetColumns {
+		form.AddCheckbox(c.Title, visible[c.Key], nil)
+	}
+	form.AddButton("Apply", func() {
+		var columns []string
+		for i, c := range assetColumns {
+			if form.GetFormItem(i).(*tview.Checkbox).IsChecked() {
+				columns = append(columns, c.Key)
+			}
+		}
+		if len(columns) == 0 {
+			return
+		}
+		p.state.Columns = columns
+		p.closeDialog("assetColumns")
+	})
+	form.AddButton("Cancel", func() {
+		p.pages.RemovePage("assetColumns")
+	})
+	form.SetBorder(true).SetTitle(" Columns ")
+
+	p.pages.AddPage("assetColumns", p.createDialogLayout(form, 40, len(assetColumns)*2+5), true, true)
+}
+
+// showViewsList displays the saved views
+// Enter applies a view, d deletes it and the last entry saves the current one
+func (p *AssetsPage) showViewsList() {
+	viewRepo := p.stores.Views
+	views, _ := viewRepo.List()
+
+	list := tview.NewList().ShowSecondaryText(true)
+	for _, v := range views {
+		list.AddItem(v.Name, p.describeView(v), 0, func() {
+			p.state = *v
+			p.closeDialog("assetViews")
+		})
+	}
+	list.AddItem("Save current view...", "Store columns, sort and filter under a name", 0, func() {
+		p.pages.RemovePage("assetViews")
+		p.showSaveViewForm()
+	})
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			p.pages.RemovePage("assetViews")
+			return nil
+		}
+		if event.Rune() == 'd' || event.Rune() == 'D' {
+			i := list.GetCurrentItem()
+			if i < len(views) {
+				viewRepo.Delete(views[i].ViewID)
+				p.pages.RemovePage("assetViews")
+				p.showViewsList()
+			}
+			return nil
+		}
+		return event
+	})
+	list.SetBorder(true).SetTitle(" Saved Views (d delete, Esc close) ")
+
+	p.pages.AddPage("assetViews", p.createDialogLayout(list, 60, len(views)*2+4), true, true)
+}
+
+// showSaveViewForm asks for a name and saves the current view state under it
+func (p *AssetsPage) showSaveViewForm() {
+	form := tview.NewForm()
+	form.AddInputField("Name", p.state.Name, 40, nil, nil)
+	form.AddButton("Save", func() {
+		name := form.GetFormItemByLabel("Name").(*tview.InputField).GetText()
+		if name == "" {
+			return
+		}
+		v := p.state
+		v.Name = name
+		if err := p.stores.Views.Save(&v); err != nil {
+			return
+		}
+		p.state = v
+		p.pages.RemovePage("assetSaveView")
+	})
+	form.AddButton("Cancel", func() {
+		p.pages.RemovePage("assetSaveView")
+	})
+	form.SetBorder(true).SetTitle(" Save View ")
+
+	p.pages.AddPage("assetSaveView", p.createDialogLayout(form, 60, 7), true, true)
+}
+
+// describeView summarizes a saved view for the views list
+func (p *AssetsPage) describeView(v *models.SavedView) string {
+	direction := "asc"
+	if v.SortDesc {
+		direction = "desc"
+	}
+	desc := fmt.Sprintf("%d columns, sorted by %s %s", len(v.Columns), v.SortColumn, direction)
+	if v.Filter != "" {
+		desc += fmt.Sprintf(", filter %q", v.Filter)
+	}
+	return desc
+}
+
+// closeDialog removes a dialog page and reloads the table with the updated
+// view state
+func (p *AssetsPage) closeDialog(name string) {
+	p.pages.RemovePage(name)
+	p.reloadTable()
+}
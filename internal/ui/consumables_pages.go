@@ -1,17 +1,30 @@
 package ui
 
 import (
-	"github.com/MawCeron/it-room/internal/db"
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/ui/keymap"
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
 type ConsumablesPage struct {
-	view *tview.Flex
-	db   *db.DB
+	view     *tview.Flex
+	store    repo.ConsumableStore
+	keys     *keymap.Registry
+	header   *tview.TextView
+	table    *tview.Table
+	lowStock bool // Only list types at or below their reorder level
 }
 
-func NewConsumablesPage(db *db.DB) *ConsumablesPage {
-	p := &ConsumablesPage{db: db}
+func NewConsumablesPage(store repo.ConsumableStore, keys *keymap.Registry) *ConsumablesPage {
+	p := &ConsumablesPage{store: store, keys: keys}
+	keys.Register(keymap.Action{
+		ID: "consumables.clear_filter", Scope: "Consumables", Description: "Clear filter",
+		Keys: []string{"x", "X"}, Handler: func() { p.ShowLowStock(false) },
+	})
 	p.build()
 	return p
 }
@@ -24,15 +37,28 @@ func (p *ConsumablesPage) View() tview.Primitive {
 	return p.view
 }
 
+// ShowLowStock switches between listing every consumable type and only
+// those at or below their reorder level
+func (p *ConsumablesPage) ShowLowStock(lowStock bool) {
+	p.lowStock = lowStock
+	p.reload()
+}
+
 func (p *ConsumablesPage) build() {
-	header := tview.NewTextView().
-		SetText("[::b]Consumables[::-]\nToner, drum, and other consumables tracking").
+	p.header = tview.NewTextView().
 		SetDynamicColors(true)
 
+	p.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	p.table.SetInputCapture(p.keys.Capture("Consumables"))
+	p.reload()
+
 	content := tview.NewFlex().
 		SetDirection(tview.FlexRow).
-		AddItem(header, 2, 0, false).
-		AddItem(nil, 1, 0, false)
+		AddItem(p.header, 2, 0, false).
+		AddItem(nil, 1, 0, false).
+		AddItem(p.table, 0, 1, true)
 
 	p.view = tview.NewFlex().
 		SetDirection(tview.FlexRow).
@@ -46,3 +72,60 @@ func (p *ConsumablesPage) build() {
 			0, 1, true).
 		AddItem(nil, 1, 0, false)
 }
+
+// reload fills the table with the consumable types and their stock
+// Types at or below their reorder level are shown in orange
+func (p *ConsumablesPage) reload() {
+	subtitle := "Toner, drum, and other consumables tracking"
+	if p.lowStock {
+		subtitle = "Low stock  [gray](x to show all)[-]"
+	}
+	p.header.SetText("[::b]Consumables[::-]\n" + subtitle)
+
+	p.table.Clear()
+	for col, h := range []string{"Name", "Part Number", "Manufacturer", "Stock", "Reorder At", "Last Purchase"} {
+		p.table.SetCell(0, col, tview.NewTableCell(h).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+
+	consumableRepo := p.store
+	var types []*models.ConsumableType
+	var err error
+	if p.lowStock {
+		types, err = consumableRepo.ListLowStock()
+	} else {
+		types, err = consumableRepo.ListTypes()
+	}
+	if err != nil {
+		p.table.SetCell(1, 0, tview.NewTableCell("Error: "+err.Error()).SetTextColor(tcell.ColorRed))
+		return
+	}
+
+	for i, c := range types {
+		r := i + 1
+		stock := tview.NewTableCell(fmt.Sprint(c.StockQuantity))
+		if c.ReorderLevel > 0 && c.StockQuantity <= c.ReorderLevel {
+			stock.SetTextColor(tcell.ColorOrange)
+		}
+		lastPurchase := ""
+		if c.LastPurchaseDate != nil {
+			lastPurchase = c.LastPurchaseDate.Format(repo.DateLayout)
+		}
+		p.table.SetCell(r, 0, tview.NewTableCell(c.Name))
+		p.table.SetCell(r, 1, tview.NewTableCell(stringOrEmpty(c.PartNumber)))
+		p.table.SetCell(r, 2, tview.NewTableCell(stringOrEmpty(c.Manufacturer)))
+		p.table.SetCell(r, 3, stock)
+		p.table.SetCell(r, 4, tview.NewTableCell(fmt.Sprint(c.ReorderLevel)))
+		p.table.SetCell(r, 5, tview.NewTableCell(lastPurchase))
+	}
+}
+
+// stringOrEmpty dereferences an optional string
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,225 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// licenseExpiryWindow is how far ahead a license counts as near expiry
+const licenseExpiryWindow = 30 * 24 * time.Hour
+
+// DashboardPage is the landing page showing inventory KPIs
+// Selecting a KPI drills down into the matching pre-filtered list
+type DashboardPage struct {
+	app    *App
+	view   *tview.Flex
+	table  *tview.Table
+	drills map[int]func() // Drill-down of each selectable row
+}
+
+func NewDashboardPage(app *App) *DashboardPage {
+	p := &DashboardPage{app: app}
+	p.build()
+	return p
+}
+
+func (p *DashboardPage) Name() string {
+	return "Dashboard"
+}
+
+func (p *DashboardPage) View() tview.Primitive {
+	return p.view
+}
+
+func (p *DashboardPage) build() {
+	header := tview.NewTextView().
+		SetText("[::b]Dashboard[::-]\nInventory at a glance, Enter opens the matching list").
+		SetDynamicColors(true)
+
+	p.table = tview.NewTable().
+		SetSelectable(true, false)
+	p.table.SetSelectedFunc(func(row, _ int) {
+		if drill, ok := p.drills[row]; ok {
+			drill()
+		}
+	})
+	// Counts change as assets are edited elsewhere, so refresh on every visit
+	p.table.SetFocusFunc(p.reload)
+
+	content := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(header, 2, 0, false).
+		AddItem(nil, 1, 0, false).
+		AddItem(p.table, 0, 1, true)
+
+	p.view = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 1, 0, false).
+		AddItem(
+			tview.NewFlex().
+				SetDirection(tview.FlexColumn).
+				AddItem(nil, 2, 0, false).
+				AddItem(content, 0, 1, true).
+				AddItem(nil, 2, 0, false),
+			0, 1, true).
+		AddItem(nil, 1, 0, false)
+}
+
+// reload recomputes every KPI
+func (p *DashboardPage) reload() {
+	selected, _ := p.table.GetSelection()
+	p.table.Clear()
+	p.drills = make(map[int]func())
+
+	assetRepo := p.app.stores.Assets
+	today := truncateToDay(time.Now())
+
+	addSection(p.table, "Assets by status")
+	statuses, err := assetRepo.CountByStatus()
+	addError(p.table, err)
+	for _, s := range statuses {
+		q := repo.AssetQuery{StatusID: s.ID}
+		p.addKPI(s.Name, s.Count, tcell.ColorWhite, p.assetDrill(s.Name, q))
+	}
+
+	addSection(p.table, "Assets by category")
+	categories, err := assetRepo.CountByCategory()
+	addError(p.table, err)
+	for _, c := range categories {
+		if c.Count == 0 {
+			continue
+		}
+		q := repo.AssetQuery{CategoryID: c.ID}
+		p.addKPI(c.Name, c.Count, tcell.ColorWhite, p.assetDrill(c.Name, q))
+	}
+
+	addSection(p.table, "Needs attention")
+	p.addAssetKPI(assetRepo, "Under maintenance", repo.AssetQuery{StatusID: models.StatusUnderMaintenance})
+	for _, days := range p.app.cfg.WarrantyLeadDays {
+		to := today.AddDate(0, 0, days)
+		p.addAssetKPI(assetRepo, fmt.Sprintf("Warranties expiring within %d days", days),
+			repo.AssetQuery{WarrantyEndFrom: &today, WarrantyEndTo: &to, ExcludeRetired: true})
+	}
+	p.addAssetKPI(assetRepo, "Overdue loans", repo.AssetQuery{OverdueOn: &today})
+
+	licenses, err := p.app.stores.Licenses.List()
+	addError(p.table, err)
+	nearExpiry := func(l *models.SoftwareLicense) bool {
+		return l.ExpirationDate != nil && !l.ExpirationDate.Before(today) &&
+			l.ExpirationDate.Sub(today) <= licenseExpiryWindow
+	}
+	overAllocated := func(l *models.SoftwareLicense) bool {
+		return l.SeatsUsed > l.SeatsPurchased
+	}
+	p.addLicenseKPI("Licenses expiring within 30 days", licenses, nearExpiry)
+	p.addLicenseKPI("Licenses over-allocated", licenses, overAllocated)
+
+	lowStock, err := p.app.stores.Consumables.ListLowStock()
+	addError(p.table, err)
+	p.addKPI("Consumables low on stock", len(lowStock), attentionColor(len(lowStock)), func() {
+		p.app.switchTo(p.app.consumables.Name())
+		p.app.consumables.ShowLowStock(true)
+	})
+
+	if selected > 0 {
+		p.table.Select(selected, 0)
+	}
+}
+
+// addAssetKPI adds a count of the assets matching q that drills down into
+// the assets table
+func (p *DashboardPage) addAssetKPI(assetRepo repo.AssetStore, label string, q repo.AssetQuery) {
+	count, err := assetRepo.CountSummaries(q)
+	addError(p.table, err)
+	p.addKPI(label, count, attentionColor(count), p.assetDrill(label, q))
+}
+
+// addLicenseKPI adds a count of the licenses matching keep that drills down
+// into the licenses table
+func (p *DashboardPage) addLicenseKPI(label string, licenses []*models.SoftwareLicense, keep func(*models.SoftwareLicense) bool) {
+	count := 0
+	for _, l := range licenses {
+		if keep(l) {
+			count++
+		}
+	}
+	p.addKPI(label, count, attentionColor(count), func() {
+		p.app.switchTo(p.app.licenses.Name())
+		p.app.licenses.ShowFiltered(label, keep)
+	})
+}
+
+// assetDrill returns a drill-down into the assets table restricted to q
+func (p *DashboardPage) assetDrill(label string, q repo.AssetQuery) func() {
+	return func() {
+		p.app.switchTo(p.app.assets.Name())
+		p.app.assets.ShowPreset(label, q)
+	}
+}
+
+// addSection adds a non-selectable section title to a report table,
+// preceded by a blank line unless it is the first row
+func addSection(t *tview.Table, title string) {
+	row := t.GetRowCount()
+	if row > 0 {
+		row++
+	}
+	t.SetCell(row, 0, tview.NewTableCell(title).
+		SetTextColor(tcell.ColorYellow).
+		SetAttributes(tcell.AttrBold).
+		SetSelectable(false))
+}
+
+// addColumnHeaders adds a non-selectable row of column titles to a report
+// table
+func addColumnHeaders(t *tview.Table, titles ...string) {
+	row := t.GetRowCount()
+	for col, title := range titles {
+		if col == 0 {
+			title = "  " + title
+		}
+		t.SetCell(row, col, tview.NewTableCell(title).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false))
+	}
+}
+
+// addError adds a row describing a failed query to a report table, if any
+func addError(t *tview.Table, err error) {
+	if err == nil {
+		return
+	}
+	t.SetCell(t.GetRowCount(), 0, tview.NewTableCell("  Error: "+err.Error()).
+		SetTextColor(tcell.ColorRed).
+		SetSelectable(false))
+}
+
+// addKPI adds a selectable row with a label and its count
+func (p *DashboardPage) addKPI(label string, count int, color tcell.Color, drill func()) {
+	row := p.table.GetRowCount()
+	p.table.SetCell(row, 0, tview.NewTableCell("  "+label).SetExpansion(1))
+	p.table.SetCell(row, 1, tview.NewTableCell(fmt.Sprint(count)).
+		SetTextColor(color).
+		SetAlign(tview.AlignRight))
+	p.drills[row] = drill
+}
+
+// attentionColor highlights counts that need action
+func attentionColor(count int) tcell.Color {
+	if count > 0 {
+		return tcell.ColorOrange
+	}
+	return tcell.ColorGreen
+}
+
+// truncateToDay returns the calendar day of t as a UTC date, like the dates
+// parsed from the database
+func truncateToDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"github.com/MawCeron/it-room/internal/ui/keymap"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showHelp displays an overlay listing every registered action with its keys,
+// grouped by scope with the global actions first
+// Calling it again while the overlay is open closes it
+func (a *App) showHelp() {
+	if a.root.HasPage("help") {
+		a.root.RemovePage("help")
+		return
+	}
+
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	for col, h := range []string{"Scope", "Keys", "Action", "ID"} {
+		table.SetCell(0, col, tview.NewTableCell(h).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+
+	row := 1
+	scopes := []string{keymap.GlobalScope}
+	byScope := map[string][]*keymap.Action{keymap.GlobalScope: nil}
+	for _, action := range a.keys.Actions() {
+		if len(action.Bound()) == 0 {
+			continue
+		}
+		if _, ok := byScope[action.Scope]; !ok {
+			scopes = append(scopes, action.Scope)
+		}
+		byScope[action.Scope] = append(byScope[action.Scope], action)
+	}
+	for _, scope := range scopes {
+		for i, action := range byScope[scope] {
+			scopeName := ""
+			if i == 0 {
+				scopeName = scope
+			}
+			table.SetCell(row, 0, tview.NewTableCell(scopeName).SetTextColor(tcell.ColorDodgerBlue))
+			table.SetCell(row, 1, tview.NewTableCell(a.keys.KeyNames(action.ID)))
+			table.SetCell(row, 2, tview.NewTableCell(action.Description))
+			table.SetCell(row, 3, tview.NewTableCell(action.ID).SetTextColor(tcell.ColorGray))
+			row++
+		}
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == 'q' {
+			a.root.RemovePage("help")
+			return nil
+		}
+		return event
+	})
+	table.SetBorder(true).SetTitle(" Keyboard Shortcuts (Esc to close) ")
+
+	layout := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(table, 0, 3, true).
+			AddItem(nil, 0, 1, false), 100, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	a.root.AddPage("help", layout, true, true)
+}
@@ -0,0 +1,192 @@
+// Package keymap keeps the registry of user actions and the keys bound to
+// them. Pages register their actions under a scope; the registry resolves
+// user overrides from the configuration file, dispatches key events and
+// describes the bindings for the help overlay and status bars.
+package keymap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// GlobalScope holds the actions available on every page
+const GlobalScope = "Global"
+
+// Action is a user command bound to one or more keys
+type Action struct {
+	ID          string   // Stable identifier used in the configuration file
+	Scope       string   // Where the action applies, e.g. GlobalScope or "Assets"
+	Description string   // Short label shown in the help overlay and status bars
+	Keys        []string // Default keys, see ParseKey
+	Handler     func()
+
+	// Remap, when set, translates the action's keys into this key instead
+	// of running Handler (used for alternative navigation keys)
+	Remap tcell.Key
+
+	// Available, when set, reports whether the action can run right now,
+	// e.g. only while its screen is open; unavailable actions are left out
+	// of the command palette and their keys pass through
+	Available func() bool
+
+	bound []Key
+}
+
+// Bound returns the keys the action is bound to after applying overrides
+func (a *Action) Bound() []Key {
+	return a.bound
+}
+
+// Registry holds every registered action and dispatches key events to them
+type Registry struct {
+	actions   []*Action
+	overrides map[string][]string
+	byScope   map[string]map[Key]*Action
+	errs      []error
+}
+
+// New creates a registry applying the given key overrides by action ID
+func New(overrides map[string][]string) *Registry {
+	return &Registry{
+		overrides: overrides,
+		byScope:   make(map[string]map[Key]*Action),
+	}
+}
+
+// Register adds an action, binding its default keys or the user's override
+// Unknown keys and conflicts within a scope are reported by Err
+func (r *Registry) Register(a Action) {
+	action := &a
+	keys := a.Keys
+	if override, ok := r.overrides[a.ID]; ok {
+		keys = override
+	}
+
+	bindings := r.byScope[a.Scope]
+	if bindings == nil {
+		bindings = make(map[Key]*Action)
+		r.byScope[a.Scope] = bindings
+	}
+
+	for _, s := range keys {
+		k, err := ParseKey(s)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("%s: %w", a.ID, err))
+			continue
+		}
+		if other, ok := bindings[k]; ok {
+			r.errs = append(r.errs, fmt.Errorf("%s: key %s is already bound to %s", a.ID, k, other.ID))
+			continue
+		}
+		bindings[k] = action
+		action.bound = append(action.bound, k)
+	}
+
+	r.actions = append(r.actions, action)
+}
+
+// Err reports invalid or conflicting bindings and overrides for actions
+// that were never registered
+func (r *Registry) Err() error {
+	errs := append([]error(nil), r.errs...)
+
+	var ids []string
+	for id := range r.overrides {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if r.Action(id) == nil {
+			errs = append(errs, fmt.Errorf("%s: unknown action", id))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Actions returns every action in registration order
+func (r *Registry) Actions() []*Action {
+	return r.actions
+}
+
+// Runnable returns the actions that can be run from the command palette:
+// every action with a handler whose Available check passes
+func (r *Registry) Runnable() []*Action {
+	var out []*Action
+	for _, a := range r.actions {
+		if a.Handler == nil || a.Remap != 0 {
+			continue
+		}
+		if a.Available != nil && !a.Available() {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// Action returns the action with the given ID, or nil
+func (r *Registry) Action(id string) *Action {
+	for _, a := range r.actions {
+		if a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+// Handle runs the action bound to the event in scope
+// It returns nil when a handler ran, a translated event for remapping
+// actions, and the original event when nothing available is bound to it
+func (r *Registry) Handle(scope string, event *tcell.EventKey) *tcell.EventKey {
+	a, ok := r.byScope[scope][KeyOf(event)]
+	if !ok || a.Available != nil && !a.Available() {
+		return event
+	}
+	if a.Remap != 0 {
+		return tcell.NewEventKey(a.Remap, 0, tcell.ModNone)
+	}
+	if a.Handler != nil {
+		a.Handler()
+	}
+	return nil
+}
+
+// Capture returns an input capture function dispatching to scope
+func (r *Registry) Capture(scope string) func(event *tcell.EventKey) *tcell.EventKey {
+	return func(event *tcell.EventKey) *tcell.EventKey {
+		return r.Handle(scope, event)
+	}
+}
+
+// KeyNames returns the keys bound to an action joined for display
+func (r *Registry) KeyNames(id string) string {
+	a := r.Action(id)
+	if a == nil {
+		return ""
+	}
+	names := make([]string, len(a.bound))
+	for i, k := range a.bound {
+		names[i] = k.String()
+	}
+	return strings.Join(names, "/")
+}
+
+// Hints formats the first key and description of each action for a status
+// bar; unbound actions are left out
+func (r *Registry) Hints(ids ...string) string {
+	var parts []string
+	for _, id := range ids {
+		a := r.Action(id)
+		if a == nil || len(a.bound) == 0 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("[yellow]%s[white] %s", tview.Escape(a.bound[0].String()), a.Description))
+	}
+	return strings.Join(parts, "  ")
+}
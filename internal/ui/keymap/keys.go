@@ -0,0 +1,66 @@
+package keymap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Key identifies a key press independently of its modifiers
+// Rune is only set for tcell.KeyRune
+type Key struct {
+	Key  tcell.Key
+	Rune rune
+}
+
+// keysByName maps lowercased tcell key names to their keys
+var keysByName = func() map[string]tcell.Key {
+	m := make(map[string]tcell.Key, len(tcell.KeyNames))
+	for k, name := range tcell.KeyNames {
+		m[strings.ToLower(name)] = k
+	}
+	return m
+}()
+
+// ParseKey parses a key as written in the configuration file: a single
+// character ("n", "?"), "Space", or a tcell key name such as "Enter", "Esc",
+// "PgDn", "F1" or "Ctrl+Q"
+func ParseKey(s string) (Key, error) {
+	if r := []rune(s); len(r) == 1 {
+		return Key{Key: tcell.KeyRune, Rune: r[0]}, nil
+	}
+
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "space" {
+		return Key{Key: tcell.KeyRune, Rune: ' '}, nil
+	}
+	name = strings.Replace(name, "ctrl+", "ctrl-", 1)
+	if k, ok := keysByName[name]; ok {
+		return Key{Key: k}, nil
+	}
+
+	return Key{}, fmt.Errorf("unknown key %q", s)
+}
+
+// KeyOf returns the key of a key event
+func KeyOf(event *tcell.EventKey) Key {
+	if event.Key() == tcell.KeyRune {
+		return Key{Key: tcell.KeyRune, Rune: event.Rune()}
+	}
+	return Key{Key: event.Key()}
+}
+
+// String returns the key as displayed in the help overlay and status bars
+func (k Key) String() string {
+	if k.Key == tcell.KeyRune {
+		if k.Rune == ' ' {
+			return "Space"
+		}
+		return string(k.Rune)
+	}
+	if name, ok := tcell.KeyNames[k.Key]; ok {
+		return strings.Replace(name, "Ctrl-", "Ctrl+", 1)
+	}
+	return fmt.Sprintf("Key[%d]", k.Key)
+}
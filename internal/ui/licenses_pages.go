@@ -1,17 +1,33 @@
 package ui
 
 import (
-	"github.com/MawCeron/it-room/internal/db"
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/ui/keymap"
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
 type LicensesPage struct {
-	view *tview.Flex
-	db   *db.DB
+	view     *tview.Flex
+	store    repo.LicenseStore
+	keys     *keymap.Registry
+	header   *tview.TextView
+	table    *tview.Table
+	licenses []*models.SoftwareLicense
+
+	keep      func(*models.SoftwareLicense) bool // Drill-down filter, nil shows all
+	keepLabel string
 }
 
-func NewLicensesPage(db *db.DB) *LicensesPage {
-	p := &LicensesPage{db: db}
+func NewLicensesPage(store repo.LicenseStore, keys *keymap.Registry) *LicensesPage {
+	p := &LicensesPage{store: store, keys: keys}
+	keys.Register(keymap.Action{
+		ID: "licenses.clear_filter", Scope: "Licenses", Description: "Clear filter",
+		Keys: []string{"x", "X"}, Handler: func() { p.ShowFiltered("", nil) },
+	})
 	p.build()
 	return p
 }
@@ -24,15 +40,41 @@ func (p *LicensesPage) View() tview.Primitive {
 	return p.view
 }
 
+// ShowFiltered lists only the licenses for which keep returns true and
+// names the filter in the header; a nil keep shows every license
+func (p *LicensesPage) ShowFiltered(label string, keep func(*models.SoftwareLicense) bool) {
+	p.keep = keep
+	p.keepLabel = label
+	p.reload()
+}
+
+// FocusLicense reloads the table and selects the given license
+func (p *LicensesPage) FocusLicense(licenseID int) {
+	p.keep = nil
+	p.keepLabel = ""
+	p.reload()
+	for i, l := range p.licenses {
+		if l.LicenseID == licenseID {
+			p.table.Select(i+1, 0)
+		}
+	}
+}
+
 func (p *LicensesPage) build() {
-	header := tview.NewTextView().
-		SetText("[::b]Licenses[::-]\nLicense management and assignments").
+	p.header = tview.NewTextView().
 		SetDynamicColors(true)
 
+	p.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	p.table.SetInputCapture(p.keys.Capture("Licenses"))
+	p.reload()
+
 	content := tview.NewFlex().
 		SetDirection(tview.FlexRow).
-		AddItem(header, 2, 0, false).
-		AddItem(nil, 1, 0, false)
+		AddItem(p.header, 2, 0, false).
+		AddItem(nil, 1, 0, false).
+		AddItem(p.table, 0, 1, true)
 
 	p.view = tview.NewFlex().
 		SetDirection(tview.FlexRow).
@@ -46,3 +88,50 @@ func (p *LicensesPage) build() {
 			0, 1, true).
 		AddItem(nil, 1, 0, false)
 }
+
+// reload fills the table with every license and its seat usage
+// Over-allocated seats are shown in red
+func (p *LicensesPage) reload() {
+	subtitle := "License management and assignments"
+	if p.keepLabel != "" {
+		subtitle = tview.Escape(p.keepLabel) + "  [gray](x to show all)[-]"
+	}
+	p.header.SetText("[::b]Licenses[::-]\n" + subtitle)
+
+	p.table.Clear()
+	for col, h := range []string{"Software", "Type", "Seats", "Purchased", "Expires"} {
+		p.table.SetCell(0, col, tview.NewTableCell(h).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+
+	licenses, err := p.store.List()
+	if err != nil {
+		p.table.SetCell(1, 0, tview.NewTableCell("Error: "+err.Error()).SetTextColor(tcell.ColorRed))
+		return
+	}
+	p.licenses = nil
+	for _, l := range licenses {
+		if p.keep == nil || p.keep(l) {
+			p.licenses = append(p.licenses, l)
+		}
+	}
+
+	for i, l := range p.licenses {
+		r := i + 1
+		seats := tview.NewTableCell(fmt.Sprintf("%d/%d", l.SeatsUsed, l.SeatsPurchased))
+		if l.SeatsUsed > l.SeatsPurchased {
+			seats.SetTextColor(tcell.ColorRed)
+		}
+		expires := ""
+		if l.ExpirationDate != nil {
+			expires = l.ExpirationDate.Format(repo.DateLayout)
+		}
+		p.table.SetCell(r, 0, tview.NewTableCell(l.SoftwareName))
+		p.table.SetCell(r, 1, tview.NewTableCell(l.LicenseType))
+		p.table.SetCell(r, 2, seats)
+		p.table.SetCell(r, 3, tview.NewTableCell(l.PurchaseDate.Format(repo.DateLayout)))
+		p.table.SetCell(r, 4, tview.NewTableCell(expires))
+	}
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MawCeron/it-room/internal/auth"
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// loginPage is the name of the sign in overlay on the root pages
+const loginPage = "login"
+
+// showLogin covers the app with a sign in form until a user signs in
+func (a *App) showLogin() {
+	message := tview.NewTextView().SetDynamicColors(true)
+	form := tview.NewForm()
+	form.AddInputField("Username", "", 30, nil, nil)
+	form.AddPasswordField("Password", "", 30, '*', nil)
+	form.AddButton("Sign in", func() {
+		username := form.GetFormItemByLabel("Username").(*tview.InputField).GetText()
+		password := form.GetFormItemByLabel("Password").(*tview.InputField).GetText()
+		u, err := auth.Login(a.stores.Users, username, password)
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			message.SetText("[red]Invalid username or password")
+			form.GetFormItemByLabel("Password").(*tview.InputField).SetText("")
+			return
+		} else if err != nil {
+			message.SetText("[red]" + tview.Escape(err.Error()))
+			return
+		}
+		a.signIn(u)
+	})
+	form.AddButton("Quit", a.app.Stop)
+
+	box := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(form, 7, 0, true).
+		AddItem(message, 1, 0, false)
+	box.SetBorder(true).SetTitle(" IT Room - Sign in ").SetBorderPadding(0, 0, 1, 1)
+
+	layout := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(box, 10, 0, true).
+			AddItem(nil, 0, 1, false), 50, 0, true).
+		AddItem(nil, 0, 1, false)
+	layout.SetBackgroundColor(tcell.ColorDefault)
+
+	a.root.AddPage(loginPage, layout, true, true)
+}
+
+// signIn makes u the acting user and reveals the app
+func (a *App) signIn(u *models.User) {
+	a.user = u
+	a.assets.SetUser(u)
+	a.frame.AddText(fmt.Sprintf("%s (%s)", u.Username, u.Role), false, tview.AlignLeft, tcell.ColorGray)
+	a.root.RemovePage(loginPage)
+	a.app.SetFocus(a.pages)
+}
+
+// signingIn reports whether the sign in form is showing
+func (a *App) signingIn() bool {
+	name, _ := a.root.GetFrontPage()
+	return name == loginPage
+}
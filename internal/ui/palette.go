@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// paletteSearchLimit caps the entities listed per kind in the command palette
+const paletteSearchLimit = 5
+
+// paletteItem is an entry of the command palette
+type paletteItem struct {
+	Title  string
+	Detail string
+	Score  int
+	Run    func()
+}
+
+// showPalette opens the command palette: a fuzzy search over the runnable
+// actions plus a jump to assets, employees and licenses by tag or name
+func (a *App) showPalette() {
+	if a.root.HasPage("palette") {
+		return
+	}
+
+	input := tview.NewInputField().
+		SetLabel("> ").
+		SetFieldWidth(0)
+	list := tview.NewList().
+		ShowSecondaryText(true).
+		SetHighlightFullLine(true)
+
+	var items []paletteItem
+	update := func(query string) {
+		items = a.paletteItems(query)
+		list.Clear()
+		for _, item := range items {
+			list.AddItem(item.Title, item.Detail, 0, nil)
+		}
+	}
+	run := func(index int) {
+		if index < 0 || index >= len(items) {
+			return
+		}
+		a.root.RemovePage("palette")
+		items[index].Run()
+	}
+
+	input.SetChangedFunc(update)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.root.RemovePage("palette")
+			return nil
+		case tcell.KeyEnter:
+			run(list.GetCurrentItem())
+			return nil
+		case tcell.KeyDown, tcell.KeyUp, tcell.KeyPgDn, tcell.KeyPgUp:
+			list.InputHandler()(event, nil)
+			return nil
+		}
+		return event
+	})
+	list.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		run(index)
+	})
+	update("")
+
+	box := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+	box.SetBorder(true).SetTitle(" Command Palette ")
+
+	layout := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 2, 0, false).
+			AddItem(box, 20, 0, true).
+			AddItem(nil, 0, 1, false), 70, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	a.root.AddPage("palette", layout, true, true)
+}
+
+// paletteItems returns the entries matching query, best matches first
+// Entities are only searched once the query has at least two characters
+func (a *App) paletteItems(query string) []paletteItem {
+	var items []paletteItem
+
+	for _, action := range a.keys.Runnable() {
+		if action.ID == "app.palette" {
+			continue
+		}
+		score, ok := fuzzyScore(query, action.Description)
+		if !ok {
+			continue
+		}
+		detail := action.Scope
+		if keys := a.keys.KeyNames(action.ID); keys != "" {
+			detail += " · " + keys
+		}
+		items = append(items, paletteItem{
+			Title:  action.Description,
+			Detail: detail,
+			Score:  score,
+			Run:    action.Handler,
+		})
+	}
+
+	if len([]rune(strings.TrimSpace(query))) >= 2 {
+		items = append(items, a.entityItems(strings.TrimSpace(query))...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Score > items[j].Score
+	})
+	return items
+}
+
+// entityItems searches assets, employees and licenses matching text
+// Search errors are skipped; the palette just shows fewer entries
+func (a *App) entityItems(text string) []paletteItem {
+	var items []paletteItem
+
+	assetList, _, _ := a.stores.Assets.PageSummaries(repo.AssetQuery{Filter: text}, nil, paletteSearchLimit)
+	for _, asset := range assetList {
+		score, _ := fuzzyScore(text, asset.AssetTag)
+		items = append(items, paletteItem{
+			Title:  "Asset " + asset.AssetTag,
+			Detail: fmt.Sprintf("%s %s · %s · %s", asset.Maker, asset.Model, asset.TypeName, asset.StatusName),
+			Score:  score,
+			Run: func() {
+				a.switchTo(a.assets.Name())
+				a.assets.OpenAsset(asset.AssetID)
+			},
+		})
+	}
+
+	employees, _ := a.stores.Employees.Search(text, paletteSearchLimit)
+	for _, e := range employees {
+		score, _ := fuzzyScore(text, e.FullName)
+		items = append(items, paletteItem{
+			Title:  "Employee " + e.FullName,
+			Detail: e.Email + " · show held assets",
+			Score:  score,
+			Run: func() {
+				a.switchTo(a.assets.Name())
+				a.assets.ShowFiltered(e.FullName)
+			},
+		})
+	}
+
+	licenses, _ := a.stores.Licenses.Search(text, paletteSearchLimit)
+	for _, l := range licenses {
+		score, _ := fuzzyScore(text, l.SoftwareName)
+		items = append(items, paletteItem{
+			Title:  "License " + l.SoftwareName,
+			Detail: licenseDetail(l),
+			Score:  score,
+			Run: func() {
+				a.switchTo(a.licenses.Name())
+				a.licenses.FocusLicense(l.LicenseID)
+			},
+		})
+	}
+
+	return items
+}
+
+// licenseDetail summarizes a license for the palette
+func licenseDetail(l *models.SoftwareLicense) string {
+	return fmt.Sprintf("%s · %d/%d seats", l.LicenseType, l.SeatsUsed, l.SeatsPurchased)
+}
+
+// fuzzyScore reports whether every rune of query appears in text in order,
+// ignoring case, and scores the match: consecutive runes and matches at the
+// start of words score higher. An empty query matches everything.
+func fuzzyScore(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(text))
+
+	score, qi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if q[qi] == ' ' {
+			qi++
+			if qi == len(q) {
+				break
+			}
+		}
+		if t[ti] != q[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 3
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 5
+		}
+		prev = ti
+		qi++
+	}
+
+	return score, qi == len(q)
+}
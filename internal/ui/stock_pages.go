@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/ui/keymap"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type StockPage struct {
+	view   *tview.Flex
+	store  repo.StockStore
+	keys   *keymap.Registry
+	header *tview.TextView
+	table  *tview.Table
+	detail *tview.TextView // Units of the selected item by location and holder
+	items  []*models.StockItem
+}
+
+func NewStockPage(store repo.StockStore, keys *keymap.Registry) *StockPage {
+	p := &StockPage{store: store, keys: keys}
+	p.build()
+	return p
+}
+
+func (p *StockPage) Name() string {
+	return "Stock"
+}
+
+func (p *StockPage) View() tview.Primitive {
+	return p.view
+}
+
+func (p *StockPage) build() {
+	p.header = tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[::b]Stock[::-]\nAccessories tracked by quantity instead of serial number")
+
+	p.detail = tview.NewTextView().
+		SetDynamicColors(true)
+
+	p.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	p.table.SetInputCapture(p.keys.Capture("Stock"))
+	p.table.SetSelectionChangedFunc(func(row, _ int) { p.showDetail(row) })
+	p.reload()
+
+	content := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(p.header, 2, 0, false).
+		AddItem(nil, 1, 0, false).
+		AddItem(p.table, 0, 1, true).
+		AddItem(p.detail, 3, 0, false)
+
+	p.view = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 1, 0, false).
+		AddItem(
+			tview.NewFlex().
+				SetDirection(tview.FlexColumn).
+				AddItem(nil, 2, 0, false).
+				AddItem(content, 0, 1, true).
+				AddItem(nil, 2, 0, false),
+			0, 1, true).
+		AddItem(nil, 1, 0, false)
+}
+
+// reload fills the table with the stock items and their totals
+// Items at or below their reorder level are shown in orange
+func (p *StockPage) reload() {
+	p.table.Clear()
+	for col, h := range []string{"Item", "Type", "On Hand", "Issued", "Reorder At"} {
+		p.table.SetCell(0, col, tview.NewTableCell(h).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+
+	var err error
+	p.items, err = p.store.ListItems()
+	if err != nil {
+		p.table.SetCell(1, 0, tview.NewTableCell("Error: "+err.Error()).SetTextColor(tcell.ColorRed))
+		return
+	}
+
+	for i, item := range p.items {
+		r := i + 1
+		onHand := tview.NewTableCell(fmt.Sprint(item.OnHand))
+		if item.ReorderLevel > 0 && item.OnHand <= item.ReorderLevel {
+			onHand.SetTextColor(tcell.ColorOrange)
+		}
+		p.table.SetCell(r, 0, tview.NewTableCell(item.Name))
+		p.table.SetCell(r, 1, tview.NewTableCell(stringOrEmpty(item.TypeName)))
+		p.table.SetCell(r, 2, onHand)
+		p.table.SetCell(r, 3, tview.NewTableCell(fmt.Sprint(item.Issued)))
+		p.table.SetCell(r, 4, tview.NewTableCell(fmt.Sprint(item.ReorderLevel)))
+	}
+	p.showDetail(1)
+}
+
+// showDetail lists where the units of the item on a table row are
+func (p *StockPage) showDetail(row int) {
+	if row < 1 || row > len(p.items) {
+		p.detail.SetText("")
+		return
+	}
+	item := p.items[row-1]
+	levels, err := p.store.ListLevels(item.ItemID)
+	if err != nil {
+		p.detail.SetText("[red]Error: " + err.Error())
+		return
+	}
+	holdings, err := p.store.ListHoldings(item.ItemID)
+	if err != nil {
+		p.detail.SetText("[red]Error: " + err.Error())
+		return
+	}
+
+	var at, held []string
+	for _, l := range levels {
+		at = append(at, fmt.Sprintf("%s %d", l.LocationName, l.Quantity))
+	}
+	for _, h := range holdings {
+		held = append(held, fmt.Sprintf("%s %d", h.EmployeeName, h.Quantity))
+	}
+	p.detail.SetText(fmt.Sprintf("[gray]In stock:[-] %s\n[gray]Held by:[-]  %s",
+		orNone(strings.Join(at, ", ")), orNone(strings.Join(held, ", "))))
+}
+
+// orNone returns s, or "none" when it is empty
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,216 @@
+// Package uitest runs the TUI on a simulated screen, so tests can press
+// keys and check what is drawn
+package uitest
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/config"
+	"github.com/MawCeron/it-room/internal/db"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/seed"
+	"github.com/MawCeron/it-room/internal/service"
+	"github.com/MawCeron/it-room/internal/ui"
+	"github.com/MawCeron/it-room/internal/ui/keymap"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Size of the simulated screen
+const (
+	Width  = 160
+	Height = 50
+)
+
+// Timeout bounds how long WaitFor waits for text to be drawn
+var Timeout = 3 * time.Second
+
+// Harness runs the app over a new database in a temporary directory
+type Harness struct {
+	t      testing.TB
+	svc    *service.Service
+	cfg    *config.Config
+	app    *ui.App
+	screen tcell.SimulationScreen
+	done   chan error // Receives the result of the app's Run
+}
+
+// New returns a harness over an empty database with the default settings
+// Records added through Service before Start are shown by the app
+func New(t testing.TB) *Harness {
+	t.Helper()
+	d, err := db.New(filepath.Join(t.TempDir(), "itroom.db"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return &Harness{t: t, svc: service.New(repo.NewStores(d.Conn)), cfg: config.Default()}
+}
+
+// Service returns the service the app runs over, to add records or check
+// what the app saved
+func (h *Harness) Service() *service.Service {
+	return h.svc
+}
+
+// Seed fills the database with generated demo records, before Start
+func (h *Harness) Seed(assets, employees int) *seed.Summary {
+	h.t.Helper()
+	summary, err := h.svc.Seed(context.Background(), seed.Options{
+		Assets: assets, Employees: employees, Seed: 1, Today: time.Now().UTC(),
+	})
+	if err != nil {
+		h.t.Fatalf("seed: %v", err)
+	}
+	return summary
+}
+
+// Config returns the settings the app starts with, to change them before
+// Start
+func (h *Harness) Config() *config.Config {
+	return h.cfg
+}
+
+// Start runs the app on the simulated screen until the test ends and waits
+// for the menu to be drawn
+func (h *Harness) Start() {
+	h.t.Helper()
+	h.screen = tcell.NewSimulationScreen("")
+	h.app = ui.NewApp(h.svc, h.cfg)
+	h.app.SetScreen(h.screen)
+	h.done = make(chan error, 1)
+	go func() { h.done <- h.app.Run() }()
+	h.t.Cleanup(h.stop)
+
+	// Running the app initializes the screen at its default size
+	h.WaitFor("IT Room")
+	h.screen.SetSize(Width, Height)
+	h.wait(func(screen string) bool {
+		return strings.Count(screen, "\n") == Height-1 && strings.Contains(screen, "IT Room")
+	}, "the app to draw at %dx%d", Width, Height)
+}
+
+// stop quits the app and reports a Run failure
+func (h *Harness) stop() {
+	h.screen.InjectKey(tcell.KeyCtrlQ, 0, tcell.ModCtrl)
+	select {
+	case err := <-h.done:
+		if err != nil {
+			h.t.Errorf("app: %v", err)
+		}
+	case <-time.After(Timeout):
+		h.t.Errorf("app did not quit")
+	}
+}
+
+// Press sends keys as written in the configuration file, e.g. "n", "Enter",
+// "Tab" or "Ctrl+E"
+func (h *Harness) Press(keys ...string) {
+	h.t.Helper()
+	for _, name := range keys {
+		k, err := keymap.ParseKey(name)
+		if err != nil {
+			h.t.Fatalf("press: %v", err)
+		}
+		mod := tcell.ModNone
+		if strings.HasPrefix(strings.ToLower(name), "ctrl+") {
+			mod = tcell.ModCtrl
+		}
+		h.screen.InjectKey(k.Key, k.Rune, mod)
+	}
+}
+
+// Type sends text one character at a time
+func (h *Harness) Type(text string) {
+	for _, r := range text {
+		h.screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+	}
+}
+
+// Screen returns the drawn text, one line per screen row with trailing
+// spaces removed
+// The cells are read on the event loop, which draws them
+func (h *Harness) Screen() string {
+	h.t.Helper()
+	read := make(chan string, 1)
+	go h.app.QueueUpdate(func() { read <- h.contents() })
+	select {
+	case screen := <-read:
+		return screen
+	case <-time.After(Timeout):
+		h.t.Fatalf("timed out reading the screen")
+		return ""
+	}
+}
+
+// contents renders the cells of the screen as text
+func (h *Harness) contents() string {
+	cells, width, height := h.screen.GetContents()
+	lines := make([]string, height)
+	for y := range height {
+		var b strings.Builder
+		for x := range width {
+			if r := cells[y*width+x].Runes; len(r) > 0 {
+				b.WriteRune(r[0])
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		lines[y] = strings.TrimRight(b.String(), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WaitFor waits until every text is drawn, failing the test with the
+// screen once Timeout passes
+func (h *Harness) WaitFor(texts ...string) {
+	h.t.Helper()
+	h.wait(func(screen string) bool {
+		for _, text := range texts {
+			if !strings.Contains(screen, text) {
+				return false
+			}
+		}
+		return true
+	}, "%q to be drawn", texts)
+}
+
+// WaitGone waits until text is no longer drawn, failing the test with the
+// screen once Timeout passes
+// Keys are handled in the background, so wait for text the keys will
+// remove to be drawn first
+func (h *Harness) WaitGone(text string) {
+	h.t.Helper()
+	h.wait(func(screen string) bool {
+		return !strings.Contains(screen, text)
+	}, "%q to go away", text)
+}
+
+// wait polls the screen until ok accepts it
+func (h *Harness) wait(ok func(screen string) bool, format string, args ...any) {
+	h.t.Helper()
+	deadline := time.Now().Add(Timeout)
+	for {
+		screen := h.Screen()
+		if ok(screen) {
+			return
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("timed out waiting for "+format+", screen:\n%s", append(args, screen)...)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Row returns the first screen line containing text, or an empty string
+func (h *Harness) Row(text string) string {
+	for _, line := range strings.Split(h.Screen(), "\n") {
+		if strings.Contains(line, text) {
+			return line
+		}
+	}
+	return ""
+}
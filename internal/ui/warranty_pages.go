@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MawCeron/it-room/internal/models"
+	"github.com/MawCeron/it-room/internal/repo"
+	"github.com/MawCeron/it-room/internal/ui/assets"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// WarrantyPage lists upcoming warranty expirations, grouped by the
+// configured lead times, and a report of warranty states by vendor
+type WarrantyPage struct {
+	app    *App
+	view   *tview.Flex
+	header *tview.TextView
+	table  *tview.Table
+	drills map[int]func() // Drill-down of each selectable row
+}
+
+func NewWarrantyPage(app *App) *WarrantyPage {
+	p := &WarrantyPage{app: app}
+	p.build()
+	return p
+}
+
+func (p *WarrantyPage) Name() string {
+	return "Warranty"
+}
+
+func (p *WarrantyPage) View() tview.Primitive {
+	return p.view
+}
+
+func (p *WarrantyPage) build() {
+	p.header = tview.NewTextView().SetDynamicColors(true)
+
+	p.table = tview.NewTable().
+		SetSelectable(true, false)
+	p.table.SetSelectedFunc(func(row, _ int) {
+		if drill, ok := p.drills[row]; ok {
+			drill()
+		}
+	})
+	p.table.SetFocusFunc(p.reload)
+
+	content := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(p.header, 2, 0, false).
+		AddItem(nil, 1, 0, false).
+		AddItem(p.table, 0, 1, true)
+
+	p.view = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 1, 0, false).
+		AddItem(
+			tview.NewFlex().
+				SetDirection(tview.FlexColumn).
+				AddItem(nil, 2, 0, false).
+				AddItem(content, 0, 1, true).
+				AddItem(nil, 2, 0, false),
+			0, 1, true).
+		AddItem(nil, 1, 0, false)
+}
+
+// reload lists the upcoming expirations and recomputes the vendor report
+func (p *WarrantyPage) reload() {
+	selected, _ := p.table.GetSelection()
+	p.table.Clear()
+	p.drills = make(map[int]func())
+
+	leadDays := p.app.cfg.WarrantyLeadDays
+	soonDays := p.app.cfg.WarrantySoonDays()
+	p.header.SetText(fmt.Sprintf("[::b]Warranty[::-]\nExpiring soon means within %d days, Enter opens the asset or vendor", soonDays))
+
+	assetRepo := p.app.stores.Assets
+	today := truncateToDay(time.Now())
+	p.addUpcoming(assetRepo, today, leadDays)
+	p.addVendorReport(assetRepo, today, soonDays)
+
+	if selected > 0 {
+		p.table.Select(selected, 0)
+	}
+}
+
+// addUpcoming lists the assets in service whose warranty ends within the
+// longest lead time, in one group per lead time
+func (p *WarrantyPage) addUpcoming(assetRepo repo.AssetStore, today time.Time, leadDays []int) {
+	if len(leadDays) == 0 {
+		return
+	}
+
+	to := today.AddDate(0, 0, leadDays[len(leadDays)-1])
+	upcoming, err := assetRepo.ListSummaries(repo.AssetQuery{
+		SortColumn:      "warranty_end_date",
+		WarrantyEndFrom: &today,
+		WarrantyEndTo:   &to,
+		ExcludeRetired:  true,
+	})
+
+	addSection(p.table, "Upcoming expirations")
+	addError(p.table, err)
+	addColumnHeaders(p.table, "Asset Tag", "Vendor", "Model", "Location", "Holder", "Ends", "Days Left")
+
+	i := 0
+	for _, days := range leadDays {
+		limit := today.AddDate(0, 0, days)
+		var group []*models.AssetSummary
+		for ; i < len(upcoming) && !upcoming[i].WarrantyEndDate.After(limit); i++ {
+			group = append(group, upcoming[i])
+		}
+
+		p.addGroup(fmt.Sprintf("Within %d days (%d)", days, len(group)))
+		for _, a := range group {
+			p.addUpcomingRow(a, today)
+		}
+	}
+}
+
+// addUpcomingRow adds an asset whose warranty is about to end
+func (p *WarrantyPage) addUpcomingRow(a *models.AssetSummary, today time.Time) {
+	row := p.table.GetRowCount()
+	days := int(a.WarrantyEndDate.Sub(today).Hours() / 24)
+	holder := ""
+	if a.HolderName != nil {
+		holder = *a.HolderName
+	}
+
+	for col, text := range []string{"  " + a.AssetTag, a.Maker, a.Model, a.LocationName, holder,
+		a.WarrantyEndDate.Format(repo.DateLayout), fmt.Sprint(days)} {
+		p.table.SetCell(row, col, tview.NewTableCell(text).
+			SetTextColor(assets.WarrantyColor(models.WarrantyExpiringSoon)))
+	}
+
+	assetID := a.AssetID
+	p.drills[row] = func() {
+		p.app.switchTo(p.app.assets.Name())
+		p.app.assets.OpenAsset(assetID)
+	}
+}
+
+// addVendorReport counts the warranties of assets in service by vendor
+func (p *WarrantyPage) addVendorReport(assetRepo repo.AssetStore, today time.Time, soonDays int) {
+	report, err := assetRepo.WarrantyReport(today, soonDays)
+
+	addSection(p.table, "By vendor")
+	addError(p.table, err)
+	addColumnHeaders(p.table, "Vendor", "Active", "Expiring Soon", "Expired", "None", "Total")
+
+	for _, s := range report {
+		row := p.table.GetRowCount()
+		p.table.SetCell(row, 0, tview.NewTableCell("  "+s.Vendor))
+		counts := []struct {
+			count int
+			color tcell.Color
+		}{
+			{s.Active, assets.WarrantyColor(models.WarrantyActive)},
+			{s.ExpiringSoon, assets.WarrantyColor(models.WarrantyExpiringSoon)},
+			{s.Expired, assets.WarrantyColor(models.WarrantyExpired)},
+			{s.None, assets.WarrantyColor(models.WarrantyNone)},
+			{s.Total(), tcell.ColorWhite},
+		}
+		for i, c := range counts {
+			color := c.color
+			if c.count == 0 {
+				color = tcell.ColorGray
+			}
+			p.table.SetCell(row, i+1, tview.NewTableCell(fmt.Sprint(c.count)).
+				SetTextColor(color).
+				SetAlign(tview.AlignRight))
+		}
+
+		label := "Vendor " + s.Vendor
+		q := repo.AssetQuery{Make: s.Vendor, ExcludeRetired: true}
+		p.drills[row] = func() {
+			p.app.switchTo(p.app.assets.Name())
+			p.app.assets.ShowPreset(label, q)
+		}
+	}
+}
+
+// addGroup adds a non-selectable group title within a section
+func (p *WarrantyPage) addGroup(title string) {
+	p.table.SetCell(p.table.GetRowCount(), 0, tview.NewTableCell(" "+title).
+		SetTextColor(tcell.ColorDodgerBlue).
+		SetSelectable(false))
+}
@@ -0,0 +1,12 @@
+-- ======================================================
+-- Saved table views (columns, sort and filter)
+-- ======================================================
+
+CREATE TABLE IF NOT EXISTS saved_views (
+    view_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    columns TEXT NOT NULL,                      -- Comma-separated column keys
+    sort_column TEXT NOT NULL DEFAULT 'asset_tag',
+    sort_desc INTEGER NOT NULL DEFAULT 0,       -- 0 = ascending, 1 = descending
+    filter TEXT NOT NULL DEFAULT ''
+);
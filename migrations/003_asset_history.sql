@@ -0,0 +1,45 @@
+-- ======================================================
+-- Asset history: location transfers, comments and attachments
+-- ======================================================
+
+-- Location changes (Movement Log)
+CREATE TABLE IF NOT EXISTS asset_transfers (
+    transfer_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    asset_id TEXT NOT NULL,
+    from_location_id INTEGER NOT NULL,
+    to_location_id INTEGER NOT NULL,
+    transfer_date TEXT NOT NULL DEFAULT (datetime('now')),
+    notes TEXT,
+
+    FOREIGN KEY (asset_id) REFERENCES assets(asset_id),
+    FOREIGN KEY (from_location_id) REFERENCES locations(location_id),
+    FOREIGN KEY (to_location_id) REFERENCES locations(location_id)
+);
+
+-- Free-form comments attached to an asset
+CREATE TABLE IF NOT EXISTS asset_comments (
+    comment_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    asset_id TEXT NOT NULL,
+    body TEXT NOT NULL,
+    author TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+
+    FOREIGN KEY (asset_id) REFERENCES assets(asset_id)
+);
+
+-- Files related to an asset (invoices, photos, manuals), stored by path
+CREATE TABLE IF NOT EXISTS asset_attachments (
+    attachment_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    asset_id TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    added_at TEXT NOT NULL DEFAULT (datetime('now')),
+    notes TEXT,
+
+    FOREIGN KEY (asset_id) REFERENCES assets(asset_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_asset_transfers_asset ON asset_transfers(asset_id);
+CREATE INDEX IF NOT EXISTS idx_asset_comments_asset ON asset_comments(asset_id);
+CREATE INDEX IF NOT EXISTS idx_asset_attachments_asset ON asset_attachments(asset_id);
+CREATE INDEX IF NOT EXISTS idx_consumable_usage_asset ON consumable_usage(asset_id);
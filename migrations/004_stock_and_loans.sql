@@ -0,0 +1,12 @@
+-- ======================================================
+-- Consumable stock levels and loan due dates
+-- ======================================================
+
+-- Units on hand and the level at which to reorder
+ALTER TABLE consumable_types ADD COLUMN stock_quantity INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE consumable_types ADD COLUMN reorder_level INTEGER NOT NULL DEFAULT 0;
+
+-- Expected return date for loaned assets (NULL for permanent assignments)
+ALTER TABLE asset_assignments ADD COLUMN due_date TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_assets_warranty_end ON assets(warranty_end_date);
@@ -0,0 +1,34 @@
+-- ======================================================
+-- User accounts, API tokens and change attribution
+-- ======================================================
+
+-- Local accounts with a role of viewer, technician or admin
+CREATE TABLE IF NOT EXISTS users (
+    user_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'technician', 'admin')),
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+-- Bearer tokens for the API, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS api_tokens (
+    token_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    last_used_at TEXT,
+
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Username of the user behind each change, NULL when nobody was signed in
+ALTER TABLE assets ADD COLUMN created_by TEXT;
+ALTER TABLE assets ADD COLUMN updated_by TEXT;
+ALTER TABLE employees ADD COLUMN created_by TEXT;
+ALTER TABLE asset_assignments ADD COLUMN assigned_by TEXT;
+ALTER TABLE asset_assignments ADD COLUMN returned_by TEXT;
+ALTER TABLE asset_transfers ADD COLUMN transferred_by TEXT;
+ALTER TABLE maintenance_logs ADD COLUMN recorded_by TEXT;
+ALTER TABLE asset_attachments ADD COLUMN added_by TEXT;
@@ -0,0 +1,552 @@
+-- ======================================================
+-- Audit trail of every change to the inventory
+-- ======================================================
+
+-- One row per inserted, updated or deleted record with its values before
+-- and after the change as JSON objects. Triggers fill it in, taking the
+-- actor from the record's attribution column when it has one.
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity TEXT NOT NULL,                       -- Table name
+    entity_id TEXT NOT NULL,                    -- Primary key of the record
+    asset_id TEXT,                              -- Asset the record belongs to, if any
+    operation TEXT NOT NULL CHECK (operation IN ('insert', 'update', 'delete')),
+    actor TEXT,                                 -- Username, NULL when unknown
+    changed_at TEXT NOT NULL DEFAULT (datetime('now')),
+    before_json TEXT,                           -- NULL for inserts
+    after_json TEXT                             -- NULL for deletes
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_asset ON audit_log(asset_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_changed_at ON audit_log(changed_at);
+
+-- assets
+CREATE TRIGGER IF NOT EXISTS audit_assets_insert AFTER INSERT ON assets
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('assets', NEW.asset_id, NEW.asset_id, 'insert', NEW.created_by,
+        json_object('asset_id', NEW.asset_id, 'asset_tag', NEW.asset_tag, 'type_id', NEW.type_id,
+        'status_id', NEW.status_id, 'serial_number', NEW.serial_number, 'make', NEW.make,
+        'model', NEW.model, 'purchase_date', NEW.purchase_date,
+        'warranty_end_date', NEW.warranty_end_date, 'location_id', NEW.location_id,
+        'notes', NEW.notes, 'created_by', NEW.created_by, 'updated_by', NEW.updated_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_assets_update AFTER UPDATE ON assets
+WHEN json_object('asset_id', OLD.asset_id, 'asset_tag', OLD.asset_tag, 'type_id', OLD.type_id,
+        'status_id', OLD.status_id, 'serial_number', OLD.serial_number, 'make', OLD.make,
+        'model', OLD.model, 'purchase_date', OLD.purchase_date,
+        'warranty_end_date', OLD.warranty_end_date, 'location_id', OLD.location_id,
+        'notes', OLD.notes, 'created_by', OLD.created_by, 'updated_by', OLD.updated_by)
+    IS NOT json_object('asset_id', NEW.asset_id, 'asset_tag', NEW.asset_tag, 'type_id', NEW.type_id,
+        'status_id', NEW.status_id, 'serial_number', NEW.serial_number, 'make', NEW.make,
+        'model', NEW.model, 'purchase_date', NEW.purchase_date,
+        'warranty_end_date', NEW.warranty_end_date, 'location_id', NEW.location_id,
+        'notes', NEW.notes, 'created_by', NEW.created_by, 'updated_by', NEW.updated_by)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('assets', NEW.asset_id, NEW.asset_id, 'update', NEW.updated_by,
+        json_object('asset_id', OLD.asset_id, 'asset_tag', OLD.asset_tag, 'type_id', OLD.type_id,
+        'status_id', OLD.status_id, 'serial_number', OLD.serial_number, 'make', OLD.make,
+        'model', OLD.model, 'purchase_date', OLD.purchase_date,
+        'warranty_end_date', OLD.warranty_end_date, 'location_id', OLD.location_id,
+        'notes', OLD.notes, 'created_by', OLD.created_by, 'updated_by', OLD.updated_by),
+        json_object('asset_id', NEW.asset_id, 'asset_tag', NEW.asset_tag, 'type_id', NEW.type_id,
+        'status_id', NEW.status_id, 'serial_number', NEW.serial_number, 'make', NEW.make,
+        'model', NEW.model, 'purchase_date', NEW.purchase_date,
+        'warranty_end_date', NEW.warranty_end_date, 'location_id', NEW.location_id,
+        'notes', NEW.notes, 'created_by', NEW.created_by, 'updated_by', NEW.updated_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_assets_delete AFTER DELETE ON assets
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('assets', OLD.asset_id, OLD.asset_id, 'delete', NULL,
+        json_object('asset_id', OLD.asset_id, 'asset_tag', OLD.asset_tag, 'type_id', OLD.type_id,
+        'status_id', OLD.status_id, 'serial_number', OLD.serial_number, 'make', OLD.make,
+        'model', OLD.model, 'purchase_date', OLD.purchase_date,
+        'warranty_end_date', OLD.warranty_end_date, 'location_id', OLD.location_id,
+        'notes', OLD.notes, 'created_by', OLD.created_by, 'updated_by', OLD.updated_by));
+END;
+
+-- asset_assignments
+CREATE TRIGGER IF NOT EXISTS audit_asset_assignments_insert AFTER INSERT ON asset_assignments
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('asset_assignments', NEW.assignment_id, NEW.asset_id, 'insert', NEW.assigned_by,
+        json_object('assignment_id', NEW.assignment_id, 'asset_id', NEW.asset_id,
+        'employee_id', NEW.employee_id, 'assignment_date', NEW.assignment_date,
+        'return_date', NEW.return_date, 'notes', NEW.notes, 'due_date', NEW.due_date,
+        'assigned_by', NEW.assigned_by, 'returned_by', NEW.returned_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_assignments_update AFTER UPDATE ON asset_assignments
+WHEN json_object('assignment_id', OLD.assignment_id, 'asset_id', OLD.asset_id,
+        'employee_id', OLD.employee_id, 'assignment_date', OLD.assignment_date,
+        'return_date', OLD.return_date, 'notes', OLD.notes, 'due_date', OLD.due_date,
+        'assigned_by', OLD.assigned_by, 'returned_by', OLD.returned_by)
+    IS NOT json_object('assignment_id', NEW.assignment_id, 'asset_id', NEW.asset_id,
+        'employee_id', NEW.employee_id, 'assignment_date', NEW.assignment_date,
+        'return_date', NEW.return_date, 'notes', NEW.notes, 'due_date', NEW.due_date,
+        'assigned_by', NEW.assigned_by, 'returned_by', NEW.returned_by)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('asset_assignments', NEW.assignment_id, NEW.asset_id, 'update', COALESCE(NEW.returned_by, NEW.assigned_by),
+        json_object('assignment_id', OLD.assignment_id, 'asset_id', OLD.asset_id,
+        'employee_id', OLD.employee_id, 'assignment_date', OLD.assignment_date,
+        'return_date', OLD.return_date, 'notes', OLD.notes, 'due_date', OLD.due_date,
+        'assigned_by', OLD.assigned_by, 'returned_by', OLD.returned_by),
+        json_object('assignment_id', NEW.assignment_id, 'asset_id', NEW.asset_id,
+        'employee_id', NEW.employee_id, 'assignment_date', NEW.assignment_date,
+        'return_date', NEW.return_date, 'notes', NEW.notes, 'due_date', NEW.due_date,
+        'assigned_by', NEW.assigned_by, 'returned_by', NEW.returned_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_assignments_delete AFTER DELETE ON asset_assignments
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('asset_assignments', OLD.assignment_id, OLD.asset_id, 'delete', NULL,
+        json_object('assignment_id', OLD.assignment_id, 'asset_id', OLD.asset_id,
+        'employee_id', OLD.employee_id, 'assignment_date', OLD.assignment_date,
+        'return_date', OLD.return_date, 'notes', OLD.notes, 'due_date', OLD.due_date,
+        'assigned_by', OLD.assigned_by, 'returned_by', OLD.returned_by));
+END;
+
+-- asset_transfers
+CREATE TRIGGER IF NOT EXISTS audit_asset_transfers_insert AFTER INSERT ON asset_transfers
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('asset_transfers', NEW.transfer_id, NEW.asset_id, 'insert', NEW.transferred_by,
+        json_object('transfer_id', NEW.transfer_id, 'asset_id', NEW.asset_id,
+        'from_location_id', NEW.from_location_id, 'to_location_id', NEW.to_location_id,
+        'transfer_date', NEW.transfer_date, 'notes', NEW.notes,
+        'transferred_by', NEW.transferred_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_transfers_update AFTER UPDATE ON asset_transfers
+WHEN json_object('transfer_id', OLD.transfer_id, 'asset_id', OLD.asset_id,
+        'from_location_id', OLD.from_location_id, 'to_location_id', OLD.to_location_id,
+        'transfer_date', OLD.transfer_date, 'notes', OLD.notes,
+        'transferred_by', OLD.transferred_by)
+    IS NOT json_object('transfer_id', NEW.transfer_id, 'asset_id', NEW.asset_id,
+        'from_location_id', NEW.from_location_id, 'to_location_id', NEW.to_location_id,
+        'transfer_date', NEW.transfer_date, 'notes', NEW.notes,
+        'transferred_by', NEW.transferred_by)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('asset_transfers', NEW.transfer_id, NEW.asset_id, 'update', NEW.transferred_by,
+        json_object('transfer_id', OLD.transfer_id, 'asset_id', OLD.asset_id,
+        'from_location_id', OLD.from_location_id, 'to_location_id', OLD.to_location_id,
+        'transfer_date', OLD.transfer_date, 'notes', OLD.notes,
+        'transferred_by', OLD.transferred_by),
+        json_object('transfer_id', NEW.transfer_id, 'asset_id', NEW.asset_id,
+        'from_location_id', NEW.from_location_id, 'to_location_id', NEW.to_location_id,
+        'transfer_date', NEW.transfer_date, 'notes', NEW.notes,
+        'transferred_by', NEW.transferred_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_transfers_delete AFTER DELETE ON asset_transfers
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('asset_transfers', OLD.transfer_id, OLD.asset_id, 'delete', NULL,
+        json_object('transfer_id', OLD.transfer_id, 'asset_id', OLD.asset_id,
+        'from_location_id', OLD.from_location_id, 'to_location_id', OLD.to_location_id,
+        'transfer_date', OLD.transfer_date, 'notes', OLD.notes,
+        'transferred_by', OLD.transferred_by));
+END;
+
+-- maintenance_logs
+CREATE TRIGGER IF NOT EXISTS audit_maintenance_logs_insert AFTER INSERT ON maintenance_logs
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('maintenance_logs', NEW.log_id, NEW.asset_id, 'insert', NEW.recorded_by,
+        json_object('log_id', NEW.log_id, 'asset_id', NEW.asset_id,
+        'maintenance_type_id', NEW.maintenance_type_id, 'maintenance_date', NEW.maintenance_date,
+        'cost', NEW.cost, 'description', NEW.description, 'performed_by', NEW.performed_by,
+        'recorded_by', NEW.recorded_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_maintenance_logs_update AFTER UPDATE ON maintenance_logs
+WHEN json_object('log_id', OLD.log_id, 'asset_id', OLD.asset_id,
+        'maintenance_type_id', OLD.maintenance_type_id, 'maintenance_date', OLD.maintenance_date,
+        'cost', OLD.cost, 'description', OLD.description, 'performed_by', OLD.performed_by,
+        'recorded_by', OLD.recorded_by)
+    IS NOT json_object('log_id', NEW.log_id, 'asset_id', NEW.asset_id,
+        'maintenance_type_id', NEW.maintenance_type_id, 'maintenance_date', NEW.maintenance_date,
+        'cost', NEW.cost, 'description', NEW.description, 'performed_by', NEW.performed_by,
+        'recorded_by', NEW.recorded_by)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('maintenance_logs', NEW.log_id, NEW.asset_id, 'update', NEW.recorded_by,
+        json_object('log_id', OLD.log_id, 'asset_id', OLD.asset_id,
+        'maintenance_type_id', OLD.maintenance_type_id, 'maintenance_date', OLD.maintenance_date,
+        'cost', OLD.cost, 'description', OLD.description, 'performed_by', OLD.performed_by,
+        'recorded_by', OLD.recorded_by),
+        json_object('log_id', NEW.log_id, 'asset_id', NEW.asset_id,
+        'maintenance_type_id', NEW.maintenance_type_id, 'maintenance_date', NEW.maintenance_date,
+        'cost', NEW.cost, 'description', NEW.description, 'performed_by', NEW.performed_by,
+        'recorded_by', NEW.recorded_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_maintenance_logs_delete AFTER DELETE ON maintenance_logs
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('maintenance_logs', OLD.log_id, OLD.asset_id, 'delete', NULL,
+        json_object('log_id', OLD.log_id, 'asset_id', OLD.asset_id,
+        'maintenance_type_id', OLD.maintenance_type_id, 'maintenance_date', OLD.maintenance_date,
+        'cost', OLD.cost, 'description', OLD.description, 'performed_by', OLD.performed_by,
+        'recorded_by', OLD.recorded_by));
+END;
+
+-- asset_comments
+CREATE TRIGGER IF NOT EXISTS audit_asset_comments_insert AFTER INSERT ON asset_comments
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('asset_comments', NEW.comment_id, NEW.asset_id, 'insert', NEW.author,
+        json_object('comment_id', NEW.comment_id, 'asset_id', NEW.asset_id, 'body', NEW.body,
+        'author', NEW.author, 'created_at', NEW.created_at));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_comments_update AFTER UPDATE ON asset_comments
+WHEN json_object('comment_id', OLD.comment_id, 'asset_id', OLD.asset_id, 'body', OLD.body,
+        'author', OLD.author, 'created_at', OLD.created_at)
+    IS NOT json_object('comment_id', NEW.comment_id, 'asset_id', NEW.asset_id, 'body', NEW.body,
+        'author', NEW.author, 'created_at', NEW.created_at)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('asset_comments', NEW.comment_id, NEW.asset_id, 'update', NEW.author,
+        json_object('comment_id', OLD.comment_id, 'asset_id', OLD.asset_id, 'body', OLD.body,
+        'author', OLD.author, 'created_at', OLD.created_at),
+        json_object('comment_id', NEW.comment_id, 'asset_id', NEW.asset_id, 'body', NEW.body,
+        'author', NEW.author, 'created_at', NEW.created_at));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_comments_delete AFTER DELETE ON asset_comments
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('asset_comments', OLD.comment_id, OLD.asset_id, 'delete', NULL,
+        json_object('comment_id', OLD.comment_id, 'asset_id', OLD.asset_id, 'body', OLD.body,
+        'author', OLD.author, 'created_at', OLD.created_at));
+END;
+
+-- asset_attachments
+CREATE TRIGGER IF NOT EXISTS audit_asset_attachments_insert AFTER INSERT ON asset_attachments
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('asset_attachments', NEW.attachment_id, NEW.asset_id, 'insert', NEW.added_by,
+        json_object('attachment_id', NEW.attachment_id, 'asset_id', NEW.asset_id,
+        'file_name', NEW.file_name, 'file_path', NEW.file_path, 'added_at', NEW.added_at,
+        'notes', NEW.notes, 'added_by', NEW.added_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_attachments_update AFTER UPDATE ON asset_attachments
+WHEN json_object('attachment_id', OLD.attachment_id, 'asset_id', OLD.asset_id,
+        'file_name', OLD.file_name, 'file_path', OLD.file_path, 'added_at', OLD.added_at,
+        'notes', OLD.notes, 'added_by', OLD.added_by)
+    IS NOT json_object('attachment_id', NEW.attachment_id, 'asset_id', NEW.asset_id,
+        'file_name', NEW.file_name, 'file_path', NEW.file_path, 'added_at', NEW.added_at,
+        'notes', NEW.notes, 'added_by', NEW.added_by)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('asset_attachments', NEW.attachment_id, NEW.asset_id, 'update', NEW.added_by,
+        json_object('attachment_id', OLD.attachment_id, 'asset_id', OLD.asset_id,
+        'file_name', OLD.file_name, 'file_path', OLD.file_path, 'added_at', OLD.added_at,
+        'notes', OLD.notes, 'added_by', OLD.added_by),
+        json_object('attachment_id', NEW.attachment_id, 'asset_id', NEW.asset_id,
+        'file_name', NEW.file_name, 'file_path', NEW.file_path, 'added_at', NEW.added_at,
+        'notes', NEW.notes, 'added_by', NEW.added_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_attachments_delete AFTER DELETE ON asset_attachments
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('asset_attachments', OLD.attachment_id, OLD.asset_id, 'delete', NULL,
+        json_object('attachment_id', OLD.attachment_id, 'asset_id', OLD.asset_id,
+        'file_name', OLD.file_name, 'file_path', OLD.file_path, 'added_at', OLD.added_at,
+        'notes', OLD.notes, 'added_by', OLD.added_by));
+END;
+
+-- software_licenses
+CREATE TRIGGER IF NOT EXISTS audit_software_licenses_insert AFTER INSERT ON software_licenses
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('software_licenses', NEW.license_id, NULL, 'insert', NULL,
+        json_object('license_id', NEW.license_id, 'software_name', NEW.software_name,
+        'license_key', NEW.license_key, 'license_type', NEW.license_type,
+        'seats_purchased', NEW.seats_purchased, 'purchase_date', NEW.purchase_date,
+        'expiration_date', NEW.expiration_date, 'notes', NEW.notes));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_software_licenses_update AFTER UPDATE ON software_licenses
+WHEN json_object('license_id', OLD.license_id, 'software_name', OLD.software_name,
+        'license_key', OLD.license_key, 'license_type', OLD.license_type,
+        'seats_purchased', OLD.seats_purchased, 'purchase_date', OLD.purchase_date,
+        'expiration_date', OLD.expiration_date, 'notes', OLD.notes)
+    IS NOT json_object('license_id', NEW.license_id, 'software_name', NEW.software_name,
+        'license_key', NEW.license_key, 'license_type', NEW.license_type,
+        'seats_purchased', NEW.seats_purchased, 'purchase_date', NEW.purchase_date,
+        'expiration_date', NEW.expiration_date, 'notes', NEW.notes)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('software_licenses', NEW.license_id, NULL, 'update', NULL,
+        json_object('license_id', OLD.license_id, 'software_name', OLD.software_name,
+        'license_key', OLD.license_key, 'license_type', OLD.license_type,
+        'seats_purchased', OLD.seats_purchased, 'purchase_date', OLD.purchase_date,
+        'expiration_date', OLD.expiration_date, 'notes', OLD.notes),
+        json_object('license_id', NEW.license_id, 'software_name', NEW.software_name,
+        'license_key', NEW.license_key, 'license_type', NEW.license_type,
+        'seats_purchased', NEW.seats_purchased, 'purchase_date', NEW.purchase_date,
+        'expiration_date', NEW.expiration_date, 'notes', NEW.notes));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_software_licenses_delete AFTER DELETE ON software_licenses
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('software_licenses', OLD.license_id, NULL, 'delete', NULL,
+        json_object('license_id', OLD.license_id, 'software_name', OLD.software_name,
+        'license_key', OLD.license_key, 'license_type', OLD.license_type,
+        'seats_purchased', OLD.seats_purchased, 'purchase_date', OLD.purchase_date,
+        'expiration_date', OLD.expiration_date, 'notes', OLD.notes));
+END;
+
+-- license_assignments
+CREATE TRIGGER IF NOT EXISTS audit_license_assignments_insert AFTER INSERT ON license_assignments
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('license_assignments', NEW.assignment_id, NEW.asset_id, 'insert', NULL,
+        json_object('assignment_id', NEW.assignment_id, 'license_id', NEW.license_id,
+        'asset_id', NEW.asset_id, 'assignment_date', NEW.assignment_date,
+        'removal_date', NEW.removal_date, 'notes', NEW.notes));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_license_assignments_update AFTER UPDATE ON license_assignments
+WHEN json_object('assignment_id', OLD.assignment_id, 'license_id', OLD.license_id,
+        'asset_id', OLD.asset_id, 'assignment_date', OLD.assignment_date,
+        'removal_date', OLD.removal_date, 'notes', OLD.notes)
+    IS NOT json_object('assignment_id', NEW.assignment_id, 'license_id', NEW.license_id,
+        'asset_id', NEW.asset_id, 'assignment_date', NEW.assignment_date,
+        'removal_date', NEW.removal_date, 'notes', NEW.notes)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('license_assignments', NEW.assignment_id, NEW.asset_id, 'update', NULL,
+        json_object('assignment_id', OLD.assignment_id, 'license_id', OLD.license_id,
+        'asset_id', OLD.asset_id, 'assignment_date', OLD.assignment_date,
+        'removal_date', OLD.removal_date, 'notes', OLD.notes),
+        json_object('assignment_id', NEW.assignment_id, 'license_id', NEW.license_id,
+        'asset_id', NEW.asset_id, 'assignment_date', NEW.assignment_date,
+        'removal_date', NEW.removal_date, 'notes', NEW.notes));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_license_assignments_delete AFTER DELETE ON license_assignments
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('license_assignments', OLD.assignment_id, OLD.asset_id, 'delete', NULL,
+        json_object('assignment_id', OLD.assignment_id, 'license_id', OLD.license_id,
+        'asset_id', OLD.asset_id, 'assignment_date', OLD.assignment_date,
+        'removal_date', OLD.removal_date, 'notes', OLD.notes));
+END;
+
+-- consumable_types
+CREATE TRIGGER IF NOT EXISTS audit_consumable_types_insert AFTER INSERT ON consumable_types
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('consumable_types', NEW.consumable_type_id, NULL, 'insert', NULL,
+        json_object('consumable_type_id', NEW.consumable_type_id, 'name', NEW.name,
+        'part_number', NEW.part_number, 'manufacturer', NEW.manufacturer,
+        'last_purchase_date', NEW.last_purchase_date, 'stock_quantity', NEW.stock_quantity,
+        'reorder_level', NEW.reorder_level));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_consumable_types_update AFTER UPDATE ON consumable_types
+WHEN json_object('consumable_type_id', OLD.consumable_type_id, 'name', OLD.name,
+        'part_number', OLD.part_number, 'manufacturer', OLD.manufacturer,
+        'last_purchase_date', OLD.last_purchase_date, 'stock_quantity', OLD.stock_quantity,
+        'reorder_level', OLD.reorder_level)
+    IS NOT json_object('consumable_type_id', NEW.consumable_type_id, 'name', NEW.name,
+        'part_number', NEW.part_number, 'manufacturer', NEW.manufacturer,
+        'last_purchase_date', NEW.last_purchase_date, 'stock_quantity', NEW.stock_quantity,
+        'reorder_level', NEW.reorder_level)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('consumable_types', NEW.consumable_type_id, NULL, 'update', NULL,
+        json_object('consumable_type_id', OLD.consumable_type_id, 'name', OLD.name,
+        'part_number', OLD.part_number, 'manufacturer', OLD.manufacturer,
+        'last_purchase_date', OLD.last_purchase_date, 'stock_quantity', OLD.stock_quantity,
+        'reorder_level', OLD.reorder_level),
+        json_object('consumable_type_id', NEW.consumable_type_id, 'name', NEW.name,
+        'part_number', NEW.part_number, 'manufacturer', NEW.manufacturer,
+        'last_purchase_date', NEW.last_purchase_date, 'stock_quantity', NEW.stock_quantity,
+        'reorder_level', NEW.reorder_level));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_consumable_types_delete AFTER DELETE ON consumable_types
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('consumable_types', OLD.consumable_type_id, NULL, 'delete', NULL,
+        json_object('consumable_type_id', OLD.consumable_type_id, 'name', OLD.name,
+        'part_number', OLD.part_number, 'manufacturer', OLD.manufacturer,
+        'last_purchase_date', OLD.last_purchase_date, 'stock_quantity', OLD.stock_quantity,
+        'reorder_level', OLD.reorder_level));
+END;
+
+-- consumable_usage
+CREATE TRIGGER IF NOT EXISTS audit_consumable_usage_insert AFTER INSERT ON consumable_usage
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('consumable_usage', NEW.usage_id, NEW.asset_id, 'insert', NULL,
+        json_object('usage_id', NEW.usage_id, 'consumable_type_id', NEW.consumable_type_id,
+        'asset_id', NEW.asset_id, 'installation_date', NEW.installation_date, 'notes', NEW.notes));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_consumable_usage_update AFTER UPDATE ON consumable_usage
+WHEN json_object('usage_id', OLD.usage_id, 'consumable_type_id', OLD.consumable_type_id,
+        'asset_id', OLD.asset_id, 'installation_date', OLD.installation_date, 'notes', OLD.notes)
+    IS NOT json_object('usage_id', NEW.usage_id, 'consumable_type_id', NEW.consumable_type_id,
+        'asset_id', NEW.asset_id, 'installation_date', NEW.installation_date, 'notes', NEW.notes)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('consumable_usage', NEW.usage_id, NEW.asset_id, 'update', NULL,
+        json_object('usage_id', OLD.usage_id, 'consumable_type_id', OLD.consumable_type_id,
+        'asset_id', OLD.asset_id, 'installation_date', OLD.installation_date, 'notes', OLD.notes),
+        json_object('usage_id', NEW.usage_id, 'consumable_type_id', NEW.consumable_type_id,
+        'asset_id', NEW.asset_id, 'installation_date', NEW.installation_date, 'notes', NEW.notes));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_consumable_usage_delete AFTER DELETE ON consumable_usage
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('consumable_usage', OLD.usage_id, OLD.asset_id, 'delete', NULL,
+        json_object('usage_id', OLD.usage_id, 'consumable_type_id', OLD.consumable_type_id,
+        'asset_id', OLD.asset_id, 'installation_date', OLD.installation_date, 'notes', OLD.notes));
+END;
+
+-- employees
+CREATE TRIGGER IF NOT EXISTS audit_employees_insert AFTER INSERT ON employees
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('employees', NEW.employee_id, NULL, 'insert', NEW.created_by,
+        json_object('employee_id', NEW.employee_id, 'full_name', NEW.full_name, 'email', NEW.email,
+        'created_by', NEW.created_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_employees_update AFTER UPDATE ON employees
+WHEN json_object('employee_id', OLD.employee_id, 'full_name', OLD.full_name, 'email', OLD.email,
+        'created_by', OLD.created_by)
+    IS NOT json_object('employee_id', NEW.employee_id, 'full_name', NEW.full_name, 'email', NEW.email,
+        'created_by', NEW.created_by)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('employees', NEW.employee_id, NULL, 'update', NULL,
+        json_object('employee_id', OLD.employee_id, 'full_name', OLD.full_name, 'email', OLD.email,
+        'created_by', OLD.created_by),
+        json_object('employee_id', NEW.employee_id, 'full_name', NEW.full_name, 'email', NEW.email,
+        'created_by', NEW.created_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_employees_delete AFTER DELETE ON employees
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('employees', OLD.employee_id, NULL, 'delete', NULL,
+        json_object('employee_id', OLD.employee_id, 'full_name', OLD.full_name, 'email', OLD.email,
+        'created_by', OLD.created_by));
+END;
+
+-- locations
+CREATE TRIGGER IF NOT EXISTS audit_locations_insert AFTER INSERT ON locations
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('locations', NEW.location_id, NULL, 'insert', NULL,
+        json_object('location_id', NEW.location_id, 'name', NEW.name, 'type', NEW.type));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_locations_update AFTER UPDATE ON locations
+WHEN json_object('location_id', OLD.location_id, 'name', OLD.name, 'type', OLD.type)
+    IS NOT json_object('location_id', NEW.location_id, 'name', NEW.name, 'type', NEW.type)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('locations', NEW.location_id, NULL, 'update', NULL,
+        json_object('location_id', OLD.location_id, 'name', OLD.name, 'type', OLD.type),
+        json_object('location_id', NEW.location_id, 'name', NEW.name, 'type', NEW.type));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_locations_delete AFTER DELETE ON locations
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('locations', OLD.location_id, NULL, 'delete', NULL,
+        json_object('location_id', OLD.location_id, 'name', OLD.name, 'type', OLD.type));
+END;
+
+-- asset_categories
+CREATE TRIGGER IF NOT EXISTS audit_asset_categories_insert AFTER INSERT ON asset_categories
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('asset_categories', NEW.category_id, NULL, 'insert', NULL,
+        json_object('category_id', NEW.category_id, 'code_prefix', NEW.code_prefix,
+        'description', NEW.description));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_categories_update AFTER UPDATE ON asset_categories
+WHEN json_object('category_id', OLD.category_id, 'code_prefix', OLD.code_prefix,
+        'description', OLD.description)
+    IS NOT json_object('category_id', NEW.category_id, 'code_prefix', NEW.code_prefix,
+        'description', NEW.description)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('asset_categories', NEW.category_id, NULL, 'update', NULL,
+        json_object('category_id', OLD.category_id, 'code_prefix', OLD.code_prefix,
+        'description', OLD.description),
+        json_object('category_id', NEW.category_id, 'code_prefix', NEW.code_prefix,
+        'description', NEW.description));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_categories_delete AFTER DELETE ON asset_categories
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('asset_categories', OLD.category_id, NULL, 'delete', NULL,
+        json_object('category_id', OLD.category_id, 'code_prefix', OLD.code_prefix,
+        'description', OLD.description));
+END;
+
+-- asset_types
+CREATE TRIGGER IF NOT EXISTS audit_asset_types_insert AFTER INSERT ON asset_types
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('asset_types', NEW.type_id, NULL, 'insert', NULL,
+        json_object('type_id', NEW.type_id, 'category_id', NEW.category_id, 'type_name', NEW.type_name));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_types_update AFTER UPDATE ON asset_types
+WHEN json_object('type_id', OLD.type_id, 'category_id', OLD.category_id, 'type_name', OLD.type_name)
+    IS NOT json_object('type_id', NEW.type_id, 'category_id', NEW.category_id, 'type_name', NEW.type_name)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('asset_types', NEW.type_id, NULL, 'update', NULL,
+        json_object('type_id', OLD.type_id, 'category_id', OLD.category_id, 'type_name', OLD.type_name),
+        json_object('type_id', NEW.type_id, 'category_id', NEW.category_id, 'type_name', NEW.type_name));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_types_delete AFTER DELETE ON asset_types
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('asset_types', OLD.type_id, NULL, 'delete', NULL,
+        json_object('type_id', OLD.type_id, 'category_id', OLD.category_id, 'type_name', OLD.type_name));
+END;
+
+-- asset_statuses
+CREATE TRIGGER IF NOT EXISTS audit_asset_statuses_insert AFTER INSERT ON asset_statuses
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('asset_statuses', NEW.status_id, NULL, 'insert', NULL,
+        json_object('status_id', NEW.status_id, 'status_name', NEW.status_name));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_statuses_update AFTER UPDATE ON asset_statuses
+WHEN json_object('status_id', OLD.status_id, 'status_name', OLD.status_name)
+    IS NOT json_object('status_id', NEW.status_id, 'status_name', NEW.status_name)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('asset_statuses', NEW.status_id, NULL, 'update', NULL,
+        json_object('status_id', OLD.status_id, 'status_name', OLD.status_name),
+        json_object('status_id', NEW.status_id, 'status_name', NEW.status_name));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_statuses_delete AFTER DELETE ON asset_statuses
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('asset_statuses', OLD.status_id, NULL, 'delete', NULL,
+        json_object('status_id', OLD.status_id, 'status_name', OLD.status_name));
+END;
+
+-- maintenance_types
+CREATE TRIGGER IF NOT EXISTS audit_maintenance_types_insert AFTER INSERT ON maintenance_types
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('maintenance_types', NEW.maintenance_type_id, NULL, 'insert', NULL,
+        json_object('maintenance_type_id', NEW.maintenance_type_id, 'type_name', NEW.type_name));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_maintenance_types_update AFTER UPDATE ON maintenance_types
+WHEN json_object('maintenance_type_id', OLD.maintenance_type_id, 'type_name', OLD.type_name)
+    IS NOT json_object('maintenance_type_id', NEW.maintenance_type_id, 'type_name', NEW.type_name)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('maintenance_types', NEW.maintenance_type_id, NULL, 'update', NULL,
+        json_object('maintenance_type_id', OLD.maintenance_type_id, 'type_name', OLD.type_name),
+        json_object('maintenance_type_id', NEW.maintenance_type_id, 'type_name', NEW.type_name));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_maintenance_types_delete AFTER DELETE ON maintenance_types
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('maintenance_types', OLD.maintenance_type_id, NULL, 'delete', NULL,
+        json_object('maintenance_type_id', OLD.maintenance_type_id, 'type_name', OLD.type_name));
+END;
@@ -0,0 +1,91 @@
+-- ======================================================
+-- Asset kits: groups of assets handed out as a unit
+-- ======================================================
+
+-- Kit templates list the asset types a kit is made of, e.g. a new hire
+-- kit of a laptop, a docking station and a headset
+CREATE TABLE IF NOT EXISTS kit_templates (
+    template_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS kit_template_items (
+    template_id INTEGER NOT NULL,
+    type_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+
+    PRIMARY KEY (template_id, type_id),
+    FOREIGN KEY (template_id) REFERENCES kit_templates(template_id),
+    FOREIGN KEY (type_id) REFERENCES asset_types(type_id)
+);
+
+-- Kits group assets that are assigned, returned and moved together
+CREATE TABLE IF NOT EXISTS kits (
+    kit_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kit_tag TEXT NOT NULL UNIQUE,               -- Human-friendly kit tag
+    template_id INTEGER,                        -- NULL for kits made without a template
+    notes TEXT,
+    created_by TEXT,                            -- Username, NULL when unknown
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+
+    FOREIGN KEY (template_id) REFERENCES kit_templates(template_id)
+);
+
+-- Members of each kit, an asset belonging to at most one kit
+CREATE TABLE IF NOT EXISTS kit_assets (
+    asset_id TEXT PRIMARY KEY,
+    kit_id INTEGER NOT NULL,
+    added_at TEXT NOT NULL DEFAULT (datetime('now')),
+    added_by TEXT,                              -- Username, NULL when unknown
+
+    FOREIGN KEY (asset_id) REFERENCES assets(asset_id),
+    FOREIGN KEY (kit_id) REFERENCES kits(kit_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kit_assets_kit ON kit_assets(kit_id);
+
+-- kits
+CREATE TRIGGER IF NOT EXISTS audit_kits_insert AFTER INSERT ON kits
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('kits', NEW.kit_id, NULL, 'insert', NEW.created_by,
+        json_object('kit_id', NEW.kit_id, 'kit_tag', NEW.kit_tag, 'template_id', NEW.template_id,
+        'notes', NEW.notes, 'created_by', NEW.created_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_kits_update AFTER UPDATE ON kits
+WHEN json_object('kit_id', OLD.kit_id, 'kit_tag', OLD.kit_tag, 'template_id', OLD.template_id,
+        'notes', OLD.notes, 'created_by', OLD.created_by)
+    IS NOT json_object('kit_id', NEW.kit_id, 'kit_tag', NEW.kit_tag, 'template_id', NEW.template_id,
+        'notes', NEW.notes, 'created_by', NEW.created_by)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('kits', NEW.kit_id, NULL, 'update', NULL,
+        json_object('kit_id', OLD.kit_id, 'kit_tag', OLD.kit_tag, 'template_id', OLD.template_id,
+        'notes', OLD.notes, 'created_by', OLD.created_by),
+        json_object('kit_id', NEW.kit_id, 'kit_tag', NEW.kit_tag, 'template_id', NEW.template_id,
+        'notes', NEW.notes, 'created_by', NEW.created_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_kits_delete AFTER DELETE ON kits
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('kits', OLD.kit_id, NULL, 'delete', NULL,
+        json_object('kit_id', OLD.kit_id, 'kit_tag', OLD.kit_tag, 'template_id', OLD.template_id,
+        'notes', OLD.notes, 'created_by', OLD.created_by));
+END;
+
+-- kit_assets
+CREATE TRIGGER IF NOT EXISTS audit_kit_assets_insert AFTER INSERT ON kit_assets
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('kit_assets', NEW.asset_id, NEW.asset_id, 'insert', NEW.added_by,
+        json_object('asset_id', NEW.asset_id, 'kit_id', NEW.kit_id, 'added_at', NEW.added_at,
+        'added_by', NEW.added_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_kit_assets_delete AFTER DELETE ON kit_assets
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('kit_assets', OLD.asset_id, OLD.asset_id, 'delete', NULL,
+        json_object('asset_id', OLD.asset_id, 'kit_id', OLD.kit_id, 'added_at', OLD.added_at,
+        'added_by', OLD.added_by));
+END;
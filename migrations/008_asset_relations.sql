@@ -0,0 +1,35 @@
+-- ======================================================
+-- Asset relations: components attached to, installed in or powered by
+-- another asset
+-- ======================================================
+
+-- Each asset has at most one parent, so the relations form trees
+CREATE TABLE IF NOT EXISTS asset_relations (
+    child_id TEXT PRIMARY KEY,
+    parent_id TEXT NOT NULL,
+    relation TEXT NOT NULL CHECK (relation IN ('attached-to', 'installed-in', 'powered-by')),
+    created_by TEXT,                            -- Username, NULL when unknown
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+
+    CHECK (child_id <> parent_id),
+    FOREIGN KEY (child_id) REFERENCES assets(asset_id),
+    FOREIGN KEY (parent_id) REFERENCES assets(asset_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_asset_relations_parent ON asset_relations(parent_id);
+
+-- asset_relations
+CREATE TRIGGER IF NOT EXISTS audit_asset_relations_insert AFTER INSERT ON asset_relations
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('asset_relations', NEW.child_id, NEW.child_id, 'insert', NEW.created_by,
+        json_object('child_id', NEW.child_id, 'parent_id', NEW.parent_id, 'relation', NEW.relation,
+        'created_by', NEW.created_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_relations_delete AFTER DELETE ON asset_relations
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('asset_relations', OLD.child_id, OLD.child_id, 'delete', NULL,
+        json_object('child_id', OLD.child_id, 'parent_id', OLD.parent_id, 'relation', OLD.relation,
+        'created_by', OLD.created_by));
+END;
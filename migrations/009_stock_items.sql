@@ -0,0 +1,64 @@
+-- ======================================================
+-- Stock items: accessories kept in bulk and tracked by quantity per
+-- location instead of by serial number
+-- ======================================================
+
+CREATE TABLE IF NOT EXISTS stock_items (
+    item_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    type_id INTEGER,                            -- Asset type the item is a kind of, NULL when none fits
+    reorder_level INTEGER NOT NULL DEFAULT 0 CHECK (reorder_level >= 0),
+    notes TEXT,
+    created_by TEXT,                            -- Username, NULL when unknown
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+
+    FOREIGN KEY (type_id) REFERENCES asset_types(type_id)
+);
+
+-- Every change to the stock of an item at a location
+-- quantity is the change to the stock at the location: adjustments add or
+-- remove units, issues to an employee remove them and returns add them back
+-- The stock at a location is the sum of its movements, and the units an
+-- employee holds are the issues less the returns
+CREATE TABLE IF NOT EXISTS stock_movements (
+    movement_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    location_id INTEGER NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('adjust', 'issue', 'return')),
+    quantity INTEGER NOT NULL,
+    employee_id INTEGER,                        -- Set for issues and returns
+    moved_at TEXT NOT NULL,
+    notes TEXT,
+    moved_by TEXT,                              -- Username, NULL when unknown
+
+    CHECK (quantity <> 0),
+    CHECK ((kind = 'adjust') = (employee_id IS NULL)),
+    CHECK (kind <> 'issue' OR quantity < 0),
+    CHECK (kind <> 'return' OR quantity > 0),
+    FOREIGN KEY (item_id) REFERENCES stock_items(item_id),
+    FOREIGN KEY (location_id) REFERENCES locations(location_id),
+    FOREIGN KEY (employee_id) REFERENCES employees(employee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_item ON stock_movements(item_id, location_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_employee ON stock_movements(employee_id);
+
+-- stock_items
+CREATE TRIGGER IF NOT EXISTS audit_stock_items_insert AFTER INSERT ON stock_items
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('stock_items', NEW.item_id, NULL, 'insert', NEW.created_by,
+        json_object('item_id', NEW.item_id, 'name', NEW.name, 'type_id', NEW.type_id,
+        'reorder_level', NEW.reorder_level, 'notes', NEW.notes, 'created_by', NEW.created_by));
+END;
+
+-- stock_movements
+CREATE TRIGGER IF NOT EXISTS audit_stock_movements_insert AFTER INSERT ON stock_movements
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('stock_movements', NEW.movement_id, NULL, 'insert', NEW.moved_by,
+        json_object('movement_id', NEW.movement_id, 'item_id', NEW.item_id,
+        'location_id', NEW.location_id, 'kind', NEW.kind, 'quantity', NEW.quantity,
+        'employee_id', NEW.employee_id, 'moved_at', NEW.moved_at, 'notes', NEW.notes,
+        'moved_by', NEW.moved_by));
+END;
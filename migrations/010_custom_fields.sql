@@ -0,0 +1,90 @@
+-- ======================================================
+-- Custom fields: admin-defined asset fields that only apply to some asset
+-- types, such as the RAM of a laptop or the IMEI of a smartphone
+-- ======================================================
+
+-- A field belongs either to one asset type or to every type in a category
+-- Values are checked by the application: text against pattern, numbers
+-- against min_value and max_value, and enums against options
+CREATE TABLE IF NOT EXISTS custom_fields (
+    field_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL COLLATE NOCASE,
+    kind TEXT NOT NULL CHECK (kind IN ('text', 'number', 'date', 'enum')),
+    type_id INTEGER,                            -- Set for the fields of one asset type
+    category_id INTEGER,                        -- Set for the fields of every type in a category
+    required INTEGER NOT NULL DEFAULT 0,
+    options TEXT,                               -- JSON array of the values an enum accepts
+    pattern TEXT,                               -- Regular expression a whole text value must match
+    min_value REAL,
+    max_value REAL,
+    created_by TEXT,                            -- Username, NULL when unknown
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+
+    CHECK ((type_id IS NULL) <> (category_id IS NULL)),
+    CHECK ((kind = 'enum') = (options IS NOT NULL)),
+    UNIQUE (type_id, name),
+    UNIQUE (category_id, name),
+    FOREIGN KEY (type_id) REFERENCES asset_types(type_id),
+    FOREIGN KEY (category_id) REFERENCES asset_categories(category_id)
+);
+
+-- The value of a custom field on an asset, numbers and dates kept in a
+-- canonical form so they compare as text
+CREATE TABLE IF NOT EXISTS asset_field_values (
+    asset_id TEXT NOT NULL,
+    field_id INTEGER NOT NULL,
+    value TEXT NOT NULL,
+    updated_by TEXT,                            -- Username, NULL when unknown
+
+    PRIMARY KEY (asset_id, field_id),
+    FOREIGN KEY (asset_id) REFERENCES assets(asset_id),
+    FOREIGN KEY (field_id) REFERENCES custom_fields(field_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_asset_field_values_field ON asset_field_values(field_id, value);
+
+-- custom_fields
+CREATE TRIGGER IF NOT EXISTS audit_custom_fields_insert AFTER INSERT ON custom_fields
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('custom_fields', NEW.field_id, NULL, 'insert', NEW.created_by,
+        json_object('field_id', NEW.field_id, 'name', NEW.name, 'kind', NEW.kind,
+        'type_id', NEW.type_id, 'category_id', NEW.category_id, 'required', NEW.required,
+        'options', NEW.options, 'pattern', NEW.pattern, 'min_value', NEW.min_value,
+        'max_value', NEW.max_value, 'created_by', NEW.created_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_custom_fields_delete AFTER DELETE ON custom_fields
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('custom_fields', OLD.field_id, NULL, 'delete', NULL,
+        json_object('field_id', OLD.field_id, 'name', OLD.name, 'kind', OLD.kind,
+        'type_id', OLD.type_id, 'category_id', OLD.category_id, 'required', OLD.required,
+        'options', OLD.options, 'pattern', OLD.pattern, 'min_value', OLD.min_value,
+        'max_value', OLD.max_value, 'created_by', OLD.created_by));
+END;
+
+-- asset_field_values
+CREATE TRIGGER IF NOT EXISTS audit_asset_field_values_insert AFTER INSERT ON asset_field_values
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('asset_field_values', NEW.field_id, NEW.asset_id, 'insert', NEW.updated_by,
+        json_object('asset_id', NEW.asset_id, 'field_id', NEW.field_id, 'value', NEW.value,
+        'updated_by', NEW.updated_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_field_values_update AFTER UPDATE ON asset_field_values
+WHEN OLD.value IS NOT NEW.value
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('asset_field_values', NEW.field_id, NEW.asset_id, 'update', NEW.updated_by,
+        json_object('asset_id', OLD.asset_id, 'field_id', OLD.field_id, 'value', OLD.value,
+        'updated_by', OLD.updated_by),
+        json_object('asset_id', NEW.asset_id, 'field_id', NEW.field_id, 'value', NEW.value,
+        'updated_by', NEW.updated_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_asset_field_values_delete AFTER DELETE ON asset_field_values
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('asset_field_values', OLD.field_id, OLD.asset_id, 'delete', NULL,
+        json_object('asset_id', OLD.asset_id, 'field_id', OLD.field_id, 'value', OLD.value,
+        'updated_by', OLD.updated_by));
+END;
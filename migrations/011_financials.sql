@@ -0,0 +1,125 @@
+-- ======================================================
+-- Financial tracking: what an asset cost, where it was bought, and how the
+-- assets of each category depreciate
+-- ======================================================
+
+ALTER TABLE assets ADD COLUMN purchase_cost REAL CHECK (purchase_cost >= 0);
+ALTER TABLE assets ADD COLUMN currency TEXT;    -- ISO 4217 code, set along with purchase_cost
+ALTER TABLE assets ADD COLUMN supplier TEXT;
+ALTER TABLE assets ADD COLUMN po_number TEXT;
+ALTER TABLE assets ADD COLUMN invoice_number TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_assets_supplier ON assets(supplier);
+
+-- One rule per category, assets of categories without one keep their cost.
+-- Straight-line takes an equal share of the cost above the salvage value
+-- each year, declining balance takes factor / useful life of the remaining
+-- value each year, never going below the salvage value.
+CREATE TABLE IF NOT EXISTS depreciation_rules (
+    category_id INTEGER PRIMARY KEY,
+    method TEXT NOT NULL CHECK (method IN ('straight-line', 'declining-balance')),
+    useful_life_years INTEGER NOT NULL CHECK (useful_life_years > 0),
+    salvage_percent REAL NOT NULL DEFAULT 0 CHECK (salvage_percent >= 0 AND salvage_percent < 100),
+    factor REAL CHECK (factor > 0),            -- Declining balance only, e.g. 2 for double declining
+    updated_by TEXT,                            -- Username, NULL when unknown
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+
+    CHECK ((method = 'declining-balance') = (factor IS NOT NULL)),
+    FOREIGN KEY (category_id) REFERENCES asset_categories(category_id)
+);
+
+-- assets, recreated to record the financial columns
+DROP TRIGGER IF EXISTS audit_assets_insert;
+DROP TRIGGER IF EXISTS audit_assets_update;
+DROP TRIGGER IF EXISTS audit_assets_delete;
+
+CREATE TRIGGER IF NOT EXISTS audit_assets_insert AFTER INSERT ON assets
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('assets', NEW.asset_id, NEW.asset_id, 'insert', NEW.created_by,
+        json_object('asset_id', NEW.asset_id, 'asset_tag', NEW.asset_tag, 'type_id', NEW.type_id,
+        'status_id', NEW.status_id, 'serial_number', NEW.serial_number, 'make', NEW.make,
+        'model', NEW.model, 'purchase_date', NEW.purchase_date,
+        'warranty_end_date', NEW.warranty_end_date, 'location_id', NEW.location_id,
+        'notes', NEW.notes, 'purchase_cost', NEW.purchase_cost, 'currency', NEW.currency,
+        'supplier', NEW.supplier, 'po_number', NEW.po_number, 'invoice_number', NEW.invoice_number,
+        'created_by', NEW.created_by, 'updated_by', NEW.updated_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_assets_update AFTER UPDATE ON assets
+WHEN json_object('asset_id', OLD.asset_id, 'asset_tag', OLD.asset_tag, 'type_id', OLD.type_id,
+        'status_id', OLD.status_id, 'serial_number', OLD.serial_number, 'make', OLD.make,
+        'model', OLD.model, 'purchase_date', OLD.purchase_date,
+        'warranty_end_date', OLD.warranty_end_date, 'location_id', OLD.location_id,
+        'notes', OLD.notes, 'purchase_cost', OLD.purchase_cost, 'currency', OLD.currency,
+        'supplier', OLD.supplier, 'po_number', OLD.po_number, 'invoice_number', OLD.invoice_number,
+        'created_by', OLD.created_by, 'updated_by', OLD.updated_by)
+    IS NOT json_object('asset_id', NEW.asset_id, 'asset_tag', NEW.asset_tag, 'type_id', NEW.type_id,
+        'status_id', NEW.status_id, 'serial_number', NEW.serial_number, 'make', NEW.make,
+        'model', NEW.model, 'purchase_date', NEW.purchase_date,
+        'warranty_end_date', NEW.warranty_end_date, 'location_id', NEW.location_id,
+        'notes', NEW.notes, 'purchase_cost', NEW.purchase_cost, 'currency', NEW.currency,
+        'supplier', NEW.supplier, 'po_number', NEW.po_number, 'invoice_number', NEW.invoice_number,
+        'created_by', NEW.created_by, 'updated_by', NEW.updated_by)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('assets', NEW.asset_id, NEW.asset_id, 'update', NEW.updated_by,
+        json_object('asset_id', OLD.asset_id, 'asset_tag', OLD.asset_tag, 'type_id', OLD.type_id,
+        'status_id', OLD.status_id, 'serial_number', OLD.serial_number, 'make', OLD.make,
+        'model', OLD.model, 'purchase_date', OLD.purchase_date,
+        'warranty_end_date', OLD.warranty_end_date, 'location_id', OLD.location_id,
+        'notes', OLD.notes, 'purchase_cost', OLD.purchase_cost, 'currency', OLD.currency,
+        'supplier', OLD.supplier, 'po_number', OLD.po_number, 'invoice_number', OLD.invoice_number,
+        'created_by', OLD.created_by, 'updated_by', OLD.updated_by),
+        json_object('asset_id', NEW.asset_id, 'asset_tag', NEW.asset_tag, 'type_id', NEW.type_id,
+        'status_id', NEW.status_id, 'serial_number', NEW.serial_number, 'make', NEW.make,
+        'model', NEW.model, 'purchase_date', NEW.purchase_date,
+        'warranty_end_date', NEW.warranty_end_date, 'location_id', NEW.location_id,
+        'notes', NEW.notes, 'purchase_cost', NEW.purchase_cost, 'currency', NEW.currency,
+        'supplier', NEW.supplier, 'po_number', NEW.po_number, 'invoice_number', NEW.invoice_number,
+        'created_by', NEW.created_by, 'updated_by', NEW.updated_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_assets_delete AFTER DELETE ON assets
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('assets', OLD.asset_id, OLD.asset_id, 'delete', NULL,
+        json_object('asset_id', OLD.asset_id, 'asset_tag', OLD.asset_tag, 'type_id', OLD.type_id,
+        'status_id', OLD.status_id, 'serial_number', OLD.serial_number, 'make', OLD.make,
+        'model', OLD.model, 'purchase_date', OLD.purchase_date,
+        'warranty_end_date', OLD.warranty_end_date, 'location_id', OLD.location_id,
+        'notes', OLD.notes, 'purchase_cost', OLD.purchase_cost, 'currency', OLD.currency,
+        'supplier', OLD.supplier, 'po_number', OLD.po_number, 'invoice_number', OLD.invoice_number,
+        'created_by', OLD.created_by, 'updated_by', OLD.updated_by));
+END;
+
+-- depreciation_rules
+CREATE TRIGGER IF NOT EXISTS audit_depreciation_rules_insert AFTER INSERT ON depreciation_rules
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, after_json)
+    VALUES ('depreciation_rules', NEW.category_id, NULL, 'insert', NEW.updated_by,
+        json_object('category_id', NEW.category_id, 'method', NEW.method,
+        'useful_life_years', NEW.useful_life_years, 'salvage_percent', NEW.salvage_percent,
+        'factor', NEW.factor, 'updated_by', NEW.updated_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_depreciation_rules_update AFTER UPDATE ON depreciation_rules
+WHEN json_object('method', OLD.method, 'useful_life_years', OLD.useful_life_years,
+        'salvage_percent', OLD.salvage_percent, 'factor', OLD.factor)
+    IS NOT json_object('method', NEW.method, 'useful_life_years', NEW.useful_life_years,
+        'salvage_percent', NEW.salvage_percent, 'factor', NEW.factor)
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json, after_json)
+    VALUES ('depreciation_rules', NEW.category_id, NULL, 'update', NEW.updated_by,
+        json_object('category_id', OLD.category_id, 'method', OLD.method,
+        'useful_life_years', OLD.useful_life_years, 'salvage_percent', OLD.salvage_percent,
+        'factor', OLD.factor, 'updated_by', OLD.updated_by),
+        json_object('category_id', NEW.category_id, 'method', NEW.method,
+        'useful_life_years', NEW.useful_life_years, 'salvage_percent', NEW.salvage_percent,
+        'factor', NEW.factor, 'updated_by', NEW.updated_by));
+END;
+CREATE TRIGGER IF NOT EXISTS audit_depreciation_rules_delete AFTER DELETE ON depreciation_rules
+BEGIN
+    INSERT INTO audit_log (entity, entity_id, asset_id, operation, actor, before_json)
+    VALUES ('depreciation_rules', OLD.category_id, NULL, 'delete', NULL,
+        json_object('category_id', OLD.category_id, 'method', OLD.method,
+        'useful_life_years', OLD.useful_life_years, 'salvage_percent', OLD.salvage_percent,
+        'factor', OLD.factor, 'updated_by', OLD.updated_by));
+END;
@@ -0,0 +1,10 @@
+// Package migrations embeds the schema files applied by the db package, so
+// the binary and the tests do not depend on the working directory
+package migrations
+
+import "embed"
+
+// FS holds the schema files
+//
+//go:embed *.sql
+var FS embed.FS